@@ -4,10 +4,13 @@ import (
 	"database/sql"
 	"e-ticketing/config"
 	"e-ticketing/internal/handler"
+	"e-ticketing/internal/middleware"
+	"e-ticketing/internal/model"
 	"e-ticketing/internal/repository"
 	"e-ticketing/internal/service"
 	"fmt"
 	"log"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	_ "github.com/lib/pq"
@@ -38,14 +41,122 @@ func main() {
 
 	// Initialize repositories
 	userRepo := repository.NewUserRepository(db)
+	notifRepo := repository.NewNotificationRepository(db)
+	organizerRepo := repository.NewOrganizerRepository(db)
+	eventRepo := repository.NewEventRepository(db)
+	venueRepo := repository.NewVenueRepository(db)
+	ticketTypeRepo := repository.NewTicketTypeRepository(db)
+	inventoryRepo := repository.NewInventoryRepository(db)
+	orderRepo := repository.NewOrderRepository(db)
+	paymentRepo := repository.NewPaymentRepository(db)
+	ticketRepo := repository.NewTicketRepository(db)
+	checkInRepo := repository.NewCheckInRepository(db)
+	seatRepo := repository.NewSeatRepository(db)
+	waitingRoomRepo := repository.NewWaitingRoomRepository(db)
+	purchaseLimitRepo := repository.NewPurchaseLimitRepository(db)
+	attendeeRepo := repository.NewAttendeeRepository(db)
+	transferRepo := repository.NewTransferRepository(db)
+	resaleRepo := repository.NewResaleRepository(db)
+	waitlistRepo := repository.NewWaitlistRepository(db)
+	promoRepo := repository.NewPromoRepository(db)
+	refundRepo := repository.NewRefundRepository(db)
+	availabilityListener, err := repository.NewAvailabilityListener(dsn)
+	if err != nil {
+		log.Fatal("Failed to listen for availability changes:", err)
+	}
 
 	// Initialize services
-	emailSvc := service.NewEmailService(cfg)
-	whatsappSvc := service.NewWhatsAppService(cfg)
+	emailSender := service.NewEmailSender(cfg)
+	defer emailSender.Close()
+
+	var devOutbox *service.DevOutbox
+	if cfg.DeliveryMode == "outbox" {
+		devOutbox, err = service.NewDevOutbox(cfg.DevOutboxDir)
+		if err != nil {
+			log.Fatal("Failed to create dev outbox:", err)
+		}
+		log.Printf("📭 Notification delivery mode: outbox (%s)", cfg.DevOutboxDir)
+	}
+
+	notificationLogSvc := service.NewNotificationLogService(notifRepo, userRepo, cfg)
+	emailSvc := service.NewEmailService(cfg, emailSender, notificationLogSvc, devOutbox)
+	whatsappSvc := service.NewWhatsAppService(cfg, notificationLogSvc, devOutbox)
 	authSvc := service.NewAuthService(userRepo, emailSvc, whatsappSvc, cfg)
+	notificationSvc := service.NewNotificationService(notifRepo, emailSvc, whatsappSvc, cfg)
+	organizerSvc := service.NewOrganizerService(organizerRepo)
+	venueSvc := service.NewVenueService(venueRepo, eventRepo, organizerRepo)
+	eventSvc := service.NewEventService(eventRepo, organizerSvc, venueSvc)
+	ticketTypeSvc := service.NewTicketTypeService(ticketTypeRepo, eventSvc)
+	availabilitySvc := service.NewAvailabilityService(availabilityListener, ticketTypeSvc, cfg)
+	waitingRoomSvc := service.NewWaitingRoomService(waitingRoomRepo, eventSvc, cfg)
+	purchaseLimitSvc := service.NewPurchaseLimitService(purchaseLimitRepo, userRepo, eventSvc, cfg)
+	attendeeSvc, err := service.NewAttendeeService(attendeeRepo, eventSvc, cfg)
+	if err != nil {
+		log.Fatal("Failed to load attendee encryption keys:", err)
+	}
+	seatSvc := service.NewSeatService(seatRepo, ticketTypeRepo, userRepo, eventSvc, waitingRoomSvc, purchaseLimitSvc, cfg)
+	inventorySvc := service.NewInventoryService(inventoryRepo, userRepo, ticketTypeSvc, eventSvc, waitingRoomSvc, purchaseLimitSvc, cfg)
+	promoSvc := service.NewPromoService(promoRepo, ticketTypeSvc, eventSvc)
+	orderSvc := service.NewOrderService(orderRepo, userRepo, inventorySvc, ticketTypeSvc, eventSvc, purchaseLimitSvc, attendeeSvc, promoSvc, cfg)
+	ticketSvc := service.NewTicketService(ticketRepo, userRepo, orderSvc)
+	ticketQRSvc, err := service.NewTicketQRService(ticketSvc, eventSvc, cfg)
+	if err != nil {
+		log.Fatal("Failed to load ticket signing keys:", err)
+	}
+	checkInSvc := service.NewCheckInService(checkInRepo, userRepo, ticketSvc, ticketQRSvc, eventSvc, organizerSvc, attendeeSvc, notificationSvc)
+	transferSvc := service.NewTransferService(transferRepo, userRepo, ticketSvc, eventSvc, attendeeSvc, notificationSvc, cfg)
+
+	var paymentGateway service.PaymentGateway
+	if cfg.PaymentGateway == "midtrans" {
+		paymentGateway = service.NewMidtransGateway(cfg)
+	} else {
+		paymentGateway = service.NewSimulatorGateway(cfg)
+	}
+	log.Printf("💳 Payment gateway: %s", paymentGateway.Name())
+	refundSvc := service.NewRefundService(refundRepo, paymentRepo, userRepo, orderSvc, eventSvc, notificationSvc, paymentGateway)
+	resaleSvc := service.NewResaleService(resaleRepo, userRepo, ticketSvc, orderSvc, eventSvc, purchaseLimitSvc, attendeeSvc, refundSvc, notificationSvc, cfg)
+	waitlistSvc := service.NewWaitlistService(waitlistRepo, userRepo, ticketTypeSvc, eventSvc, purchaseLimitSvc, resaleSvc, notificationSvc, cfg)
+	userSvc := service.NewUserService(userRepo)
+	paymentSvc := service.NewPaymentService(paymentRepo, userRepo, orderSvc, ticketSvc, purchaseLimitSvc, resaleSvc, refundSvc, paymentGateway, cfg)
 
 	// Initialize handlers
-	authHandler := handler.NewAuthHandler(authSvc)
+	authHandler := handler.NewAuthHandler(authSvc, cfg)
+	notificationHandler := handler.NewNotificationHandler(notificationSvc, notificationLogSvc)
+	organizerHandler := handler.NewOrganizerHandler(organizerSvc, eventSvc)
+	eventHandler := handler.NewEventHandler(eventSvc)
+	venueHandler := handler.NewVenueHandler(venueSvc)
+	ticketTypeHandler := handler.NewTicketTypeHandler(ticketTypeSvc)
+	holdHandler := handler.NewHoldHandler(inventorySvc)
+	seatHandler := handler.NewSeatHandler(seatSvc)
+	availabilityHandler := handler.NewAvailabilityHandler(availabilitySvc)
+	waitingRoomHandler := handler.NewWaitingRoomHandler(waitingRoomSvc)
+	purchaseLimitHandler := handler.NewPurchaseLimitHandler(purchaseLimitSvc)
+	attendeeHandler := handler.NewAttendeeHandler(attendeeSvc)
+	transferHandler := handler.NewTransferHandler(transferSvc)
+	resaleHandler := handler.NewResaleHandler(resaleSvc)
+	waitlistHandler := handler.NewWaitlistHandler(waitlistSvc)
+	promoHandler := handler.NewPromoHandler(promoSvc)
+	orderHandler := handler.NewOrderHandler(orderSvc)
+	paymentHandler := handler.NewPaymentHandler(paymentSvc)
+	refundHandler := handler.NewRefundHandler(refundSvc)
+	ticketHandler := handler.NewTicketHandler(ticketSvc, ticketQRSvc)
+	checkInHandler := handler.NewCheckInHandler(checkInSvc)
+	userHandler := handler.NewUserHandler(userSvc)
+
+	// Background jobs
+	stopJobs := make(chan struct{})
+	defer close(stopJobs)
+	notificationLogSvc.StartRetentionPruner(time.Hour, stopJobs)
+	if devOutbox != nil {
+		devOutbox.StartPruner(time.Duration(cfg.DevOutboxRetentionHours)*time.Hour, time.Hour, stopJobs)
+	}
+	inventorySvc.StartHoldSweeper(time.Duration(cfg.HoldSweepIntervalSeconds)*time.Second, stopJobs)
+	orderSvc.StartExpirySweeper(time.Duration(cfg.HoldSweepIntervalSeconds)*time.Second, stopJobs)
+	transferSvc.StartExpirySweeper(time.Duration(cfg.HoldSweepIntervalSeconds)*time.Second, stopJobs)
+	resaleSvc.StartWithdrawSweeper(time.Duration(cfg.HoldSweepIntervalSeconds)*time.Second, stopJobs)
+	waitlistSvc.StartOfferSweeper(time.Duration(cfg.HoldSweepIntervalSeconds)*time.Second, stopJobs)
+	availabilitySvc.Start(stopJobs)
+	waitingRoomSvc.StartAdmitter(time.Duration(cfg.WaitingRoomTickSeconds)*time.Second, stopJobs)
 
 	// Setup Gin router
 	router := gin.Default()
@@ -55,6 +166,23 @@ func main() {
 		c.JSON(200, gin.H{"status": "ok", "message": "E-Ticketing API is running"})
 	})
 
+	// Development outbox, readable from the local machine only since it holds
+	// OTPs and verification links
+	if devOutbox != nil {
+		devHandler := handler.NewDevHandler(devOutbox)
+		router.GET("/dev/outbox", middleware.LoopbackOnly(), devHandler.Outbox)
+	}
+
+	// Payment simulator, for the payment's buyer
+	if _, ok := paymentGateway.(*service.SimulatorGateway); ok && cfg.AppEnv != "production" {
+		router.POST("/dev/payments/:id/simulate", middleware.AuthRequired(cfg.JWTSecret), paymentHandler.Simulate)
+	}
+
+	// Email queue metrics, for admins only
+	router.GET("/metrics/email", middleware.AuthRequired(cfg.JWTSecret), middleware.RequireRole(model.RoleAdmin), func(c *gin.Context) {
+		c.JSON(200, emailSender.Stats())
+	})
+
 	// API routes
 	v1 := router.Group("/api/v1")
 	{
@@ -65,6 +193,180 @@ func main() {
 			auth.POST("/verify-otp", authHandler.VerifyOTP)
 			auth.GET("/verify-email", authHandler.VerifyEmailToken)
 			auth.POST("/resend-otp", authHandler.ResendOTP)
+			auth.POST("/login", authHandler.Login)
+		}
+
+		notifications := v1.Group("/notifications")
+		{
+			notifications.GET("/unsubscribe", notificationHandler.UnsubscribePage)
+			notifications.POST("/unsubscribe", notificationHandler.Unsubscribe)
+		}
+
+		payments := v1.Group("/payments")
+		{
+			payments.POST("/notifications/:gateway", paymentHandler.Notification)
+		}
+
+		tickets := v1.Group("/tickets")
+		{
+			tickets.GET("/verification-keys", ticketHandler.VerificationKeys)
+		}
+
+		authRequired := middleware.AuthRequired(cfg.JWTSecret)
+		authOptional := middleware.AuthOptional(cfg.JWTSecret)
+
+		me := v1.Group("/me", authRequired)
+		{
+			me.GET("/notification-preferences", notificationHandler.GetPreferences)
+			me.PUT("/notification-preferences", notificationHandler.UpdatePreferences)
+			me.GET("/organizers", organizerHandler.GetMyOrganizers)
+			me.POST("/step-up", authHandler.RequestStepUp)
+			me.POST("/step-up/verify", authHandler.VerifyStepUp)
+			me.GET("/orders", orderHandler.ListMyOrders)
+			me.GET("/orders/:id", orderHandler.GetMyOrder)
+			me.POST("/orders/:id/cancel", orderHandler.CancelOrder)
+			me.POST("/orders/:id/payments", paymentHandler.CreatePayment)
+			me.POST("/orders/:id/refunds", refundHandler.RequestRefund)
+			me.GET("/refunds", refundHandler.ListMyRefunds)
+			me.GET("/refunds/:id", refundHandler.GetMyRefund)
+			me.POST("/refunds/:id/cancel", refundHandler.CancelRefund)
+			me.GET("/payments/:id", paymentHandler.GetMyPayment)
+			me.POST("/payments/:id/refresh", paymentHandler.RefreshStatus)
+			me.GET("/tickets", ticketHandler.ListMyTickets)
+			me.GET("/tickets/:id", ticketHandler.GetMyTicket)
+			me.GET("/tickets/:id/qr", ticketHandler.GetMyTicketQR)
+			me.GET("/tickets/:id/history", transferHandler.TicketHistory)
+			me.POST("/tickets/:id/transfer", transferHandler.CreateTransfer)
+			me.GET("/ticket-transfers", transferHandler.ListTransfers)
+			me.GET("/ticket-transfers/:id", transferHandler.GetTransfer)
+			me.POST("/ticket-transfers/:id/accept", transferHandler.AcceptTransfer)
+			me.POST("/ticket-transfers/:id/decline", transferHandler.DeclineTransfer)
+			me.POST("/ticket-transfers/:id/cancel", transferHandler.CancelTransfer)
+			me.POST("/tickets/:id/resale", resaleHandler.CreateListing)
+			me.GET("/resale-listings", resaleHandler.ListMyListings)
+			me.GET("/resale-listings/:id", resaleHandler.GetMyListing)
+			me.POST("/resale-listings/:id/withdraw", resaleHandler.WithdrawListing)
+			me.GET("/waitlist", waitlistHandler.ListMyEntries)
+			me.POST("/waitlist/:id/cancel", waitlistHandler.CancelEntry)
+		}
+
+		organizers := v1.Group("/organizers")
+		{
+			organizers.GET("/:id", organizerHandler.GetOrganizer)
+			organizers.POST("", authRequired, organizerHandler.CreateOrganizer)
+			organizers.PUT("/:id", authRequired, organizerHandler.UpdateOrganizer)
+			organizers.GET("/:id/events", authRequired, organizerHandler.ListEvents)
+		}
+
+		venues := v1.Group("/venues")
+		{
+			venues.GET("", venueHandler.ListVenues)
+			venues.GET("/nearby", venueHandler.NearbyVenues)
+			venues.GET("/:id", venueHandler.GetVenue)
+			venues.POST("", authRequired, venueHandler.CreateVenue)
+			venues.PUT("/:id", authRequired, venueHandler.UpdateVenue)
+			venues.DELETE("/:id", authRequired, venueHandler.DeleteVenue)
+		}
+
+		events := v1.Group("/events")
+		{
+			events.GET("", eventHandler.ListEvents)
+			events.GET("/:id", eventHandler.GetEvent)
+			events.POST("", authRequired, eventHandler.CreateEvent)
+			events.PUT("/:id", authRequired, eventHandler.UpdateEvent)
+			events.PATCH("/:id/status", authRequired, eventHandler.UpdateEventStatus)
+			events.DELETE("/:id", authRequired, eventHandler.DeleteEvent)
+
+			events.GET("/:id/ticket-types", authOptional, ticketTypeHandler.ListTicketTypes)
+			events.POST("/:id/ticket-types", authRequired, ticketTypeHandler.CreateTicketType)
+			events.PUT("/:id/ticket-types/:ticketTypeId", authRequired, ticketTypeHandler.UpdateTicketType)
+			events.DELETE("/:id/ticket-types/:ticketTypeId", authRequired, ticketTypeHandler.DeleteTicketType)
+
+			events.GET("/:id/availability/stream", authOptional, availabilityHandler.Stream)
+			events.GET("/:id/seatmap", authOptional, seatHandler.GetSeatMap)
+			events.POST("/:id/seatmap", authRequired, seatHandler.ImportSeatMap)
+			events.POST("/:id/seat-holds", authRequired, seatHandler.CreateSeatHold)
+
+			events.GET("/:id/waiting-room", authRequired, waitingRoomHandler.GetStats)
+			events.PUT("/:id/waiting-room", authRequired, waitingRoomHandler.Configure)
+			events.POST("/:id/waiting-room/pause", authRequired, waitingRoomHandler.Pause)
+			events.POST("/:id/waiting-room/resume", authRequired, waitingRoomHandler.Resume)
+			events.POST("/:id/queue", authRequired, waitingRoomHandler.JoinQueue)
+			events.GET("/:id/queue", authRequired, waitingRoomHandler.GetQueueTicket)
+
+			events.GET("/:id/purchase-policy", authOptional, purchaseLimitHandler.GetPolicy)
+			events.PUT("/:id/purchase-policy", authRequired, purchaseLimitHandler.ConfigurePolicy)
+			events.GET("/:id/purchase-clusters", authRequired, purchaseLimitHandler.ListClusters)
+			events.GET("/:id/attendee-requirements", authOptional, attendeeHandler.GetRequirements)
+			events.PUT("/:id/attendee-requirements", authRequired, attendeeHandler.ConfigureRequirements)
+			events.GET("/:id/transfer-policy", authOptional, transferHandler.GetPolicy)
+			events.PUT("/:id/transfer-policy", authRequired, transferHandler.ConfigurePolicy)
+			events.GET("/:id/tickets/:ticketId/history", authRequired, transferHandler.EventTicketHistory)
+			events.GET("/:id/resale-policy", authOptional, resaleHandler.GetPolicy)
+			events.PUT("/:id/resale-policy", authRequired, resaleHandler.ConfigurePolicy)
+			events.GET("/:id/refund-policy", authOptional, refundHandler.GetPolicy)
+			events.PUT("/:id/refund-policy", authRequired, refundHandler.ConfigurePolicy)
+			events.GET("/:id/refunds", authRequired, refundHandler.ListEventRefunds)
+			events.GET("/:id/refunds/:refundId", authRequired, refundHandler.GetEventRefund)
+			events.POST("/:id/refunds/:refundId/approve", authRequired, refundHandler.ApproveRefund)
+			events.POST("/:id/refunds/:refundId/reject", authRequired, refundHandler.RejectRefund)
+			events.GET("/:id/resale-listings", authOptional, resaleHandler.ListOffers)
+			events.POST("/:id/waitlist", authRequired, waitlistHandler.JoinWaitlist)
+			events.GET("/:id/waitlist", authRequired, waitlistHandler.EventStats)
+			events.GET("/:id/promotions", authRequired, promoHandler.ListPromotions)
+			events.POST("/:id/promotions", authRequired, promoHandler.CreatePromotion)
+			events.GET("/:id/promotions/:promotionId", authRequired, promoHandler.GetPromotion)
+			events.PUT("/:id/promotions/:promotionId", authRequired, promoHandler.UpdatePromotion)
+			events.GET("/:id/promotions/:promotionId/codes", authRequired, promoHandler.ListCodes)
+			events.POST("/:id/promotions/:promotionId/codes", authRequired, promoHandler.CreateCode)
+			events.POST("/:id/promotions/:promotionId/codes/generate", authRequired, promoHandler.GenerateCodes)
+
+			events.GET("/:id/gate-devices", authRequired, checkInHandler.ListDevices)
+			events.POST("/:id/gate-devices", authRequired, checkInHandler.RegisterDevice)
+			events.DELETE("/:id/gate-devices/:deviceId", authRequired, checkInHandler.DeactivateDevice)
+			events.GET("/:id/attendance", authRequired, checkInHandler.Attendance)
+			events.GET("/:id/suspicious-scans", authRequired, checkInHandler.SuspiciousScans)
+		}
+
+		holds := v1.Group("/holds", authRequired)
+		{
+			holds.POST("", holdHandler.CreateHold)
+			holds.GET("/:id", holdHandler.GetHold)
+			holds.DELETE("/:id", holdHandler.ReleaseHold)
+		}
+
+		orders := v1.Group("/orders", authRequired)
+		{
+			orders.POST("", orderHandler.CreateOrder)
+			orders.POST("/preview", orderHandler.PreviewOrder)
+		}
+
+		resale := v1.Group("/resale-listings", authRequired)
+		{
+			resale.POST("/:id/orders", resaleHandler.BuyListing)
+		}
+
+		waitlist := v1.Group("/waitlist")
+		{
+			waitlist.GET("/offer", waitlistHandler.GetOffer)
+			waitlist.POST("/claim", authRequired, waitlistHandler.ClaimOffer)
+		}
+
+		checkin := v1.Group("/checkin", authRequired, middleware.RequireRole(model.RoleCheckin, model.RoleAdmin))
+		{
+			checkin.POST("/scan", checkInHandler.Scan)
+			checkin.GET("/devices/:deviceId/manifest", checkInHandler.Manifest)
+			checkin.POST("/sync", checkInHandler.SyncScans)
+		}
+
+		admin := v1.Group("/admin", authRequired, middleware.RequireRole(model.RoleAdmin))
+		{
+			admin.GET("/notifications", notificationHandler.SearchLogs)
+			admin.PUT("/users/:id/role", userHandler.UpdateUserRole)
+			admin.GET("/resale-payouts", resaleHandler.ListPayouts)
+			admin.POST("/resale-listings/:id/payout", resaleHandler.MarkPayoutPaid)
+			admin.GET("/refunds", refundHandler.ListRefunds)
+			admin.POST("/refunds/:id/complete", refundHandler.CompleteRefund)
 		}
 	}
 
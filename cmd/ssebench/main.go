@@ -0,0 +1,157 @@
+// Command ssebench load tests the availability stream by holding many
+// concurrent subscribers open and reporting delivery latency.
+//
+//	go run ./cmd/ssebench -url http://localhost:8080/api/v1/events/<id>/availability/stream -n 5000 -duration 1m
+//
+// Generate traffic meanwhile (holds, orders, seat picks) to see events flow.
+// Raise the open file limit (ulimit -n) on both ends for large -n.
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type stats struct {
+	connected atomic.Int64
+	failed    atomic.Int64
+	dropped   atomic.Int64
+	events    atomic.Int64
+	resyncs   atomic.Int64
+
+	mu        sync.Mutex
+	latencies []time.Duration
+}
+
+func (s *stats) observe(latency time.Duration) {
+	s.mu.Lock()
+	s.latencies = append(s.latencies, latency)
+	s.mu.Unlock()
+}
+
+func main() {
+	url := flag.String("url", "", "availability stream URL")
+	subscribers := flag.Int("n", 1000, "concurrent subscribers")
+	duration := flag.Duration("duration", 30*time.Second, "how long to keep the streams open")
+	ramp := flag.Duration("ramp", time.Millisecond, "delay between opening connections")
+	token := flag.String("token", "", "optional bearer token")
+	flag.Parse()
+
+	if *url == "" {
+		log.Fatal("-url is required")
+	}
+
+	client := &http.Client{Transport: &http.Transport{
+		MaxIdleConnsPerHost: *subscribers,
+		DisableCompression:  true,
+	}}
+
+	st := &stats{}
+	deadline := time.Now().Add(*duration)
+	var wg sync.WaitGroup
+
+	go func() {
+		ticker := time.NewTicker(5 * time.Second)
+		defer ticker.Stop()
+		for range ticker.C {
+			log.Printf("connected=%d failed=%d dropped=%d events=%d resyncs=%d",
+				st.connected.Load(), st.failed.Load(), st.dropped.Load(), st.events.Load(), st.resyncs.Load())
+		}
+	}()
+
+	for i := 0; i < *subscribers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			subscribe(client, *url, *token, deadline, st)
+		}()
+		time.Sleep(*ramp)
+	}
+	wg.Wait()
+
+	report(st, *subscribers)
+}
+
+func subscribe(client *http.Client, url, token string, deadline time.Time, st *stats) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		st.failed.Add(1)
+		return
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		st.failed.Add(1)
+		return
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		st.failed.Add(1)
+		return
+	}
+	st.connected.Add(1)
+
+	// Close the body at the deadline to end the read loop
+	timer := time.AfterFunc(time.Until(deadline), func() { resp.Body.Close() })
+	defer timer.Stop()
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	name := ""
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "event:"):
+			name = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+		case strings.HasPrefix(line, "data:"):
+			if name == "snapshot" {
+				continue
+			}
+			st.events.Add(1)
+			if name == "resync" {
+				st.resyncs.Add(1)
+			}
+			var payload struct {
+				At time.Time `json:"at"`
+			}
+			if err := json.Unmarshal([]byte(strings.TrimPrefix(line, "data:")), &payload); err == nil && !payload.At.IsZero() {
+				st.observe(time.Since(payload.At))
+			}
+		case line == "":
+			name = ""
+		}
+	}
+	if time.Now().Before(deadline) {
+		st.dropped.Add(1)
+	}
+}
+
+func report(st *stats, subscribers int) {
+	fmt.Printf("subscribers: %d requested, %d connected, %d failed, %d dropped early\n",
+		subscribers, st.connected.Load(), st.failed.Load(), st.dropped.Load())
+	fmt.Printf("events received: %d (%d resyncs)\n", st.events.Load(), st.resyncs.Load())
+
+	latencies := st.latencies
+	if len(latencies) == 0 {
+		return
+	}
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	percentile := func(p float64) time.Duration {
+		return latencies[int(float64(len(latencies)-1)*p)]
+	}
+	fmt.Printf("delivery latency: p50=%v p95=%v p99=%v max=%v\n",
+		percentile(0.50), percentile(0.95), percentile(0.99), latencies[len(latencies)-1])
+}
@@ -1,64 +1,252 @@
 package config
 
 import (
+	"errors"
+	"fmt"
 	"os"
 	"strconv"
+	"strings"
 
 	"github.com/joho/godotenv"
 )
 
 type Config struct {
-	Port             string
-	AppEnv           string
-	DBHost           string
-	DBPort           string
-	DBUser           string
-	DBPassword       string
-	DBName           string
-	JWTSecret        string
-	SMTPHost         string
-	SMTPPort         int
-	SMTPUser         string
-	SMTPPassword     string
-	SMTPFrom         string
-	WAAPIUrl         string
-	WAAPIToken       string
-	OTPExpiryMinutes int
+	Port              string
+	AppEnv            string
+	AppBaseURL        string
+	VerifyRedirectURL string
+	MobileDeepLink    string
+	DBHost            string
+	DBPort            string
+	DBUser            string
+	DBPassword        string
+	DBName            string
+	JWTSecret         string
+	JWTExpiryHours    int
+	SMTPHost          string
+	SMTPPort          int
+	SMTPUser          string
+	SMTPPassword      string
+	SMTPFrom          string
+	SMTPPoolSize      int
+	SMTPQueueSize     int
+	SMTPBatchSize     int
+	SMTPRateLimit     int
+	SMTPSendTimeout   int
+	WAAPIUrl          string
+	WAAPIToken        string
+	OTPExpiryMinutes  int
+
+	NotificationLogRetentionDays int
+
+	// IdentifierHashKey keys the HMAC of emails, phones, devices and payment
+	// instruments kept for lookups. Changing it orphans existing hashes.
+	IdentifierHashKey string
+
+	// DeliveryMode is "live" (real SMTP/WhatsApp) or "outbox" (write messages
+	// to DevOutboxDir). Outbox mode must be asked for, or APP_ENV set to
+	// development, and is never allowed in production. Outbox messages are
+	// deleted after DevOutboxRetentionHours.
+	DeliveryMode            string
+	DevOutboxDir            string
+	DevOutboxRetentionHours int
+
+	HoldDurationMinutes      int
+	HoldSweepIntervalSeconds int
+	OrderPaymentMinutes      int
+
+	// PaymentGateway selects the provider: "simulator" or "midtrans"
+	PaymentGateway    string
+	MidtransServerKey string
+	MidtransSnapURL   string
+	MidtransAPIURL    string
+
+	// Ticket QR codes are signed with TicketSigningKeys[TicketSigningKeyID].
+	// Keys are "kid:base64" lists; rotate by adding a new signing key and
+	// moving the old one's public half to TicketVerifyKeys.
+	TicketSigningKeyID    string
+	TicketSigningKeys     map[string]string
+	TicketVerifyKeys      map[string]string
+	TicketQRValidityHours int
+
+	// Availability streams: events buffered per subscriber before it is told
+	// to resync, and the subscriber cap per event
+	AvailabilityStreamBuffer           int
+	AvailabilityMaxSubscribersPerEvent int
+
+	// WaitingRoomTickSeconds is how often queued buyers are admitted
+	WaitingRoomTickSeconds int
+
+	// Attendee ID numbers are encrypted with AttendeeKeys[AttendeeKeyID].
+	// Keys are "kid:base64" lists of 32-byte AES keys; keep old keys listed
+	// after rotating so existing numbers can still be read.
+	AttendeeKeyID string
+	AttendeeKeys  map[string]string
+
+	// ResaleFeePercent is the platform fee kept from each resale payout
+	ResaleFeePercent int
+
+	// WaitlistOfferMinutes is how long a waitlisted buyer has to claim
+	// freed tickets before they go to the next in line
+	WaitlistOfferMinutes int
 }
 
 var AppConfig *Config
 
+// defaultJWTSecret is the JWT_SECRET fallback; it is public, so nothing
+// that must not be forged may rely on it.
+const defaultJWTSecret = "secret"
+
 func LoadConfig() (*Config, error) {
 	godotenv.Load()
 
 	smtpPort, _ := strconv.Atoi(getEnv("SMTP_PORT", "587"))
+	jwtExpiry, _ := strconv.Atoi(getEnv("JWT_EXPIRY_HOURS", "24"))
 	otpExpiry, _ := strconv.Atoi(getEnv("OTP_EXPIRY_MINUTES", "5"))
+	logRetention, _ := strconv.Atoi(getEnv("NOTIFICATION_LOG_RETENTION_DAYS", "90"))
+	smtpPoolSize, _ := strconv.Atoi(getEnv("SMTP_POOL_SIZE", "4"))
+	smtpQueueSize, _ := strconv.Atoi(getEnv("SMTP_QUEUE_SIZE", "1000"))
+	smtpBatchSize, _ := strconv.Atoi(getEnv("SMTP_BATCH_SIZE", "20"))
+	smtpRateLimit, _ := strconv.Atoi(getEnv("SMTP_RATE_LIMIT", "10"))
+	smtpSendTimeout, _ := strconv.Atoi(getEnv("SMTP_SEND_TIMEOUT_SECONDS", "30"))
+
+	jwtSecret := getEnv("JWT_SECRET", defaultJWTSecret)
+	appEnv := getEnv("APP_ENV", "development")
+	// Development defaults (outbox delivery, simulated payments) only apply
+	// when APP_ENV says so, not to deployments that leave it unset
+	explicitDev := os.Getenv("APP_ENV") == "development"
+	defaultDeliveryMode := "live"
+	if explicitDev {
+		defaultDeliveryMode = "outbox"
+	}
+	deliveryMode := getEnv("NOTIFICATION_DELIVERY_MODE", defaultDeliveryMode)
+	if appEnv == "production" {
+		deliveryMode = "live"
+	}
+	outboxRetention, _ := strconv.Atoi(getEnv("DEV_OUTBOX_RETENTION_HOURS", "24"))
+
+	holdDuration, _ := strconv.Atoi(getEnv("HOLD_DURATION_MINUTES", "10"))
+	holdSweepInterval, _ := strconv.Atoi(getEnv("HOLD_SWEEP_INTERVAL_SECONDS", "30"))
+	orderPayment, _ := strconv.Atoi(getEnv("ORDER_PAYMENT_MINUTES", "30"))
+
+	qrValidity, _ := strconv.Atoi(getEnv("TICKET_QR_VALIDITY_HOURS", "24"))
+
+	streamBuffer, _ := strconv.Atoi(getEnv("AVAILABILITY_STREAM_BUFFER", "64"))
+	maxSubscribers, _ := strconv.Atoi(getEnv("AVAILABILITY_MAX_SUBSCRIBERS_PER_EVENT", "20000"))
+	waitingRoomTick, _ := strconv.Atoi(getEnv("WAITING_ROOM_TICK_SECONDS", "2"))
+	resaleFee, _ := strconv.Atoi(getEnv("RESALE_FEE_PERCENT", "10"))
+	waitlistOffer, _ := strconv.Atoi(getEnv("WAITLIST_OFFER_MINUTES", "30"))
+
+	defaultGateway := "midtrans"
+	if explicitDev {
+		defaultGateway = "simulator"
+	}
 
 	AppConfig = &Config{
-		Port:             getEnv("PORT", "8080"),
-		AppEnv:           getEnv("APP_ENV", "development"),
-		DBHost:           getEnv("DB_HOST", "localhost"),
-		DBPort:           getEnv("DB_PORT", "5432"),
-		DBUser:           getEnv("DB_USER", "postgres"),
-		DBPassword:       getEnv("DB_PASSWORD", ""),
-		DBName:           getEnv("DB_NAME", "e_ticketing"),
-		JWTSecret:        getEnv("JWT_SECRET", "secret"),
-		SMTPHost:         getEnv("SMTP_HOST", "smtp.gmail.com"),
-		SMTPPort:         smtpPort,
-		SMTPUser:         getEnv("SMTP_USER", ""),
-		SMTPPassword:     getEnv("SMTP_PASSWORD", ""),
-		SMTPFrom:         getEnv("SMTP_FROM", ""),
-		WAAPIUrl:         getEnv("WA_API_URL", ""),
-		WAAPIToken:       getEnv("WA_API_TOKEN", ""),
-		OTPExpiryMinutes: otpExpiry,
+		Port:              getEnv("PORT", "8080"),
+		AppEnv:            appEnv,
+		AppBaseURL:        strings.TrimRight(getEnv("APP_BASE_URL", "http://localhost:8080"), "/"),
+		VerifyRedirectURL: getEnv("EMAIL_VERIFY_REDIRECT_URL", ""),
+		MobileDeepLink:    getEnv("MOBILE_DEEP_LINK_URL", ""),
+		DBHost:            getEnv("DB_HOST", "localhost"),
+		DBPort:            getEnv("DB_PORT", "5432"),
+		DBUser:            getEnv("DB_USER", "postgres"),
+		DBPassword:        getEnv("DB_PASSWORD", ""),
+		DBName:            getEnv("DB_NAME", "e_ticketing"),
+		JWTSecret:         jwtSecret,
+		JWTExpiryHours:    jwtExpiry,
+		SMTPHost:          getEnv("SMTP_HOST", "smtp.gmail.com"),
+		SMTPPort:          smtpPort,
+		SMTPUser:          getEnv("SMTP_USER", ""),
+		SMTPPassword:      getEnv("SMTP_PASSWORD", ""),
+		SMTPFrom:          getEnv("SMTP_FROM", ""),
+		SMTPPoolSize:      smtpPoolSize,
+		SMTPQueueSize:     smtpQueueSize,
+		SMTPBatchSize:     smtpBatchSize,
+		SMTPRateLimit:     smtpRateLimit,
+		SMTPSendTimeout:   smtpSendTimeout,
+		WAAPIUrl:          getEnv("WA_API_URL", ""),
+		WAAPIToken:        getEnv("WA_API_TOKEN", ""),
+		OTPExpiryMinutes:  otpExpiry,
+
+		NotificationLogRetentionDays: logRetention,
+
+		IdentifierHashKey: getEnv("IDENTIFIER_HASH_KEY", jwtSecret),
+
+		DeliveryMode:            deliveryMode,
+		DevOutboxDir:            getEnv("DEV_OUTBOX_DIR", "tmp/outbox"),
+		DevOutboxRetentionHours: outboxRetention,
+
+		HoldDurationMinutes:      holdDuration,
+		HoldSweepIntervalSeconds: holdSweepInterval,
+		OrderPaymentMinutes:      orderPayment,
+
+		PaymentGateway:    getEnv("PAYMENT_GATEWAY", defaultGateway),
+		MidtransServerKey: getEnv("MIDTRANS_SERVER_KEY", ""),
+		MidtransSnapURL:   strings.TrimRight(getEnv("MIDTRANS_SNAP_URL", "https://app.sandbox.midtrans.com"), "/"),
+		MidtransAPIURL:    strings.TrimRight(getEnv("MIDTRANS_API_URL", "https://api.sandbox.midtrans.com"), "/"),
+
+		TicketSigningKeyID:    getEnv("TICKET_SIGNING_KEY_ID", ""),
+		TicketSigningKeys:     parseKeyList(getEnv("TICKET_SIGNING_KEYS", "")),
+		TicketVerifyKeys:      parseKeyList(getEnv("TICKET_VERIFY_KEYS", "")),
+		TicketQRValidityHours: qrValidity,
+
+		AvailabilityStreamBuffer:           streamBuffer,
+		AvailabilityMaxSubscribersPerEvent: maxSubscribers,
+
+		WaitingRoomTickSeconds: waitingRoomTick,
+
+		AttendeeKeyID: getEnv("ATTENDEE_ENCRYPTION_KEY_ID", ""),
+		AttendeeKeys:  parseKeyList(getEnv("ATTENDEE_ENCRYPTION_KEYS", "")),
+
+		ResaleFeePercent: resaleFee,
+
+		WaitlistOfferMinutes: waitlistOffer,
 	}
 
+	if err := AppConfig.validatePaymentGateway(); err != nil {
+		return nil, err
+	}
 	return AppConfig, nil
 }
 
+// validatePaymentGateway refuses gateway setups whose payment callbacks
+// anyone could sign: Midtrans without a server key, or the simulator keyed
+// by the default JWT secret.
+func (c *Config) validatePaymentGateway() error {
+	switch c.PaymentGateway {
+	case "midtrans":
+		if c.MidtransServerKey == "" {
+			return errors.New("MIDTRANS_SERVER_KEY wajib diisi untuk payment gateway midtrans")
+		}
+	case "simulator":
+		if c.AppEnv == "production" {
+			return errors.New("payment gateway simulator tidak boleh dipakai di production")
+		}
+		if c.JWTSecret == defaultJWTSecret {
+			return errors.New("JWT_SECRET wajib diganti dari nilai bawaan untuk payment gateway simulator")
+		}
+	default:
+		return fmt.Errorf("PAYMENT_GATEWAY tidak dikenal: %s", c.PaymentGateway)
+	}
+	return nil
+}
+
 func getEnv(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
 		return value
 	}
 	return defaultValue
-};
\ No newline at end of file
+}
+
+// parseKeyList parses "kid1:value1,kid2:value2" into a map.
+func parseKeyList(value string) map[string]string {
+	keys := map[string]string{}
+	for _, entry := range strings.Split(value, ",") {
+		kid, key, ok := strings.Cut(strings.TrimSpace(entry), ":")
+		if ok && kid != "" && key != "" {
+			keys[kid] = key
+		}
+	}
+	return keys
+}
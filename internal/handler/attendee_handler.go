@@ -0,0 +1,54 @@
+package handler
+
+import (
+	"e-ticketing/internal/model"
+	"e-ticketing/internal/service"
+	"e-ticketing/pkg/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AttendeeHandler struct {
+	attendeeService *service.AttendeeService
+}
+
+func NewAttendeeHandler(attendeeService *service.AttendeeService) *AttendeeHandler {
+	return &AttendeeHandler{attendeeService: attendeeService}
+}
+
+func (h *AttendeeHandler) GetRequirements(c *gin.Context) {
+	eventID, ok := paramUUID(c, "id")
+	if !ok {
+		return
+	}
+
+	requirements, err := h.attendeeService.GetRequirements(currentActor(c), eventID)
+	if err != nil {
+		serviceError(c, "Gagal mengambil data peserta yang diwajibkan", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Data peserta yang diwajibkan", requirements)
+}
+
+func (h *AttendeeHandler) ConfigureRequirements(c *gin.Context) {
+	eventID, ok := paramUUID(c, "id")
+	if !ok {
+		return
+	}
+
+	var req model.ConfigureAttendeeRequirementsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Validasi gagal", err.Error())
+		return
+	}
+
+	requirements, err := h.attendeeService.ConfigureRequirements(currentActor(c), eventID, &req)
+	if err != nil {
+		serviceError(c, "Gagal mengatur data peserta", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Data peserta berhasil diatur", requirements)
+}
@@ -1,20 +1,27 @@
 package handler
 
 import (
+	"e-ticketing/config"
 	"e-ticketing/internal/model"
 	"e-ticketing/internal/service"
 	"e-ticketing/pkg/utils"
+	"errors"
+	"html/template"
+	"log"
 	"net/http"
+	"net/url"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 )
 
 type AuthHandler struct {
 	authService *service.AuthService
+	config      *config.Config
 }
 
-func NewAuthHandler(authService *service.AuthService) *AuthHandler {
-	return &AuthHandler{authService: authService}
+func NewAuthHandler(authService *service.AuthService, cfg *config.Config) *AuthHandler {
+	return &AuthHandler{authService: authService, config: cfg}
 }
 
 func (h *AuthHandler) Register(c *gin.Context) {
@@ -42,14 +49,7 @@ func (h *AuthHandler) SelectVerificationMethod(c *gin.Context) {
 		return
 	}
 
-	baseURL := c.Request.Host
-	scheme := "http"
-	if c.Request.TLS != nil {
-		scheme = "https"
-	}
-	fullBaseURL := scheme + "://" + baseURL
-
-	err := h.authService.SelectVerificationMethod(&req, fullBaseURL)
+	err := h.authService.SelectVerificationMethod(&req, h.config.AppBaseURL)
 	if err != nil {
 		utils.ErrorResponse(c, http.StatusBadRequest, "Gagal mengirim OTP", err.Error())
 		return
@@ -81,24 +81,126 @@ func (h *AuthHandler) VerifyOTP(c *gin.Context) {
 	})
 }
 
+// VerifyEmailToken is opened from the link in the verification email, so by
+// default it answers with a browser-friendly result: a redirect to the
+// configured frontend (or the mobile app when ?client=app), or a plain HTML
+// page. API clients asking for application/json still get the JSON envelope.
 func (h *AuthHandler) VerifyEmailToken(c *gin.Context) {
 	token := c.Query("token")
+	wantsJSON := strings.Contains(c.GetHeader("Accept"), "application/json")
+
 	if token == "" {
-		utils.ErrorResponse(c, http.StatusBadRequest, "Token tidak ditemukan", "Token is required")
+		if wantsJSON {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Token tidak ditemukan", "Token is required")
+			return
+		}
+		h.renderVerifyEmailResult(c, false, "Token verifikasi tidak ditemukan")
 		return
 	}
 
 	response, err := h.authService.VerifyEmailToken(token)
+	if wantsJSON {
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Verifikasi gagal", err.Error())
+			return
+		}
+		utils.SuccessResponse(c, http.StatusOK, response.Message, gin.H{
+			"verified": response.Success,
+		})
+		return
+	}
+
 	if err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, "Verifikasi gagal", err.Error())
+		h.renderVerifyEmailResult(c, false, verifyEmailFailure(err))
 		return
 	}
+	h.renderVerifyEmailResult(c, true, response.Message)
+}
+
+// verifyEmailFailure picks the fixed reason shown for a failed verification.
+// The page and the redirect to the frontend or app never carry the raw
+// error, which may be a database error.
+func verifyEmailFailure(err error) string {
+	if errors.Is(err, service.ErrInvalidVerificationToken) {
+		return "Link verifikasi tidak valid atau sudah kedaluwarsa"
+	}
+	log.Printf("Email verification failed: %v", err)
+	return "Verifikasi gagal karena kendala sistem, silakan coba lagi nanti"
+}
 
-	utils.SuccessResponse(c, http.StatusOK, response.Message, gin.H{
-		"verified": response.Success,
+func (h *AuthHandler) renderVerifyEmailResult(c *gin.Context, success bool, message string) {
+	status := "failed"
+	if success {
+		status = "success"
+	}
+
+	target := h.config.VerifyRedirectURL
+	if c.Query("client") == "app" && h.config.MobileDeepLink != "" {
+		target = h.config.MobileDeepLink
+	}
+	if target != "" {
+		if redirect, err := appendQuery(target, status, message); err == nil {
+			c.Redirect(http.StatusFound, redirect)
+			return
+		}
+	}
+
+	var appLink string
+	if h.config.MobileDeepLink != "" {
+		appLink, _ = appendQuery(h.config.MobileDeepLink, status, message)
+	}
+
+	httpStatus := http.StatusOK
+	if !success {
+		httpStatus = http.StatusBadRequest
+	}
+
+	c.Status(httpStatus)
+	c.Header("Content-Type", "text/html; charset=utf-8")
+	verifyEmailPage.Execute(c.Writer, gin.H{
+		"Success": success,
+		"Message": message,
+		"AppLink": template.URL(appLink),
 	})
 }
 
+func appendQuery(target, status, message string) (string, error) {
+	u, err := url.Parse(target)
+	if err != nil {
+		return "", err
+	}
+	q := u.Query()
+	q.Set("status", status)
+	q.Set("message", message)
+	u.RawQuery = q.Encode()
+	return u.String(), nil
+}
+
+var verifyEmailPage = template.Must(template.New("verify-email").Parse(`<!DOCTYPE html>
+<html lang="id">
+<head>
+	<meta charset="utf-8">
+	<meta name="viewport" content="width=device-width, initial-scale=1">
+	<title>Verifikasi Email - E-Ticketing</title>
+</head>
+<body style="font-family: Arial, sans-serif; padding: 20px; text-align: center;">
+	{{if .Success}}
+	<h2 style="color: #4CAF50;">Verifikasi Berhasil</h2>
+	{{else}}
+	<h2 style="color: #E53935;">Verifikasi Gagal</h2>
+	{{end}}
+	<p>{{.Message}}</p>
+	{{if .AppLink}}
+	<div style="margin: 30px 0;">
+		<a href="{{.AppLink}}" style="background-color: #4CAF50; color: white; padding: 15px 30px; text-decoration: none; border-radius: 5px; font-size: 16px;">
+			Buka Aplikasi
+		</a>
+	</div>
+	{{end}}
+	<p>Salam,<br>Tim E-Ticketing</p>
+</body>
+</html>`))
+
 func (h *AuthHandler) ResendOTP(c *gin.Context) {
 	var req model.ResendOTPRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -106,18 +208,58 @@ func (h *AuthHandler) ResendOTP(c *gin.Context) {
 		return
 	}
 
-	baseURL := c.Request.Host
-	scheme := "http"
-	if c.Request.TLS != nil {
-		scheme = "https"
-	}
-	fullBaseURL := scheme + "://" + baseURL
-
-	err := h.authService.ResendOTP(&req, fullBaseURL)
+	err := h.authService.ResendOTP(&req, h.config.AppBaseURL)
 	if err != nil {
 		utils.ErrorResponse(c, http.StatusBadRequest, "Gagal mengirim ulang OTP", err.Error())
 		return
 	}
 
 	utils.SuccessResponse(c, http.StatusOK, "OTP berhasil dikirim ulang via "+req.Method, nil)
-}
\ No newline at end of file
+}
+
+func (h *AuthHandler) RequestStepUp(c *gin.Context) {
+	var req model.RequestStepUpRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Validasi gagal", err.Error())
+		return
+	}
+
+	if err := h.authService.RequestStepUp(currentActor(c), &req); err != nil {
+		serviceError(c, "Gagal mengirim OTP", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "OTP telah dikirim", nil)
+}
+
+func (h *AuthHandler) VerifyStepUp(c *gin.Context) {
+	var req model.VerifyStepUpRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Validasi gagal", err.Error())
+		return
+	}
+
+	response, err := h.authService.VerifyStepUp(currentActor(c), &req)
+	if err != nil {
+		serviceError(c, "Verifikasi gagal", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Verifikasi berhasil", response)
+}
+
+func (h *AuthHandler) Login(c *gin.Context) {
+	var req model.LoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Validasi gagal", err.Error())
+		return
+	}
+
+	response, err := h.authService.Login(&req)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "Login gagal", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Login berhasil", response)
+}
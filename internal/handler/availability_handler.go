@@ -0,0 +1,83 @@
+package handler
+
+import (
+	"e-ticketing/internal/model"
+	"e-ticketing/internal/service"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// streamHeartbeat keeps proxies from closing idle streams
+	streamHeartbeat = 15 * time.Second
+	// streamWriteTimeout drops clients that stop reading altogether
+	streamWriteTimeout = 10 * time.Second
+)
+
+type AvailabilityHandler struct {
+	availabilityService *service.AvailabilityService
+}
+
+func NewAvailabilityHandler(availabilityService *service.AvailabilityService) *AvailabilityHandler {
+	return &AvailabilityHandler{availabilityService: availabilityService}
+}
+
+// Stream sends availability changes of an event as Server-Sent Events: a
+// "snapshot" of ticket type stock first, then "ticket_type", "seat",
+// "seatmap" and "resync" events as they happen.
+func (h *AvailabilityHandler) Stream(c *gin.Context) {
+	eventID, ok := paramUUID(c, "id")
+	if !ok {
+		return
+	}
+
+	sub, snapshot, err := h.availabilityService.Subscribe(currentActor(c), eventID)
+	if err != nil {
+		serviceError(c, "Gagal membuka stream ketersediaan", err)
+		return
+	}
+	defer h.availabilityService.Unsubscribe(sub)
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	rc := http.NewResponseController(c.Writer)
+	send := func(write func()) bool {
+		rc.SetWriteDeadline(time.Now().Add(streamWriteTimeout))
+		write()
+		return rc.Flush() == nil
+	}
+
+	if !send(func() { c.SSEvent("snapshot", snapshot) }) {
+		return
+	}
+
+	heartbeat := time.NewTicker(streamHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case event := <-sub.Events():
+			if !send(func() { c.SSEvent(event.Type, event) }) {
+				return
+			}
+		case <-sub.Lagged():
+			sub.Drain()
+			resync := model.AvailabilityEvent{EventID: eventID, Type: model.AvailabilityResync, At: time.Now()}
+			if !send(func() { c.SSEvent(resync.Type, resync) }) {
+				return
+			}
+		case <-heartbeat.C:
+			if !send(func() { c.Writer.WriteString(": ping\n\n") }) {
+				return
+			}
+		case <-c.Request.Context().Done():
+			return
+		}
+	}
+}
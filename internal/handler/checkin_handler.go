@@ -0,0 +1,162 @@
+package handler
+
+import (
+	"e-ticketing/internal/model"
+	"e-ticketing/internal/service"
+	"e-ticketing/pkg/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CheckInHandler struct {
+	checkInService *service.CheckInService
+}
+
+func NewCheckInHandler(checkInService *service.CheckInService) *CheckInHandler {
+	return &CheckInHandler{checkInService: checkInService}
+}
+
+func (h *CheckInHandler) RegisterDevice(c *gin.Context) {
+	eventID, ok := paramUUID(c, "id")
+	if !ok {
+		return
+	}
+
+	var req model.RegisterGateDeviceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Validasi gagal", err.Error())
+		return
+	}
+
+	device, err := h.checkInService.RegisterDevice(currentActor(c), eventID, &req)
+	if err != nil {
+		serviceError(c, "Gagal mendaftarkan perangkat gate", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "Perangkat gate berhasil didaftarkan", device)
+}
+
+func (h *CheckInHandler) ListDevices(c *gin.Context) {
+	eventID, ok := paramUUID(c, "id")
+	if !ok {
+		return
+	}
+
+	devices, err := h.checkInService.ListDevices(currentActor(c), eventID)
+	if err != nil {
+		serviceError(c, "Gagal mengambil daftar perangkat gate", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Daftar perangkat gate", devices)
+}
+
+func (h *CheckInHandler) DeactivateDevice(c *gin.Context) {
+	eventID, ok := paramUUID(c, "id")
+	if !ok {
+		return
+	}
+	deviceID, ok := paramUUID(c, "deviceId")
+	if !ok {
+		return
+	}
+
+	if err := h.checkInService.DeactivateDevice(currentActor(c), eventID, deviceID); err != nil {
+		serviceError(c, "Gagal menonaktifkan perangkat gate", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Perangkat gate dinonaktifkan", nil)
+}
+
+// Scan answers 200 for an admitted ticket, 409 for a ticket that was already
+// scanned and 422 for one that cannot be admitted; the body always carries
+// the scan result for the gate display.
+func (h *CheckInHandler) Scan(c *gin.Context) {
+	var req model.ScanRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Validasi gagal", err.Error())
+		return
+	}
+
+	result, err := h.checkInService.Scan(currentActor(c), &req)
+	if err != nil {
+		serviceError(c, "Gagal memproses scan", err)
+		return
+	}
+
+	switch result.Result {
+	case model.ScanAdmitted:
+		utils.SuccessResponse(c, http.StatusOK, "Tiket valid, silakan masuk", result)
+	case model.ScanDuplicate:
+		c.JSON(http.StatusConflict, utils.Response{Success: false, Message: "Tiket sudah digunakan", Data: result, Error: result.Reason})
+	default:
+		c.JSON(http.StatusUnprocessableEntity, utils.Response{Success: false, Message: "Tiket ditolak", Data: result, Error: result.Reason})
+	}
+}
+
+// Manifest serves the signed offline ticket manifest for a scanner.
+func (h *CheckInHandler) Manifest(c *gin.Context) {
+	manifest, err := h.checkInService.Manifest(c.Param("deviceId"))
+	if err != nil {
+		serviceError(c, "Gagal membuat manifest tiket", err)
+		return
+	}
+
+	c.Header("Cache-Control", "private, no-store")
+	utils.SuccessResponse(c, http.StatusOK, "Manifest tiket", manifest)
+}
+
+func (h *CheckInHandler) SyncScans(c *gin.Context) {
+	var req model.SyncScansRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Validasi gagal", err.Error())
+		return
+	}
+
+	result, err := h.checkInService.SyncScans(currentActor(c), &req)
+	if err != nil {
+		serviceError(c, "Gagal sinkronisasi scan offline", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Sinkronisasi scan offline selesai", result)
+}
+
+func (h *CheckInHandler) SuspiciousScans(c *gin.Context) {
+	eventID, ok := paramUUID(c, "id")
+	if !ok {
+		return
+	}
+
+	var req model.PaginationQuery
+	if err := c.ShouldBindQuery(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Validasi gagal", err.Error())
+		return
+	}
+
+	result, err := h.checkInService.SuspiciousScans(currentActor(c), eventID, &req)
+	if err != nil {
+		serviceError(c, "Gagal mengambil scan mencurigakan", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Daftar scan mencurigakan", result)
+}
+
+func (h *CheckInHandler) Attendance(c *gin.Context) {
+	eventID, ok := paramUUID(c, "id")
+	if !ok {
+		return
+	}
+
+	attendance, err := h.checkInService.Attendance(currentActor(c), eventID)
+	if err != nil {
+		serviceError(c, "Gagal mengambil data kehadiran", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Data kehadiran", attendance)
+}
@@ -0,0 +1,36 @@
+package handler
+
+import (
+	"e-ticketing/internal/service"
+	"e-ticketing/pkg/utils"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DevHandler exposes development-only helpers. Its routes are registered only
+// when the outbox delivery mode is active.
+type DevHandler struct {
+	outbox *service.DevOutbox
+}
+
+func NewDevHandler(outbox *service.DevOutbox) *DevHandler {
+	return &DevHandler{outbox: outbox}
+}
+
+func (h *DevHandler) Outbox(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if err != nil || limit < 1 || limit > 200 {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Validasi gagal", "limit must be between 1 and 200")
+		return
+	}
+
+	messages, err := h.outbox.List(limit, c.Query("to"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Gagal membaca outbox", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Outbox pengembangan", messages)
+}
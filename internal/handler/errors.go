@@ -0,0 +1,44 @@
+package handler
+
+import (
+	"e-ticketing/internal/middleware"
+	"e-ticketing/internal/model"
+	"e-ticketing/internal/service"
+	"e-ticketing/pkg/utils"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// serviceError writes an error response with a status derived from the
+// service sentinel errors; anything unrecognised is a bad request.
+func serviceError(c *gin.Context, message string, err error) {
+	status := http.StatusBadRequest
+	switch {
+	case errors.Is(err, service.ErrNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, service.ErrForbidden):
+		status = http.StatusForbidden
+	case errors.Is(err, service.ErrConflict):
+		status = http.StatusConflict
+	case errors.Is(err, service.ErrUnavailable):
+		status = http.StatusServiceUnavailable
+	}
+	utils.ErrorResponse(c, status, message, err.Error())
+}
+
+// paramUUID parses a UUID path parameter, writing a 400 response on failure.
+func paramUUID(c *gin.Context, name string) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param(name))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Validasi gagal", name+" tidak valid")
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+func currentActor(c *gin.Context) model.Actor {
+	return model.Actor{UserID: middleware.GetUserID(c), Role: middleware.GetUserRole(c)}
+}
@@ -0,0 +1,121 @@
+package handler
+
+import (
+	"e-ticketing/internal/model"
+	"e-ticketing/internal/service"
+	"e-ticketing/pkg/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type EventHandler struct {
+	eventService *service.EventService
+}
+
+func NewEventHandler(eventService *service.EventService) *EventHandler {
+	return &EventHandler{eventService: eventService}
+}
+
+func (h *EventHandler) ListEvents(c *gin.Context) {
+	var req model.ListEventsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Validasi gagal", err.Error())
+		return
+	}
+
+	result, err := h.eventService.ListPublicEvents(&req)
+	if err != nil {
+		serviceError(c, "Gagal mengambil daftar event", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Daftar event", result)
+}
+
+func (h *EventHandler) GetEvent(c *gin.Context) {
+	id, ok := paramUUID(c, "id")
+	if !ok {
+		return
+	}
+
+	event, err := h.eventService.GetPublicEvent(id)
+	if err != nil {
+		serviceError(c, "Gagal mengambil event", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Detail event", event)
+}
+
+func (h *EventHandler) CreateEvent(c *gin.Context) {
+	var req model.CreateEventRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Validasi gagal", err.Error())
+		return
+	}
+
+	event, err := h.eventService.CreateEvent(currentActor(c), &req)
+	if err != nil {
+		serviceError(c, "Gagal membuat event", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "Event berhasil dibuat", event)
+}
+
+func (h *EventHandler) UpdateEvent(c *gin.Context) {
+	id, ok := paramUUID(c, "id")
+	if !ok {
+		return
+	}
+
+	var req model.UpdateEventRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Validasi gagal", err.Error())
+		return
+	}
+
+	event, err := h.eventService.UpdateEvent(currentActor(c), id, &req)
+	if err != nil {
+		serviceError(c, "Gagal memperbarui event", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Event berhasil diperbarui", event)
+}
+
+func (h *EventHandler) UpdateEventStatus(c *gin.Context) {
+	id, ok := paramUUID(c, "id")
+	if !ok {
+		return
+	}
+
+	var req model.UpdateEventStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Validasi gagal", err.Error())
+		return
+	}
+
+	event, err := h.eventService.UpdateEventStatus(currentActor(c), id, req.Status)
+	if err != nil {
+		serviceError(c, "Gagal mengubah status event", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Status event berhasil diubah", event)
+}
+
+func (h *EventHandler) DeleteEvent(c *gin.Context) {
+	id, ok := paramUUID(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.eventService.DeleteEvent(currentActor(c), id); err != nil {
+		serviceError(c, "Gagal menghapus event", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Event berhasil dihapus", nil)
+}
@@ -0,0 +1,66 @@
+package handler
+
+import (
+	"e-ticketing/internal/model"
+	"e-ticketing/internal/service"
+	"e-ticketing/pkg/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type HoldHandler struct {
+	inventoryService *service.InventoryService
+}
+
+func NewHoldHandler(inventoryService *service.InventoryService) *HoldHandler {
+	return &HoldHandler{inventoryService: inventoryService}
+}
+
+func (h *HoldHandler) CreateHold(c *gin.Context) {
+	var req model.CreateHoldRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Validasi gagal", err.Error())
+		return
+	}
+
+	req.QueueToken = c.GetHeader(queueTokenHeader)
+	req.DeviceID = c.GetHeader(deviceIDHeader)
+
+	hold, err := h.inventoryService.PlaceHold(currentActor(c), &req)
+	if err != nil {
+		serviceError(c, "Gagal memesan tiket", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "Tiket berhasil di-hold", hold)
+}
+
+func (h *HoldHandler) GetHold(c *gin.Context) {
+	id, ok := paramUUID(c, "id")
+	if !ok {
+		return
+	}
+
+	hold, err := h.inventoryService.GetHold(currentActor(c), id)
+	if err != nil {
+		serviceError(c, "Gagal mengambil hold", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Detail hold", hold)
+}
+
+func (h *HoldHandler) ReleaseHold(c *gin.Context) {
+	id, ok := paramUUID(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.inventoryService.ReleaseHold(currentActor(c), id); err != nil {
+		serviceError(c, "Gagal melepas hold", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Hold berhasil dilepas", nil)
+}
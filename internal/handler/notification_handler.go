@@ -0,0 +1,147 @@
+package handler
+
+import (
+	"e-ticketing/internal/middleware"
+	"e-ticketing/internal/model"
+	"e-ticketing/internal/service"
+	"e-ticketing/pkg/utils"
+	"html/template"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+type NotificationHandler struct {
+	notificationService *service.NotificationService
+	logService          *service.NotificationLogService
+}
+
+func NewNotificationHandler(notificationService *service.NotificationService, logService *service.NotificationLogService) *NotificationHandler {
+	return &NotificationHandler{notificationService: notificationService, logService: logService}
+}
+
+func (h *NotificationHandler) GetPreferences(c *gin.Context) {
+	pref, err := h.notificationService.GetPreference(middleware.GetUserID(c))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Gagal mengambil preferensi notifikasi", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Preferensi notifikasi", pref)
+}
+
+func (h *NotificationHandler) UpdatePreferences(c *gin.Context) {
+	var req model.UpdateNotificationPreferenceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Validasi gagal", err.Error())
+		return
+	}
+
+	pref, err := h.notificationService.UpdatePreference(middleware.GetUserID(c), &req)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Gagal memperbarui preferensi notifikasi", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Preferensi notifikasi berhasil diperbarui", pref)
+}
+
+// UnsubscribePage answers the link in the email footer with a confirmation
+// page only. Mail scanners and link prefetchers open links, so a GET never
+// changes the preference; the page's button POSTs to Unsubscribe.
+func (h *NotificationHandler) UnsubscribePage(c *gin.Context) {
+	token := c.Query("token")
+	data := gin.H{"Token": token}
+	status := http.StatusOK
+	if token == "" {
+		data["Message"], status = "Token berhenti berlangganan tidak ditemukan", http.StatusBadRequest
+	} else if err := h.notificationService.VerifyUnsubscribeToken(token); err != nil {
+		data["Message"], status = "Link berhenti berlangganan tidak valid atau sudah kedaluwarsa", http.StatusBadRequest
+	}
+	renderUnsubscribePage(c, status, data)
+}
+
+// Unsubscribe handles the confirmation page's form and RFC 8058 one-click
+// unsubscribe requests from mail clients, which get JSON.
+func (h *NotificationHandler) Unsubscribe(c *gin.Context) {
+	token := c.Query("token")
+	if token == "" {
+		token = c.PostForm("token")
+	}
+	fromPage := c.PostForm("List-Unsubscribe") == "" && !strings.Contains(c.GetHeader("Accept"), "application/json")
+
+	if token == "" {
+		if fromPage {
+			renderUnsubscribePage(c, http.StatusBadRequest, gin.H{"Message": "Token berhenti berlangganan tidak ditemukan"})
+			return
+		}
+		utils.ErrorResponse(c, http.StatusBadRequest, "Token tidak ditemukan", "Token is required")
+		return
+	}
+
+	if err := h.notificationService.Unsubscribe(token); err != nil {
+		if fromPage {
+			renderUnsubscribePage(c, http.StatusBadRequest, gin.H{"Message": "Link berhenti berlangganan tidak valid atau sudah kedaluwarsa"})
+			return
+		}
+		utils.ErrorResponse(c, http.StatusBadRequest, "Gagal berhenti berlangganan", err.Error())
+		return
+	}
+
+	if fromPage {
+		renderUnsubscribePage(c, http.StatusOK, gin.H{"Done": true})
+		return
+	}
+	utils.SuccessResponse(c, http.StatusOK, "Anda telah berhenti berlangganan email promosi", nil)
+}
+
+func renderUnsubscribePage(c *gin.Context, status int, data gin.H) {
+	c.Status(status)
+	c.Header("Content-Type", "text/html; charset=utf-8")
+	unsubscribePage.Execute(c.Writer, data)
+}
+
+var unsubscribePage = template.Must(template.New("unsubscribe").Parse(`<!DOCTYPE html>
+<html lang="id">
+<head>
+	<meta charset="utf-8">
+	<meta name="viewport" content="width=device-width, initial-scale=1">
+	<title>Berhenti Berlangganan - E-Ticketing</title>
+</head>
+<body style="font-family: Arial, sans-serif; padding: 20px; text-align: center;">
+	{{if .Done}}
+	<h2 style="color: #4CAF50;">Berhasil Berhenti Berlangganan</h2>
+	<p>Anda tidak akan lagi menerima email promosi. Notifikasi pesanan dan tiket tetap dikirim.</p>
+	{{else if .Message}}
+	<h2 style="color: #E53935;">Gagal Berhenti Berlangganan</h2>
+	<p>{{.Message}}</p>
+	{{else}}
+	<h2>Berhenti Berlangganan Email Promosi?</h2>
+	<p>Anda tidak akan lagi menerima email promosi. Notifikasi pesanan dan tiket tetap dikirim.</p>
+	<form method="post" style="margin: 30px 0;">
+		<input type="hidden" name="token" value="{{.Token}}">
+		<button type="submit" style="background-color: #E53935; color: white; padding: 15px 30px; border: none; border-radius: 5px; font-size: 16px;">
+			Berhenti Berlangganan
+		</button>
+	</form>
+	{{end}}
+	<p>Salam,<br>Tim E-Ticketing</p>
+</body>
+</html>`))
+
+func (h *NotificationHandler) SearchLogs(c *gin.Context) {
+	var req model.SearchNotificationLogsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Validasi gagal", err.Error())
+		return
+	}
+
+	result, err := h.logService.Search(&req)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Gagal mencari log notifikasi", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Log notifikasi", result)
+}
@@ -0,0 +1,98 @@
+package handler
+
+import (
+	"e-ticketing/internal/model"
+	"e-ticketing/internal/service"
+	"e-ticketing/pkg/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type OrderHandler struct {
+	orderService *service.OrderService
+}
+
+func NewOrderHandler(orderService *service.OrderService) *OrderHandler {
+	return &OrderHandler{orderService: orderService}
+}
+
+func (h *OrderHandler) CreateOrder(c *gin.Context) {
+	var req model.CreateOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Validasi gagal", err.Error())
+		return
+	}
+	req.DeviceID = c.GetHeader(deviceIDHeader)
+
+	order, err := h.orderService.CreateOrder(currentActor(c), &req)
+	if err != nil {
+		serviceError(c, "Gagal membuat order", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "Order berhasil dibuat", order)
+}
+
+// PreviewOrder prices holds and a promo code without placing the order.
+func (h *OrderHandler) PreviewOrder(c *gin.Context) {
+	var req model.CreateOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Validasi gagal", err.Error())
+		return
+	}
+
+	order, err := h.orderService.PreviewOrder(currentActor(c), &req)
+	if err != nil {
+		serviceError(c, "Gagal menghitung order", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Rincian order", order)
+}
+
+func (h *OrderHandler) ListMyOrders(c *gin.Context) {
+	var req model.ListOrdersRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Validasi gagal", err.Error())
+		return
+	}
+
+	result, err := h.orderService.ListMyOrders(currentActor(c), &req)
+	if err != nil {
+		serviceError(c, "Gagal mengambil daftar order", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Daftar order", result)
+}
+
+func (h *OrderHandler) GetMyOrder(c *gin.Context) {
+	id, ok := paramUUID(c, "id")
+	if !ok {
+		return
+	}
+
+	order, err := h.orderService.GetMyOrder(currentActor(c), id)
+	if err != nil {
+		serviceError(c, "Gagal mengambil order", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Detail order", order)
+}
+
+func (h *OrderHandler) CancelOrder(c *gin.Context) {
+	id, ok := paramUUID(c, "id")
+	if !ok {
+		return
+	}
+
+	order, err := h.orderService.CancelOrder(currentActor(c), id)
+	if err != nil {
+		serviceError(c, "Gagal membatalkan order", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Order berhasil dibatalkan", order)
+}
@@ -0,0 +1,102 @@
+package handler
+
+import (
+	"e-ticketing/internal/model"
+	"e-ticketing/internal/service"
+	"e-ticketing/pkg/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type OrganizerHandler struct {
+	organizerService *service.OrganizerService
+	eventService     *service.EventService
+}
+
+func NewOrganizerHandler(organizerService *service.OrganizerService, eventService *service.EventService) *OrganizerHandler {
+	return &OrganizerHandler{organizerService: organizerService, eventService: eventService}
+}
+
+func (h *OrganizerHandler) CreateOrganizer(c *gin.Context) {
+	var req model.CreateOrganizerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Validasi gagal", err.Error())
+		return
+	}
+
+	organizer, err := h.organizerService.CreateOrganizer(currentActor(c), &req)
+	if err != nil {
+		serviceError(c, "Gagal membuat organizer", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "Organizer berhasil dibuat", organizer)
+}
+
+func (h *OrganizerHandler) GetOrganizer(c *gin.Context) {
+	id, ok := paramUUID(c, "id")
+	if !ok {
+		return
+	}
+
+	organizer, err := h.organizerService.GetOrganizer(id)
+	if err != nil {
+		serviceError(c, "Gagal mengambil organizer", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Detail organizer", organizer)
+}
+
+func (h *OrganizerHandler) GetMyOrganizers(c *gin.Context) {
+	organizers, err := h.organizerService.GetMyOrganizers(currentActor(c))
+	if err != nil {
+		serviceError(c, "Gagal mengambil organizer", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Daftar organizer", organizers)
+}
+
+func (h *OrganizerHandler) UpdateOrganizer(c *gin.Context) {
+	id, ok := paramUUID(c, "id")
+	if !ok {
+		return
+	}
+
+	var req model.UpdateOrganizerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Validasi gagal", err.Error())
+		return
+	}
+
+	organizer, err := h.organizerService.UpdateOrganizer(currentActor(c), id, &req)
+	if err != nil {
+		serviceError(c, "Gagal memperbarui organizer", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Organizer berhasil diperbarui", organizer)
+}
+
+func (h *OrganizerHandler) ListEvents(c *gin.Context) {
+	id, ok := paramUUID(c, "id")
+	if !ok {
+		return
+	}
+
+	var req model.ListEventsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Validasi gagal", err.Error())
+		return
+	}
+
+	result, err := h.eventService.ListOrganizerEvents(currentActor(c), id, &req)
+	if err != nil {
+		serviceError(c, "Gagal mengambil daftar event", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Daftar event organizer", result)
+}
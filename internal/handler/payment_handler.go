@@ -0,0 +1,118 @@
+package handler
+
+import (
+	"e-ticketing/internal/model"
+	"e-ticketing/internal/service"
+	"e-ticketing/pkg/utils"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+const maxNotificationBodySize = 64 << 10
+
+type PaymentHandler struct {
+	paymentService *service.PaymentService
+}
+
+func NewPaymentHandler(paymentService *service.PaymentService) *PaymentHandler {
+	return &PaymentHandler{paymentService: paymentService}
+}
+
+func (h *PaymentHandler) CreatePayment(c *gin.Context) {
+	orderID, ok := paramUUID(c, "id")
+	if !ok {
+		return
+	}
+
+	var req model.CreatePaymentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Validasi gagal", err.Error())
+		return
+	}
+
+	payment, err := h.paymentService.CreatePayment(currentActor(c), orderID, &req)
+	if err != nil {
+		serviceError(c, "Gagal membuat pembayaran", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "Pembayaran berhasil dibuat", payment)
+}
+
+func (h *PaymentHandler) GetMyPayment(c *gin.Context) {
+	id, ok := paramUUID(c, "id")
+	if !ok {
+		return
+	}
+
+	payment, err := h.paymentService.GetMyPayment(currentActor(c), id)
+	if err != nil {
+		serviceError(c, "Gagal mengambil pembayaran", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Detail pembayaran", payment)
+}
+
+func (h *PaymentHandler) RefreshStatus(c *gin.Context) {
+	id, ok := paramUUID(c, "id")
+	if !ok {
+		return
+	}
+
+	payment, err := h.paymentService.RefreshStatus(currentActor(c), id)
+	if err != nil {
+		serviceError(c, "Gagal memperbarui status pembayaran", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Status pembayaran diperbarui", payment)
+}
+
+// Notification receives gateway callbacks. Anything other than a 2xx makes
+// the gateway retry, so only bad signatures and unknown payments are
+// rejected.
+func (h *PaymentHandler) Notification(c *gin.Context) {
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxNotificationBodySize))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Gagal membaca notifikasi", err.Error())
+		return
+	}
+
+	if err := h.paymentService.HandleNotification(c.Param("gateway"), body); err != nil {
+		if errors.Is(err, service.ErrInvalidSignature) {
+			utils.ErrorResponse(c, http.StatusUnauthorized, "Notifikasi ditolak", err.Error())
+			return
+		}
+		serviceError(c, "Gagal memproses notifikasi pembayaran", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Notifikasi diproses", nil)
+}
+
+// Simulate is a development-only route that settles, fails or expires a
+// payment through the simulator gateway.
+func (h *PaymentHandler) Simulate(c *gin.Context) {
+	id, ok := paramUUID(c, "id")
+	if !ok {
+		return
+	}
+
+	var req model.SimulatePaymentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Validasi gagal", err.Error())
+		return
+	}
+
+	payment, err := h.paymentService.Simulate(currentActor(c), id, &req)
+	if err != nil {
+		serviceError(c, "Gagal mensimulasikan pembayaran", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Simulasi pembayaran berhasil", payment)
+}
@@ -0,0 +1,179 @@
+package handler
+
+import (
+	"e-ticketing/internal/model"
+	"e-ticketing/internal/service"
+	"e-ticketing/pkg/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PromoHandler struct {
+	promoService *service.PromoService
+}
+
+func NewPromoHandler(promoService *service.PromoService) *PromoHandler {
+	return &PromoHandler{promoService: promoService}
+}
+
+func (h *PromoHandler) CreatePromotion(c *gin.Context) {
+	eventID, ok := paramUUID(c, "id")
+	if !ok {
+		return
+	}
+
+	var req model.CreatePromotionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Validasi gagal", err.Error())
+		return
+	}
+
+	promo, err := h.promoService.CreatePromotion(currentActor(c), eventID, &req)
+	if err != nil {
+		serviceError(c, "Gagal membuat promo", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "Promo berhasil dibuat", promo)
+}
+
+func (h *PromoHandler) UpdatePromotion(c *gin.Context) {
+	eventID, ok := paramUUID(c, "id")
+	if !ok {
+		return
+	}
+	id, ok := paramUUID(c, "promotionId")
+	if !ok {
+		return
+	}
+
+	var req model.UpdatePromotionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Validasi gagal", err.Error())
+		return
+	}
+
+	promo, err := h.promoService.UpdatePromotion(currentActor(c), eventID, id, &req)
+	if err != nil {
+		serviceError(c, "Gagal memperbarui promo", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Promo berhasil diperbarui", promo)
+}
+
+func (h *PromoHandler) GetPromotion(c *gin.Context) {
+	eventID, ok := paramUUID(c, "id")
+	if !ok {
+		return
+	}
+	id, ok := paramUUID(c, "promotionId")
+	if !ok {
+		return
+	}
+
+	promo, err := h.promoService.GetPromotion(currentActor(c), eventID, id)
+	if err != nil {
+		serviceError(c, "Gagal mengambil promo", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Detail promo", promo)
+}
+
+func (h *PromoHandler) ListPromotions(c *gin.Context) {
+	eventID, ok := paramUUID(c, "id")
+	if !ok {
+		return
+	}
+
+	var req model.ListPromotionsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Validasi gagal", err.Error())
+		return
+	}
+
+	result, err := h.promoService.ListPromotions(currentActor(c), eventID, &req)
+	if err != nil {
+		serviceError(c, "Gagal mengambil daftar promo", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Daftar promo", result)
+}
+
+func (h *PromoHandler) CreateCode(c *gin.Context) {
+	eventID, ok := paramUUID(c, "id")
+	if !ok {
+		return
+	}
+	promotionID, ok := paramUUID(c, "promotionId")
+	if !ok {
+		return
+	}
+
+	var req model.CreatePromoCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Validasi gagal", err.Error())
+		return
+	}
+
+	code, err := h.promoService.CreateCode(currentActor(c), eventID, promotionID, &req)
+	if err != nil {
+		serviceError(c, "Gagal membuat kode promo", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "Kode promo berhasil dibuat", code)
+}
+
+func (h *PromoHandler) GenerateCodes(c *gin.Context) {
+	eventID, ok := paramUUID(c, "id")
+	if !ok {
+		return
+	}
+	promotionID, ok := paramUUID(c, "promotionId")
+	if !ok {
+		return
+	}
+
+	var req model.GeneratePromoCodesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Validasi gagal", err.Error())
+		return
+	}
+
+	codes, err := h.promoService.GenerateCodes(currentActor(c), eventID, promotionID, &req)
+	if err != nil {
+		serviceError(c, "Gagal membuat kode promo", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "Kode promo berhasil dibuat", codes)
+}
+
+func (h *PromoHandler) ListCodes(c *gin.Context) {
+	eventID, ok := paramUUID(c, "id")
+	if !ok {
+		return
+	}
+	promotionID, ok := paramUUID(c, "promotionId")
+	if !ok {
+		return
+	}
+
+	var req model.ListPromoCodesRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Validasi gagal", err.Error())
+		return
+	}
+
+	result, err := h.promoService.ListCodes(currentActor(c), eventID, promotionID, &req)
+	if err != nil {
+		serviceError(c, "Gagal mengambil daftar kode promo", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Daftar kode promo", result)
+}
@@ -0,0 +1,72 @@
+package handler
+
+import (
+	"e-ticketing/internal/model"
+	"e-ticketing/internal/service"
+	"e-ticketing/pkg/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// deviceIDHeader carries the client's device identifier on holds and orders.
+const deviceIDHeader = "X-Device-ID"
+
+type PurchaseLimitHandler struct {
+	purchaseLimitService *service.PurchaseLimitService
+}
+
+func NewPurchaseLimitHandler(purchaseLimitService *service.PurchaseLimitService) *PurchaseLimitHandler {
+	return &PurchaseLimitHandler{purchaseLimitService: purchaseLimitService}
+}
+
+func (h *PurchaseLimitHandler) GetPolicy(c *gin.Context) {
+	eventID, ok := paramUUID(c, "id")
+	if !ok {
+		return
+	}
+
+	policy, err := h.purchaseLimitService.GetPolicy(currentActor(c), eventID)
+	if err != nil {
+		serviceError(c, "Gagal mengambil batas pembelian", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Batas pembelian", policy)
+}
+
+func (h *PurchaseLimitHandler) ConfigurePolicy(c *gin.Context) {
+	eventID, ok := paramUUID(c, "id")
+	if !ok {
+		return
+	}
+
+	var req model.ConfigurePurchasePolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Validasi gagal", err.Error())
+		return
+	}
+
+	policy, err := h.purchaseLimitService.ConfigurePolicy(currentActor(c), eventID, &req)
+	if err != nil {
+		serviceError(c, "Gagal mengatur batas pembelian", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Batas pembelian berhasil diatur", policy)
+}
+
+func (h *PurchaseLimitHandler) ListClusters(c *gin.Context) {
+	eventID, ok := paramUUID(c, "id")
+	if !ok {
+		return
+	}
+
+	clusters, err := h.purchaseLimitService.ListClusters(currentActor(c), eventID)
+	if err != nil {
+		serviceError(c, "Gagal mengambil klaster pembeli", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Daftar klaster pembeli", clusters)
+}
@@ -0,0 +1,242 @@
+package handler
+
+import (
+	"e-ticketing/internal/model"
+	"e-ticketing/internal/service"
+	"e-ticketing/pkg/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type RefundHandler struct {
+	refundService *service.RefundService
+}
+
+func NewRefundHandler(refundService *service.RefundService) *RefundHandler {
+	return &RefundHandler{refundService: refundService}
+}
+
+func (h *RefundHandler) GetPolicy(c *gin.Context) {
+	eventID, ok := paramUUID(c, "id")
+	if !ok {
+		return
+	}
+
+	policy, err := h.refundService.GetPolicy(currentActor(c), eventID)
+	if err != nil {
+		serviceError(c, "Gagal mengambil aturan refund", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Aturan refund tiket", policy)
+}
+
+func (h *RefundHandler) ConfigurePolicy(c *gin.Context) {
+	eventID, ok := paramUUID(c, "id")
+	if !ok {
+		return
+	}
+
+	var req model.ConfigureRefundPolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Validasi gagal", err.Error())
+		return
+	}
+
+	policy, err := h.refundService.ConfigurePolicy(currentActor(c), eventID, &req)
+	if err != nil {
+		serviceError(c, "Gagal mengatur aturan refund", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Aturan refund berhasil diatur", policy)
+}
+
+func (h *RefundHandler) RequestRefund(c *gin.Context) {
+	orderID, ok := paramUUID(c, "id")
+	if !ok {
+		return
+	}
+
+	var req model.CreateRefundRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Validasi gagal", err.Error())
+		return
+	}
+
+	refund, err := h.refundService.RequestRefund(currentActor(c), orderID, &req)
+	if err != nil {
+		serviceError(c, "Gagal mengajukan refund", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "Pengajuan refund berhasil dikirim", refund)
+}
+
+func (h *RefundHandler) ListMyRefunds(c *gin.Context) {
+	var req model.ListRefundsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Validasi gagal", err.Error())
+		return
+	}
+
+	result, err := h.refundService.ListMyRefunds(currentActor(c), &req)
+	if err != nil {
+		serviceError(c, "Gagal mengambil daftar refund", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Daftar refund", result)
+}
+
+func (h *RefundHandler) GetMyRefund(c *gin.Context) {
+	id, ok := paramUUID(c, "id")
+	if !ok {
+		return
+	}
+
+	refund, err := h.refundService.GetMyRefund(currentActor(c), id)
+	if err != nil {
+		serviceError(c, "Gagal mengambil refund", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Detail refund", refund)
+}
+
+func (h *RefundHandler) CancelRefund(c *gin.Context) {
+	id, ok := paramUUID(c, "id")
+	if !ok {
+		return
+	}
+
+	refund, err := h.refundService.CancelRefund(currentActor(c), id)
+	if err != nil {
+		serviceError(c, "Gagal membatalkan refund", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Pengajuan refund berhasil dibatalkan", refund)
+}
+
+func (h *RefundHandler) ListEventRefunds(c *gin.Context) {
+	eventID, ok := paramUUID(c, "id")
+	if !ok {
+		return
+	}
+
+	var req model.ListRefundsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Validasi gagal", err.Error())
+		return
+	}
+
+	result, err := h.refundService.ListEventRefunds(currentActor(c), eventID, &req)
+	if err != nil {
+		serviceError(c, "Gagal mengambil daftar refund", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Daftar refund", result)
+}
+
+func (h *RefundHandler) GetEventRefund(c *gin.Context) {
+	eventID, ok := paramUUID(c, "id")
+	if !ok {
+		return
+	}
+	id, ok := paramUUID(c, "refundId")
+	if !ok {
+		return
+	}
+
+	refund, err := h.refundService.GetEventRefund(currentActor(c), eventID, id)
+	if err != nil {
+		serviceError(c, "Gagal mengambil refund", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Detail refund", refund)
+}
+
+func (h *RefundHandler) ApproveRefund(c *gin.Context) {
+	eventID, ok := paramUUID(c, "id")
+	if !ok {
+		return
+	}
+	id, ok := paramUUID(c, "refundId")
+	if !ok {
+		return
+	}
+
+	refund, err := h.refundService.ApproveRefund(currentActor(c), eventID, id)
+	if err != nil {
+		serviceError(c, "Gagal menyetujui refund", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Refund disetujui", refund)
+}
+
+func (h *RefundHandler) RejectRefund(c *gin.Context) {
+	eventID, ok := paramUUID(c, "id")
+	if !ok {
+		return
+	}
+	id, ok := paramUUID(c, "refundId")
+	if !ok {
+		return
+	}
+
+	var req model.RejectRefundRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Validasi gagal", err.Error())
+		return
+	}
+
+	refund, err := h.refundService.RejectRefund(currentActor(c), eventID, id, &req)
+	if err != nil {
+		serviceError(c, "Gagal menolak refund", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Refund ditolak", refund)
+}
+
+func (h *RefundHandler) ListRefunds(c *gin.Context) {
+	var req model.ListRefundsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Validasi gagal", err.Error())
+		return
+	}
+
+	result, err := h.refundService.ListRefunds(&req)
+	if err != nil {
+		serviceError(c, "Gagal mengambil daftar refund", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Daftar refund", result)
+}
+
+func (h *RefundHandler) CompleteRefund(c *gin.Context) {
+	id, ok := paramUUID(c, "id")
+	if !ok {
+		return
+	}
+
+	var req model.CompleteRefundRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Validasi gagal", err.Error())
+		return
+	}
+
+	refund, err := h.refundService.CompleteRefund(id, &req)
+	if err != nil {
+		serviceError(c, "Gagal menyelesaikan refund", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Refund berhasil diselesaikan", refund)
+}
@@ -0,0 +1,205 @@
+package handler
+
+import (
+	"e-ticketing/internal/model"
+	"e-ticketing/internal/service"
+	"e-ticketing/pkg/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ResaleHandler struct {
+	resaleService *service.ResaleService
+}
+
+func NewResaleHandler(resaleService *service.ResaleService) *ResaleHandler {
+	return &ResaleHandler{resaleService: resaleService}
+}
+
+func (h *ResaleHandler) GetPolicy(c *gin.Context) {
+	eventID, ok := paramUUID(c, "id")
+	if !ok {
+		return
+	}
+
+	policy, err := h.resaleService.GetPolicy(currentActor(c), eventID)
+	if err != nil {
+		serviceError(c, "Gagal mengambil aturan resale", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Aturan resale tiket", policy)
+}
+
+func (h *ResaleHandler) ConfigurePolicy(c *gin.Context) {
+	eventID, ok := paramUUID(c, "id")
+	if !ok {
+		return
+	}
+
+	var req model.ConfigureResalePolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Validasi gagal", err.Error())
+		return
+	}
+
+	policy, err := h.resaleService.ConfigurePolicy(currentActor(c), eventID, &req)
+	if err != nil {
+		serviceError(c, "Gagal mengatur aturan resale", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Aturan resale berhasil diatur", policy)
+}
+
+func (h *ResaleHandler) ListOffers(c *gin.Context) {
+	eventID, ok := paramUUID(c, "id")
+	if !ok {
+		return
+	}
+
+	var req model.ListResaleListingsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Validasi gagal", err.Error())
+		return
+	}
+
+	result, err := h.resaleService.ListOffers(currentActor(c), eventID, &req)
+	if err != nil {
+		serviceError(c, "Gagal mengambil daftar tiket resale", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Daftar tiket resale", result)
+}
+
+func (h *ResaleHandler) CreateListing(c *gin.Context) {
+	ticketID, ok := paramUUID(c, "id")
+	if !ok {
+		return
+	}
+
+	var req model.CreateResaleListingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Validasi gagal", err.Error())
+		return
+	}
+
+	listing, err := h.resaleService.CreateListing(currentActor(c), ticketID, &req)
+	if err != nil {
+		serviceError(c, "Gagal menjual tiket", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "Tiket berhasil dijual di resale", listing)
+}
+
+func (h *ResaleHandler) ListMyListings(c *gin.Context) {
+	var req model.ListMyResaleListingsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Validasi gagal", err.Error())
+		return
+	}
+
+	result, err := h.resaleService.ListMyListings(currentActor(c), &req)
+	if err != nil {
+		serviceError(c, "Gagal mengambil daftar listing resale", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Daftar listing resale", result)
+}
+
+func (h *ResaleHandler) GetMyListing(c *gin.Context) {
+	id, ok := paramUUID(c, "id")
+	if !ok {
+		return
+	}
+
+	listing, err := h.resaleService.GetMyListing(currentActor(c), id)
+	if err != nil {
+		serviceError(c, "Gagal mengambil listing resale", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Detail listing resale", listing)
+}
+
+func (h *ResaleHandler) WithdrawListing(c *gin.Context) {
+	id, ok := paramUUID(c, "id")
+	if !ok {
+		return
+	}
+
+	listing, err := h.resaleService.WithdrawListing(currentActor(c), id)
+	if err != nil {
+		serviceError(c, "Gagal menarik listing resale", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Listing resale ditarik", listing)
+}
+
+// BuyListing takes an optional body with the buyer's holder details and
+// returns a pending order to pay through the usual payment endpoints.
+func (h *ResaleHandler) BuyListing(c *gin.Context) {
+	id, ok := paramUUID(c, "id")
+	if !ok {
+		return
+	}
+
+	var req model.BuyResaleListingRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Validasi gagal", err.Error())
+			return
+		}
+	}
+	req.DeviceID = c.GetHeader(deviceIDHeader)
+
+	order, err := h.resaleService.BuyListing(currentActor(c), id, &req)
+	if err != nil {
+		serviceError(c, "Gagal membeli tiket resale", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "Order berhasil dibuat", order)
+}
+
+func (h *ResaleHandler) ListPayouts(c *gin.Context) {
+	var req model.ListResalePayoutsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Validasi gagal", err.Error())
+		return
+	}
+
+	result, err := h.resaleService.ListPayouts(&req)
+	if err != nil {
+		serviceError(c, "Gagal mengambil daftar payout resale", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Daftar payout resale", result)
+}
+
+func (h *ResaleHandler) MarkPayoutPaid(c *gin.Context) {
+	id, ok := paramUUID(c, "id")
+	if !ok {
+		return
+	}
+
+	var req model.MarkResalePayoutRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Validasi gagal", err.Error())
+		return
+	}
+
+	listing, err := h.resaleService.MarkPayoutPaid(id, &req)
+	if err != nil {
+		serviceError(c, "Gagal mencatat payout resale", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Payout resale berhasil dicatat", listing)
+}
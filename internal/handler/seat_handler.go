@@ -0,0 +1,99 @@
+package handler
+
+import (
+	"e-ticketing/internal/model"
+	"e-ticketing/internal/service"
+	"e-ticketing/pkg/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+// maxSeatMapUpload bounds seat map imports; a 100k seat CSV is about 4MB.
+const maxSeatMapUpload = 16 << 20
+
+type SeatHandler struct {
+	seatService *service.SeatService
+}
+
+func NewSeatHandler(seatService *service.SeatService) *SeatHandler {
+	return &SeatHandler{seatService: seatService}
+}
+
+func (h *SeatHandler) GetSeatMap(c *gin.Context) {
+	eventID, ok := paramUUID(c, "id")
+	if !ok {
+		return
+	}
+
+	seatMap, err := h.seatService.GetSeatMap(currentActor(c), eventID)
+	if err != nil {
+		serviceError(c, "Gagal mengambil seat map", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Seat map event", seatMap)
+}
+
+// ImportSeatMap accepts the JSON import format, or CSV when sent as text/csv.
+func (h *SeatHandler) ImportSeatMap(c *gin.Context) {
+	eventID, ok := paramUUID(c, "id")
+	if !ok {
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxSeatMapUpload)
+
+	var req *model.SeatMapImport
+	if c.ContentType() == "text/csv" {
+		parsed, err := service.ParseSeatMapCSV(c.Request.Body)
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Validasi gagal", err.Error())
+			return
+		}
+		if err := binding.Validator.ValidateStruct(parsed); err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Validasi gagal", err.Error())
+			return
+		}
+		req = parsed
+	} else {
+		req = &model.SeatMapImport{}
+		if err := c.ShouldBindJSON(req); err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Validasi gagal", err.Error())
+			return
+		}
+	}
+
+	seatMap, err := h.seatService.ImportSeatMap(currentActor(c), eventID, req)
+	if err != nil {
+		serviceError(c, "Gagal mengimpor seat map", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Seat map berhasil diimpor", seatMap)
+}
+
+func (h *SeatHandler) CreateSeatHold(c *gin.Context) {
+	eventID, ok := paramUUID(c, "id")
+	if !ok {
+		return
+	}
+
+	var req model.CreateSeatHoldRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Validasi gagal", err.Error())
+		return
+	}
+
+	req.QueueToken = c.GetHeader(queueTokenHeader)
+	req.DeviceID = c.GetHeader(deviceIDHeader)
+
+	holds, err := h.seatService.PlaceSeatHold(currentActor(c), eventID, &req)
+	if err != nil {
+		serviceError(c, "Gagal memesan kursi", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "Kursi berhasil di-hold", holds)
+}
@@ -0,0 +1,103 @@
+package handler
+
+import (
+	"e-ticketing/internal/model"
+	"e-ticketing/internal/service"
+	"e-ticketing/pkg/utils"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TicketHandler struct {
+	ticketService *service.TicketService
+	qrService     *service.TicketQRService
+}
+
+func NewTicketHandler(ticketService *service.TicketService, qrService *service.TicketQRService) *TicketHandler {
+	return &TicketHandler{ticketService: ticketService, qrService: qrService}
+}
+
+func (h *TicketHandler) ListMyTickets(c *gin.Context) {
+	var req model.ListTicketsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Validasi gagal", err.Error())
+		return
+	}
+
+	result, err := h.ticketService.ListMyTickets(currentActor(c), &req)
+	if err != nil {
+		serviceError(c, "Gagal mengambil daftar tiket", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Daftar tiket", result)
+}
+
+func (h *TicketHandler) GetMyTicket(c *gin.Context) {
+	id, ok := paramUUID(c, "id")
+	if !ok {
+		return
+	}
+
+	ticket, err := h.ticketService.GetMyTicket(currentActor(c), id)
+	if err != nil {
+		serviceError(c, "Gagal mengambil tiket", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Detail tiket", ticket)
+}
+
+// GetMyTicketQR returns the signed QR for a ticket as PNG (default), SVG or
+// the raw payload text.
+func (h *TicketHandler) GetMyTicketQR(c *gin.Context) {
+	id, ok := paramUUID(c, "id")
+	if !ok {
+		return
+	}
+
+	format := c.DefaultQuery("format", "png")
+	size, err := strconv.Atoi(c.DefaultQuery("size", "512"))
+	if err != nil || size < 128 || size > 2048 {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Validasi gagal", "size must be between 128 and 2048")
+		return
+	}
+
+	payload, err := h.qrService.Payload(currentActor(c), id)
+	if err != nil {
+		serviceError(c, "Gagal membuat QR tiket", err)
+		return
+	}
+
+	// Payloads are bearer credentials; keep them out of shared caches
+	c.Header("Cache-Control", "private, no-store")
+
+	switch format {
+	case "png":
+		png, err := h.qrService.RenderPNG(payload, size)
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusInternalServerError, "Gagal membuat QR tiket", err.Error())
+			return
+		}
+		c.Data(http.StatusOK, "image/png", png)
+	case "svg":
+		svg, err := h.qrService.RenderSVG(payload)
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusInternalServerError, "Gagal membuat QR tiket", err.Error())
+			return
+		}
+		c.Data(http.StatusOK, "image/svg+xml", svg)
+	case "text":
+		utils.SuccessResponse(c, http.StatusOK, "Payload QR tiket", gin.H{"payload": payload})
+	default:
+		utils.ErrorResponse(c, http.StatusBadRequest, "Validasi gagal", "format must be png, svg or text")
+	}
+}
+
+// VerificationKeys publishes the public keys scanners use to verify QR codes
+// offline.
+func (h *TicketHandler) VerificationKeys(c *gin.Context) {
+	utils.SuccessResponse(c, http.StatusOK, "Kunci verifikasi QR tiket", gin.H{"keys": h.qrService.PublicKeys()})
+}
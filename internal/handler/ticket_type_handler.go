@@ -0,0 +1,97 @@
+package handler
+
+import (
+	"e-ticketing/internal/model"
+	"e-ticketing/internal/service"
+	"e-ticketing/pkg/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TicketTypeHandler struct {
+	ticketTypeService *service.TicketTypeService
+}
+
+func NewTicketTypeHandler(ticketTypeService *service.TicketTypeService) *TicketTypeHandler {
+	return &TicketTypeHandler{ticketTypeService: ticketTypeService}
+}
+
+func (h *TicketTypeHandler) ListTicketTypes(c *gin.Context) {
+	eventID, ok := paramUUID(c, "id")
+	if !ok {
+		return
+	}
+
+	ticketTypes, err := h.ticketTypeService.ListTicketTypes(currentActor(c), eventID)
+	if err != nil {
+		serviceError(c, "Gagal mengambil tipe tiket", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Daftar tipe tiket", ticketTypes)
+}
+
+func (h *TicketTypeHandler) CreateTicketType(c *gin.Context) {
+	eventID, ok := paramUUID(c, "id")
+	if !ok {
+		return
+	}
+
+	var req model.CreateTicketTypeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Validasi gagal", err.Error())
+		return
+	}
+
+	ticketType, err := h.ticketTypeService.CreateTicketType(currentActor(c), eventID, &req)
+	if err != nil {
+		serviceError(c, "Gagal membuat tipe tiket", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "Tipe tiket berhasil dibuat", ticketType)
+}
+
+func (h *TicketTypeHandler) UpdateTicketType(c *gin.Context) {
+	eventID, ok := paramUUID(c, "id")
+	if !ok {
+		return
+	}
+	id, ok := paramUUID(c, "ticketTypeId")
+	if !ok {
+		return
+	}
+
+	var req model.UpdateTicketTypeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Validasi gagal", err.Error())
+		return
+	}
+
+	ticketType, err := h.ticketTypeService.UpdateTicketType(currentActor(c), eventID, id, &req)
+	if err != nil {
+		serviceError(c, "Gagal memperbarui tipe tiket", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Tipe tiket berhasil diperbarui", ticketType)
+}
+
+func (h *TicketTypeHandler) DeleteTicketType(c *gin.Context) {
+	eventID, ok := paramUUID(c, "id")
+	if !ok {
+		return
+	}
+	id, ok := paramUUID(c, "ticketTypeId")
+	if !ok {
+		return
+	}
+
+	if err := h.ticketTypeService.DeleteTicketType(currentActor(c), eventID, id); err != nil {
+		serviceError(c, "Gagal menghapus tipe tiket", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Tipe tiket berhasil dihapus", nil)
+}
@@ -0,0 +1,194 @@
+package handler
+
+import (
+	"e-ticketing/internal/model"
+	"e-ticketing/internal/service"
+	"e-ticketing/pkg/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TransferHandler struct {
+	transferService *service.TransferService
+}
+
+func NewTransferHandler(transferService *service.TransferService) *TransferHandler {
+	return &TransferHandler{transferService: transferService}
+}
+
+func (h *TransferHandler) GetPolicy(c *gin.Context) {
+	eventID, ok := paramUUID(c, "id")
+	if !ok {
+		return
+	}
+
+	policy, err := h.transferService.GetPolicy(currentActor(c), eventID)
+	if err != nil {
+		serviceError(c, "Gagal mengambil aturan transfer", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Aturan transfer tiket", policy)
+}
+
+func (h *TransferHandler) ConfigurePolicy(c *gin.Context) {
+	eventID, ok := paramUUID(c, "id")
+	if !ok {
+		return
+	}
+
+	var req model.ConfigureTransferPolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Validasi gagal", err.Error())
+		return
+	}
+
+	policy, err := h.transferService.ConfigurePolicy(currentActor(c), eventID, &req)
+	if err != nil {
+		serviceError(c, "Gagal mengatur aturan transfer", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Aturan transfer berhasil diatur", policy)
+}
+
+func (h *TransferHandler) CreateTransfer(c *gin.Context) {
+	ticketID, ok := paramUUID(c, "id")
+	if !ok {
+		return
+	}
+
+	var req model.CreateTransferRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Validasi gagal", err.Error())
+		return
+	}
+
+	transfer, err := h.transferService.CreateTransfer(currentActor(c), ticketID, &req)
+	if err != nil {
+		serviceError(c, "Gagal mentransfer tiket", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "Transfer tiket berhasil dikirim", transfer)
+}
+
+func (h *TransferHandler) ListTransfers(c *gin.Context) {
+	var req model.ListTransfersRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Validasi gagal", err.Error())
+		return
+	}
+
+	result, err := h.transferService.ListTransfers(currentActor(c), &req)
+	if err != nil {
+		serviceError(c, "Gagal mengambil daftar transfer", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Daftar transfer tiket", result)
+}
+
+func (h *TransferHandler) GetTransfer(c *gin.Context) {
+	id, ok := paramUUID(c, "id")
+	if !ok {
+		return
+	}
+
+	transfer, err := h.transferService.GetTransfer(currentActor(c), id)
+	if err != nil {
+		serviceError(c, "Gagal mengambil transfer", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Detail transfer tiket", transfer)
+}
+
+// AcceptTransfer takes an optional body with the recipient's holder details.
+func (h *TransferHandler) AcceptTransfer(c *gin.Context) {
+	id, ok := paramUUID(c, "id")
+	if !ok {
+		return
+	}
+
+	var req model.AcceptTransferRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Validasi gagal", err.Error())
+			return
+		}
+	}
+
+	ticket, err := h.transferService.AcceptTransfer(currentActor(c), id, &req)
+	if err != nil {
+		serviceError(c, "Gagal menerima transfer", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Transfer diterima, tiket baru telah diterbitkan", ticket)
+}
+
+func (h *TransferHandler) DeclineTransfer(c *gin.Context) {
+	id, ok := paramUUID(c, "id")
+	if !ok {
+		return
+	}
+
+	transfer, err := h.transferService.DeclineTransfer(currentActor(c), id)
+	if err != nil {
+		serviceError(c, "Gagal menolak transfer", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Transfer ditolak", transfer)
+}
+
+func (h *TransferHandler) CancelTransfer(c *gin.Context) {
+	id, ok := paramUUID(c, "id")
+	if !ok {
+		return
+	}
+
+	transfer, err := h.transferService.CancelTransfer(currentActor(c), id)
+	if err != nil {
+		serviceError(c, "Gagal membatalkan transfer", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Transfer dibatalkan", transfer)
+}
+
+func (h *TransferHandler) TicketHistory(c *gin.Context) {
+	ticketID, ok := paramUUID(c, "id")
+	if !ok {
+		return
+	}
+
+	history, err := h.transferService.TicketHistory(currentActor(c), ticketID)
+	if err != nil {
+		serviceError(c, "Gagal mengambil riwayat tiket", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Riwayat kepemilikan tiket", history)
+}
+
+func (h *TransferHandler) EventTicketHistory(c *gin.Context) {
+	eventID, ok := paramUUID(c, "id")
+	if !ok {
+		return
+	}
+	ticketID, ok := paramUUID(c, "ticketId")
+	if !ok {
+		return
+	}
+
+	history, err := h.transferService.EventTicketHistory(currentActor(c), eventID, ticketID)
+	if err != nil {
+		serviceError(c, "Gagal mengambil riwayat tiket", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Riwayat kepemilikan tiket", history)
+}
@@ -0,0 +1,38 @@
+package handler
+
+import (
+	"e-ticketing/internal/model"
+	"e-ticketing/internal/service"
+	"e-ticketing/pkg/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type UserHandler struct {
+	userService *service.UserService
+}
+
+func NewUserHandler(userService *service.UserService) *UserHandler {
+	return &UserHandler{userService: userService}
+}
+
+func (h *UserHandler) UpdateUserRole(c *gin.Context) {
+	id, ok := paramUUID(c, "id")
+	if !ok {
+		return
+	}
+
+	var req model.UpdateUserRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Validasi gagal", err.Error())
+		return
+	}
+
+	if err := h.userService.UpdateUserRole(currentActor(c), id, req.Role); err != nil {
+		serviceError(c, "Gagal mengubah role user", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Role user berhasil diubah", gin.H{"user_id": id, "role": req.Role})
+}
@@ -0,0 +1,116 @@
+package handler
+
+import (
+	"e-ticketing/internal/model"
+	"e-ticketing/internal/service"
+	"e-ticketing/pkg/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type VenueHandler struct {
+	venueService *service.VenueService
+}
+
+func NewVenueHandler(venueService *service.VenueService) *VenueHandler {
+	return &VenueHandler{venueService: venueService}
+}
+
+func (h *VenueHandler) ListVenues(c *gin.Context) {
+	var req model.ListVenuesRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Validasi gagal", err.Error())
+		return
+	}
+
+	result, err := h.venueService.ListVenues(&req)
+	if err != nil {
+		serviceError(c, "Gagal mengambil daftar venue", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Daftar venue", result)
+}
+
+func (h *VenueHandler) NearbyVenues(c *gin.Context) {
+	var req model.NearbyVenuesRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Validasi gagal", err.Error())
+		return
+	}
+
+	result, err := h.venueService.NearbyVenues(&req)
+	if err != nil {
+		serviceError(c, "Gagal mencari venue terdekat", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Venue terdekat", result)
+}
+
+func (h *VenueHandler) GetVenue(c *gin.Context) {
+	id, ok := paramUUID(c, "id")
+	if !ok {
+		return
+	}
+
+	venue, err := h.venueService.GetVenue(id)
+	if err != nil {
+		serviceError(c, "Gagal mengambil venue", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Detail venue", venue)
+}
+
+func (h *VenueHandler) CreateVenue(c *gin.Context) {
+	var req model.CreateVenueRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Validasi gagal", err.Error())
+		return
+	}
+
+	venue, err := h.venueService.CreateVenue(currentActor(c), &req)
+	if err != nil {
+		serviceError(c, "Gagal membuat venue", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "Venue berhasil dibuat", venue)
+}
+
+func (h *VenueHandler) UpdateVenue(c *gin.Context) {
+	id, ok := paramUUID(c, "id")
+	if !ok {
+		return
+	}
+
+	var req model.UpdateVenueRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Validasi gagal", err.Error())
+		return
+	}
+
+	venue, err := h.venueService.UpdateVenue(currentActor(c), id, &req)
+	if err != nil {
+		serviceError(c, "Gagal memperbarui venue", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Venue berhasil diperbarui", venue)
+}
+
+func (h *VenueHandler) DeleteVenue(c *gin.Context) {
+	id, ok := paramUUID(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.venueService.DeleteVenue(currentActor(c), id); err != nil {
+		serviceError(c, "Gagal menghapus venue", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Venue berhasil dihapus", nil)
+}
@@ -0,0 +1,110 @@
+package handler
+
+import (
+	"e-ticketing/internal/model"
+	"e-ticketing/internal/service"
+	"e-ticketing/pkg/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// queueTokenHeader carries the waiting room token when placing holds.
+const queueTokenHeader = "X-Queue-Token"
+
+type WaitingRoomHandler struct {
+	waitingRoomService *service.WaitingRoomService
+}
+
+func NewWaitingRoomHandler(waitingRoomService *service.WaitingRoomService) *WaitingRoomHandler {
+	return &WaitingRoomHandler{waitingRoomService: waitingRoomService}
+}
+
+func (h *WaitingRoomHandler) Configure(c *gin.Context) {
+	eventID, ok := paramUUID(c, "id")
+	if !ok {
+		return
+	}
+
+	var req model.ConfigureWaitingRoomRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Validasi gagal", err.Error())
+		return
+	}
+
+	room, err := h.waitingRoomService.ConfigureWaitingRoom(currentActor(c), eventID, &req)
+	if err != nil {
+		serviceError(c, "Gagal mengatur waiting room", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Waiting room berhasil diatur", room)
+}
+
+func (h *WaitingRoomHandler) GetStats(c *gin.Context) {
+	eventID, ok := paramUUID(c, "id")
+	if !ok {
+		return
+	}
+
+	stats, err := h.waitingRoomService.GetWaitingRoomStats(currentActor(c), eventID)
+	if err != nil {
+		serviceError(c, "Gagal mengambil waiting room", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Detail waiting room", stats)
+}
+
+func (h *WaitingRoomHandler) Pause(c *gin.Context) {
+	h.setPaused(c, true, "Waiting room dijeda")
+}
+
+func (h *WaitingRoomHandler) Resume(c *gin.Context) {
+	h.setPaused(c, false, "Waiting room dilanjutkan")
+}
+
+func (h *WaitingRoomHandler) setPaused(c *gin.Context, paused bool, message string) {
+	eventID, ok := paramUUID(c, "id")
+	if !ok {
+		return
+	}
+
+	room, err := h.waitingRoomService.SetPaused(currentActor(c), eventID, paused)
+	if err != nil {
+		serviceError(c, "Gagal mengubah waiting room", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, message, room)
+}
+
+func (h *WaitingRoomHandler) JoinQueue(c *gin.Context) {
+	eventID, ok := paramUUID(c, "id")
+	if !ok {
+		return
+	}
+
+	ticket, err := h.waitingRoomService.JoinQueue(currentActor(c), eventID)
+	if err != nil {
+		serviceError(c, "Gagal masuk antrean", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Berhasil masuk antrean", ticket)
+}
+
+func (h *WaitingRoomHandler) GetQueueTicket(c *gin.Context) {
+	eventID, ok := paramUUID(c, "id")
+	if !ok {
+		return
+	}
+
+	ticket, err := h.waitingRoomService.GetQueueTicket(currentActor(c), eventID)
+	if err != nil {
+		serviceError(c, "Gagal mengambil posisi antrean", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Posisi antrean", ticket)
+}
@@ -0,0 +1,119 @@
+package handler
+
+import (
+	"e-ticketing/internal/model"
+	"e-ticketing/internal/service"
+	"e-ticketing/pkg/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type WaitlistHandler struct {
+	waitlistService *service.WaitlistService
+}
+
+func NewWaitlistHandler(waitlistService *service.WaitlistService) *WaitlistHandler {
+	return &WaitlistHandler{waitlistService: waitlistService}
+}
+
+func (h *WaitlistHandler) JoinWaitlist(c *gin.Context) {
+	eventID, ok := paramUUID(c, "id")
+	if !ok {
+		return
+	}
+
+	var req model.JoinWaitlistRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Validasi gagal", err.Error())
+		return
+	}
+
+	entry, err := h.waitlistService.JoinWaitlist(currentActor(c), eventID, &req)
+	if err != nil {
+		serviceError(c, "Gagal masuk daftar tunggu", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "Berhasil masuk daftar tunggu", entry)
+}
+
+func (h *WaitlistHandler) EventStats(c *gin.Context) {
+	eventID, ok := paramUUID(c, "id")
+	if !ok {
+		return
+	}
+
+	stats, err := h.waitlistService.EventStats(currentActor(c), eventID)
+	if err != nil {
+		serviceError(c, "Gagal mengambil daftar tunggu", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Ringkasan daftar tunggu", stats)
+}
+
+func (h *WaitlistHandler) ListMyEntries(c *gin.Context) {
+	var req model.ListWaitlistRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Validasi gagal", err.Error())
+		return
+	}
+
+	result, err := h.waitlistService.ListMyEntries(currentActor(c), &req)
+	if err != nil {
+		serviceError(c, "Gagal mengambil daftar tunggu", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Daftar tunggu", result)
+}
+
+func (h *WaitlistHandler) CancelEntry(c *gin.Context) {
+	id, ok := paramUUID(c, "id")
+	if !ok {
+		return
+	}
+
+	entry, err := h.waitlistService.CancelEntry(currentActor(c), id)
+	if err != nil {
+		serviceError(c, "Gagal keluar dari daftar tunggu", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Berhasil keluar dari daftar tunggu", entry)
+}
+
+// GetOffer is the target of the link in the offer email.
+func (h *WaitlistHandler) GetOffer(c *gin.Context) {
+	token := c.Query("token")
+	if token == "" {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Token tidak ditemukan", "Token is required")
+		return
+	}
+
+	entry, err := h.waitlistService.GetOffer(token)
+	if err != nil {
+		serviceError(c, "Gagal mengambil penawaran daftar tunggu", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Penawaran daftar tunggu", entry)
+}
+
+func (h *WaitlistHandler) ClaimOffer(c *gin.Context) {
+	var req model.ClaimWaitlistOfferRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Validasi gagal", err.Error())
+		return
+	}
+	req.DeviceID = c.GetHeader(deviceIDHeader)
+
+	claim, err := h.waitlistService.ClaimOffer(currentActor(c), &req)
+	if err != nil {
+		serviceError(c, "Gagal mengambil penawaran daftar tunggu", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "Penawaran daftar tunggu berhasil diambil", claim)
+}
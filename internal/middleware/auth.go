@@ -0,0 +1,94 @@
+package middleware
+
+import (
+	"e-ticketing/pkg/utils"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const (
+	ContextUserID   = "user_id"
+	ContextUserRole = "user_role"
+)
+
+// AuthRequired validates the Bearer JWT and stores the user ID in the context.
+func AuthRequired(secret string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		header := c.GetHeader("Authorization")
+		if !strings.HasPrefix(header, "Bearer ") {
+			utils.ErrorResponse(c, http.StatusUnauthorized, "Tidak terautentikasi", "Authorization header is required")
+			c.Abort()
+			return
+		}
+
+		claims, err := utils.ParseJWT(strings.TrimPrefix(header, "Bearer "), secret)
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusUnauthorized, "Tidak terautentikasi", err.Error())
+			c.Abort()
+			return
+		}
+
+		userID, err := uuid.Parse(claims.UserID)
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusUnauthorized, "Tidak terautentikasi", "invalid user id in token")
+			c.Abort()
+			return
+		}
+
+		c.Set(ContextUserID, userID)
+		c.Set(ContextUserRole, claims.Role)
+		c.Next()
+	}
+}
+
+// AuthOptional behaves like AuthRequired when a valid Bearer token is sent
+// and lets anonymous requests through otherwise.
+func AuthOptional(secret string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		header := c.GetHeader("Authorization")
+		if strings.HasPrefix(header, "Bearer ") {
+			if claims, err := utils.ParseJWT(strings.TrimPrefix(header, "Bearer "), secret); err == nil {
+				if userID, err := uuid.Parse(claims.UserID); err == nil {
+					c.Set(ContextUserID, userID)
+					c.Set(ContextUserRole, claims.Role)
+				}
+			}
+		}
+		c.Next()
+	}
+}
+
+// RequireRole must run after AuthRequired and rejects users whose role is not
+// in the allowed list.
+func RequireRole(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		role := c.GetString(ContextUserRole)
+		for _, allowed := range roles {
+			if role == allowed {
+				c.Next()
+				return
+			}
+		}
+
+		utils.ErrorResponse(c, http.StatusForbidden, "Akses ditolak", "insufficient role")
+		c.Abort()
+	}
+}
+
+// GetUserRole returns the authenticated user role set by AuthRequired.
+func GetUserRole(c *gin.Context) string {
+	return c.GetString(ContextUserRole)
+}
+
+// GetUserID returns the authenticated user ID set by AuthRequired.
+func GetUserID(c *gin.Context) uuid.UUID {
+	if v, ok := c.Get(ContextUserID); ok {
+		if id, ok := v.(uuid.UUID); ok {
+			return id
+		}
+	}
+	return uuid.Nil
+}
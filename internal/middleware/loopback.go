@@ -0,0 +1,26 @@
+package middleware
+
+import (
+	"e-ticketing/pkg/utils"
+	"net"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// LoopbackOnly lets through only requests made directly from the local
+// machine. It trusts the connection's address, and turns away requests a
+// proxy forwarded, since a proxy on the same host connects from loopback on
+// behalf of remote clients.
+func LoopbackOnly() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ip := net.ParseIP(c.RemoteIP())
+		forwarded := c.GetHeader("X-Forwarded-For") != "" || c.GetHeader("X-Real-IP") != "" || c.GetHeader("Forwarded") != ""
+		if ip == nil || !ip.IsLoopback() || forwarded {
+			utils.ErrorResponse(c, http.StatusForbidden, "Akses ditolak", "only available from localhost")
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
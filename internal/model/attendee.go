@@ -0,0 +1,70 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Attendee ID types
+const (
+	IDTypeKTP      = "ktp"
+	IDTypePassport = "passport"
+)
+
+// AttendeeRequirements lists the holder details an event asks for per ticket
+// at checkout.
+type AttendeeRequirements struct {
+	EventID          uuid.UUID `json:"event_id"`
+	RequireName      bool      `json:"require_name"`
+	RequireIDNumber  bool      `json:"require_id_number"`
+	RequireEmail     bool      `json:"require_email"`
+	RequirePhone     bool      `json:"require_phone"`
+	RequireBirthDate bool      `json:"require_birth_date"`
+	CreatedAt        time.Time `json:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at"`
+}
+
+// Any reports whether the event asks for any holder details at all.
+func (r *AttendeeRequirements) Any() bool {
+	return r.RequireName || r.RequireIDNumber || r.RequireEmail || r.RequirePhone || r.RequireBirthDate
+}
+
+// Attendee is the named holder of one ticket of an order item. IDNumber is
+// always the masked number; the encrypted one never leaves the server.
+type Attendee struct {
+	Sequence          int        `json:"sequence"`
+	Name              string     `json:"name,omitempty"`
+	IDType            string     `json:"id_type,omitempty"`
+	IDNumber          string     `json:"id_number,omitempty"`
+	IDNumberEncrypted string     `json:"-"`
+	Email             string     `json:"email,omitempty"`
+	Phone             string     `json:"phone,omitempty"`
+	BirthDate         *time.Time `json:"birth_date,omitempty"`
+}
+
+// Request DTOs
+type ConfigureAttendeeRequirementsRequest struct {
+	RequireName      bool `json:"require_name"`
+	RequireIDNumber  bool `json:"require_id_number"`
+	RequireEmail     bool `json:"require_email"`
+	RequirePhone     bool `json:"require_phone"`
+	RequireBirthDate bool `json:"require_birth_date"`
+}
+
+// AttendeeDetails are the holder details given for one ticket.
+type AttendeeDetails struct {
+	Name      string `json:"name" binding:"max=100"`
+	IDType    string `json:"id_type" binding:"omitempty,oneof=ktp passport"`
+	IDNumber  string `json:"id_number" binding:"max=32"`
+	Email     string `json:"email" binding:"omitempty,email,max=255"`
+	Phone     string `json:"phone" binding:"max=20"`
+	BirthDate string `json:"birth_date" binding:"omitempty,datetime=2006-01-02"`
+}
+
+// AttendeeInput names the holder of one ticket of a hold; the n-th input for
+// a hold belongs to its n-th ticket.
+type AttendeeInput struct {
+	HoldID string `json:"hold_id" binding:"required,uuid"`
+	AttendeeDetails
+}
@@ -0,0 +1,36 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Availability event types
+const (
+	AvailabilityTicketType = "ticket_type"
+	AvailabilitySeat       = "seat"
+	// AvailabilitySeatMap means the seat map was replaced; refetch it
+	AvailabilitySeatMap = "seatmap"
+	// AvailabilityResync means updates may have been missed; refetch
+	// availability before applying further events
+	AvailabilityResync = "resync"
+)
+
+// AvailabilityEvent is one change streamed to buyers watching an event.
+type AvailabilityEvent struct {
+	EventID      uuid.UUID  `json:"event_id"`
+	Type         string     `json:"type"`
+	ID           *uuid.UUID `json:"id,omitempty"`
+	TicketTypeID *uuid.UUID `json:"ticket_type_id,omitempty"`
+	Available    *int       `json:"available,omitempty"`
+	Status       string     `json:"status,omitempty"`
+	At           time.Time  `json:"at"`
+}
+
+// StockLevel is the remaining stock of a ticket type, sent as the initial
+// snapshot of an availability stream.
+type StockLevel struct {
+	TicketTypeID uuid.UUID `json:"ticket_type_id"`
+	Available    int       `json:"available"`
+}
@@ -0,0 +1,141 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Scan results
+const (
+	ScanAdmitted  = "admitted"
+	ScanDuplicate = "duplicate"
+	ScanRejected  = "rejected"
+)
+
+// GateDevice is a scanner registered to one gate of an event.
+type GateDevice struct {
+	ID           uuid.UUID  `json:"id"`
+	EventID      uuid.UUID  `json:"event_id"`
+	Name         string     `json:"name"`
+	Gate         string     `json:"gate"`
+	IsActive     bool       `json:"is_active"`
+	RegisteredBy uuid.UUID  `json:"registered_by"`
+	LastSeenAt   *time.Time `json:"last_seen_at,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+// CheckIn records the first successful scan of a ticket.
+type CheckIn struct {
+	ID           uuid.UUID `json:"id"`
+	TicketID     uuid.UUID `json:"ticket_id"`
+	EventID      uuid.UUID `json:"event_id"`
+	GateDeviceID uuid.UUID `json:"gate_device_id"`
+	Gate         string    `json:"gate"`
+	DeviceName   string    `json:"device_name"`
+	ScannedBy    uuid.UUID `json:"scanned_by"`
+	ScannedAt    time.Time `json:"scanned_at"`
+}
+
+// ScanResult is what the gate screen shows after a scan.
+type ScanResult struct {
+	Result       string         `json:"result"`
+	Reason       string         `json:"reason,omitempty"`
+	Ticket       *ScannedTicket `json:"ticket,omitempty"`
+	CheckIn      *CheckIn       `json:"check_in,omitempty"`
+	FirstCheckIn *CheckIn       `json:"first_check_in,omitempty"`
+}
+
+// ScannedTicket shows the holder to the gate. IDCheckRequired asks staff to
+// compare the holder's ID document; IDMatch is set when they entered the
+// number of the ID presented.
+type ScannedTicket struct {
+	ID              uuid.UUID `json:"id"`
+	Code            string    `json:"code"`
+	HolderName      string    `json:"holder_name"`
+	TicketTypeName  string    `json:"ticket_type_name"`
+	EventTitle      string    `json:"event_title"`
+	Status          string    `json:"status"`
+	Attendee        *Attendee `json:"attendee,omitempty"`
+	IDCheckRequired bool      `json:"id_check_required"`
+	IDMatch         *bool     `json:"id_match,omitempty"`
+}
+
+type GateAttendance struct {
+	Gate      string `json:"gate"`
+	CheckedIn int    `json:"checked_in"`
+}
+
+// Attendance is the live check-in counter of an event.
+type Attendance struct {
+	EventID   uuid.UUID        `json:"event_id"`
+	Issued    int              `json:"issued"`
+	CheckedIn int              `json:"checked_in"`
+	ByGate    []GateAttendance `json:"by_gate"`
+}
+
+// Request DTOs
+type RegisterGateDeviceRequest struct {
+	Name string `json:"name" binding:"required,max=100"`
+	Gate string `json:"gate" binding:"required,max=100"`
+}
+
+type ScanRequest struct {
+	Payload  string `json:"payload" binding:"required,max=2048"`
+	DeviceID string `json:"device_id" binding:"required,uuid"`
+	// IDNumber is the number on the ID presented at the gate, if checked
+	IDNumber string `json:"id_number" binding:"max=32"`
+}
+
+// CheckInManifest lists the tickets a scanner may admit while offline. Valid
+// and Used are ticketqr.EncodeIDSet values; the manifest is delivered inside
+// a signed ticketqr.Document.
+type CheckInManifest struct {
+	EventID     uuid.UUID `json:"event_id"`
+	DeviceID    uuid.UUID `json:"device_id"`
+	Gate        string    `json:"gate"`
+	GeneratedAt int64     `json:"generated_at"`
+	ExpiresAt   int64     `json:"expires_at"`
+	Valid       string    `json:"valid"`
+	Used        string    `json:"used"`
+	ValidCount  int       `json:"valid_count"`
+	UsedCount   int       `json:"used_count"`
+}
+
+// OfflineScan is one scan uploaded by a device after working offline, with
+// the outcome assigned by the server.
+type OfflineScan struct {
+	ID               uuid.UUID  `json:"id"`
+	GateDeviceID     uuid.UUID  `json:"gate_device_id"`
+	EventID          uuid.UUID  `json:"event_id"`
+	TicketID         uuid.UUID  `json:"ticket_id"`
+	ClientScanID     string     `json:"client_scan_id"`
+	ScannedBy        uuid.UUID  `json:"scanned_by"`
+	ScannedAt        time.Time  `json:"scanned_at"`
+	Outcome          string     `json:"outcome"`
+	Reason           string     `json:"reason,omitempty"`
+	Suspicious       bool       `json:"suspicious"`
+	ConflictDeviceID *uuid.UUID `json:"conflict_device_id,omitempty"`
+	SyncedAt         time.Time  `json:"synced_at"`
+}
+
+type SyncResult struct {
+	Processed  int           `json:"processed"`
+	Admitted   int           `json:"admitted"`
+	Duplicates int           `json:"duplicates"`
+	Rejected   int           `json:"rejected"`
+	Suspicious int           `json:"suspicious"`
+	Scans      []OfflineScan `json:"scans"`
+}
+
+type OfflineScanInput struct {
+	ClientScanID string    `json:"client_scan_id" binding:"required,max=64"`
+	TicketID     string    `json:"ticket_id" binding:"required,uuid"`
+	ScannedAt    time.Time `json:"scanned_at" binding:"required"`
+}
+
+type SyncScansRequest struct {
+	DeviceID string             `json:"device_id" binding:"required,uuid"`
+	Scans    []OfflineScanInput `json:"scans" binding:"required,min=1,max=500,dive"`
+}
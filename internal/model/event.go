@@ -0,0 +1,131 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Event statuses
+const (
+	EventStatusDraft     = "draft"
+	EventStatusPublished = "published"
+	EventStatusOnSale    = "on_sale"
+	EventStatusSoldOut   = "sold_out"
+	EventStatusCancelled = "cancelled"
+	EventStatusFinished  = "finished"
+)
+
+// EventStatusTransitions lists the statuses an event may move to from each
+// status. Cancelled and finished are terminal.
+var EventStatusTransitions = map[string][]string{
+	EventStatusDraft:     {EventStatusPublished, EventStatusCancelled},
+	EventStatusPublished: {EventStatusDraft, EventStatusOnSale, EventStatusCancelled},
+	EventStatusOnSale:    {EventStatusSoldOut, EventStatusCancelled, EventStatusFinished},
+	EventStatusSoldOut:   {EventStatusOnSale, EventStatusCancelled, EventStatusFinished},
+}
+
+// CanTransitionEvent reports whether an event may move from one status to another.
+func CanTransitionEvent(from, to string) bool {
+	for _, allowed := range EventStatusTransitions[from] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
+
+// IsPublicEventStatus reports whether events in this status are visible to buyers.
+func IsPublicEventStatus(status string) bool {
+	return status != EventStatusDraft
+}
+
+type Organizer struct {
+	ID          uuid.UUID `json:"id"`
+	OwnerID     uuid.UUID `json:"owner_id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description,omitempty"`
+	Email       string    `json:"email,omitempty"`
+	Phone       string    `json:"phone,omitempty"`
+	LogoURL     string    `json:"logo_url,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+type Event struct {
+	ID          uuid.UUID  `json:"id"`
+	OrganizerID uuid.UUID  `json:"organizer_id"`
+	VenueID     *uuid.UUID `json:"venue_id,omitempty"`
+	Title       string     `json:"title"`
+	Description string     `json:"description,omitempty"`
+	Category    string     `json:"category"`
+	BannerURL   string     `json:"banner_url,omitempty"`
+	StartTime   time.Time  `json:"start_time"`
+	EndTime     time.Time  `json:"end_time"`
+	Timezone    string     `json:"timezone"`
+	Status      string     `json:"status"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// EventFilter is the resolved list filter used by the repository.
+type EventFilter struct {
+	OrganizerID *uuid.UUID
+	Category    string
+	Statuses    []string
+	Search      string
+	StartsAfter *time.Time
+	City        string
+	Near        *GeoFilter
+	Limit       int
+	Offset      int
+}
+
+// Request DTOs
+type CreateOrganizerRequest struct {
+	Name        string `json:"name" binding:"required,min=2,max=255"`
+	Description string `json:"description"`
+	Email       string `json:"email" binding:"omitempty,email"`
+	Phone       string `json:"phone" binding:"omitempty,min=10,max=15"`
+	LogoURL     string `json:"logo_url" binding:"omitempty,url"`
+}
+
+type UpdateOrganizerRequest = CreateOrganizerRequest
+
+type CreateEventRequest struct {
+	OrganizerID string    `json:"organizer_id" binding:"required,uuid"`
+	VenueID     string    `json:"venue_id" binding:"omitempty,uuid"`
+	Title       string    `json:"title" binding:"required,min=3,max=255"`
+	Description string    `json:"description"`
+	Category    string    `json:"category" binding:"required,max=50"`
+	BannerURL   string    `json:"banner_url" binding:"omitempty,url"`
+	StartTime   time.Time `json:"start_time" binding:"required"`
+	EndTime     time.Time `json:"end_time" binding:"required,gtfield=StartTime"`
+	Timezone    string    `json:"timezone" binding:"required,timezone"`
+}
+
+type UpdateEventRequest struct {
+	VenueID     string    `json:"venue_id" binding:"omitempty,uuid"`
+	Title       string    `json:"title" binding:"required,min=3,max=255"`
+	Description string    `json:"description"`
+	Category    string    `json:"category" binding:"required,max=50"`
+	BannerURL   string    `json:"banner_url" binding:"omitempty,url"`
+	StartTime   time.Time `json:"start_time" binding:"required"`
+	EndTime     time.Time `json:"end_time" binding:"required,gtfield=StartTime"`
+	Timezone    string    `json:"timezone" binding:"required,timezone"`
+}
+
+type UpdateEventStatusRequest struct {
+	Status string `json:"status" binding:"required,oneof=draft published on_sale sold_out cancelled finished"`
+}
+
+type ListEventsRequest struct {
+	PaginationQuery
+	NearbyQuery
+	City        string `form:"city"`
+	OrganizerID string `form:"organizer_id" binding:"omitempty,uuid"`
+	Category    string `form:"category"`
+	Status      string `form:"status" binding:"omitempty,oneof=draft published on_sale sold_out cancelled finished"`
+	Search      string `form:"q"`
+	From        string `form:"from" binding:"omitempty,datetime=2006-01-02"`
+}
@@ -0,0 +1,45 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Hold statuses
+const (
+	HoldStatusActive    = "active"
+	HoldStatusReleased  = "released"
+	HoldStatusExpired   = "expired"
+	HoldStatusConverted = "converted"
+)
+
+// InventoryHold reserves a quantity of a ticket type for one buyer during
+// checkout. Stock is taken from ticket_types.available when the hold is
+// placed and returned when it is released or expires.
+type InventoryHold struct {
+	ID           uuid.UUID `json:"id"`
+	UserID       uuid.UUID `json:"user_id"`
+	TicketTypeID uuid.UUID `json:"ticket_type_id"`
+	Quantity     int       `json:"quantity"`
+	Status       string    `json:"status"`
+	ExpiresAt    time.Time `json:"expires_at"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// IsActive reports whether the hold still reserves stock at t.
+func (h *InventoryHold) IsActive(at time.Time) bool {
+	return h.Status == HoldStatusActive && at.Before(h.ExpiresAt)
+}
+
+// Request DTOs
+type CreateHoldRequest struct {
+	TicketTypeID string `json:"ticket_type_id" binding:"required,uuid"`
+	Quantity     int    `json:"quantity" binding:"required,min=1"`
+	// QueueToken comes from the X-Queue-Token header when the event has a
+	// waiting room
+	QueueToken string `json:"-"`
+	// DeviceID comes from the X-Device-ID header
+	DeviceID string `json:"-"`
+}
@@ -0,0 +1,115 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Notification categories. Security messages (OTP, verification links) are
+// mandatory and always delivered regardless of user preferences.
+const (
+	NotificationSecurity      = "security"
+	NotificationTransactional = "transactional"
+	NotificationMarketing     = "marketing"
+)
+
+const (
+	ChannelEmail    = "email"
+	ChannelWhatsApp = "whatsapp"
+)
+
+type NotificationPreference struct {
+	UserID               uuid.UUID `json:"user_id"`
+	TransactionalEnabled bool      `json:"transactional_enabled"`
+	MarketingOptIn       bool      `json:"marketing_opt_in"`
+	PreferredChannel     string    `json:"preferred_channel"`
+	QuietHoursStart      string    `json:"quiet_hours_start,omitempty"`
+	QuietHoursEnd        string    `json:"quiet_hours_end,omitempty"`
+	Timezone             string    `json:"timezone"`
+	CreatedAt            time.Time `json:"created_at"`
+	UpdatedAt            time.Time `json:"updated_at"`
+}
+
+// DefaultNotificationPreference is used for users who never saved preferences.
+func DefaultNotificationPreference(userID uuid.UUID) *NotificationPreference {
+	return &NotificationPreference{
+		UserID:               userID,
+		TransactionalEnabled: true,
+		MarketingOptIn:       false,
+		PreferredChannel:     ChannelEmail,
+		Timezone:             "Asia/Jakarta",
+	}
+}
+
+// Delivery statuses recorded in the notification log
+const (
+	DeliverySent   = "sent"
+	DeliveryFailed = "failed"
+)
+
+// Notification is a channel-agnostic message handed to NotificationService.
+type Notification struct {
+	Category     string
+	Template     string
+	Subject      string
+	EmailBody    string
+	WhatsAppBody string
+}
+
+// NotificationLog records a single outbound send attempt. Recipients are
+// stored masked for display plus a hash for lookups.
+type NotificationLog struct {
+	ID               uuid.UUID `json:"id"`
+	Channel          string    `json:"channel"`
+	Template         string    `json:"template"`
+	RecipientMasked  string    `json:"recipient"`
+	RecipientHash    string    `json:"-"`
+	Status           string    `json:"status"`
+	ProviderResponse string    `json:"provider_response,omitempty"`
+	LatencyMs        int64     `json:"latency_ms"`
+	CreatedAt        time.Time `json:"created_at"`
+}
+
+// NotificationLogFilter is the resolved search filter used by the repository.
+type NotificationLogFilter struct {
+	RecipientHashes []string
+	Channel         string
+	Status          string
+	From            *time.Time
+	To              *time.Time
+	Limit           int
+	Offset          int
+}
+
+// Request DTOs
+type SearchNotificationLogsRequest struct {
+	PaginationQuery
+	UserID  string `form:"user_id" binding:"omitempty,uuid"`
+	Channel string `form:"channel" binding:"omitempty,oneof=email whatsapp"`
+	Status  string `form:"status" binding:"omitempty,oneof=sent failed"`
+	From    string `form:"from" binding:"omitempty,datetime=2006-01-02"`
+	To      string `form:"to" binding:"omitempty,datetime=2006-01-02"`
+}
+
+type UpdateNotificationPreferenceRequest struct {
+	TransactionalEnabled *bool   `json:"transactional_enabled"`
+	MarketingOptIn       *bool   `json:"marketing_opt_in"`
+	PreferredChannel     string  `json:"preferred_channel" binding:"omitempty,oneof=email whatsapp"`
+	QuietHoursStart      *string `json:"quiet_hours_start"`
+	QuietHoursEnd        *string `json:"quiet_hours_end"`
+	Timezone             string  `json:"timezone" binding:"omitempty,timezone"`
+}
+
+// OutboxMessage is a message captured by the development outbox instead of
+// being delivered. Meta carries machine-readable values such as the OTP code.
+type OutboxMessage struct {
+	ID        string            `json:"id"`
+	Channel   string            `json:"channel"`
+	Template  string            `json:"template"`
+	Recipient string            `json:"recipient"`
+	Subject   string            `json:"subject,omitempty"`
+	Body      string            `json:"body"`
+	Meta      map[string]string `json:"meta,omitempty"`
+	CreatedAt time.Time         `json:"created_at"`
+}
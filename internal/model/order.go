@@ -0,0 +1,91 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Order statuses
+const (
+	OrderStatusPending         = "pending"
+	OrderStatusAwaitingPayment = "awaiting_payment"
+	OrderStatusPaid            = "paid"
+	OrderStatusExpired         = "expired"
+	OrderStatusCancelled       = "cancelled"
+	OrderStatusRefunded        = "refunded"
+)
+
+// OrderStatusTransitions lists the statuses an order may move to from each
+// status. Expired, cancelled and refunded are terminal.
+var OrderStatusTransitions = map[string][]string{
+	OrderStatusPending:         {OrderStatusAwaitingPayment, OrderStatusPaid, OrderStatusExpired, OrderStatusCancelled},
+	OrderStatusAwaitingPayment: {OrderStatusPaid, OrderStatusExpired, OrderStatusCancelled},
+	OrderStatusPaid:            {OrderStatusRefunded},
+}
+
+// CanTransitionOrder reports whether an order may move from one status to another.
+func CanTransitionOrder(from, to string) bool {
+	for _, allowed := range OrderStatusTransitions[from] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
+
+// OpenOrderStatuses still hold stock and can be paid or expire.
+var OpenOrderStatuses = []string{OrderStatusPending, OrderStatusAwaitingPayment}
+
+// Order is what the buyer pays for. TotalAmount is due after
+// DiscountAmount, the discount PromoCode gave; RefundedAmount of it has been
+// paid back.
+type Order struct {
+	ID             uuid.UUID   `json:"id"`
+	OrderNumber    string      `json:"order_number"`
+	UserID         uuid.UUID   `json:"user_id"`
+	EventID        uuid.UUID   `json:"event_id"`
+	Status         string      `json:"status"`
+	TotalAmount    int64       `json:"total_amount"`
+	DiscountAmount int64       `json:"discount_amount"`
+	PromoCode      string      `json:"promo_code,omitempty"`
+	RefundedAmount int64       `json:"refunded_amount"`
+	Currency       string      `json:"currency"`
+	ExpiresAt      time.Time   `json:"expires_at"`
+	PaidAt         *time.Time  `json:"paid_at,omitempty"`
+	Items          []OrderItem `json:"items,omitempty"`
+	CreatedAt      time.Time   `json:"created_at"`
+	UpdatedAt      time.Time   `json:"updated_at"`
+}
+
+// OrderItem buys Quantity tickets of a type from stock, or with
+// ResaleListingID set, one ticket resold on the platform. DiscountAmount is
+// its share of the order's discount, taken off Subtotal.
+type OrderItem struct {
+	ID              uuid.UUID   `json:"id"`
+	OrderID         uuid.UUID   `json:"order_id"`
+	TicketTypeID    uuid.UUID   `json:"ticket_type_id"`
+	HoldID          *uuid.UUID  `json:"hold_id,omitempty"`
+	ResaleListingID *uuid.UUID  `json:"resale_listing_id,omitempty"`
+	Quantity        int         `json:"quantity"`
+	UnitPrice       int64       `json:"unit_price"`
+	Subtotal        int64       `json:"subtotal"`
+	DiscountAmount  int64       `json:"discount_amount"`
+	Seats           []OrderSeat `json:"seats,omitempty"`
+	Attendees       []Attendee  `json:"attendees,omitempty"`
+	CreatedAt       time.Time   `json:"created_at"`
+}
+
+// Request DTOs
+type CreateOrderRequest struct {
+	HoldIDs   []string        `json:"hold_ids" binding:"required,min=1,max=10,dive,uuid"`
+	Attendees []AttendeeInput `json:"attendees" binding:"omitempty,max=100,dive"`
+	PromoCode string          `json:"promo_code" binding:"omitempty,max=50"`
+	// DeviceID comes from the X-Device-ID header
+	DeviceID string `json:"-"`
+}
+
+type ListOrdersRequest struct {
+	PaginationQuery
+	Status string `form:"status" binding:"omitempty,oneof=pending awaiting_payment paid expired cancelled refunded"`
+}
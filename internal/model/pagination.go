@@ -0,0 +1,31 @@
+package model
+
+type PaginationQuery struct {
+	Page  int `form:"page" binding:"omitempty,min=1"`
+	Limit int `form:"limit" binding:"omitempty,min=1,max=100"`
+}
+
+// Normalize fills in defaults for missing page and limit values.
+func (p *PaginationQuery) Normalize() {
+	if p.Page < 1 {
+		p.Page = 1
+	}
+	if p.Limit < 1 {
+		p.Limit = 20
+	}
+}
+
+func (p *PaginationQuery) Offset() int {
+	return (p.Page - 1) * p.Limit
+}
+
+type PaginationMeta struct {
+	Page  int `json:"page"`
+	Limit int `json:"limit"`
+	Total int `json:"total"`
+}
+
+type PaginatedResponse struct {
+	Items      interface{}    `json:"items"`
+	Pagination PaginationMeta `json:"pagination"`
+}
@@ -0,0 +1,105 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Payment statuses
+const (
+	PaymentStatusPending  = "pending"
+	PaymentStatusPaid     = "paid"
+	PaymentStatusFailed   = "failed"
+	PaymentStatusExpired  = "expired"
+	PaymentStatusRefunded = "refunded"
+)
+
+// Payment methods
+const (
+	PaymentMethodBankTransfer = "bank_transfer"
+	PaymentMethodQRIS         = "qris"
+	PaymentMethodGoPay        = "gopay"
+	PaymentMethodShopeePay    = "shopeepay"
+)
+
+type Payment struct {
+	ID               uuid.UUID  `json:"id"`
+	OrderID          uuid.UUID  `json:"order_id"`
+	Gateway          string     `json:"gateway"`
+	Method           string     `json:"method"`
+	Bank             string     `json:"bank,omitempty"`
+	Status           string     `json:"status"`
+	Amount           int64      `json:"amount"`
+	Currency         string     `json:"currency"`
+	ExternalID       string     `json:"external_id"`
+	GatewayReference string     `json:"gateway_reference,omitempty"`
+	PaymentToken     string     `json:"payment_token,omitempty"`
+	RedirectURL      string     `json:"redirect_url,omitempty"`
+	VANumber         string     `json:"va_number,omitempty"`
+	QRString         string     `json:"qr_string,omitempty"`
+	ExpiresAt        *time.Time `json:"expires_at,omitempty"`
+	PaidAt           *time.Time `json:"paid_at,omitempty"`
+	CreatedAt        time.Time  `json:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at"`
+}
+
+// ChargeRequest is what PaymentService asks a gateway to create.
+type ChargeRequest struct {
+	ExternalID    string
+	Amount        int64
+	Currency      string
+	Method        string
+	Bank          string
+	CustomerName  string
+	CustomerEmail string
+	CustomerPhone string
+	ExpiresAt     time.Time
+}
+
+// ChargeResult holds the payment instructions returned by a gateway.
+type ChargeResult struct {
+	Reference   string
+	Token       string
+	RedirectURL string
+	VANumber    string
+	QRString    string
+}
+
+// GatewayStatus is a gateway-reported transaction state normalised to one of
+// the payment statuses.
+type GatewayStatus struct {
+	ExternalID string
+	Reference  string
+	Status     string
+	Amount     int64
+	// Instrument identifies the card or account that paid, when known
+	Instrument string
+	Raw        string
+}
+
+// ChargeRefund is what RefundService asks a gateway to pay back. RefundKey
+// identifies the refund so a retried call is not paid twice.
+type ChargeRefund struct {
+	ExternalID string
+	RefundKey  string
+	Amount     int64
+	Reason     string
+}
+
+// RefundResult is a gateway's confirmation of a refund.
+type RefundResult struct {
+	Reference string
+	Raw       string
+}
+
+// Request DTOs
+type CreatePaymentRequest struct {
+	Method string `json:"method" binding:"required,oneof=bank_transfer qris gopay shopeepay"`
+	Bank   string `json:"bank" binding:"required_if=Method bank_transfer,omitempty,oneof=bca bni bri mandiri permata"`
+}
+
+type SimulatePaymentRequest struct {
+	Result     string `json:"result" binding:"required,oneof=success fail expire"`
+	MaskedCard string `json:"masked_card" binding:"omitempty,max=32"`
+}
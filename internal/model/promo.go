@@ -0,0 +1,128 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Discount types
+const (
+	DiscountTypeFixed      = "fixed"
+	DiscountTypePercentage = "percentage"
+)
+
+// Promo redemption statuses
+const (
+	RedemptionStatusActive   = "active"
+	RedemptionStatusReleased = "released"
+)
+
+// Promotion is an organizer's discount rule. A fixed discount takes
+// DiscountValue off the order; a percentage one takes DiscountValue percent
+// of the eligible tickets, at most MaxDiscount. Only tickets of
+// TicketTypeIDs are eligible, or all of the event's when it is empty.
+// UsageCount counts the orders currently redeeming it.
+type Promotion struct {
+	ID             uuid.UUID   `json:"id"`
+	EventID        uuid.UUID   `json:"event_id"`
+	Name           string      `json:"name"`
+	DiscountType   string      `json:"discount_type"`
+	DiscountValue  int64       `json:"discount_value"`
+	MaxDiscount    *int64      `json:"max_discount,omitempty"`
+	MinOrderAmount int64       `json:"min_order_amount"`
+	UsageLimit     *int        `json:"usage_limit,omitempty"`
+	PerUserLimit   *int        `json:"per_user_limit,omitempty"`
+	UsageCount     int         `json:"usage_count"`
+	StartsAt       *time.Time  `json:"starts_at,omitempty"`
+	EndsAt         *time.Time  `json:"ends_at,omitempty"`
+	Active         bool        `json:"active"`
+	TicketTypeIDs  []uuid.UUID `json:"ticket_type_ids"`
+	CreatedAt      time.Time   `json:"created_at"`
+	UpdatedAt      time.Time   `json:"updated_at"`
+}
+
+// IsValid reports whether the promotion can be redeemed at t.
+func (p *Promotion) IsValid(at time.Time) bool {
+	if !p.Active {
+		return false
+	}
+	if p.StartsAt != nil && at.Before(*p.StartsAt) {
+		return false
+	}
+	return p.EndsAt == nil || at.Before(*p.EndsAt)
+}
+
+// AppliesTo reports whether tickets of the type are eligible.
+func (p *Promotion) AppliesTo(ticketTypeID uuid.UUID) bool {
+	if len(p.TicketTypeIDs) == 0 {
+		return true
+	}
+	for _, id := range p.TicketTypeIDs {
+		if id == ticketTypeID {
+			return true
+		}
+	}
+	return false
+}
+
+// PromoCode redeems a promotion. MaxUses caps how many orders may use this
+// code; nil leaves only the promotion's limits.
+type PromoCode struct {
+	ID          uuid.UUID `json:"id"`
+	PromotionID uuid.UUID `json:"promotion_id"`
+	EventID     uuid.UUID `json:"event_id"`
+	Code        string    `json:"code"`
+	MaxUses     *int      `json:"max_uses,omitempty"`
+	UsageCount  int       `json:"usage_count"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// PromoRedemption records the discount an order received through a code.
+type PromoRedemption struct {
+	ID             uuid.UUID `json:"id"`
+	PromotionID    uuid.UUID `json:"promotion_id"`
+	PromoCodeID    uuid.UUID `json:"promo_code_id"`
+	OrderID        uuid.UUID `json:"order_id"`
+	UserID         uuid.UUID `json:"user_id"`
+	DiscountAmount int64     `json:"discount_amount"`
+	Status         string    `json:"status"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// Request DTOs
+type CreatePromotionRequest struct {
+	Name           string     `json:"name" binding:"required,min=2,max=100"`
+	DiscountType   string     `json:"discount_type" binding:"required,oneof=fixed percentage"`
+	DiscountValue  int64      `json:"discount_value" binding:"required,min=1"`
+	MaxDiscount    *int64     `json:"max_discount" binding:"omitempty,min=1"`
+	MinOrderAmount int64      `json:"min_order_amount" binding:"omitempty,min=0"`
+	UsageLimit     *int       `json:"usage_limit" binding:"omitempty,min=1"`
+	PerUserLimit   *int       `json:"per_user_limit" binding:"omitempty,min=1"`
+	StartsAt       *time.Time `json:"starts_at"`
+	EndsAt         *time.Time `json:"ends_at"`
+	Active         *bool      `json:"active"`
+	TicketTypeIDs  []string   `json:"ticket_type_ids" binding:"omitempty,max=50,dive,uuid"`
+}
+
+type UpdatePromotionRequest = CreatePromotionRequest
+
+type CreatePromoCodeRequest struct {
+	Code    string `json:"code" binding:"required,min=3,max=50,alphanum"`
+	MaxUses *int   `json:"max_uses" binding:"omitempty,min=1"`
+}
+
+// GeneratePromoCodesRequest creates Count single-use codes starting with
+// Prefix.
+type GeneratePromoCodesRequest struct {
+	Count  int    `json:"count" binding:"required,min=1,max=1000"`
+	Prefix string `json:"prefix" binding:"omitempty,max=20,alphanum"`
+}
+
+type ListPromotionsRequest struct {
+	PaginationQuery
+}
+
+type ListPromoCodesRequest struct {
+	PaginationQuery
+}
@@ -0,0 +1,84 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Purchase signal kinds
+const (
+	SignalDevice     = "device"
+	SignalInstrument = "instrument"
+)
+
+// PurchasePolicy holds an event's anti-scalping rules. A zero limit means
+// no limit. Ticket counts cover active holds, open orders and paid orders.
+type PurchasePolicy struct {
+	EventID                 uuid.UUID `json:"event_id"`
+	MaxTicketsPerUser       int       `json:"max_tickets_per_user"`
+	MaxTicketsPerPhone      int       `json:"max_tickets_per_phone"`
+	MaxTicketsPerInstrument int       `json:"max_tickets_per_instrument"`
+	RequireStepUp           bool      `json:"require_step_up"`
+	StepUpWindowMinutes     int       `json:"step_up_window_minutes"`
+	CreatedAt               time.Time `json:"created_at"`
+	UpdatedAt               time.Time `json:"updated_at"`
+}
+
+// HoldLimit is the per-user and per-phone limit a new hold of Quantity
+// tickets must stay within. A zero limit means no limit.
+type HoldLimit struct {
+	EventID     uuid.UUID
+	UserID      uuid.UUID
+	Quantity    int
+	MaxPerUser  int
+	MaxPerPhone int
+}
+
+// InstrumentLimit is the per-instrument limit an order of Quantity tickets
+// must stay within once it is paid with the instrument.
+type InstrumentLimit struct {
+	EventID        uuid.UUID
+	OrderID        uuid.UUID
+	InstrumentHash string
+	Quantity       int
+	Max            int
+}
+
+// PurchaseCluster is a group of accounts that bought for the same event
+// from one device or payment instrument.
+type PurchaseCluster struct {
+	Kind     string           `json:"kind"`
+	ID       string           `json:"id"`
+	Accounts []ClusterAccount `json:"accounts"`
+	Tickets  int              `json:"tickets"`
+}
+
+type ClusterAccount struct {
+	UserID  uuid.UUID `json:"user_id"`
+	Name    string    `json:"name"`
+	Email   string    `json:"email"`
+	Phone   string    `json:"phone"`
+	Tickets int       `json:"tickets"`
+}
+
+type StepUpResponse struct {
+	StepUpAt time.Time `json:"step_up_at"`
+}
+
+// Request DTOs
+type ConfigurePurchasePolicyRequest struct {
+	MaxTicketsPerUser       int  `json:"max_tickets_per_user" binding:"min=0,max=1000"`
+	MaxTicketsPerPhone      int  `json:"max_tickets_per_phone" binding:"min=0,max=1000"`
+	MaxTicketsPerInstrument int  `json:"max_tickets_per_instrument" binding:"min=0,max=1000"`
+	RequireStepUp           bool `json:"require_step_up"`
+	StepUpWindowMinutes     int  `json:"step_up_window_minutes" binding:"omitempty,min=1,max=1440"`
+}
+
+type RequestStepUpRequest struct {
+	Method string `json:"method" binding:"required,oneof=email whatsapp"`
+}
+
+type VerifyStepUpRequest struct {
+	OTP string `json:"otp" binding:"required,len=6"`
+}
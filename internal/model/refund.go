@@ -0,0 +1,108 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Refund statuses. An approved refund has its tickets voided and waits for
+// the gateway; a failed one can be retried or settled by hand.
+const (
+	RefundStatusRequested = "requested"
+	RefundStatusApproved  = "approved"
+	RefundStatusRefunded  = "refunded"
+	RefundStatusFailed    = "failed"
+	RefundStatusRejected  = "rejected"
+	RefundStatusCancelled = "cancelled"
+)
+
+// RefundPolicy says whether buyers of an event may ask for their money back.
+// Requests close DeadlineHoursBeforeStart before the event starts and
+// FeePercent of the refunded amount is kept. Cancelled events refund in full
+// regardless of the policy.
+type RefundPolicy struct {
+	EventID                  uuid.UUID `json:"event_id"`
+	Enabled                  bool      `json:"enabled"`
+	DeadlineHoursBeforeStart int       `json:"deadline_hours_before_start"`
+	FeePercent               int       `json:"fee_percent"`
+	CreatedAt                time.Time `json:"created_at"`
+	UpdatedAt                time.Time `json:"updated_at"`
+}
+
+// Refund asks for some or all of an order's tickets to be paid back. Amount
+// is what was paid for them; RefundAmount is returned after FeeAmount.
+// Refunds with a PaymentID instead pay back that whole payment because it
+// could not go towards the order, and those with an OrderItemID a resale
+// item that could not be delivered; neither has items.
+type Refund struct {
+	ID               uuid.UUID    `json:"id"`
+	OrderID          uuid.UUID    `json:"order_id"`
+	PaymentID        *uuid.UUID   `json:"payment_id,omitempty"`
+	OrderItemID      *uuid.UUID   `json:"order_item_id,omitempty"`
+	EventID          uuid.UUID    `json:"event_id"`
+	UserID           uuid.UUID    `json:"user_id"`
+	Status           string       `json:"status"`
+	Reason           string       `json:"reason"`
+	Amount           int64        `json:"amount"`
+	FeeAmount        int64        `json:"fee_amount"`
+	RefundAmount     int64        `json:"refund_amount"`
+	Currency         string       `json:"currency"`
+	RejectReason     string       `json:"reject_reason,omitempty"`
+	FailureReason    string       `json:"failure_reason,omitempty"`
+	GatewayReference string       `json:"gateway_reference,omitempty"`
+	ReviewedBy       *uuid.UUID   `json:"reviewed_by,omitempty"`
+	ReviewedAt       *time.Time   `json:"reviewed_at,omitempty"`
+	RefundedAt       *time.Time   `json:"refunded_at,omitempty"`
+	Items            []RefundItem `json:"items,omitempty"`
+	CreatedAt        time.Time    `json:"created_at"`
+	UpdatedAt        time.Time    `json:"updated_at"`
+	OrderNumber      string       `json:"order_number,omitempty"`
+	EventTitle       string       `json:"event_title,omitempty"`
+}
+
+// RefundItem is one ticket of a refund and what was paid for it.
+type RefundItem struct {
+	TicketID       uuid.UUID `json:"ticket_id"`
+	Code           string    `json:"code,omitempty"`
+	TicketTypeName string    `json:"ticket_type_name,omitempty"`
+	Amount         int64     `json:"amount"`
+}
+
+// RefundFilter is the resolved list filter used by the repository.
+type RefundFilter struct {
+	EventID *uuid.UUID
+	UserID  *uuid.UUID
+	Status  string
+	Limit   int
+	Offset  int
+}
+
+// Request DTOs
+type ConfigureRefundPolicyRequest struct {
+	Enabled                  *bool `json:"enabled" binding:"required"`
+	DeadlineHoursBeforeStart int   `json:"deadline_hours_before_start" binding:"min=0,max=8760"`
+	FeePercent               int   `json:"fee_percent" binding:"min=0,max=100"`
+}
+
+// CreateRefundRequest refunds the listed tickets of the order, or all of its
+// refundable tickets when TicketIDs is empty.
+type CreateRefundRequest struct {
+	TicketIDs []string `json:"ticket_ids" binding:"omitempty,max=100,dive,uuid"`
+	Reason    string   `json:"reason" binding:"required,max=500"`
+}
+
+type RejectRefundRequest struct {
+	Reason string `json:"reason" binding:"required,max=500"`
+}
+
+// CompleteRefundRequest records a refund paid back outside the gateway, e.g.
+// by bank transfer for a virtual account payment.
+type CompleteRefundRequest struct {
+	Reference string `json:"reference" binding:"required,max=100"`
+}
+
+type ListRefundsRequest struct {
+	PaginationQuery
+	Status string `form:"status" binding:"omitempty,oneof=requested approved refunded failed rejected cancelled"`
+}
@@ -0,0 +1,138 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Resale listing statuses
+const (
+	ResaleStatusActive    = "active"
+	ResaleStatusSold      = "sold"
+	ResaleStatusWithdrawn = "withdrawn"
+)
+
+// Resale withdraw reasons; all but the seller's own are set automatically.
+const (
+	ResaleWithdrawnBySeller   = "seller"
+	ResaleWithdrawnTicketUsed = "ticket_used"
+	ResaleWithdrawnTicketGone = "ticket_unavailable"
+	ResaleWithdrawnCancelled  = "event_cancelled"
+	ResaleWithdrawnClosed     = "resale_closed"
+)
+
+// Resale payout statuses
+const (
+	PayoutStatusPending = "pending"
+	PayoutStatusPaid    = "paid"
+)
+
+// ResalePolicy says whether an event's tickets may be resold on the platform
+// and at most at what share of face value. FeePercent is the platform fee
+// deducted from the seller's payout.
+type ResalePolicy struct {
+	EventID                 uuid.UUID `json:"event_id"`
+	Enabled                 bool      `json:"enabled"`
+	MaxPricePercent         int       `json:"max_price_percent"`
+	CloseMinutesBeforeStart int       `json:"close_minutes_before_start"`
+	FeePercent              int       `json:"fee_percent"`
+	CreatedAt               time.Time `json:"created_at"`
+	UpdatedAt               time.Time `json:"updated_at"`
+}
+
+// ResaleListing offers a ticket for sale. While a buyer's order is open the
+// listing stays active but is reserved by OrderID; once paid, the seller's
+// ticket is replaced by NewTicketID in the buyer's name.
+type ResaleListing struct {
+	ID              uuid.UUID  `json:"id"`
+	TicketID        uuid.UUID  `json:"ticket_id"`
+	EventID         uuid.UUID  `json:"event_id"`
+	TicketTypeID    uuid.UUID  `json:"ticket_type_id"`
+	SellerUserID    uuid.UUID  `json:"seller_user_id"`
+	Price           int64      `json:"price"`
+	FaceValue       int64      `json:"face_value"`
+	FeeAmount       int64      `json:"fee_amount"`
+	PayoutAmount    int64      `json:"payout_amount"`
+	Currency        string     `json:"currency"`
+	Status          string     `json:"status"`
+	WithdrawReason  string     `json:"withdraw_reason,omitempty"`
+	Reserved        bool       `json:"reserved"`
+	OrderID         *uuid.UUID `json:"order_id,omitempty"`
+	BuyerUserID     *uuid.UUID `json:"buyer_user_id,omitempty"`
+	NewTicketID     *uuid.UUID `json:"new_ticket_id,omitempty"`
+	PayoutStatus    string     `json:"payout_status,omitempty"`
+	PayoutReference string     `json:"payout_reference,omitempty"`
+	SoldAt          *time.Time `json:"sold_at,omitempty"`
+	WithdrawnAt     *time.Time `json:"withdrawn_at,omitempty"`
+	PaidOutAt       *time.Time `json:"paid_out_at,omitempty"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+	TicketTypeName  string     `json:"ticket_type_name,omitempty"`
+	EventTitle      string     `json:"event_title,omitempty"`
+	Seat            *OrderSeat `json:"seat,omitempty"`
+}
+
+// ResaleOffer is the public view of an active listing; it leaves out the
+// seller and the ticket being sold.
+type ResaleOffer struct {
+	ID             uuid.UUID  `json:"id"`
+	EventID        uuid.UUID  `json:"event_id"`
+	TicketTypeID   uuid.UUID  `json:"ticket_type_id"`
+	TicketTypeName string     `json:"ticket_type_name"`
+	Price          int64      `json:"price"`
+	FaceValue      int64      `json:"face_value"`
+	Currency       string     `json:"currency"`
+	Reserved       bool       `json:"reserved"`
+	Seat           *OrderSeat `json:"seat,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+// ResaleListingFilter is the resolved list filter used by the repository.
+type ResaleListingFilter struct {
+	EventID      *uuid.UUID
+	TicketTypeID *uuid.UUID
+	SellerID     *uuid.UUID
+	Status       string
+	PayoutStatus string
+	Limit        int
+	Offset       int
+}
+
+// Request DTOs
+type ConfigureResalePolicyRequest struct {
+	Enabled                 *bool `json:"enabled" binding:"required"`
+	MaxPricePercent         int   `json:"max_price_percent" binding:"required,min=1,max=100"`
+	CloseMinutesBeforeStart int   `json:"close_minutes_before_start" binding:"min=0,max=10080"`
+}
+
+type CreateResaleListingRequest struct {
+	Price int64 `json:"price" binding:"required,min=1"`
+}
+
+// BuyResaleListingRequest carries the buyer's holder details when the event
+// asks for them.
+type BuyResaleListingRequest struct {
+	Attendee *AttendeeDetails `json:"attendee"`
+	// DeviceID comes from the X-Device-ID header
+	DeviceID string `json:"-"`
+}
+
+type ListResaleListingsRequest struct {
+	PaginationQuery
+	TicketTypeID string `form:"ticket_type_id" binding:"omitempty,uuid"`
+}
+
+type ListMyResaleListingsRequest struct {
+	PaginationQuery
+	Status string `form:"status" binding:"omitempty,oneof=active sold withdrawn"`
+}
+
+type ListResalePayoutsRequest struct {
+	PaginationQuery
+	PayoutStatus string `form:"payout_status" binding:"omitempty,oneof=pending paid"`
+}
+
+type MarkResalePayoutRequest struct {
+	Reference string `json:"reference" binding:"required,max=100"`
+}
@@ -0,0 +1,88 @@
+package model
+
+import (
+	"github.com/google/uuid"
+)
+
+// Seat statuses
+const (
+	SeatStatusAvailable = "available"
+	SeatStatusHeld      = "held"
+	SeatStatusSold      = "sold"
+	SeatStatusBlocked   = "blocked"
+)
+
+type SeatSection struct {
+	ID        uuid.UUID `json:"id"`
+	EventID   uuid.UUID `json:"event_id"`
+	Name      string    `json:"name"`
+	SortOrder int       `json:"sort_order"`
+	Seats     []Seat    `json:"seats"`
+}
+
+// Seat is one sellable place. Its ticket type is the price tier; X and Y
+// position it on the rendered map.
+type Seat struct {
+	ID           uuid.UUID  `json:"id"`
+	EventID      uuid.UUID  `json:"-"`
+	SectionID    uuid.UUID  `json:"section_id"`
+	Row          string     `json:"row"`
+	Number       string     `json:"number"`
+	X            float64    `json:"x"`
+	Y            float64    `json:"y"`
+	TicketTypeID uuid.UUID  `json:"ticket_type_id"`
+	Status       string     `json:"status"`
+	HoldID       *uuid.UUID `json:"-"`
+}
+
+type SeatTier struct {
+	TicketTypeID uuid.UUID `json:"ticket_type_id"`
+	Name         string    `json:"name"`
+	Price        int64     `json:"price"`
+	Currency     string    `json:"currency"`
+}
+
+// SeatMap is the seat-level availability of an event.
+type SeatMap struct {
+	EventID        uuid.UUID     `json:"event_id"`
+	Sections       []SeatSection `json:"sections"`
+	Tiers          []SeatTier    `json:"tiers"`
+	TotalSeats     int           `json:"total_seats"`
+	AvailableSeats int           `json:"available_seats"`
+}
+
+// OrderSeat is a seat assigned to an order item.
+type OrderSeat struct {
+	SeatID  uuid.UUID `json:"seat_id"`
+	Section string    `json:"section"`
+	Row     string    `json:"row"`
+	Number  string    `json:"number"`
+}
+
+// SeatMapImport is the JSON import format. CSV imports use the columns
+// section,row,number,x,y,tier[,blocked] and are converted to this shape.
+// Tier is the name of one of the event's ticket types.
+type SeatMapImport struct {
+	Sections []SeatSectionImport `json:"sections" binding:"required,min=1,dive"`
+}
+
+type SeatSectionImport struct {
+	Name  string       `json:"name" binding:"required,max=100"`
+	Seats []SeatImport `json:"seats" binding:"required,min=1,dive"`
+}
+
+type SeatImport struct {
+	Row     string  `json:"row" binding:"required,max=20"`
+	Number  string  `json:"number" binding:"required,max=20"`
+	X       float64 `json:"x"`
+	Y       float64 `json:"y"`
+	Tier    string  `json:"tier" binding:"required"`
+	Blocked bool    `json:"blocked"`
+}
+
+// Request DTOs
+type CreateSeatHoldRequest struct {
+	SeatIDs    []string `json:"seat_ids" binding:"required,min=1,max=20,dive,uuid"`
+	QueueToken string   `json:"-"`
+	DeviceID   string   `json:"-"`
+}
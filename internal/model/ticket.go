@@ -0,0 +1,58 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Ticket statuses
+const (
+	TicketStatusValid       = "valid"
+	TicketStatusUsed        = "used"
+	TicketStatusVoid        = "void"
+	TicketStatusTransferred = "transferred"
+)
+
+// Ticket is one admission issued from a paid order item. Sequence numbers
+// the tickets within an item so issuing the same order twice is a no-op.
+// A transfer voids the ticket and issues a new one whose PreviousTicketID
+// points back at it.
+type Ticket struct {
+	ID               uuid.UUID  `json:"id"`
+	Code             string     `json:"code"`
+	OrderID          uuid.UUID  `json:"order_id"`
+	OrderItemID      uuid.UUID  `json:"order_item_id"`
+	Sequence         int        `json:"sequence"`
+	EventID          uuid.UUID  `json:"event_id"`
+	TicketTypeID     uuid.UUID  `json:"ticket_type_id"`
+	UserID           uuid.UUID  `json:"user_id"`
+	SeatID           *uuid.UUID `json:"seat_id,omitempty"`
+	PreviousTicketID *uuid.UUID `json:"previous_ticket_id,omitempty"`
+	Seat             *OrderSeat `json:"seat,omitempty"`
+	HolderName       string     `json:"holder_name"`
+	Attendee         *Attendee  `json:"attendee,omitempty"`
+	Status           string     `json:"status"`
+	UsedAt           *time.Time `json:"used_at,omitempty"`
+	CreatedAt        time.Time  `json:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at"`
+	TicketTypeName   string     `json:"ticket_type_name,omitempty"`
+	EventTitle       string     `json:"event_title,omitempty"`
+	EventStartTime   *time.Time `json:"event_start_time,omitempty"`
+}
+
+// TicketFilter is the resolved list filter used by the repository.
+type TicketFilter struct {
+	UserID  *uuid.UUID
+	EventID *uuid.UUID
+	Status  string
+	Limit   int
+	Offset  int
+}
+
+// Request DTOs
+type ListTicketsRequest struct {
+	PaginationQuery
+	Status  string `form:"status" binding:"omitempty,oneof=valid used void transferred"`
+	EventID string `form:"event_id" binding:"omitempty,uuid"`
+}
@@ -0,0 +1,60 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Ticket type visibility
+const (
+	TicketVisibilityPublic = "public"
+	TicketVisibilityHidden = "hidden"
+)
+
+// DefaultCurrency is used when a ticket type does not specify one. Prices are
+// always stored as integer amounts of the currency's smallest unit (rupiah).
+const DefaultCurrency = "IDR"
+
+type TicketType struct {
+	ID          uuid.UUID `json:"id"`
+	EventID     uuid.UUID `json:"event_id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description,omitempty"`
+	Price       int64     `json:"price"`
+	Currency    string    `json:"currency"`
+	Quota       int       `json:"quota"`
+	Available   int       `json:"available"`
+	MinPerOrder int       `json:"min_per_order"`
+	MaxPerOrder int       `json:"max_per_order"`
+	SalesStart  time.Time `json:"sales_start"`
+	SalesEnd    time.Time `json:"sales_end"`
+	Visibility  string    `json:"visibility"`
+	SortOrder   int       `json:"sort_order"`
+	// ReservedSeating types are sold per seat; quota follows the seat map
+	ReservedSeating bool      `json:"reserved_seating"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// IsOnSale reports whether the ticket type sales window is open at t.
+func (t *TicketType) IsOnSale(at time.Time) bool {
+	return !at.Before(t.SalesStart) && at.Before(t.SalesEnd)
+}
+
+// Request DTOs
+type CreateTicketTypeRequest struct {
+	Name        string    `json:"name" binding:"required,min=2,max=100"`
+	Description string    `json:"description"`
+	Price       *int64    `json:"price" binding:"required,min=0"`
+	Currency    string    `json:"currency" binding:"omitempty,iso4217"`
+	Quota       int       `json:"quota" binding:"required,min=1"`
+	MinPerOrder int       `json:"min_per_order" binding:"omitempty,min=1"`
+	MaxPerOrder int       `json:"max_per_order" binding:"omitempty,min=1"`
+	SalesStart  time.Time `json:"sales_start" binding:"required"`
+	SalesEnd    time.Time `json:"sales_end" binding:"required,gtfield=SalesStart"`
+	Visibility  string    `json:"visibility" binding:"omitempty,oneof=public hidden"`
+	SortOrder   int       `json:"sort_order"`
+}
+
+type UpdateTicketTypeRequest = CreateTicketTypeRequest
@@ -0,0 +1,109 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Transfer statuses
+const (
+	TransferStatusPending   = "pending"
+	TransferStatusAccepted  = "accepted"
+	TransferStatusDeclined  = "declined"
+	TransferStatusCancelled = "cancelled"
+	TransferStatusExpired   = "expired"
+)
+
+// Ownership sources
+const (
+	OwnershipPurchase = "purchase"
+	OwnershipTransfer = "transfer"
+)
+
+// TransferPolicy says when and how often tickets of an event may change
+// hands. Transfers close CloseMinutesBeforeStart before the event starts;
+// MaxTransfersPerTicket of 0 means no limit.
+type TransferPolicy struct {
+	EventID                 uuid.UUID  `json:"event_id"`
+	Enabled                 bool       `json:"enabled"`
+	OpensAt                 *time.Time `json:"opens_at,omitempty"`
+	CloseMinutesBeforeStart int        `json:"close_minutes_before_start"`
+	MaxTransfersPerTicket   int        `json:"max_transfers_per_ticket"`
+	CreatedAt               time.Time  `json:"created_at"`
+	UpdatedAt               time.Time  `json:"updated_at"`
+}
+
+// TicketTransfer is an offer of a ticket to the owner of an email or phone.
+type TicketTransfer struct {
+	ID             uuid.UUID  `json:"id"`
+	TicketID       uuid.UUID  `json:"ticket_id"`
+	EventID        uuid.UUID  `json:"event_id"`
+	FromUserID     uuid.UUID  `json:"from_user_id"`
+	FromName       string     `json:"from_name,omitempty"`
+	ToEmail        string     `json:"to_email,omitempty"`
+	ToPhone        string     `json:"to_phone,omitempty"`
+	ToUserID       *uuid.UUID `json:"to_user_id,omitempty"`
+	Message        string     `json:"message,omitempty"`
+	Status         string     `json:"status"`
+	NewTicketID    *uuid.UUID `json:"new_ticket_id,omitempty"`
+	ExpiresAt      time.Time  `json:"expires_at"`
+	RespondedAt    *time.Time `json:"responded_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+	TicketTypeName string     `json:"ticket_type_name,omitempty"`
+	EventTitle     string     `json:"event_title,omitempty"`
+}
+
+// TransferFilter is the resolved list filter used by the repository.
+// Incoming transfers are those accepted by the user or still pending for
+// their email or normalized phone.
+type TransferFilter struct {
+	UserID    uuid.UUID
+	Email     string
+	Phone     string
+	Direction string
+	Status    string
+	Limit     int
+	Offset    int
+}
+
+// TicketOwnership is one link in the chain of tickets issued for the same
+// admission, oldest first.
+type TicketOwnership struct {
+	TicketID   uuid.UUID  `json:"ticket_id"`
+	Code       string     `json:"code"`
+	UserID     uuid.UUID  `json:"user_id"`
+	HolderName string     `json:"holder_name"`
+	Status     string     `json:"status"`
+	Source     string     `json:"source"`
+	TransferID *uuid.UUID `json:"transfer_id,omitempty"`
+	AcquiredAt time.Time  `json:"acquired_at"`
+}
+
+// Request DTOs
+type ConfigureTransferPolicyRequest struct {
+	Enabled                 *bool      `json:"enabled" binding:"required"`
+	OpensAt                 *time.Time `json:"opens_at"`
+	CloseMinutesBeforeStart int        `json:"close_minutes_before_start" binding:"min=0,max=10080"`
+	MaxTransfersPerTicket   int        `json:"max_transfers_per_ticket" binding:"min=0,max=100"`
+}
+
+// CreateTransferRequest targets the recipient by email or by phone.
+type CreateTransferRequest struct {
+	Email   string `json:"email" binding:"omitempty,email,max=255"`
+	Phone   string `json:"phone" binding:"omitempty,min=8,max=20"`
+	Message string `json:"message" binding:"max=280"`
+}
+
+// AcceptTransferRequest carries the recipient's own holder details when the
+// event asks for them.
+type AcceptTransferRequest struct {
+	Attendee *AttendeeDetails `json:"attendee"`
+}
+
+type ListTransfersRequest struct {
+	PaginationQuery
+	Direction string `form:"direction" binding:"omitempty,oneof=incoming outgoing"`
+	Status    string `form:"status" binding:"omitempty,oneof=pending accepted declined cancelled expired"`
+}
@@ -6,6 +6,13 @@ import (
 	"github.com/google/uuid"
 )
 
+// User roles
+const (
+	RoleUser    = "user"
+	RoleAdmin   = "admin"
+	RoleCheckin = "checkin"
+)
+
 type User struct {
 	ID                 uuid.UUID `json:"id"`
 	Name               string    `json:"name"`
@@ -14,10 +21,31 @@ type User struct {
 	Password           string    `json:"-"`
 	IsVerified         bool      `json:"is_verified"`
 	VerificationMethod string    `json:"verification_method,omitempty"`
+	Role               string    `json:"role"`
 	CreatedAt          time.Time `json:"created_at"`
 	UpdatedAt          time.Time `json:"updated_at"`
 }
 
+// Actor is the authenticated user performing a request.
+type Actor struct {
+	UserID uuid.UUID
+	Role   string
+}
+
+func (a Actor) IsAdmin() bool {
+	return a.Role == RoleAdmin
+}
+
+// CanCheckIn reports whether the actor may scan tickets at the gate.
+func (a Actor) CanCheckIn() bool {
+	return a.Role == RoleCheckin || a.Role == RoleAdmin
+}
+
+// IsAnonymous reports whether the request carried no valid token.
+func (a Actor) IsAnonymous() bool {
+	return a.UserID == uuid.Nil
+}
+
 type OTPVerification struct {
 	ID        uuid.UUID `json:"id"`
 	UserID    uuid.UUID `json:"user_id"`
@@ -52,6 +80,15 @@ type ResendOTPRequest struct {
 	Method string `json:"method" binding:"required,oneof=email whatsapp"`
 }
 
+type UpdateUserRoleRequest struct {
+	Role string `json:"role" binding:"required,oneof=user admin checkin"`
+}
+
+type LoginRequest struct {
+	Email    string `json:"email" binding:"required,email"`
+	Password string `json:"password" binding:"required"`
+}
+
 // Response DTOs
 type RegisterResponse struct {
 	UserID  string `json:"user_id"`
@@ -61,4 +98,10 @@ type RegisterResponse struct {
 type VerificationResponse struct {
 	Success bool   `json:"success"`
 	Message string `json:"message"`
-}
\ No newline at end of file
+}
+
+type LoginResponse struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+	User      *User     `json:"user"`
+}
@@ -0,0 +1,89 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Venue struct {
+	ID          uuid.UUID `json:"id"`
+	CreatedBy   uuid.UUID `json:"created_by"`
+	Name        string    `json:"name"`
+	Address     string    `json:"address"`
+	City        string    `json:"city"`
+	Province    string    `json:"province"`
+	Latitude    float64   `json:"latitude"`
+	Longitude   float64   `json:"longitude"`
+	Capacity    int       `json:"capacity"`
+	Timezone    string    `json:"timezone"`
+	Facilities  []string  `json:"facilities"`
+	MapImageURL string    `json:"map_image_url,omitempty"`
+	DistanceKm  *float64  `json:"distance_km,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// GeoFilter restricts results to a radius around a point.
+type GeoFilter struct {
+	Latitude  float64
+	Longitude float64
+	RadiusKm  float64
+}
+
+// VenueFilter is the resolved list filter used by the repository.
+type VenueFilter struct {
+	City     string
+	Province string
+	Search   string
+	Near     *GeoFilter
+	Limit    int
+	Offset   int
+}
+
+// Request DTOs
+type CreateVenueRequest struct {
+	Name        string   `json:"name" binding:"required,min=2,max=255"`
+	Address     string   `json:"address" binding:"required"`
+	City        string   `json:"city" binding:"required,max=100"`
+	Province    string   `json:"province" binding:"required,max=100"`
+	Latitude    *float64 `json:"latitude" binding:"required,latitude"`
+	Longitude   *float64 `json:"longitude" binding:"required,longitude"`
+	Capacity    int      `json:"capacity" binding:"required,min=1"`
+	Timezone    string   `json:"timezone" binding:"required,timezone"`
+	Facilities  []string `json:"facilities" binding:"omitempty,dive,max=100"`
+	MapImageURL string   `json:"map_image_url" binding:"omitempty,url"`
+}
+
+type UpdateVenueRequest = CreateVenueRequest
+
+type ListVenuesRequest struct {
+	PaginationQuery
+	City     string `form:"city"`
+	Province string `form:"province"`
+	Search   string `form:"q"`
+}
+
+// NearbyQuery is shared by the venue and event "near me" searches.
+type NearbyQuery struct {
+	Latitude  *float64 `form:"lat" binding:"required_with=Longitude,omitempty,latitude"`
+	Longitude *float64 `form:"lng" binding:"required_with=Latitude,omitempty,longitude"`
+	RadiusKm  float64  `form:"radius_km" binding:"omitempty,gt=0,max=500"`
+}
+
+// Geo returns the resolved geo filter, or nil when no point was given.
+func (q *NearbyQuery) Geo() *GeoFilter {
+	if q.Latitude == nil || q.Longitude == nil {
+		return nil
+	}
+	radius := q.RadiusKm
+	if radius == 0 {
+		radius = 25
+	}
+	return &GeoFilter{Latitude: *q.Latitude, Longitude: *q.Longitude, RadiusKm: radius}
+}
+
+type NearbyVenuesRequest struct {
+	PaginationQuery
+	NearbyQuery
+}
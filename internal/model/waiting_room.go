@@ -0,0 +1,70 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Queue entry statuses
+const (
+	QueueStatusWaiting  = "waiting"
+	QueueStatusAdmitted = "admitted"
+	QueueStatusExpired  = "expired"
+)
+
+// WaitingRoom queues buyers in front of checkout. Entries whose position is
+// at or below AdmittedPosition are let in; the cursor advances by
+// AdmissionRate per minute while the room is open and not paused.
+type WaitingRoom struct {
+	EventID                uuid.UUID  `json:"event_id"`
+	Enabled                bool       `json:"enabled"`
+	Paused                 bool       `json:"paused"`
+	OpensAt                time.Time  `json:"opens_at"`
+	AdmissionRate          int        `json:"admission_rate"`
+	AdmissionWindowMinutes int        `json:"admission_window_minutes"`
+	Shuffled               bool       `json:"shuffled"`
+	NextPosition           int64      `json:"next_position"`
+	AdmittedPosition       int64      `json:"admitted_position"`
+	LastAdmissionAt        *time.Time `json:"last_admission_at,omitempty"`
+	CreatedAt              time.Time  `json:"created_at"`
+	UpdatedAt              time.Time  `json:"updated_at"`
+}
+
+// WaitingRoomStats is the organizer view of a room.
+type WaitingRoomStats struct {
+	WaitingRoom
+	Waiting  int `json:"waiting"`
+	Admitted int `json:"admitted"`
+	Expired  int `json:"expired"`
+}
+
+// QueueEntry is one buyer's place in a waiting room. Position stays empty
+// for buyers who joined before the room opened until they are shuffled.
+type QueueEntry struct {
+	ID                 uuid.UUID  `json:"id"`
+	EventID            uuid.UUID  `json:"event_id"`
+	UserID             uuid.UUID  `json:"user_id"`
+	Position           *int64     `json:"position"`
+	Status             string     `json:"status"`
+	JoinedAt           time.Time  `json:"joined_at"`
+	AdmittedAt         *time.Time `json:"admitted_at,omitempty"`
+	AdmissionExpiresAt *time.Time `json:"admission_expires_at,omitempty"`
+}
+
+// QueueTicket is what a buyer polls: their entry, how many are ahead and a
+// signed token to present when placing holds once admitted.
+type QueueTicket struct {
+	QueueEntry
+	Ahead                int64  `json:"ahead"`
+	EstimatedWaitSeconds int64  `json:"estimated_wait_seconds"`
+	Token                string `json:"token,omitempty"`
+}
+
+// Request DTOs
+type ConfigureWaitingRoomRequest struct {
+	Enabled                *bool     `json:"enabled" binding:"required"`
+	OpensAt                time.Time `json:"opens_at" binding:"required"`
+	AdmissionRate          int       `json:"admission_rate" binding:"required,min=1,max=100000"`
+	AdmissionWindowMinutes int       `json:"admission_window_minutes" binding:"omitempty,min=1,max=120"`
+}
@@ -0,0 +1,87 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Waitlist entry statuses
+const (
+	WaitlistStatusWaiting   = "waiting"
+	WaitlistStatusOffered   = "offered"
+	WaitlistStatusClaimed   = "claimed"
+	WaitlistStatusExpired   = "expired"
+	WaitlistStatusCancelled = "cancelled"
+)
+
+// WaitlistEntry queues a buyer for a sold-out ticket type. When stock frees
+// up the entry is offered up to Quantity tickets, set aside from the public
+// until OfferExpiresAt; an offer of a resale listing carries ResaleListingID
+// instead.
+type WaitlistEntry struct {
+	ID              uuid.UUID  `json:"id"`
+	EventID         uuid.UUID  `json:"event_id"`
+	TicketTypeID    uuid.UUID  `json:"ticket_type_id"`
+	UserID          uuid.UUID  `json:"user_id"`
+	Quantity        int        `json:"quantity"`
+	Status          string     `json:"status"`
+	OfferedQuantity int        `json:"offered_quantity,omitempty"`
+	ResaleListingID *uuid.UUID `json:"resale_listing_id,omitempty"`
+	OfferedAt       *time.Time `json:"offered_at,omitempty"`
+	OfferExpiresAt  *time.Time `json:"offer_expires_at,omitempty"`
+	ClaimedAt       *time.Time `json:"claimed_at,omitempty"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+	TicketTypeName  string     `json:"ticket_type_name,omitempty"`
+	EventTitle      string     `json:"event_title,omitempty"`
+	// Ahead counts waiting entries that joined earlier
+	Ahead int `json:"ahead,omitempty"`
+}
+
+type WaitlistFilter struct {
+	UserID uuid.UUID
+	Status string
+	Limit  int
+	Offset int
+}
+
+// WaitlistStats summarises one ticket type's waitlist for the organizer.
+type WaitlistStats struct {
+	TicketTypeID    uuid.UUID `json:"ticket_type_id"`
+	TicketTypeName  string    `json:"ticket_type_name"`
+	Waiting         int       `json:"waiting"`
+	WaitingQuantity int       `json:"waiting_quantity"`
+	Offered         int       `json:"offered"`
+	OfferedQuantity int       `json:"offered_quantity"`
+	Claimed         int       `json:"claimed"`
+}
+
+// WaitlistClaim is what claiming an offer yields: holds to check out like
+// any others, or a pending order for an offered resale listing.
+type WaitlistClaim struct {
+	Holds []InventoryHold `json:"holds,omitempty"`
+	Order *Order          `json:"order,omitempty"`
+}
+
+// Request DTOs
+type JoinWaitlistRequest struct {
+	TicketTypeID string `json:"ticket_type_id" binding:"required,uuid"`
+	Quantity     int    `json:"quantity" binding:"required,min=1"`
+}
+
+type ListWaitlistRequest struct {
+	PaginationQuery
+	Status string `form:"status" binding:"omitempty,oneof=waiting offered claimed expired cancelled"`
+}
+
+// ClaimWaitlistOfferRequest takes the token from the offer link. Offers of
+// a reserved seating type also pick their seats, at most the offered
+// quantity.
+type ClaimWaitlistOfferRequest struct {
+	Token    string           `json:"token" binding:"required"`
+	SeatIDs  []string         `json:"seat_ids" binding:"omitempty,max=50,dive,uuid"`
+	Attendee *AttendeeDetails `json:"attendee"`
+	// DeviceID comes from the X-Device-ID header
+	DeviceID string `json:"-"`
+}
@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"database/sql"
+	"e-ticketing/internal/model"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+type AttendeeRepository struct {
+	db *sql.DB
+}
+
+func NewAttendeeRepository(db *sql.DB) *AttendeeRepository {
+	return &AttendeeRepository{db: db}
+}
+
+const attendeeRequirementsColumns = `event_id, require_name, require_id_number, require_email, require_phone,
+	require_birth_date, created_at, updated_at`
+
+func scanAttendeeRequirements(row interface{ Scan(...interface{}) error }) (*model.AttendeeRequirements, error) {
+	r := &model.AttendeeRequirements{}
+	err := row.Scan(&r.EventID, &r.RequireName, &r.RequireIDNumber, &r.RequireEmail, &r.RequirePhone,
+		&r.RequireBirthDate, &r.CreatedAt, &r.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *AttendeeRepository) SaveRequirements(req *model.AttendeeRequirements) error {
+	query := `
+		INSERT INTO attendee_requirements (event_id, require_name, require_id_number, require_email, require_phone, require_birth_date)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (event_id) DO UPDATE SET require_name = $2, require_id_number = $3, require_email = $4,
+			require_phone = $5, require_birth_date = $6, updated_at = $7
+		RETURNING created_at, updated_at`
+
+	return r.db.QueryRow(query, req.EventID, req.RequireName, req.RequireIDNumber, req.RequireEmail, req.RequirePhone,
+		req.RequireBirthDate, time.Now()).
+		Scan(&req.CreatedAt, &req.UpdatedAt)
+}
+
+func (r *AttendeeRepository) GetRequirements(eventID uuid.UUID) (*model.AttendeeRequirements, error) {
+	query := `SELECT ` + attendeeRequirementsColumns + ` FROM attendee_requirements WHERE event_id = $1`
+	return scanAttendeeRequirements(r.db.QueryRow(query, eventID))
+}
+
+// GetEncryptedIDNumber returns the encrypted ID number of a ticket's holder.
+func (r *AttendeeRepository) GetEncryptedIDNumber(orderItemID uuid.UUID, sequence int) (string, error) {
+	var encrypted string
+	err := r.db.QueryRow(`SELECT id_number_encrypted FROM order_attendees WHERE order_item_id = $1 AND sequence = $2`,
+		orderItemID, sequence).Scan(&encrypted)
+	return encrypted, err
+}
+
+func insertAttendees(tx *sql.Tx, item *model.OrderItem) error {
+	for i := range item.Attendees {
+		if err := insertAttendee(tx, item.ID, &item.Attendees[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func insertAttendee(tx *sql.Tx, orderItemID uuid.UUID, a *model.Attendee) error {
+	_, err := tx.Exec(`
+		INSERT INTO order_attendees (order_item_id, sequence, name, id_type, id_number_encrypted, id_number_masked,
+			email, phone, birth_date)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`,
+		orderItemID, a.Sequence, a.Name, a.IDType, a.IDNumberEncrypted, a.IDNumber, a.Email, a.Phone, a.BirthDate)
+	return err
+}
+
+func attendeesByItems(db *sql.DB, itemIDs []uuid.UUID) (map[uuid.UUID][]model.Attendee, error) {
+	rows, err := db.Query(`
+		SELECT order_item_id, sequence, name, id_type, id_number_masked, email, phone, birth_date
+		FROM order_attendees
+		WHERE order_item_id = ANY($1)
+		ORDER BY sequence`, pq.Array(itemIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	attendees := map[uuid.UUID][]model.Attendee{}
+	for rows.Next() {
+		var itemID uuid.UUID
+		var a model.Attendee
+		var birthDate sql.NullTime
+		if err := rows.Scan(&itemID, &a.Sequence, &a.Name, &a.IDType, &a.IDNumber, &a.Email, &a.Phone, &birthDate); err != nil {
+			return nil, err
+		}
+		if birthDate.Valid {
+			a.BirthDate = &birthDate.Time
+		}
+		attendees[itemID] = append(attendees[itemID], a)
+	}
+	return attendees, rows.Err()
+}
@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"e-ticketing/internal/model"
+	"encoding/json"
+	"log"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// AvailabilityChannel is the NOTIFY channel the availability triggers
+// publish on.
+const AvailabilityChannel = "availability"
+
+// AvailabilityListener turns availability notifications into events. It
+// holds its own connection, since LISTEN does not work through the pool.
+type AvailabilityListener struct {
+	listener *pq.Listener
+}
+
+func NewAvailabilityListener(dsn string) (*AvailabilityListener, error) {
+	listener := pq.NewListener(dsn, time.Second, time.Minute, func(ev pq.ListenerEventType, err error) {
+		if err != nil {
+			log.Printf("Availability listener: %v", err)
+		}
+	})
+	if err := listener.Listen(AvailabilityChannel); err != nil {
+		listener.Close()
+		return nil, err
+	}
+	return &AvailabilityListener{listener: listener}, nil
+}
+
+// Run delivers notifications to handle until stop is closed. Notifications
+// sent while the connection was down are lost, so after a reconnect handle
+// receives a resync event with no event ID.
+func (l *AvailabilityListener) Run(handle func(model.AvailabilityEvent), stop <-chan struct{}) {
+	ping := time.NewTicker(90 * time.Second)
+	defer ping.Stop()
+
+	for {
+		select {
+		case n := <-l.listener.Notify:
+			if n == nil {
+				handle(model.AvailabilityEvent{Type: model.AvailabilityResync, At: time.Now()})
+				continue
+			}
+			var event model.AvailabilityEvent
+			if err := json.Unmarshal([]byte(n.Extra), &event); err != nil {
+				log.Printf("Availability listener: invalid payload %q: %v", n.Extra, err)
+				continue
+			}
+			event.At = time.Now()
+			handle(event)
+		case <-ping.C:
+			// Detects a dead connection when the channel is quiet
+			go l.listener.Ping()
+		case <-stop:
+			l.listener.Close()
+			return
+		}
+	}
+}
@@ -0,0 +1,407 @@
+package repository
+
+import (
+	"database/sql"
+	"e-ticketing/internal/model"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+type CheckInRepository struct {
+	db *sql.DB
+}
+
+func NewCheckInRepository(db *sql.DB) *CheckInRepository {
+	return &CheckInRepository{db: db}
+}
+
+const gateDeviceColumns = `id, event_id, name, gate, is_active, registered_by, last_seen_at, created_at, updated_at`
+
+func scanGateDevice(row interface{ Scan(...interface{}) error }) (*model.GateDevice, error) {
+	d := &model.GateDevice{}
+	var lastSeen sql.NullTime
+	err := row.Scan(&d.ID, &d.EventID, &d.Name, &d.Gate, &d.IsActive, &d.RegisteredBy, &lastSeen, &d.CreatedAt, &d.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if lastSeen.Valid {
+		d.LastSeenAt = &lastSeen.Time
+	}
+	return d, nil
+}
+
+func (r *CheckInRepository) CreateGateDevice(d *model.GateDevice) error {
+	query := `
+		INSERT INTO gate_devices (event_id, name, gate, registered_by)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, is_active, created_at, updated_at`
+
+	return r.db.QueryRow(query, d.EventID, d.Name, d.Gate, d.RegisteredBy).
+		Scan(&d.ID, &d.IsActive, &d.CreatedAt, &d.UpdatedAt)
+}
+
+func (r *CheckInRepository) GetGateDevice(id uuid.UUID) (*model.GateDevice, error) {
+	query := `SELECT ` + gateDeviceColumns + ` FROM gate_devices WHERE id = $1`
+	return scanGateDevice(r.db.QueryRow(query, id))
+}
+
+func (r *CheckInRepository) ListGateDevices(eventID uuid.UUID) ([]model.GateDevice, error) {
+	query := `SELECT ` + gateDeviceColumns + ` FROM gate_devices WHERE event_id = $1 ORDER BY gate, name`
+
+	rows, err := r.db.Query(query, eventID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	devices := []model.GateDevice{}
+	for rows.Next() {
+		d, err := scanGateDevice(rows)
+		if err != nil {
+			return nil, err
+		}
+		devices = append(devices, *d)
+	}
+	return devices, rows.Err()
+}
+
+func (r *CheckInRepository) DeactivateGateDevice(id uuid.UUID) error {
+	query := `UPDATE gate_devices SET is_active = FALSE, updated_at = $1 WHERE id = $2`
+	_, err := r.db.Exec(query, time.Now(), id)
+	return err
+}
+
+func (r *CheckInRepository) TouchGateDevice(id uuid.UUID, at time.Time) error {
+	query := `UPDATE gate_devices SET last_seen_at = $1 WHERE id = $2`
+	_, err := r.db.Exec(query, at, id)
+	return err
+}
+
+// CheckInTicket marks a valid ticket used and records the scan in one
+// transaction. The conditional update serialises concurrent scans of the same
+// ticket: only one of them sees the valid status, the rest get false. A
+// ticket listed for resale or being transferred is not admitted and returns
+// ErrTicketInTransit.
+func (r *CheckInRepository) CheckInTicket(ticketID uuid.UUID, device *model.GateDevice, scannedBy uuid.UUID, at time.Time) (*model.CheckIn, bool, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, false, err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`
+		UPDATE tickets t SET status = $1, used_at = $2, updated_at = $2
+		WHERE t.id = $3 AND t.event_id = $4 AND t.status = $5 AND NOT `+ticketInTransit,
+		model.TicketStatusUsed, at, ticketID, device.EventID, model.TicketStatusValid)
+	if err != nil {
+		return nil, false, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return nil, false, err
+	}
+	if affected != 1 {
+		var inTransit bool
+		err := tx.QueryRow(`SELECT `+ticketInTransit+` FROM tickets t WHERE t.id = $1 AND t.status = $2`,
+			ticketID, model.TicketStatusValid).Scan(&inTransit)
+		if err == sql.ErrNoRows {
+			return nil, false, nil
+		}
+		if err != nil {
+			return nil, false, err
+		}
+		if inTransit {
+			return nil, false, ErrTicketInTransit
+		}
+		return nil, false, nil
+	}
+
+	checkIn := &model.CheckIn{
+		TicketID:     ticketID,
+		EventID:      device.EventID,
+		GateDeviceID: device.ID,
+		Gate:         device.Gate,
+		DeviceName:   device.Name,
+		ScannedBy:    scannedBy,
+		ScannedAt:    at,
+	}
+	err = tx.QueryRow(`
+		INSERT INTO check_ins (ticket_id, event_id, gate_device_id, scanned_by, scanned_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id`,
+		ticketID, device.EventID, device.ID, scannedBy, at).Scan(&checkIn.ID)
+	if err != nil {
+		return nil, false, err
+	}
+
+	return checkIn, true, tx.Commit()
+}
+
+func (r *CheckInRepository) GetCheckInByTicket(ticketID uuid.UUID) (*model.CheckIn, error) {
+	c := &model.CheckIn{}
+	query := `
+		SELECT c.id, c.ticket_id, c.event_id, c.gate_device_id, d.gate, d.name, c.scanned_by, c.scanned_at
+		FROM check_ins c JOIN gate_devices d ON d.id = c.gate_device_id
+		WHERE c.ticket_id = $1`
+
+	err := r.db.QueryRow(query, ticketID).Scan(&c.ID, &c.TicketID, &c.EventID, &c.GateDeviceID, &c.Gate,
+		&c.DeviceName, &c.ScannedBy, &c.ScannedAt)
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// GetAttendance counts admitted tickets per gate against the tickets that
+// can still be used to enter.
+func (r *CheckInRepository) GetAttendance(eventID uuid.UUID) (*model.Attendance, error) {
+	a := &model.Attendance{EventID: eventID, ByGate: []model.GateAttendance{}}
+
+	err := r.db.QueryRow(`SELECT COUNT(*) FROM tickets WHERE event_id = $1 AND status = ANY($2)`,
+		eventID, pq.Array([]string{model.TicketStatusValid, model.TicketStatusUsed})).Scan(&a.Issued)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.Query(`
+		SELECT d.gate, COUNT(*)
+		FROM check_ins c JOIN gate_devices d ON d.id = c.gate_device_id
+		WHERE c.event_id = $1
+		GROUP BY d.gate ORDER BY d.gate`, eventID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var g model.GateAttendance
+		if err := rows.Scan(&g.Gate, &g.CheckedIn); err != nil {
+			return nil, err
+		}
+		a.CheckedIn += g.CheckedIn
+		a.ByGate = append(a.ByGate, g)
+	}
+	return a, rows.Err()
+}
+
+// ListManifestTickets returns the IDs of tickets that can still be admitted
+// and of those already checked in. Tickets in transit are left out, so
+// offline scanners reject them too.
+func (r *CheckInRepository) ListManifestTickets(eventID uuid.UUID) ([]uuid.UUID, []uuid.UUID, error) {
+	rows, err := r.db.Query(`
+		SELECT t.id, t.status FROM tickets t
+		WHERE t.event_id = $1 AND t.status = ANY($2) AND NOT (t.status = $3 AND `+ticketInTransit+`)`,
+		eventID, pq.Array([]string{model.TicketStatusValid, model.TicketStatusUsed}), model.TicketStatusValid)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	valid := []uuid.UUID{}
+	used := []uuid.UUID{}
+	for rows.Next() {
+		var id uuid.UUID
+		var status string
+		if err := rows.Scan(&id, &status); err != nil {
+			return nil, nil, err
+		}
+		if status == model.TicketStatusUsed {
+			used = append(used, id)
+		} else {
+			valid = append(valid, id)
+		}
+	}
+	return valid, used, rows.Err()
+}
+
+const offlineScanColumns = `id, gate_device_id, event_id, ticket_id, client_scan_id, scanned_by, scanned_at, outcome,
+	COALESCE(reason, ''), suspicious, conflict_device_id, synced_at`
+
+func scanOfflineScan(row interface{ Scan(...interface{}) error }) (*model.OfflineScan, error) {
+	s := &model.OfflineScan{}
+	var conflict uuid.NullUUID
+	err := row.Scan(&s.ID, &s.GateDeviceID, &s.EventID, &s.TicketID, &s.ClientScanID, &s.ScannedBy, &s.ScannedAt,
+		&s.Outcome, &s.Reason, &s.Suspicious, &conflict, &s.SyncedAt)
+	if err != nil {
+		return nil, err
+	}
+	if conflict.Valid {
+		s.ConflictDeviceID = &conflict.UUID
+	}
+	return s, nil
+}
+
+// SyncOfflineScan records an uploaded scan and resolves it against the
+// ticket's check-in. The earliest scan wins, ties broken by the lower device
+// ID, so the result does not depend on which device syncs first. It returns
+// false when the scan was already synced, in which case scan is filled with
+// the stored outcome.
+func (r *CheckInRepository) SyncOfflineScan(scan *model.OfflineScan) (bool, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRow(`
+		INSERT INTO offline_scans (gate_device_id, event_id, ticket_id, client_scan_id, scanned_by, scanned_at, outcome)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (gate_device_id, client_scan_id) DO NOTHING
+		RETURNING id, synced_at`,
+		scan.GateDeviceID, scan.EventID, scan.TicketID, scan.ClientScanID, scan.ScannedBy, scan.ScannedAt, model.ScanRejected).
+		Scan(&scan.ID, &scan.SyncedAt)
+	if err == sql.ErrNoRows {
+		stored, err := scanOfflineScan(r.db.QueryRow(`SELECT `+offlineScanColumns+` FROM offline_scans
+			WHERE gate_device_id = $1 AND client_scan_id = $2`, scan.GateDeviceID, scan.ClientScanID))
+		if err != nil {
+			return false, err
+		}
+		*scan = *stored
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	if err := r.resolveOfflineScan(tx, scan); err != nil {
+		return false, err
+	}
+
+	_, err = tx.Exec(`
+		UPDATE offline_scans SET outcome = $1, reason = NULLIF($2, ''), suspicious = $3, conflict_device_id = $4
+		WHERE id = $5`,
+		scan.Outcome, scan.Reason, scan.Suspicious, scan.ConflictDeviceID, scan.ID)
+	if err != nil {
+		return false, err
+	}
+	return true, tx.Commit()
+}
+
+func (r *CheckInRepository) resolveOfflineScan(tx *sql.Tx, scan *model.OfflineScan) error {
+	// Row lock serialises syncs and live scans touching the same ticket
+	var eventID uuid.UUID
+	var status string
+	var inTransit bool
+	err := tx.QueryRow(`SELECT t.event_id, t.status, `+ticketInTransit+` FROM tickets t WHERE t.id = $1 FOR UPDATE`,
+		scan.TicketID).Scan(&eventID, &status, &inTransit)
+	if err == sql.ErrNoRows {
+		scan.Outcome, scan.Reason = model.ScanRejected, "tiket tidak ditemukan"
+		scan.Suspicious = true
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if eventID != scan.EventID {
+		scan.Outcome, scan.Reason = model.ScanRejected, "tiket untuk event lain"
+		scan.Suspicious = true
+		return nil
+	}
+
+	switch status {
+	case model.TicketStatusValid:
+		// The live path rejects these; a device that let one in offline let in
+		// a ticket someone else may be about to receive
+		if inTransit {
+			scan.Outcome, scan.Reason = model.ScanRejected, "tiket sedang dijual ulang atau ditransfer"
+			scan.Suspicious = true
+			return nil
+		}
+		if _, err := tx.Exec(`
+			INSERT INTO check_ins (ticket_id, event_id, gate_device_id, scanned_by, scanned_at)
+			VALUES ($1, $2, $3, $4, $5)`,
+			scan.TicketID, scan.EventID, scan.GateDeviceID, scan.ScannedBy, scan.ScannedAt); err != nil {
+			return err
+		}
+		if _, err := tx.Exec(`UPDATE tickets SET status = $1, used_at = $2, updated_at = $3 WHERE id = $4`,
+			model.TicketStatusUsed, scan.ScannedAt, time.Now(), scan.TicketID); err != nil {
+			return err
+		}
+		scan.Outcome = model.ScanAdmitted
+		return nil
+
+	case model.TicketStatusUsed:
+	default:
+		// The device let in a ticket that was voided or transferred meanwhile
+		scan.Outcome, scan.Reason = model.ScanRejected, "tiket berstatus "+status
+		scan.Suspicious = true
+		return nil
+	}
+
+	var firstDevice uuid.UUID
+	var firstAt time.Time
+	err = tx.QueryRow(`SELECT gate_device_id, scanned_at FROM check_ins WHERE ticket_id = $1`, scan.TicketID).
+		Scan(&firstDevice, &firstAt)
+	if err != nil {
+		return err
+	}
+
+	if firstDevice == scan.GateDeviceID && firstAt.Equal(scan.ScannedAt) {
+		scan.Outcome, scan.Reason = model.ScanDuplicate, "scan yang sama sudah tercatat"
+		return nil
+	}
+
+	conflict := firstDevice
+	scan.ConflictDeviceID = &conflict
+	scan.Suspicious = firstDevice != scan.GateDeviceID
+
+	earlier := scan.ScannedAt.Before(firstAt) ||
+		(scan.ScannedAt.Equal(firstAt) && scan.GateDeviceID.String() < firstDevice.String())
+	if !earlier {
+		scan.Outcome, scan.Reason = model.ScanDuplicate, "tiket sudah discan lebih dulu di perangkat lain"
+		if !scan.Suspicious {
+			scan.Reason = "tiket sudah discan lebih dulu"
+		}
+		return nil
+	}
+
+	// This scan happened first: it takes over the check-in and the previous
+	// winner becomes the duplicate
+	if _, err := tx.Exec(`
+		UPDATE check_ins SET gate_device_id = $1, scanned_by = $2, scanned_at = $3 WHERE ticket_id = $4`,
+		scan.GateDeviceID, scan.ScannedBy, scan.ScannedAt, scan.TicketID); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`UPDATE tickets SET used_at = $1, updated_at = $2 WHERE id = $3`,
+		scan.ScannedAt, time.Now(), scan.TicketID); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`
+		UPDATE offline_scans SET outcome = $1, reason = $2, conflict_device_id = $3,
+			suspicious = (gate_device_id <> $3)
+		WHERE ticket_id = $4 AND outcome = $5 AND id <> $6`,
+		model.ScanDuplicate, "tiket sudah discan lebih dulu", scan.GateDeviceID, scan.TicketID,
+		model.ScanAdmitted, scan.ID); err != nil {
+		return err
+	}
+	scan.Outcome = model.ScanAdmitted
+	return nil
+}
+
+func (r *CheckInRepository) ListSuspiciousScans(eventID uuid.UUID, limit, offset int) ([]model.OfflineScan, int, error) {
+	var total int
+	if err := r.db.QueryRow(`SELECT COUNT(*) FROM offline_scans WHERE event_id = $1 AND suspicious`, eventID).
+		Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query := `SELECT ` + offlineScanColumns + ` FROM offline_scans
+		WHERE event_id = $1 AND suspicious ORDER BY scanned_at DESC LIMIT $2 OFFSET $3`
+	rows, err := r.db.Query(query, eventID, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	scans := []model.OfflineScan{}
+	for rows.Next() {
+		s, err := scanOfflineScan(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		scans = append(scans, *s)
+	}
+	return scans, total, rows.Err()
+}
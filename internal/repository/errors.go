@@ -0,0 +1,37 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/lib/pq"
+)
+
+// Promo redemption errors, for an order that would take a promo past its
+// overall or code limits, or past how often one user may redeem it.
+var (
+	ErrPromoLimitReached     = errors.New("promo usage limit reached")
+	ErrPromoUserLimitReached = errors.New("promo per-user limit reached")
+)
+
+// ErrTicketInTransit is returned when a ticket cannot be checked in because
+// it is listed for resale or being transferred.
+var ErrTicketInTransit = errors.New("ticket is listed for resale or being transferred")
+
+// IsUniqueViolation reports whether err is a PostgreSQL unique constraint error.
+func IsUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505"
+}
+
+// IsTicketCodeConflict reports whether err is a clash on the unique ticket
+// code, which a retry with a freshly generated code resolves.
+func IsTicketCodeConflict(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505" && pqErr.Constraint == "tickets_code_key"
+}
+
+// IsForeignKeyViolation reports whether err is a PostgreSQL foreign key error.
+func IsForeignKeyViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23503"
+}
@@ -0,0 +1,164 @@
+package repository
+
+import (
+	"database/sql"
+	"e-ticketing/internal/model"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+type EventRepository struct {
+	db *sql.DB
+}
+
+func NewEventRepository(db *sql.DB) *EventRepository {
+	return &EventRepository{db: db}
+}
+
+const eventColumns = `id, organizer_id, venue_id, title, COALESCE(description, ''), category, COALESCE(banner_url, ''),
+	start_time, end_time, timezone, status, created_at, updated_at`
+
+func scanEvent(row interface{ Scan(...interface{}) error }) (*model.Event, error) {
+	e := &model.Event{}
+	var venueID uuid.NullUUID
+	err := row.Scan(&e.ID, &e.OrganizerID, &venueID, &e.Title, &e.Description, &e.Category, &e.BannerURL,
+		&e.StartTime, &e.EndTime, &e.Timezone, &e.Status, &e.CreatedAt, &e.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if venueID.Valid {
+		e.VenueID = &venueID.UUID
+	}
+	return e, nil
+}
+
+func (r *EventRepository) CreateEvent(e *model.Event) error {
+	query := `
+		INSERT INTO events (organizer_id, venue_id, title, description, category, banner_url, start_time, end_time, timezone, status)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING id, created_at, updated_at`
+
+	return r.db.QueryRow(query, e.OrganizerID, e.VenueID, e.Title, e.Description, e.Category, e.BannerURL,
+		e.StartTime, e.EndTime, e.Timezone, e.Status).
+		Scan(&e.ID, &e.CreatedAt, &e.UpdatedAt)
+}
+
+func (r *EventRepository) GetEventByID(id uuid.UUID) (*model.Event, error) {
+	query := `SELECT ` + eventColumns + ` FROM events WHERE id = $1`
+	return scanEvent(r.db.QueryRow(query, id))
+}
+
+// UpdateEvent saves the event and, in the same transaction, checks that its
+// ticket types still fit in its venue, returning a *CapacityError otherwise.
+func (r *EventRepository) UpdateEvent(e *model.Event) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		UPDATE events SET venue_id = $1, title = $2, description = $3, category = $4, banner_url = $5,
+			start_time = $6, end_time = $7, timezone = $8, updated_at = $9
+		WHERE id = $10
+		RETURNING updated_at`
+
+	err = tx.QueryRow(query, e.VenueID, e.Title, e.Description, e.Category, e.BannerURL,
+		e.StartTime, e.EndTime, e.Timezone, time.Now(), e.ID).
+		Scan(&e.UpdatedAt)
+	if err != nil {
+		return err
+	}
+	if err := checkVenueCapacity(tx, e.ID, nil, 0); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// UpdateEventStatus only applies when the stored status still equals from,
+// so two concurrent transitions cannot both succeed.
+func (r *EventRepository) UpdateEventStatus(id uuid.UUID, from, to string) (bool, error) {
+	query := `UPDATE events SET status = $1, updated_at = $2 WHERE id = $3 AND status = $4`
+	result, err := r.db.Exec(query, to, time.Now(), id, from)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected == 1, err
+}
+
+func (r *EventRepository) CountEventsByVenue(venueID uuid.UUID) (int, error) {
+	var count int
+	err := r.db.QueryRow(`SELECT COUNT(*) FROM events WHERE venue_id = $1`, venueID).Scan(&count)
+	return count, err
+}
+
+func (r *EventRepository) DeleteEvent(id uuid.UUID) error {
+	query := `DELETE FROM events WHERE id = $1 AND status = $2`
+	_, err := r.db.Exec(query, id, model.EventStatusDraft)
+	return err
+}
+
+func (r *EventRepository) ListEvents(filter *model.EventFilter) ([]model.Event, int, error) {
+	conditions := []string{"1 = 1"}
+	args := []interface{}{}
+
+	addCondition := func(clause string, value interface{}) {
+		args = append(args, value)
+		conditions = append(conditions, fmt.Sprintf(clause, len(args)))
+	}
+
+	if filter.OrganizerID != nil {
+		addCondition("organizer_id = $%d", *filter.OrganizerID)
+	}
+	if filter.Category != "" {
+		addCondition("category = $%d", filter.Category)
+	}
+	if len(filter.Statuses) > 0 {
+		addCondition("status = ANY($%d)", pq.Array(filter.Statuses))
+	}
+	if filter.Search != "" {
+		addCondition("title ILIKE $%d", "%"+filter.Search+"%")
+	}
+	if filter.StartsAfter != nil {
+		addCondition("start_time >= $%d", *filter.StartsAfter)
+	}
+	if filter.City != "" {
+		addCondition("venue_id IN (SELECT id FROM venues WHERE city ILIKE $%d)", filter.City)
+	}
+	if filter.Near != nil {
+		args = append(args, filter.Near.Latitude, filter.Near.Longitude)
+		distance := haversineSQL("latitude", "longitude", fmt.Sprintf("$%d", len(args)-1), fmt.Sprintf("$%d", len(args)))
+		addCondition("venue_id IN (SELECT id FROM venues WHERE "+distance+" <= $%d)", filter.Near.RadiusKm)
+	}
+
+	where := strings.Join(conditions, " AND ")
+
+	var total int
+	if err := r.db.QueryRow(`SELECT COUNT(*) FROM events WHERE `+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query := fmt.Sprintf(`SELECT %s FROM events WHERE %s ORDER BY start_time LIMIT $%d OFFSET $%d`,
+		eventColumns, where, len(args)+1, len(args)+2)
+
+	rows, err := r.db.Query(query, append(args, filter.Limit, filter.Offset)...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	events := []model.Event{}
+	for rows.Next() {
+		e, err := scanEvent(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		events = append(events, *e)
+	}
+	return events, total, rows.Err()
+}
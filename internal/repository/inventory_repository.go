@@ -0,0 +1,133 @@
+package repository
+
+import (
+	"database/sql"
+	"e-ticketing/internal/model"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type InventoryRepository struct {
+	db *sql.DB
+}
+
+func NewInventoryRepository(db *sql.DB) *InventoryRepository {
+	return &InventoryRepository{db: db}
+}
+
+const holdColumns = `id, user_id, ticket_type_id, quantity, status, expires_at, created_at, updated_at`
+
+func scanHold(row interface{ Scan(...interface{}) error }) (*model.InventoryHold, error) {
+	h := &model.InventoryHold{}
+	err := row.Scan(&h.ID, &h.UserID, &h.TicketTypeID, &h.Quantity, &h.Status, &h.ExpiresAt, &h.CreatedAt, &h.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+// publicStock is the stock of the ticket_types row in scope that the public
+// may take: what is left after the quantities waitlisted buyers are still
+// waiting for.
+const publicStock = `available - (SELECT COALESCE(SUM(w.quantity), 0) FROM waitlist_entries w
+	WHERE w.ticket_type_id = ticket_types.id AND w.status = '` + model.WaitlistStatusWaiting + `')`
+
+// CreateHold decrements available stock and records the hold in one
+// transaction. The conditional UPDATE is what prevents overselling: under
+// concurrent requests PostgreSQL serialises writers on the ticket type row
+// and re-checks the public stock after acquiring the lock. It returns false
+// when there is not enough stock, and a *PurchaseLimitError when the hold
+// would exceed limit.
+func (r *InventoryRepository) CreateHold(hold *model.InventoryHold, limit *model.HoldLimit) (bool, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	if err := checkHoldLimit(tx, limit); err != nil {
+		return false, err
+	}
+
+	result, err := tx.Exec(`
+		UPDATE ticket_types SET available = available - $1, updated_at = $2
+		WHERE id = $3 AND `+publicStock+` >= $1`,
+		hold.Quantity, time.Now(), hold.TicketTypeID)
+	if err != nil {
+		return false, err
+	}
+	if affected, err := result.RowsAffected(); err != nil || affected == 0 {
+		return false, err
+	}
+
+	if err := insertHold(tx, hold); err != nil {
+		return false, err
+	}
+	return true, tx.Commit()
+}
+
+// insertHold records a hold whose stock the caller has already set aside.
+func insertHold(tx *sql.Tx, hold *model.InventoryHold) error {
+	return tx.QueryRow(`
+		INSERT INTO inventory_holds (user_id, ticket_type_id, quantity, status, expires_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at, updated_at`,
+		hold.UserID, hold.TicketTypeID, hold.Quantity, hold.Status, hold.ExpiresAt).
+		Scan(&hold.ID, &hold.CreatedAt, &hold.UpdatedAt)
+}
+
+func (r *InventoryRepository) GetHoldByID(id uuid.UUID) (*model.InventoryHold, error) {
+	query := `SELECT ` + holdColumns + ` FROM inventory_holds WHERE id = $1`
+	return scanHold(r.db.QueryRow(query, id))
+}
+
+// ReleaseHold returns the stock of an active hold and frees its seats. It
+// returns false when the hold was no longer active (already released,
+// expired or converted).
+func (r *InventoryRepository) ReleaseHold(id uuid.UUID, status string) (bool, error) {
+	query := `
+		WITH released AS (
+			UPDATE inventory_holds SET status = $1, updated_at = $2
+			WHERE id = $3 AND status = $4
+			RETURNING id, ticket_type_id, quantity
+		), freed_seats AS (
+			UPDATE seats SET status = '` + model.SeatStatusAvailable + `', hold_id = NULL, updated_at = $2
+			WHERE hold_id IN (SELECT id FROM released)
+		)
+		UPDATE ticket_types t SET available = t.available + released.quantity, updated_at = $2
+		FROM released WHERE t.id = released.ticket_type_id`
+
+	result, err := r.db.Exec(query, status, time.Now(), id, model.HoldStatusActive)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected == 1, err
+}
+
+// ReleaseExpiredHolds expires every active hold past its deadline and
+// returns the stock in a single statement, so a crash cannot leave stock
+// decremented for an expired hold. It returns the number of ticket types
+// that received stock back.
+func (r *InventoryRepository) ReleaseExpiredHolds(now time.Time) (int64, error) {
+	query := `
+		WITH expired AS (
+			UPDATE inventory_holds SET status = $1, updated_at = $2
+			WHERE status = $3 AND expires_at <= $2
+			RETURNING id, ticket_type_id, quantity
+		), freed_seats AS (
+			UPDATE seats SET status = '` + model.SeatStatusAvailable + `', hold_id = NULL, updated_at = $2
+			WHERE hold_id IN (SELECT id FROM expired)
+		), totals AS (
+			SELECT ticket_type_id, SUM(quantity) AS quantity FROM expired GROUP BY ticket_type_id
+		)
+		UPDATE ticket_types t SET available = t.available + totals.quantity, updated_at = $2
+		FROM totals WHERE t.id = totals.ticket_type_id`
+
+	result, err := r.db.Exec(query, model.HoldStatusExpired, now, model.HoldStatusActive)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
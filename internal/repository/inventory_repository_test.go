@@ -0,0 +1,131 @@
+package repository
+
+import (
+	"database/sql"
+	"e-ticketing/internal/model"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// testDB connects to the database in TEST_DATABASE_URL, which must already
+// have every migration applied. Tests are skipped without it.
+func testDB(t *testing.T) *sql.DB {
+	t.Helper()
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+	if err := db.Ping(); err != nil {
+		t.Fatalf("ping database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+// createTicketType inserts a ticket type with the given stock along with the
+// user, organizer and event it needs, and removes them when the test ends.
+func createTicketType(t *testing.T, db *sql.DB, stock int) (userID, ticketTypeID uuid.UUID) {
+	t.Helper()
+	suffix := uuid.NewString()[:12]
+	now := time.Now()
+
+	var organizerID, eventID uuid.UUID
+	err := db.QueryRow(`INSERT INTO users (name, email, phone, password) VALUES ('Test', $1, $2, 'x') RETURNING id`,
+		suffix+"@example.com", "08"+suffix[:10]).Scan(&userID)
+	if err == nil {
+		err = db.QueryRow(`INSERT INTO organizers (owner_id, name) VALUES ($1, 'Test') RETURNING id`, userID).Scan(&organizerID)
+	}
+	if err == nil {
+		err = db.QueryRow(`
+			INSERT INTO events (organizer_id, title, category, start_time, end_time, status)
+			VALUES ($1, 'Test', 'music', $2, $3, 'published') RETURNING id`,
+			organizerID, now.Add(48*time.Hour), now.Add(50*time.Hour)).Scan(&eventID)
+	}
+	if err == nil {
+		err = db.QueryRow(`
+			INSERT INTO ticket_types (event_id, name, price, quota, available, sales_start, sales_end)
+			VALUES ($1, 'Reguler', 100000, $2, $2, $3, $4) RETURNING id`,
+			eventID, stock, now.Add(-time.Hour), now.Add(24*time.Hour)).Scan(&ticketTypeID)
+	}
+	if err != nil {
+		t.Fatalf("create fixtures: %v", err)
+	}
+
+	t.Cleanup(func() {
+		db.Exec(`DELETE FROM inventory_holds WHERE ticket_type_id = $1`, ticketTypeID)
+		db.Exec(`DELETE FROM ticket_types WHERE id = $1`, ticketTypeID)
+		db.Exec(`DELETE FROM events WHERE id = $1`, eventID)
+		db.Exec(`DELETE FROM organizers WHERE id = $1`, organizerID)
+		db.Exec(`DELETE FROM users WHERE id = $1`, userID)
+	})
+	return userID, ticketTypeID
+}
+
+func TestCreateHoldDoesNotOversellUnderConcurrency(t *testing.T) {
+	const (
+		stock    = 5
+		attempts = 20
+	)
+	db := testDB(t)
+	db.SetMaxOpenConns(attempts)
+	userID, ticketTypeID := createTicketType(t, db, stock)
+	repo := NewInventoryRepository(db)
+
+	var (
+		wg        sync.WaitGroup
+		mu        sync.Mutex
+		succeeded int
+		start     = make(chan struct{})
+	)
+	for i := 0; i < attempts; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			ok, err := repo.CreateHold(&model.InventoryHold{
+				UserID:       userID,
+				TicketTypeID: ticketTypeID,
+				Quantity:     1,
+				Status:       model.HoldStatusActive,
+				ExpiresAt:    time.Now().Add(10 * time.Minute),
+			}, nil)
+			if err != nil {
+				t.Errorf("create hold: %v", err)
+				return
+			}
+			if ok {
+				mu.Lock()
+				succeeded++
+				mu.Unlock()
+			}
+		}()
+	}
+	close(start)
+	wg.Wait()
+
+	if succeeded != stock {
+		t.Errorf("successful holds = %d, want %d", succeeded, stock)
+	}
+
+	var available, holds int
+	if err := db.QueryRow(`SELECT available FROM ticket_types WHERE id = $1`, ticketTypeID).Scan(&available); err != nil {
+		t.Fatalf("read available: %v", err)
+	}
+	if err := db.QueryRow(`SELECT COUNT(*) FROM inventory_holds WHERE ticket_type_id = $1`, ticketTypeID).Scan(&holds); err != nil {
+		t.Fatalf("count holds: %v", err)
+	}
+	if available != 0 {
+		t.Errorf("available = %d, want 0", available)
+	}
+	if holds != stock {
+		t.Errorf("recorded holds = %d, want %d", holds, stock)
+	}
+}
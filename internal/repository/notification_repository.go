@@ -0,0 +1,144 @@
+package repository
+
+import (
+	"database/sql"
+	"e-ticketing/internal/model"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+type NotificationRepository struct {
+	db *sql.DB
+}
+
+func NewNotificationRepository(db *sql.DB) *NotificationRepository {
+	return &NotificationRepository{db: db}
+}
+
+func (r *NotificationRepository) GetPreference(userID uuid.UUID) (*model.NotificationPreference, error) {
+	pref := &model.NotificationPreference{}
+	query := `
+		SELECT user_id, transactional_enabled, marketing_opt_in, preferred_channel,
+			COALESCE(quiet_hours_start, ''), COALESCE(quiet_hours_end, ''), timezone, created_at, updated_at
+		FROM notification_preferences
+		WHERE user_id = $1`
+
+	err := r.db.QueryRow(query, userID).Scan(
+		&pref.UserID, &pref.TransactionalEnabled, &pref.MarketingOptIn, &pref.PreferredChannel,
+		&pref.QuietHoursStart, &pref.QuietHoursEnd, &pref.Timezone, &pref.CreatedAt, &pref.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return pref, nil
+}
+
+func (r *NotificationRepository) UpsertPreference(pref *model.NotificationPreference) error {
+	query := `
+		INSERT INTO notification_preferences
+			(user_id, transactional_enabled, marketing_opt_in, preferred_channel, quiet_hours_start, quiet_hours_end, timezone)
+		VALUES ($1, $2, $3, $4, NULLIF($5, ''), NULLIF($6, ''), $7)
+		ON CONFLICT (user_id) DO UPDATE SET
+			transactional_enabled = EXCLUDED.transactional_enabled,
+			marketing_opt_in = EXCLUDED.marketing_opt_in,
+			preferred_channel = EXCLUDED.preferred_channel,
+			quiet_hours_start = EXCLUDED.quiet_hours_start,
+			quiet_hours_end = EXCLUDED.quiet_hours_end,
+			timezone = EXCLUDED.timezone,
+			updated_at = $8
+		RETURNING created_at, updated_at`
+
+	return r.db.QueryRow(query, pref.UserID, pref.TransactionalEnabled, pref.MarketingOptIn, pref.PreferredChannel,
+		pref.QuietHoursStart, pref.QuietHoursEnd, pref.Timezone, time.Now()).
+		Scan(&pref.CreatedAt, &pref.UpdatedAt)
+}
+
+func (r *NotificationRepository) SetMarketingOptIn(userID uuid.UUID, optIn bool) error {
+	query := `
+		INSERT INTO notification_preferences (user_id, marketing_opt_in)
+		VALUES ($1, $2)
+		ON CONFLICT (user_id) DO UPDATE SET marketing_opt_in = EXCLUDED.marketing_opt_in, updated_at = $3`
+	_, err := r.db.Exec(query, userID, optIn, time.Now())
+	return err
+}
+
+// Delivery log methods
+func (r *NotificationRepository) CreateLog(entry *model.NotificationLog) error {
+	query := `
+		INSERT INTO notification_logs (channel, template, recipient_masked, recipient_hash, status, provider_response, latency_ms)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, created_at`
+
+	return r.db.QueryRow(query, entry.Channel, entry.Template, entry.RecipientMasked, entry.RecipientHash,
+		entry.Status, entry.ProviderResponse, entry.LatencyMs).
+		Scan(&entry.ID, &entry.CreatedAt)
+}
+
+func (r *NotificationRepository) SearchLogs(filter *model.NotificationLogFilter) ([]model.NotificationLog, int, error) {
+	conditions := []string{"1 = 1"}
+	args := []interface{}{}
+
+	addCondition := func(clause string, value interface{}) {
+		args = append(args, value)
+		conditions = append(conditions, fmt.Sprintf(clause, len(args)))
+	}
+
+	if len(filter.RecipientHashes) > 0 {
+		addCondition("recipient_hash = ANY($%d)", pq.Array(filter.RecipientHashes))
+	}
+	if filter.Channel != "" {
+		addCondition("channel = $%d", filter.Channel)
+	}
+	if filter.Status != "" {
+		addCondition("status = $%d", filter.Status)
+	}
+	if filter.From != nil {
+		addCondition("created_at >= $%d", *filter.From)
+	}
+	if filter.To != nil {
+		addCondition("created_at < $%d", *filter.To)
+	}
+
+	where := strings.Join(conditions, " AND ")
+
+	var total int
+	if err := r.db.QueryRow(`SELECT COUNT(*) FROM notification_logs WHERE `+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query := fmt.Sprintf(`
+		SELECT id, channel, template, recipient_masked, recipient_hash, status, COALESCE(provider_response, ''), latency_ms, created_at
+		FROM notification_logs
+		WHERE %s
+		ORDER BY created_at DESC
+		LIMIT $%d OFFSET $%d`, where, len(args)+1, len(args)+2)
+
+	rows, err := r.db.Query(query, append(args, filter.Limit, filter.Offset)...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	logs := []model.NotificationLog{}
+	for rows.Next() {
+		var entry model.NotificationLog
+		if err := rows.Scan(&entry.ID, &entry.Channel, &entry.Template, &entry.RecipientMasked, &entry.RecipientHash,
+			&entry.Status, &entry.ProviderResponse, &entry.LatencyMs, &entry.CreatedAt); err != nil {
+			return nil, 0, err
+		}
+		logs = append(logs, entry)
+	}
+	return logs, total, rows.Err()
+}
+
+func (r *NotificationRepository) PruneLogs(before time.Time) (int64, error) {
+	result, err := r.db.Exec(`DELETE FROM notification_logs WHERE created_at < $1`, before)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,320 @@
+package repository
+
+import (
+	"database/sql"
+	"e-ticketing/internal/model"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+type OrderRepository struct {
+	db *sql.DB
+}
+
+func NewOrderRepository(db *sql.DB) *OrderRepository {
+	return &OrderRepository{db: db}
+}
+
+const orderColumns = `id, order_number, user_id, event_id, status, total_amount, discount_amount, COALESCE(promo_code, ''),
+	refunded_amount, currency, expires_at, paid_at, created_at, updated_at`
+
+func scanOrder(row interface{ Scan(...interface{}) error }) (*model.Order, error) {
+	o := &model.Order{}
+	var paidAt sql.NullTime
+	err := row.Scan(&o.ID, &o.OrderNumber, &o.UserID, &o.EventID, &o.Status, &o.TotalAmount, &o.DiscountAmount, &o.PromoCode,
+		&o.RefundedAmount, &o.Currency, &o.ExpiresAt, &paidAt, &o.CreatedAt, &o.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if paidAt.Valid {
+		o.PaidAt = &paidAt.Time
+	}
+	return o, nil
+}
+
+// CreateOrderFromHolds converts the given active holds into an order. Stock
+// was already taken when the holds were placed, so it stays reserved for
+// the order. It returns false when any hold is no longer active, and
+// ErrPromoLimitReached or ErrPromoUserLimitReached when the promo
+// redemption, if any, is past its limits.
+func (r *OrderRepository) CreateOrderFromHolds(order *model.Order, holdIDs []uuid.UUID, redemption *model.PromoRedemption) (bool, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	result, err := tx.Exec(`
+		UPDATE inventory_holds SET status = $1, updated_at = $2
+		WHERE id = ANY($3) AND user_id = $4 AND status = $5 AND expires_at > $2`,
+		model.HoldStatusConverted, now, pq.Array(holdIDs), order.UserID, model.HoldStatusActive)
+	if err != nil {
+		return false, err
+	}
+	if affected, err := result.RowsAffected(); err != nil || affected != int64(len(holdIDs)) {
+		return false, err
+	}
+
+	err = tx.QueryRow(`
+		INSERT INTO orders (order_number, user_id, event_id, status, total_amount, discount_amount, promo_code,
+			currency, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, NULLIF($7, ''), $8, $9)
+		RETURNING id, created_at, updated_at`,
+		order.OrderNumber, order.UserID, order.EventID, order.Status, order.TotalAmount, order.DiscountAmount,
+		order.PromoCode, order.Currency, order.ExpiresAt).
+		Scan(&order.ID, &order.CreatedAt, &order.UpdatedAt)
+	if err != nil {
+		return false, err
+	}
+
+	if redemption != nil {
+		redemption.OrderID = order.ID
+		if err := redeemPromo(tx, redemption); err != nil {
+			return false, err
+		}
+	}
+
+	for i := range order.Items {
+		item := &order.Items[i]
+		item.OrderID = order.ID
+		err = tx.QueryRow(`
+			INSERT INTO order_items (order_id, ticket_type_id, hold_id, quantity, unit_price, subtotal, discount_amount)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)
+			RETURNING id, created_at`,
+			item.OrderID, item.TicketTypeID, item.HoldID, item.Quantity, item.UnitPrice, item.Subtotal,
+			item.DiscountAmount).
+			Scan(&item.ID, &item.CreatedAt)
+		if err != nil {
+			return false, err
+		}
+		if err := insertAttendees(tx, item); err != nil {
+			return false, err
+		}
+	}
+
+	return true, tx.Commit()
+}
+
+func (r *OrderRepository) GetOrderByID(id uuid.UUID) (*model.Order, error) {
+	query := `SELECT ` + orderColumns + ` FROM orders WHERE id = $1`
+	order, err := scanOrder(r.db.QueryRow(query, id))
+	if err != nil {
+		return nil, err
+	}
+
+	order.Items, err = r.GetOrderItems(id)
+	if err != nil {
+		return nil, err
+	}
+	return order, nil
+}
+
+func (r *OrderRepository) GetOrderItems(orderID uuid.UUID) ([]model.OrderItem, error) {
+	query := `
+		SELECT id, order_id, ticket_type_id, hold_id, resale_listing_id, quantity, unit_price, subtotal, discount_amount,
+			created_at
+		FROM order_items WHERE order_id = $1 ORDER BY created_at`
+
+	rows, err := r.db.Query(query, orderID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := []model.OrderItem{}
+	for rows.Next() {
+		var item model.OrderItem
+		var holdID, listingID uuid.NullUUID
+		if err := rows.Scan(&item.ID, &item.OrderID, &item.TicketTypeID, &holdID, &listingID, &item.Quantity,
+			&item.UnitPrice, &item.Subtotal, &item.DiscountAmount, &item.CreatedAt); err != nil {
+			return nil, err
+		}
+		if holdID.Valid {
+			item.HoldID = &holdID.UUID
+		}
+		if listingID.Valid {
+			item.ResaleListingID = &listingID.UUID
+		}
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(items) == 0 {
+		return items, nil
+	}
+	itemIDs := make([]uuid.UUID, len(items))
+	for i, item := range items {
+		itemIDs[i] = item.ID
+	}
+	attendees, err := attendeesByItems(r.db, itemIDs)
+	if err != nil {
+		return nil, err
+	}
+	for i := range items {
+		items[i].Attendees = attendees[items[i].ID]
+	}
+
+	// Reserved seating items carry the seats their hold claimed
+	holdIDs := []uuid.UUID{}
+	for _, item := range items {
+		if item.HoldID != nil {
+			holdIDs = append(holdIDs, *item.HoldID)
+		}
+	}
+	if len(holdIDs) == 0 {
+		return items, nil
+	}
+	seats, err := seatsByHolds(r.db, holdIDs)
+	if err != nil {
+		return nil, err
+	}
+	for i := range items {
+		if items[i].HoldID != nil {
+			items[i].Seats = seats[*items[i].HoldID]
+		}
+	}
+	return items, nil
+}
+
+func (r *OrderRepository) ListOrdersByUser(userID uuid.UUID, status string, limit, offset int) ([]model.Order, int, error) {
+	where := `user_id = $1 AND ($2 = '' OR status = $2)`
+
+	var total int
+	if err := r.db.QueryRow(`SELECT COUNT(*) FROM orders WHERE `+where, userID, status).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query := fmt.Sprintf(`SELECT %s FROM orders WHERE %s ORDER BY created_at DESC LIMIT $3 OFFSET $4`, orderColumns, where)
+	rows, err := r.db.Query(query, userID, status, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	orders := []model.Order{}
+	for rows.Next() {
+		o, err := scanOrder(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		orders = append(orders, *o)
+	}
+	return orders, total, rows.Err()
+}
+
+// UpdateOrderStatus moves an order to status only if it is currently in one
+// of the from statuses. It returns false when the order was not in any of
+// them, which makes repeated transitions (e.g. duplicate callbacks) no-ops.
+func (r *OrderRepository) UpdateOrderStatus(id uuid.UUID, from []string, to string) (bool, error) {
+	now := time.Now()
+	query := `
+		UPDATE orders SET status = $1, updated_at = $2,
+			paid_at = CASE WHEN $1 = '` + model.OrderStatusPaid + `' THEN $2 ELSE paid_at END
+		WHERE id = $3 AND status = ANY($4)`
+
+	result, err := r.db.Exec(query, to, now, id, pq.Array(from))
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected == 1, err
+}
+
+// MarkOrderPaid moves an order from the from status to paid, checking limit
+// in the same transaction. It returns false when the order was no longer in
+// from, and a *PurchaseLimitError when the instrument that paid is over its
+// limit.
+func (r *OrderRepository) MarkOrderPaid(id uuid.UUID, from string, limit *model.InstrumentLimit) (bool, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	if err := checkInstrumentLimit(tx, limit); err != nil {
+		return false, err
+	}
+
+	now := time.Now()
+	result, err := tx.Exec(`
+		UPDATE orders SET status = $1, updated_at = $2, paid_at = $2
+		WHERE id = $3 AND status = $4`,
+		model.OrderStatusPaid, now, id, from)
+	if err != nil {
+		return false, err
+	}
+	if affected, err := result.RowsAffected(); err != nil || affected == 0 {
+		return false, err
+	}
+	return true, tx.Commit()
+}
+
+// CloseOrder moves an open order to a terminal status (cancelled/expired)
+// and returns its tickets, seats and promo usage in a single statement.
+// Resale items took no stock, so their listings simply become free to buy
+// again.
+func (r *OrderRepository) CloseOrder(id uuid.UUID, status string) (bool, error) {
+	query := `
+		WITH closed AS (
+			UPDATE orders SET status = $1, updated_at = $2
+			WHERE id = $3 AND status = ANY($4)
+			RETURNING id
+		), freed_seats AS (
+			UPDATE seats SET status = '` + model.SeatStatusAvailable + `', hold_id = NULL, updated_at = $2
+			WHERE hold_id IN (SELECT hold_id FROM order_items WHERE order_id IN (SELECT id FROM closed))
+		), totals AS (
+			SELECT ticket_type_id, SUM(quantity) AS quantity FROM order_items
+			WHERE order_id IN (SELECT id FROM closed) AND resale_listing_id IS NULL GROUP BY ticket_type_id
+		), restocked AS (
+			UPDATE ticket_types t SET available = t.available + totals.quantity, updated_at = $2
+			FROM totals WHERE t.id = totals.ticket_type_id
+		), ` + releasePromos("closed") + `
+		SELECT COUNT(*) FROM closed`
+
+	var closed int
+	err := r.db.QueryRow(query, status, time.Now(), id, pq.Array(model.OpenOrderStatuses)).Scan(&closed)
+	return closed > 0, err
+}
+
+// ExpireOrders expires every open order past its payment deadline, returns
+// its stock and promo usage and reports the expired order IDs.
+func (r *OrderRepository) ExpireOrders(now time.Time) ([]uuid.UUID, error) {
+	query := `
+		WITH expired AS (
+			UPDATE orders SET status = $1, updated_at = $2
+			WHERE status = ANY($3) AND expires_at <= $2
+			RETURNING id
+		), freed_seats AS (
+			UPDATE seats SET status = '` + model.SeatStatusAvailable + `', hold_id = NULL, updated_at = $2
+			WHERE hold_id IN (SELECT hold_id FROM order_items WHERE order_id IN (SELECT id FROM expired))
+		), totals AS (
+			SELECT ticket_type_id, SUM(quantity) AS quantity FROM order_items
+			WHERE order_id IN (SELECT id FROM expired) AND resale_listing_id IS NULL GROUP BY ticket_type_id
+		), restocked AS (
+			UPDATE ticket_types t SET available = t.available + totals.quantity, updated_at = $2
+			FROM totals WHERE t.id = totals.ticket_type_id
+		), ` + releasePromos("expired") + `
+		SELECT id FROM expired`
+
+	rows, err := r.db.Query(query, model.OrderStatusExpired, now, pq.Array(model.OpenOrderStatuses))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := []uuid.UUID{}
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
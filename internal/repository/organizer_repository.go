@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"database/sql"
+	"e-ticketing/internal/model"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type OrganizerRepository struct {
+	db *sql.DB
+}
+
+func NewOrganizerRepository(db *sql.DB) *OrganizerRepository {
+	return &OrganizerRepository{db: db}
+}
+
+const organizerColumns = `id, owner_id, name, COALESCE(description, ''), COALESCE(email, ''), COALESCE(phone, ''), COALESCE(logo_url, ''), created_at, updated_at`
+
+func scanOrganizer(row interface{ Scan(...interface{}) error }) (*model.Organizer, error) {
+	o := &model.Organizer{}
+	err := row.Scan(&o.ID, &o.OwnerID, &o.Name, &o.Description, &o.Email, &o.Phone, &o.LogoURL, &o.CreatedAt, &o.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return o, nil
+}
+
+func (r *OrganizerRepository) CreateOrganizer(o *model.Organizer) error {
+	query := `
+		INSERT INTO organizers (owner_id, name, description, email, phone, logo_url)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at, updated_at`
+
+	return r.db.QueryRow(query, o.OwnerID, o.Name, o.Description, o.Email, o.Phone, o.LogoURL).
+		Scan(&o.ID, &o.CreatedAt, &o.UpdatedAt)
+}
+
+func (r *OrganizerRepository) GetOrganizerByID(id uuid.UUID) (*model.Organizer, error) {
+	query := `SELECT ` + organizerColumns + ` FROM organizers WHERE id = $1`
+	return scanOrganizer(r.db.QueryRow(query, id))
+}
+
+func (r *OrganizerRepository) GetOrganizersByOwner(ownerID uuid.UUID) ([]model.Organizer, error) {
+	query := `SELECT ` + organizerColumns + ` FROM organizers WHERE owner_id = $1 ORDER BY created_at`
+
+	rows, err := r.db.Query(query, ownerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	organizers := []model.Organizer{}
+	for rows.Next() {
+		o, err := scanOrganizer(rows)
+		if err != nil {
+			return nil, err
+		}
+		organizers = append(organizers, *o)
+	}
+	return organizers, rows.Err()
+}
+
+func (r *OrganizerRepository) UpdateOrganizer(o *model.Organizer) error {
+	query := `
+		UPDATE organizers SET name = $1, description = $2, email = $3, phone = $4, logo_url = $5, updated_at = $6
+		WHERE id = $7
+		RETURNING updated_at`
+
+	return r.db.QueryRow(query, o.Name, o.Description, o.Email, o.Phone, o.LogoURL, time.Now(), o.ID).
+		Scan(&o.UpdatedAt)
+}
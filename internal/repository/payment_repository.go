@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"database/sql"
+	"e-ticketing/internal/model"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type PaymentRepository struct {
+	db *sql.DB
+}
+
+func NewPaymentRepository(db *sql.DB) *PaymentRepository {
+	return &PaymentRepository{db: db}
+}
+
+const paymentColumns = `id, order_id, gateway, method, COALESCE(bank, ''), status, amount, currency, external_id,
+	COALESCE(gateway_reference, ''), COALESCE(payment_token, ''), COALESCE(redirect_url, ''), COALESCE(va_number, ''),
+	COALESCE(qr_string, ''), expires_at, paid_at, created_at, updated_at`
+
+func scanPayment(row interface{ Scan(...interface{}) error }) (*model.Payment, error) {
+	p := &model.Payment{}
+	var expiresAt, paidAt sql.NullTime
+	err := row.Scan(&p.ID, &p.OrderID, &p.Gateway, &p.Method, &p.Bank, &p.Status, &p.Amount, &p.Currency, &p.ExternalID,
+		&p.GatewayReference, &p.PaymentToken, &p.RedirectURL, &p.VANumber,
+		&p.QRString, &expiresAt, &paidAt, &p.CreatedAt, &p.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if expiresAt.Valid {
+		p.ExpiresAt = &expiresAt.Time
+	}
+	if paidAt.Valid {
+		p.PaidAt = &paidAt.Time
+	}
+	return p, nil
+}
+
+func (r *PaymentRepository) CreatePayment(p *model.Payment) error {
+	query := `
+		INSERT INTO payments (id, order_id, gateway, method, bank, status, amount, currency, external_id,
+			gateway_reference, payment_token, redirect_url, va_number, qr_string, expires_at)
+		VALUES ($1, $2, $3, $4, NULLIF($5, ''), $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
+		RETURNING created_at, updated_at`
+
+	return r.db.QueryRow(query, p.ID, p.OrderID, p.Gateway, p.Method, p.Bank, p.Status, p.Amount, p.Currency, p.ExternalID,
+		p.GatewayReference, p.PaymentToken, p.RedirectURL, p.VANumber, p.QRString, p.ExpiresAt).
+		Scan(&p.CreatedAt, &p.UpdatedAt)
+}
+
+func (r *PaymentRepository) GetPaymentByID(id uuid.UUID) (*model.Payment, error) {
+	query := `SELECT ` + paymentColumns + ` FROM payments WHERE id = $1`
+	return scanPayment(r.db.QueryRow(query, id))
+}
+
+func (r *PaymentRepository) GetPaymentByExternalID(externalID string) (*model.Payment, error) {
+	query := `SELECT ` + paymentColumns + ` FROM payments WHERE external_id = $1`
+	return scanPayment(r.db.QueryRow(query, externalID))
+}
+
+func (r *PaymentRepository) GetPaymentsByOrder(orderID uuid.UUID) ([]model.Payment, error) {
+	query := `SELECT ` + paymentColumns + ` FROM payments WHERE order_id = $1 ORDER BY created_at DESC`
+
+	rows, err := r.db.Query(query, orderID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	payments := []model.Payment{}
+	for rows.Next() {
+		p, err := scanPayment(rows)
+		if err != nil {
+			return nil, err
+		}
+		payments = append(payments, *p)
+	}
+	return payments, rows.Err()
+}
+
+// UpdatePaymentStatus applies a gateway status only while the payment is
+// still in the from status, so replayed notifications change nothing.
+func (r *PaymentRepository) UpdatePaymentStatus(id uuid.UUID, from, to, reference, instrumentHash, notification string) (bool, error) {
+	now := time.Now()
+	query := `
+		UPDATE payments SET status = $1, gateway_reference = COALESCE(NULLIF($2, ''), gateway_reference),
+			last_notification = $3, updated_at = $4,
+			paid_at = CASE WHEN $1 = '` + model.PaymentStatusPaid + `' THEN $4 ELSE paid_at END,
+			instrument_hash = COALESCE(NULLIF($7, ''), instrument_hash)
+		WHERE id = $5 AND status = $6`
+
+	result, err := r.db.Exec(query, to, reference, notification, now, id, from, instrumentHash)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected == 1, err
+}
@@ -0,0 +1,327 @@
+package repository
+
+import (
+	"database/sql"
+	"e-ticketing/internal/model"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+type PromoRepository struct {
+	db *sql.DB
+}
+
+func NewPromoRepository(db *sql.DB) *PromoRepository {
+	return &PromoRepository{db: db}
+}
+
+const promotionColumns = `p.id, p.event_id, p.name, p.discount_type, p.discount_value, p.max_discount, p.min_order_amount,
+	p.usage_limit, p.per_user_limit, p.usage_count, p.starts_at, p.ends_at, p.active, p.created_at, p.updated_at,
+	ARRAY(SELECT ticket_type_id FROM promotion_ticket_types WHERE promotion_id = p.id ORDER BY ticket_type_id)`
+
+func scanPromotion(row interface{ Scan(...interface{}) error }) (*model.Promotion, error) {
+	p := &model.Promotion{}
+	var maxDiscount sql.NullInt64
+	var usageLimit, perUserLimit sql.NullInt32
+	var startsAt, endsAt sql.NullTime
+	var ticketTypeIDs []string
+	err := row.Scan(&p.ID, &p.EventID, &p.Name, &p.DiscountType, &p.DiscountValue, &maxDiscount, &p.MinOrderAmount,
+		&usageLimit, &perUserLimit, &p.UsageCount, &startsAt, &endsAt, &p.Active, &p.CreatedAt, &p.UpdatedAt,
+		pq.Array(&ticketTypeIDs))
+	if err != nil {
+		return nil, err
+	}
+	if maxDiscount.Valid {
+		p.MaxDiscount = &maxDiscount.Int64
+	}
+	if usageLimit.Valid {
+		limit := int(usageLimit.Int32)
+		p.UsageLimit = &limit
+	}
+	if perUserLimit.Valid {
+		limit := int(perUserLimit.Int32)
+		p.PerUserLimit = &limit
+	}
+	if startsAt.Valid {
+		p.StartsAt = &startsAt.Time
+	}
+	if endsAt.Valid {
+		p.EndsAt = &endsAt.Time
+	}
+	p.TicketTypeIDs = make([]uuid.UUID, 0, len(ticketTypeIDs))
+	for _, raw := range ticketTypeIDs {
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			return nil, err
+		}
+		p.TicketTypeIDs = append(p.TicketTypeIDs, id)
+	}
+	return p, nil
+}
+
+const promoCodeColumns = `id, promotion_id, event_id, code, max_uses, usage_count, created_at`
+
+func scanPromoCode(row interface{ Scan(...interface{}) error }) (*model.PromoCode, error) {
+	c := &model.PromoCode{}
+	var maxUses sql.NullInt32
+	err := row.Scan(&c.ID, &c.PromotionID, &c.EventID, &c.Code, &maxUses, &c.UsageCount, &c.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if maxUses.Valid {
+		uses := int(maxUses.Int32)
+		c.MaxUses = &uses
+	}
+	return c, nil
+}
+
+func (r *PromoRepository) CreatePromotion(p *model.Promotion) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRow(`
+		INSERT INTO promotions (event_id, name, discount_type, discount_value, max_discount, min_order_amount,
+			usage_limit, per_user_limit, starts_at, ends_at, active)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		RETURNING id, created_at, updated_at`,
+		p.EventID, p.Name, p.DiscountType, p.DiscountValue, p.MaxDiscount, p.MinOrderAmount,
+		p.UsageLimit, p.PerUserLimit, p.StartsAt, p.EndsAt, p.Active).
+		Scan(&p.ID, &p.CreatedAt, &p.UpdatedAt)
+	if err != nil {
+		return err
+	}
+	if err := savePromotionTicketTypes(tx, p); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// UpdatePromotion saves the rules and replaces the eligible ticket types.
+// Orders already redeeming the promotion keep their discount.
+func (r *PromoRepository) UpdatePromotion(p *model.Promotion) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRow(`
+		UPDATE promotions SET name = $1, discount_type = $2, discount_value = $3, max_discount = $4,
+			min_order_amount = $5, usage_limit = $6, per_user_limit = $7, starts_at = $8, ends_at = $9, active = $10,
+			updated_at = $11
+		WHERE id = $12
+		RETURNING usage_count, updated_at`,
+		p.Name, p.DiscountType, p.DiscountValue, p.MaxDiscount, p.MinOrderAmount, p.UsageLimit, p.PerUserLimit,
+		p.StartsAt, p.EndsAt, p.Active, time.Now(), p.ID).
+		Scan(&p.UsageCount, &p.UpdatedAt)
+	if err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM promotion_ticket_types WHERE promotion_id = $1`, p.ID); err != nil {
+		return err
+	}
+	if err := savePromotionTicketTypes(tx, p); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func savePromotionTicketTypes(tx *sql.Tx, p *model.Promotion) error {
+	if len(p.TicketTypeIDs) == 0 {
+		return nil
+	}
+	_, err := tx.Exec(`
+		INSERT INTO promotion_ticket_types (promotion_id, ticket_type_id)
+		SELECT $1, unnest($2::uuid[])`,
+		p.ID, pq.Array(p.TicketTypeIDs))
+	return err
+}
+
+func (r *PromoRepository) GetPromotion(id uuid.UUID) (*model.Promotion, error) {
+	query := `SELECT ` + promotionColumns + ` FROM promotions p WHERE p.id = $1`
+	return scanPromotion(r.db.QueryRow(query, id))
+}
+
+func (r *PromoRepository) ListPromotions(eventID uuid.UUID, limit, offset int) ([]model.Promotion, int, error) {
+	var total int
+	if err := r.db.QueryRow(`SELECT COUNT(*) FROM promotions WHERE event_id = $1`, eventID).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query := `SELECT ` + promotionColumns + ` FROM promotions p WHERE p.event_id = $1
+		ORDER BY p.created_at DESC LIMIT $2 OFFSET $3`
+	rows, err := r.db.Query(query, eventID, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	promotions := []model.Promotion{}
+	for rows.Next() {
+		p, err := scanPromotion(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		promotions = append(promotions, *p)
+	}
+	return promotions, total, rows.Err()
+}
+
+func (r *PromoRepository) CreateCode(c *model.PromoCode) error {
+	query := `
+		INSERT INTO promo_codes (promotion_id, event_id, code, max_uses)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at`
+
+	return r.db.QueryRow(query, c.PromotionID, c.EventID, c.Code, c.MaxUses).Scan(&c.ID, &c.CreatedAt)
+}
+
+// CreateCodes inserts generated codes in one transaction, skipping any that
+// collide with an existing code of the event. It returns the codes created.
+func (r *PromoRepository) CreateCodes(codes []model.PromoCode) ([]model.PromoCode, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	created := []model.PromoCode{}
+	for _, c := range codes {
+		err := tx.QueryRow(`
+			INSERT INTO promo_codes (promotion_id, event_id, code, max_uses)
+			VALUES ($1, $2, $3, $4)
+			ON CONFLICT (event_id, code) DO NOTHING
+			RETURNING id, created_at`,
+			c.PromotionID, c.EventID, c.Code, c.MaxUses).Scan(&c.ID, &c.CreatedAt)
+		if err == sql.ErrNoRows {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		created = append(created, c)
+	}
+	return created, tx.Commit()
+}
+
+func (r *PromoRepository) GetCode(eventID uuid.UUID, code string) (*model.PromoCode, error) {
+	query := `SELECT ` + promoCodeColumns + ` FROM promo_codes WHERE event_id = $1 AND code = $2`
+	return scanPromoCode(r.db.QueryRow(query, eventID, code))
+}
+
+func (r *PromoRepository) ListCodes(promotionID uuid.UUID, limit, offset int) ([]model.PromoCode, int, error) {
+	var total int
+	if err := r.db.QueryRow(`SELECT COUNT(*) FROM promo_codes WHERE promotion_id = $1`, promotionID).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query := `SELECT ` + promoCodeColumns + ` FROM promo_codes WHERE promotion_id = $1
+		ORDER BY created_at, code LIMIT $2 OFFSET $3`
+	rows, err := r.db.Query(query, promotionID, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	codes := []model.PromoCode{}
+	for rows.Next() {
+		c, err := scanPromoCode(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		codes = append(codes, *c)
+	}
+	return codes, total, rows.Err()
+}
+
+// CountUserRedemptions counts the user's orders currently redeeming the
+// promotion.
+func (r *PromoRepository) CountUserRedemptions(promotionID, userID uuid.UUID) (int, error) {
+	var count int
+	err := r.db.QueryRow(`
+		SELECT COUNT(*) FROM promo_redemptions WHERE promotion_id = $1 AND user_id = $2 AND status = $3`,
+		promotionID, userID, model.RedemptionStatusActive).Scan(&count)
+	return count, err
+}
+
+// redeemPromo counts the redemption against the promotion's and the code's
+// limits and records it for the order. The counters are conditional
+// updates, so concurrent orders cannot redeem past a limit, and the
+// promotion row they lock makes the per-user check run one order at a time.
+// It returns ErrPromoLimitReached or ErrPromoUserLimitReached when a limit
+// is reached.
+func redeemPromo(tx *sql.Tx, redemption *model.PromoRedemption) error {
+	now := time.Now()
+	result, err := tx.Exec(`
+		UPDATE promotions SET usage_count = usage_count + 1, updated_at = $1
+		WHERE id = $2 AND active AND (usage_limit IS NULL OR usage_count < usage_limit)`,
+		now, redemption.PromotionID)
+	if err != nil {
+		return err
+	}
+	if affected, err := result.RowsAffected(); err != nil || affected == 0 {
+		return limitReached(err, ErrPromoLimitReached)
+	}
+
+	result, err = tx.Exec(`
+		UPDATE promo_codes SET usage_count = usage_count + 1
+		WHERE id = $1 AND (max_uses IS NULL OR usage_count < max_uses)`,
+		redemption.PromoCodeID)
+	if err != nil {
+		return err
+	}
+	if affected, err := result.RowsAffected(); err != nil || affected == 0 {
+		return limitReached(err, ErrPromoLimitReached)
+	}
+
+	var allowed bool
+	err = tx.QueryRow(`
+		SELECT per_user_limit IS NULL OR per_user_limit > (
+			SELECT COUNT(*) FROM promo_redemptions WHERE promotion_id = p.id AND user_id = $2 AND status = $3
+		) FROM promotions p WHERE p.id = $1`,
+		redemption.PromotionID, redemption.UserID, model.RedemptionStatusActive).Scan(&allowed)
+	if err != nil || !allowed {
+		return limitReached(err, ErrPromoUserLimitReached)
+	}
+
+	redemption.Status = model.RedemptionStatusActive
+	return tx.QueryRow(`
+		INSERT INTO promo_redemptions (promotion_id, promo_code_id, order_id, user_id, discount_amount, status)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at`,
+		redemption.PromotionID, redemption.PromoCodeID, redemption.OrderID, redemption.UserID,
+		redemption.DiscountAmount, redemption.Status).
+		Scan(&redemption.ID, &redemption.CreatedAt)
+}
+
+// limitReached returns err when the check itself failed, or limit otherwise.
+func limitReached(err, limit error) error {
+	if err != nil {
+		return err
+	}
+	return limit
+}
+
+// releasePromos returns the CTEs that give back the promo usage of the
+// orders listed in the orders CTE, for statements that close orders. $2 must
+// be the current time.
+func releasePromos(orders string) string {
+	return `released_promos AS (
+			UPDATE promo_redemptions SET status = '` + model.RedemptionStatusReleased + `', updated_at = $2
+			WHERE order_id IN (SELECT id FROM ` + orders + `) AND status = '` + model.RedemptionStatusActive + `'
+			RETURNING promotion_id, promo_code_id
+		), released_promotions AS (
+			UPDATE promotions p SET usage_count = p.usage_count - r.uses, updated_at = $2
+			FROM (SELECT promotion_id, COUNT(*) AS uses FROM released_promos GROUP BY promotion_id) r
+			WHERE p.id = r.promotion_id
+		), released_codes AS (
+			UPDATE promo_codes c SET usage_count = c.usage_count - r.uses
+			FROM (SELECT promo_code_id, COUNT(*) AS uses FROM released_promos GROUP BY promo_code_id) r
+			WHERE c.id = r.promo_code_id
+		)`
+}
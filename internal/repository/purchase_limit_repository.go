@@ -0,0 +1,268 @@
+package repository
+
+import (
+	"database/sql"
+	"e-ticketing/internal/model"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+type PurchaseLimitRepository struct {
+	db *sql.DB
+}
+
+func NewPurchaseLimitRepository(db *sql.DB) *PurchaseLimitRepository {
+	return &PurchaseLimitRepository{db: db}
+}
+
+// Scopes of a PurchaseLimitError
+const (
+	LimitScopeUser       = "user"
+	LimitScopePhone      = "phone"
+	LimitScopeInstrument = "instrument"
+)
+
+// PurchaseLimitError is returned when a hold would take the buyer past an
+// event's per-user or per-phone limit, or an order past its per-instrument
+// limit. Current is what was already counted.
+type PurchaseLimitError struct {
+	Scope   string
+	Limit   int
+	Current int
+}
+
+func (e *PurchaseLimitError) Error() string {
+	return fmt.Sprintf("%s limit of %d tickets reached, %d held", e.Scope, e.Limit, e.Current)
+}
+
+const purchasePolicyColumns = `event_id, max_tickets_per_user, max_tickets_per_phone, max_tickets_per_instrument,
+	require_step_up, step_up_window_minutes, created_at, updated_at`
+
+// normalizedPhone folds formatting and the leading 0 of column into the 62
+// country code, so +62 812-..., 0812... and 62812... count as one phone. It
+// matches utils.NormalizePhone.
+func normalizedPhone(column string) string {
+	return `regexp_replace(regexp_replace(` + column + `, '\D', '', 'g'), '^0', '62')`
+}
+
+func scanPurchasePolicy(row interface{ Scan(...interface{}) error }) (*model.PurchasePolicy, error) {
+	p := &model.PurchasePolicy{}
+	err := row.Scan(&p.EventID, &p.MaxTicketsPerUser, &p.MaxTicketsPerPhone, &p.MaxTicketsPerInstrument,
+		&p.RequireStepUp, &p.StepUpWindowMinutes, &p.CreatedAt, &p.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func (r *PurchaseLimitRepository) SavePolicy(p *model.PurchasePolicy) error {
+	query := `
+		INSERT INTO purchase_policies (event_id, max_tickets_per_user, max_tickets_per_phone, max_tickets_per_instrument,
+			require_step_up, step_up_window_minutes)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (event_id) DO UPDATE SET max_tickets_per_user = $2, max_tickets_per_phone = $3,
+			max_tickets_per_instrument = $4, require_step_up = $5, step_up_window_minutes = $6, updated_at = $7
+		RETURNING created_at, updated_at`
+
+	return r.db.QueryRow(query, p.EventID, p.MaxTicketsPerUser, p.MaxTicketsPerPhone, p.MaxTicketsPerInstrument,
+		p.RequireStepUp, p.StepUpWindowMinutes, time.Now()).
+		Scan(&p.CreatedAt, &p.UpdatedAt)
+}
+
+func (r *PurchaseLimitRepository) GetPolicy(eventID uuid.UUID) (*model.PurchasePolicy, error) {
+	query := `SELECT ` + purchasePolicyColumns + ` FROM purchase_policies WHERE event_id = $1`
+	return scanPurchasePolicy(r.db.QueryRow(query, eventID))
+}
+
+// countTickets returns how many tickets of the event the users hold or have
+// ordered, counting active holds, open orders and paid orders.
+func countTickets(tx *sql.Tx, eventID uuid.UUID, userIDs []uuid.UUID) (int, error) {
+	query := `
+		SELECT COALESCE(SUM(quantity), 0) FROM (
+			SELECT h.quantity FROM inventory_holds h
+			JOIN ticket_types t ON t.id = h.ticket_type_id
+			WHERE t.event_id = $1 AND h.user_id = ANY($2) AND h.status = $3 AND h.expires_at > $4
+			UNION ALL
+			SELECT oi.quantity FROM order_items oi
+			JOIN orders o ON o.id = oi.order_id
+			WHERE o.event_id = $1 AND o.user_id = ANY($2) AND o.status = ANY($5)
+		) counted`
+
+	var total int
+	err := tx.QueryRow(query, eventID, pq.Array(userIDs), model.HoldStatusActive, time.Now(),
+		pq.Array(purchasedOrderStatuses())).Scan(&total)
+	return total, err
+}
+
+// usersSharingPhone returns the verified accounts, including userID itself,
+// whose phone number normalizes to the same one as userID's.
+func usersSharingPhone(tx *sql.Tx, userID uuid.UUID) ([]uuid.UUID, error) {
+	query := `
+		SELECT id FROM users
+		WHERE ` + normalizedPhone("phone") + ` = (SELECT ` + normalizedPhone("phone") + ` FROM users WHERE id = $1)
+			AND (is_verified OR id = $1)`
+
+	rows, err := tx.Query(query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := []uuid.UUID{}
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// checkHoldLimit runs first in a hold's transaction. It takes a lock shared
+// by every account with the buyer's phone number for the event, which
+// covers the buyer's own holds too, so concurrent holds are counted one at a
+// time. It returns a *PurchaseLimitError when the new hold would exceed the
+// limit; a nil limit checks nothing.
+func checkHoldLimit(tx *sql.Tx, limit *model.HoldLimit) error {
+	if limit == nil {
+		return nil
+	}
+
+	_, err := tx.Exec(`
+		SELECT pg_advisory_xact_lock(hashtextextended($1 || ':' || `+normalizedPhone("phone")+`, 0))
+		FROM users WHERE id = $2`,
+		limit.EventID.String(), limit.UserID)
+	if err != nil {
+		return err
+	}
+
+	if limit.MaxPerUser > 0 {
+		count, err := countTickets(tx, limit.EventID, []uuid.UUID{limit.UserID})
+		if err != nil {
+			return err
+		}
+		if count+limit.Quantity > limit.MaxPerUser {
+			return &PurchaseLimitError{Scope: LimitScopeUser, Limit: limit.MaxPerUser, Current: count}
+		}
+	}
+
+	if limit.MaxPerPhone > 0 {
+		userIDs, err := usersSharingPhone(tx, limit.UserID)
+		if err != nil {
+			return err
+		}
+		count, err := countTickets(tx, limit.EventID, userIDs)
+		if err != nil {
+			return err
+		}
+		if count+limit.Quantity > limit.MaxPerPhone {
+			return &PurchaseLimitError{Scope: LimitScopePhone, Limit: limit.MaxPerPhone, Current: count}
+		}
+	}
+	return nil
+}
+
+// checkInstrumentLimit runs first in the transaction that marks an order
+// paid. It locks the event's instrument so orders it pays are counted one at
+// a time, and returns a *PurchaseLimitError when the order would exceed the
+// limit; a nil limit checks nothing.
+func checkInstrumentLimit(tx *sql.Tx, limit *model.InstrumentLimit) error {
+	if limit == nil {
+		return nil
+	}
+
+	if _, err := tx.Exec(`SELECT pg_advisory_xact_lock(hashtextextended($1, 0))`,
+		limit.EventID.String()+":"+limit.InstrumentHash); err != nil {
+		return err
+	}
+
+	count, err := countTicketsByInstrument(tx, limit.EventID, limit.InstrumentHash, limit.OrderID)
+	if err != nil {
+		return err
+	}
+	if count+limit.Quantity > limit.Max {
+		return &PurchaseLimitError{Scope: LimitScopeInstrument, Limit: limit.Max, Current: count}
+	}
+	return nil
+}
+
+// countTicketsByInstrument returns the tickets of the event in paid orders
+// settled with the instrument, leaving out excludeOrderID.
+func countTicketsByInstrument(tx *sql.Tx, eventID uuid.UUID, instrumentHash string, excludeOrderID uuid.UUID) (int, error) {
+	query := `
+		SELECT COALESCE(SUM(oi.quantity), 0) FROM order_items oi
+		JOIN orders o ON o.id = oi.order_id
+		WHERE o.event_id = $1 AND o.status = $2 AND o.id <> $3
+			AND EXISTS (SELECT 1 FROM payments p WHERE p.order_id = o.id AND p.instrument_hash = $4 AND p.status = $5)`
+
+	var total int
+	err := tx.QueryRow(query, eventID, model.OrderStatusPaid, excludeOrderID, instrumentHash, model.PaymentStatusPaid).Scan(&total)
+	return total, err
+}
+
+// RecordSignal notes that the user bought for the event from a device or
+// instrument.
+func (r *PurchaseLimitRepository) RecordSignal(eventID, userID uuid.UUID, kind, valueHash string) error {
+	query := `
+		INSERT INTO purchase_signals (event_id, user_id, kind, value_hash)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (event_id, user_id, kind, value_hash) DO UPDATE SET last_seen_at = $5`
+
+	_, err := r.db.Exec(query, eventID, userID, kind, valueHash, time.Now())
+	return err
+}
+
+// ListClusters returns the devices and instruments of the event used by more
+// than one account, with every account involved and its ordered tickets.
+func (r *PurchaseLimitRepository) ListClusters(eventID uuid.UUID) ([]model.PurchaseCluster, error) {
+	query := `
+		WITH shared AS (
+			SELECT kind, value_hash FROM purchase_signals
+			WHERE event_id = $1
+			GROUP BY kind, value_hash
+			HAVING COUNT(DISTINCT user_id) > 1
+		)
+		SELECT s.kind, s.value_hash, u.id, u.name, u.email, u.phone,
+			COALESCE((
+				SELECT SUM(oi.quantity) FROM order_items oi
+				JOIN orders o ON o.id = oi.order_id
+				WHERE o.event_id = $1 AND o.user_id = u.id AND o.status = ANY($2)
+			), 0)
+		FROM purchase_signals s
+		JOIN shared ON shared.kind = s.kind AND shared.value_hash = s.value_hash
+		JOIN users u ON u.id = s.user_id
+		WHERE s.event_id = $1
+		ORDER BY s.kind, s.value_hash, u.name`
+
+	rows, err := r.db.Query(query, eventID, pq.Array(purchasedOrderStatuses()))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	clusters := []model.PurchaseCluster{}
+	for rows.Next() {
+		var kind, valueHash string
+		var account model.ClusterAccount
+		if err := rows.Scan(&kind, &valueHash, &account.UserID, &account.Name, &account.Email, &account.Phone, &account.Tickets); err != nil {
+			return nil, err
+		}
+
+		last := len(clusters) - 1
+		if last < 0 || clusters[last].Kind != kind || clusters[last].ID != valueHash {
+			clusters = append(clusters, model.PurchaseCluster{Kind: kind, ID: valueHash})
+			last++
+		}
+		clusters[last].Accounts = append(clusters[last].Accounts, account)
+		clusters[last].Tickets += account.Tickets
+	}
+	return clusters, rows.Err()
+}
+
+func purchasedOrderStatuses() []string {
+	return append([]string{model.OrderStatusPaid}, model.OpenOrderStatuses...)
+}
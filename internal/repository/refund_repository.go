@@ -0,0 +1,415 @@
+package repository
+
+import (
+	"database/sql"
+	"e-ticketing/internal/model"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+type RefundRepository struct {
+	db *sql.DB
+}
+
+func NewRefundRepository(db *sql.DB) *RefundRepository {
+	return &RefundRepository{db: db}
+}
+
+// A ticket can be refunded while it is valid in the buyer's hands and not in
+// transit.
+const refundableTicket = `t.status = '` + model.TicketStatusValid + `' AND NOT ` + ticketInTransit
+
+const refundPolicyColumns = `event_id, enabled, deadline_hours_before_start, fee_percent, created_at, updated_at`
+
+func scanRefundPolicy(row interface{ Scan(...interface{}) error }) (*model.RefundPolicy, error) {
+	p := &model.RefundPolicy{}
+	err := row.Scan(&p.EventID, &p.Enabled, &p.DeadlineHoursBeforeStart, &p.FeePercent, &p.CreatedAt, &p.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Refund reads join the order and event for display.
+const refundColumns = `r.id, r.order_id, r.payment_id, r.order_item_id, r.event_id, r.user_id, r.status, r.reason, r.amount, r.fee_amount, r.refund_amount,
+	r.currency, r.reject_reason, r.failure_reason, r.gateway_reference, r.reviewed_by, r.reviewed_at, r.refunded_at,
+	r.created_at, r.updated_at, o.order_number, e.title`
+
+const refundFrom = `refunds r
+	JOIN orders o ON o.id = r.order_id
+	JOIN events e ON e.id = r.event_id`
+
+func scanRefund(row interface{ Scan(...interface{}) error }) (*model.Refund, error) {
+	r := &model.Refund{}
+	var paymentID, orderItemID, reviewedBy uuid.NullUUID
+	var reviewedAt, refundedAt sql.NullTime
+	err := row.Scan(&r.ID, &r.OrderID, &paymentID, &orderItemID, &r.EventID, &r.UserID, &r.Status, &r.Reason, &r.Amount, &r.FeeAmount, &r.RefundAmount,
+		&r.Currency, &r.RejectReason, &r.FailureReason, &r.GatewayReference, &reviewedBy, &reviewedAt, &refundedAt,
+		&r.CreatedAt, &r.UpdatedAt, &r.OrderNumber, &r.EventTitle)
+	if err != nil {
+		return nil, err
+	}
+	if paymentID.Valid {
+		r.PaymentID = &paymentID.UUID
+	}
+	if orderItemID.Valid {
+		r.OrderItemID = &orderItemID.UUID
+	}
+	if reviewedBy.Valid {
+		r.ReviewedBy = &reviewedBy.UUID
+	}
+	if reviewedAt.Valid {
+		r.ReviewedAt = &reviewedAt.Time
+	}
+	if refundedAt.Valid {
+		r.RefundedAt = &refundedAt.Time
+	}
+	return r, nil
+}
+
+func (r *RefundRepository) SavePolicy(p *model.RefundPolicy) error {
+	query := `
+		INSERT INTO refund_policies (event_id, enabled, deadline_hours_before_start, fee_percent)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (event_id) DO UPDATE SET enabled = $2, deadline_hours_before_start = $3, fee_percent = $4,
+			updated_at = $5
+		RETURNING created_at, updated_at`
+
+	return r.db.QueryRow(query, p.EventID, p.Enabled, p.DeadlineHoursBeforeStart, p.FeePercent, time.Now()).
+		Scan(&p.CreatedAt, &p.UpdatedAt)
+}
+
+func (r *RefundRepository) GetPolicy(eventID uuid.UUID) (*model.RefundPolicy, error) {
+	query := `SELECT ` + refundPolicyColumns + ` FROM refund_policies WHERE event_id = $1`
+	return scanRefundPolicy(r.db.QueryRow(query, eventID))
+}
+
+// RefundableTickets returns the order's tickets that userID still holds and
+// may refund.
+func (r *RefundRepository) RefundableTickets(orderID, userID uuid.UUID) ([]model.Ticket, error) {
+	query := `SELECT ` + ticketColumns + ` FROM ` + ticketFrom + `
+		WHERE t.order_id = $1 AND t.user_id = $2 AND ` + refundableTicket + `
+		ORDER BY t.created_at, t.sequence`
+
+	rows, err := r.db.Query(query, orderID, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tickets := []model.Ticket{}
+	for rows.Next() {
+		t, err := scanTicket(rows)
+		if err != nil {
+			return nil, err
+		}
+		tickets = append(tickets, *t)
+	}
+	return tickets, rows.Err()
+}
+
+// CreateRefund inserts a refund with its items. An order may only have one
+// open request and a payment or order item only one refund; a second one
+// fails with a unique violation.
+func (r *RefundRepository) CreateRefund(refund *model.Refund) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRow(`
+		INSERT INTO refunds (order_id, payment_id, order_item_id, event_id, user_id, status, reason, amount, fee_amount,
+			refund_amount, currency)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		RETURNING id, created_at, updated_at`,
+		refund.OrderID, refund.PaymentID, refund.OrderItemID, refund.EventID, refund.UserID, refund.Status, refund.Reason, refund.Amount, refund.FeeAmount,
+		refund.RefundAmount, refund.Currency).
+		Scan(&refund.ID, &refund.CreatedAt, &refund.UpdatedAt)
+	if err != nil {
+		return err
+	}
+
+	for _, item := range refund.Items {
+		if _, err := tx.Exec(`INSERT INTO refund_items (refund_id, ticket_id, amount) VALUES ($1, $2, $3)`,
+			refund.ID, item.TicketID, item.Amount); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (r *RefundRepository) GetRefund(id uuid.UUID) (*model.Refund, error) {
+	query := `SELECT ` + refundColumns + ` FROM ` + refundFrom + ` WHERE r.id = $1`
+	refund, err := scanRefund(r.db.QueryRow(query, id))
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := r.refundItems([]uuid.UUID{id})
+	if err != nil {
+		return nil, err
+	}
+	refund.Items = items[id]
+	return refund, nil
+}
+
+func (r *RefundRepository) ListRefunds(filter *model.RefundFilter) ([]model.Refund, int, error) {
+	conditions := []string{"1 = 1"}
+	args := []interface{}{}
+
+	addCondition := func(clause string, value interface{}) {
+		args = append(args, value)
+		conditions = append(conditions, fmt.Sprintf(clause, len(args)))
+	}
+
+	if filter.EventID != nil {
+		addCondition("r.event_id = $%d", *filter.EventID)
+	}
+	if filter.UserID != nil {
+		addCondition("r.user_id = $%d", *filter.UserID)
+	}
+	if filter.Status != "" {
+		addCondition("r.status = $%d", filter.Status)
+	}
+
+	where := strings.Join(conditions, " AND ")
+
+	var total int
+	if err := r.db.QueryRow(`SELECT COUNT(*) FROM refunds r WHERE `+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	args = append(args, filter.Limit, filter.Offset)
+	query := fmt.Sprintf(`SELECT %s FROM %s WHERE %s ORDER BY r.created_at DESC LIMIT $%d OFFSET $%d`,
+		refundColumns, refundFrom, where, len(args)-1, len(args))
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	refunds := []model.Refund{}
+	for rows.Next() {
+		refund, err := scanRefund(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		refunds = append(refunds, *refund)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	if len(refunds) == 0 {
+		return refunds, total, nil
+	}
+	ids := make([]uuid.UUID, len(refunds))
+	for i, refund := range refunds {
+		ids[i] = refund.ID
+	}
+	items, err := r.refundItems(ids)
+	if err != nil {
+		return nil, 0, err
+	}
+	for i := range refunds {
+		refunds[i].Items = items[refunds[i].ID]
+	}
+	return refunds, total, nil
+}
+
+// refundItems loads the items of the given refunds keyed by refund.
+func (r *RefundRepository) refundItems(refundIDs []uuid.UUID) (map[uuid.UUID][]model.RefundItem, error) {
+	rows, err := r.db.Query(`
+		SELECT ri.refund_id, ri.ticket_id, t.code, tt.name, ri.amount
+		FROM refund_items ri
+		JOIN tickets t ON t.id = ri.ticket_id
+		JOIN ticket_types tt ON tt.id = t.ticket_type_id
+		WHERE ri.refund_id = ANY($1)
+		ORDER BY t.created_at, t.sequence`, pq.Array(refundIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := map[uuid.UUID][]model.RefundItem{}
+	for rows.Next() {
+		var refundID uuid.UUID
+		var item model.RefundItem
+		if err := rows.Scan(&refundID, &item.TicketID, &item.Code, &item.TicketTypeName, &item.Amount); err != nil {
+			return nil, err
+		}
+		items[refundID] = append(items[refundID], item)
+	}
+	return items, rows.Err()
+}
+
+// CancelRefund withdraws the buyer's request while it is still awaiting
+// review. It returns false when it is not.
+func (r *RefundRepository) CancelRefund(id, userID uuid.UUID) (bool, error) {
+	return r.updateStatus(`
+		UPDATE refunds SET status = $1, updated_at = $2
+		WHERE id = $3 AND user_id = $4 AND status = $5`,
+		model.RefundStatusCancelled, time.Now(), id, userID, model.RefundStatusRequested)
+}
+
+// RejectRefund turns down a request awaiting review. It returns false when
+// it is not.
+func (r *RefundRepository) RejectRefund(id, reviewerID uuid.UUID, reason string) (bool, error) {
+	return r.updateStatus(`
+		UPDATE refunds SET status = $1, reject_reason = $2, reviewed_by = $3, reviewed_at = $4, updated_at = $4
+		WHERE id = $5 AND status = $6`,
+		model.RefundStatusRejected, reason, reviewerID, time.Now(), id, model.RefundStatusRequested)
+}
+
+// ApproveRefund approves a request awaiting review and, in the same
+// transaction, voids its tickets and returns their stock and seats. It
+// returns false when the request is not awaiting review, and sql.ErrNoRows
+// when any of its tickets can no longer be refunded, e.g. because it was
+// used or transferred meanwhile.
+func (r *RefundRepository) ApproveRefund(id, reviewerID uuid.UUID) (bool, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	var userID uuid.UUID
+	err = tx.QueryRow(`
+		UPDATE refunds SET status = $1, reviewed_by = $2, reviewed_at = $3, updated_at = $3
+		WHERE id = $4 AND status = $5
+		RETURNING user_id`,
+		model.RefundStatusApproved, reviewerID, now, id, model.RefundStatusRequested).Scan(&userID)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	query := `
+		WITH voided AS (
+			UPDATE tickets t SET status = $1, updated_at = $2
+			WHERE t.id IN (SELECT ticket_id FROM refund_items WHERE refund_id = $3) AND t.user_id = $4
+				AND ` + refundableTicket + `
+			RETURNING t.ticket_type_id, t.seat_id
+		), freed_seats AS (
+			UPDATE seats SET status = '` + model.SeatStatusAvailable + `', hold_id = NULL, updated_at = $2
+			WHERE id IN (SELECT seat_id FROM voided)
+		), totals AS (
+			SELECT ticket_type_id, COUNT(*) AS quantity FROM voided GROUP BY ticket_type_id
+		), restocked AS (
+			UPDATE ticket_types t SET available = t.available + totals.quantity, updated_at = $2
+			FROM totals WHERE t.id = totals.ticket_type_id
+		)
+		SELECT (SELECT COUNT(*) FROM voided), (SELECT COUNT(*) FROM refund_items WHERE refund_id = $3)`
+
+	var voided, items int
+	if err := tx.QueryRow(query, model.TicketStatusVoid, now, id, userID).Scan(&voided, &items); err != nil {
+		return false, err
+	}
+	if voided != items {
+		return false, sql.ErrNoRows
+	}
+
+	return true, tx.Commit()
+}
+
+// RetryRefund puts a failed refund back to approved for another gateway
+// attempt. It returns false when the refund has not failed.
+func (r *RefundRepository) RetryRefund(id uuid.UUID) (bool, error) {
+	return r.updateStatus(`
+		UPDATE refunds SET status = $1, updated_at = $2
+		WHERE id = $3 AND status = $4`,
+		model.RefundStatusApproved, time.Now(), id, model.RefundStatusFailed)
+}
+
+// FailRefund records why the gateway did not pay an approved refund back.
+func (r *RefundRepository) FailRefund(id uuid.UUID, reason string) (bool, error) {
+	return r.updateStatus(`
+		UPDATE refunds SET status = $1, failure_reason = $2, updated_at = $3
+		WHERE id = $4 AND status = $5`,
+		model.RefundStatusFailed, reason, time.Now(), id, model.RefundStatusApproved)
+}
+
+// CompleteRefund marks a refund in the from status as paid back and adds it
+// to its order. Once every ticket of the order is refunded and its total
+// paid back, the order and its payment become refunded. A refund of a whole
+// payment only marks that payment refunded. It returns false when the
+// refund is not in the from status.
+func (r *RefundRepository) CompleteRefund(id uuid.UUID, from, reference string) (bool, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	var orderID uuid.UUID
+	var paymentID uuid.NullUUID
+	var amount int64
+	err = tx.QueryRow(`
+		UPDATE refunds SET status = $1, gateway_reference = $2, failure_reason = '', refunded_at = $3, updated_at = $3
+		WHERE id = $4 AND status = $5
+		RETURNING order_id, payment_id, refund_amount`,
+		model.RefundStatusRefunded, reference, now, id, from).Scan(&orderID, &paymentID, &amount)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	if paymentID.Valid {
+		if _, err := tx.Exec(`
+			UPDATE payments SET status = $1, updated_at = $2
+			WHERE id = $3 AND status = $4`,
+			model.PaymentStatusRefunded, now, paymentID.UUID, model.PaymentStatusPaid); err != nil {
+			return false, err
+		}
+		return true, tx.Commit()
+	}
+
+	var status string
+	err = tx.QueryRow(`
+		UPDATE orders o SET refunded_amount = o.refunded_amount + $1, updated_at = $2,
+			status = CASE WHEN
+				(SELECT COALESCE(SUM(r.amount), 0) FROM refunds r
+					WHERE r.order_id = o.id AND r.status = $3 AND r.payment_id IS NULL) >= o.total_amount
+				AND NOT EXISTS (SELECT 1 FROM tickets t WHERE t.order_id = o.id AND t.status IN ($4, $5))
+			THEN $6 ELSE o.status END
+		WHERE o.id = $7
+		RETURNING o.status`,
+		amount, now, model.RefundStatusRefunded, model.TicketStatusValid, model.TicketStatusUsed,
+		model.OrderStatusRefunded, orderID).Scan(&status)
+	if err != nil {
+		return false, err
+	}
+
+	if status == model.OrderStatusRefunded {
+		if _, err := tx.Exec(`
+			UPDATE payments SET status = $1, updated_at = $2
+			WHERE order_id = $3 AND status = $4`,
+			model.PaymentStatusRefunded, now, orderID, model.PaymentStatusPaid); err != nil {
+			return false, err
+		}
+	}
+
+	return true, tx.Commit()
+}
+
+func (r *RefundRepository) updateStatus(query string, args ...interface{}) (bool, error) {
+	result, err := r.db.Exec(query, args...)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected == 1, err
+}
@@ -0,0 +1,381 @@
+package repository
+
+import (
+	"database/sql"
+	"e-ticketing/internal/model"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type ResaleRepository struct {
+	db *sql.DB
+}
+
+func NewResaleRepository(db *sql.DB) *ResaleRepository {
+	return &ResaleRepository{db: db}
+}
+
+// A listing is reserved while an order for it is open, and stays taken once
+// that order is paid until the sale completes.
+const resaleOpenOrderStatuses = `'` + model.OrderStatusPending + `', '` + model.OrderStatusAwaitingPayment + `'`
+const resaleTakenOrderStatuses = resaleOpenOrderStatuses + `, '` + model.OrderStatusPaid + `'`
+
+const resalePolicyColumns = `event_id, enabled, max_price_percent, close_minutes_before_start, created_at, updated_at`
+
+func scanResalePolicy(row interface{ Scan(...interface{}) error }) (*model.ResalePolicy, error) {
+	p := &model.ResalePolicy{}
+	err := row.Scan(&p.EventID, &p.Enabled, &p.MaxPricePercent, &p.CloseMinutesBeforeStart, &p.CreatedAt, &p.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Listing reads join the ticket, its type, seat and event for display.
+const resaleListingColumns = `l.id, l.ticket_id, l.event_id, t.ticket_type_id, l.seller_user_id, l.price, l.face_value,
+	l.fee_amount, l.payout_amount, l.currency, l.status, l.withdraw_reason, l.status = 'active' AND o.id IS NOT NULL,
+	l.order_id, l.buyer_user_id, l.new_ticket_id, l.payout_status, l.payout_reference, l.sold_at, l.withdrawn_at,
+	l.paid_out_at, l.created_at, l.updated_at, tt.name, e.title,
+	t.seat_id, COALESCE(sec.name, ''), COALESCE(s.row_label, ''), COALESCE(s.number, '')`
+
+const resaleListingFrom = `resale_listings l
+	JOIN tickets t ON t.id = l.ticket_id
+	JOIN ticket_types tt ON tt.id = t.ticket_type_id
+	JOIN events e ON e.id = l.event_id
+	LEFT JOIN orders o ON o.id = l.order_id AND o.status IN (` + resaleTakenOrderStatuses + `)
+	LEFT JOIN seats s ON s.id = t.seat_id
+	LEFT JOIN seat_sections sec ON sec.id = s.section_id`
+
+func scanResaleListing(row interface{ Scan(...interface{}) error }) (*model.ResaleListing, error) {
+	l := &model.ResaleListing{}
+	var orderID, buyerID, newTicketID, seatID uuid.NullUUID
+	var soldAt, withdrawnAt, paidOutAt sql.NullTime
+	var seat model.OrderSeat
+	err := row.Scan(&l.ID, &l.TicketID, &l.EventID, &l.TicketTypeID, &l.SellerUserID, &l.Price, &l.FaceValue,
+		&l.FeeAmount, &l.PayoutAmount, &l.Currency, &l.Status, &l.WithdrawReason, &l.Reserved,
+		&orderID, &buyerID, &newTicketID, &l.PayoutStatus, &l.PayoutReference, &soldAt, &withdrawnAt,
+		&paidOutAt, &l.CreatedAt, &l.UpdatedAt, &l.TicketTypeName, &l.EventTitle,
+		&seatID, &seat.Section, &seat.Row, &seat.Number)
+	if err != nil {
+		return nil, err
+	}
+	if orderID.Valid {
+		l.OrderID = &orderID.UUID
+	}
+	if buyerID.Valid {
+		l.BuyerUserID = &buyerID.UUID
+	}
+	if newTicketID.Valid {
+		l.NewTicketID = &newTicketID.UUID
+	}
+	if soldAt.Valid {
+		l.SoldAt = &soldAt.Time
+	}
+	if withdrawnAt.Valid {
+		l.WithdrawnAt = &withdrawnAt.Time
+	}
+	if paidOutAt.Valid {
+		l.PaidOutAt = &paidOutAt.Time
+	}
+	if seatID.Valid {
+		seat.SeatID = seatID.UUID
+		l.Seat = &seat
+	}
+	return l, nil
+}
+
+func (r *ResaleRepository) SavePolicy(p *model.ResalePolicy) error {
+	query := `
+		INSERT INTO resale_policies (event_id, enabled, max_price_percent, close_minutes_before_start)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (event_id) DO UPDATE SET enabled = $2, max_price_percent = $3, close_minutes_before_start = $4,
+			updated_at = $5
+		RETURNING created_at, updated_at`
+
+	return r.db.QueryRow(query, p.EventID, p.Enabled, p.MaxPricePercent, p.CloseMinutesBeforeStart, time.Now()).
+		Scan(&p.CreatedAt, &p.UpdatedAt)
+}
+
+func (r *ResaleRepository) GetPolicy(eventID uuid.UUID) (*model.ResalePolicy, error) {
+	query := `SELECT ` + resalePolicyColumns + ` FROM resale_policies WHERE event_id = $1`
+	return scanResalePolicy(r.db.QueryRow(query, eventID))
+}
+
+// FaceValue returns what was originally paid for the admission behind a
+// ticket, following transfers and resales back to the first purchase.
+func (r *ResaleRepository) FaceValue(ticketID uuid.UUID) (int64, string, error) {
+	var faceValue int64
+	var currency string
+	err := r.db.QueryRow(`
+		WITH RECURSIVE chain AS (
+			SELECT id, previous_ticket_id, order_item_id FROM tickets WHERE id = $1
+			UNION ALL
+			SELECT t.id, t.previous_ticket_id, t.order_item_id FROM tickets t JOIN chain c ON t.id = c.previous_ticket_id
+		)
+		SELECT oi.unit_price, o.currency
+		FROM chain c
+		JOIN order_items oi ON oi.id = c.order_item_id
+		JOIN orders o ON o.id = oi.order_id
+		WHERE c.previous_ticket_id IS NULL`, ticketID).Scan(&faceValue, &currency)
+	return faceValue, currency, err
+}
+
+// CreateListing inserts an active listing. A ticket may only have one active
+// listing; a second one fails with a unique violation. It returns
+// sql.ErrNoRows when the ticket has a pending transfer.
+func (r *ResaleRepository) CreateListing(l *model.ResaleListing) error {
+	query := `
+		INSERT INTO resale_listings (ticket_id, event_id, seller_user_id, price, face_value, fee_amount, payout_amount,
+			currency, status)
+		SELECT $1, $2, $3, $4, $5, $6, $7, $8, $9
+		WHERE NOT EXISTS (SELECT 1 FROM ticket_transfers WHERE ticket_id = $1 AND status = $10)
+		RETURNING id, created_at, updated_at`
+
+	return r.db.QueryRow(query, l.TicketID, l.EventID, l.SellerUserID, l.Price, l.FaceValue, l.FeeAmount,
+		l.PayoutAmount, l.Currency, l.Status, model.TransferStatusPending).
+		Scan(&l.ID, &l.CreatedAt, &l.UpdatedAt)
+}
+
+func (r *ResaleRepository) GetListing(id uuid.UUID) (*model.ResaleListing, error) {
+	query := `SELECT ` + resaleListingColumns + ` FROM ` + resaleListingFrom + ` WHERE l.id = $1`
+	return scanResaleListing(r.db.QueryRow(query, id))
+}
+
+// ListListings lists listings cheapest first for an event, newest first
+// otherwise, and sold listings by sale time when filtering payouts.
+func (r *ResaleRepository) ListListings(filter *model.ResaleListingFilter) ([]model.ResaleListing, int, error) {
+	conditions := []string{}
+	args := []interface{}{}
+	order := "l.created_at DESC"
+
+	if filter.EventID != nil {
+		args = append(args, *filter.EventID)
+		conditions = append(conditions, fmt.Sprintf("l.event_id = $%d", len(args)))
+		order = "l.price, l.created_at"
+	}
+	if filter.TicketTypeID != nil {
+		args = append(args, *filter.TicketTypeID)
+		conditions = append(conditions, fmt.Sprintf("t.ticket_type_id = $%d", len(args)))
+	}
+	if filter.SellerID != nil {
+		args = append(args, *filter.SellerID)
+		conditions = append(conditions, fmt.Sprintf("l.seller_user_id = $%d", len(args)))
+	}
+	if filter.Status != "" {
+		args = append(args, filter.Status)
+		conditions = append(conditions, fmt.Sprintf("l.status = $%d", len(args)))
+	}
+	if filter.PayoutStatus != "" {
+		args = append(args, filter.PayoutStatus)
+		conditions = append(conditions, fmt.Sprintf("l.payout_status = $%d", len(args)))
+		order = "l.sold_at"
+	}
+
+	where := ""
+	if len(conditions) > 0 {
+		where = " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	var total int
+	countQuery := `SELECT COUNT(*) FROM resale_listings l JOIN tickets t ON t.id = l.ticket_id` + where
+	if err := r.db.QueryRow(countQuery, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	args = append(args, filter.Limit, filter.Offset)
+	query := fmt.Sprintf(`SELECT %s FROM %s%s ORDER BY %s LIMIT $%d OFFSET $%d`,
+		resaleListingColumns, resaleListingFrom, where, order, len(args)-1, len(args))
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	listings := []model.ResaleListing{}
+	for rows.Next() {
+		l, err := scanResaleListing(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		listings = append(listings, *l)
+	}
+	return listings, total, rows.Err()
+}
+
+// WithdrawListing takes an active listing off sale for its seller. It
+// returns false when the listing is no longer active or a buyer's order is
+// holding it.
+func (r *ResaleRepository) WithdrawListing(id, sellerID uuid.UUID) (bool, error) {
+	now := time.Now()
+	result, err := r.db.Exec(`
+		UPDATE resale_listings l SET status = $1, withdraw_reason = $2, withdrawn_at = $3, updated_at = $3
+		WHERE l.id = $4 AND l.seller_user_id = $5 AND l.status = $6
+			AND NOT EXISTS (SELECT 1 FROM orders o WHERE o.id = l.order_id AND o.status IN (`+resaleTakenOrderStatuses+`))`,
+		model.ResaleStatusWithdrawn, model.ResaleWithdrawnBySeller, now, id, sellerID, model.ResaleStatusActive)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected == 1, err
+}
+
+// CreateResaleOrder creates a pending order for one listing and reserves the
+// listing for it. It returns false when the listing is no longer active,
+// another buyer's order is holding it, or the waitlist comes first: the
+// listing is offered to someone else, or others are still waiting for its
+// ticket type. It returns a *PurchaseLimitError when the order would exceed
+// limit.
+func (r *ResaleRepository) CreateResaleOrder(order *model.Order, listingID uuid.UUID, limit *model.HoldLimit) (bool, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	if err := checkHoldLimit(tx, limit); err != nil {
+		return false, err
+	}
+
+	err = tx.QueryRow(`
+		INSERT INTO orders (order_number, user_id, event_id, status, total_amount, currency, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, created_at, updated_at`,
+		order.OrderNumber, order.UserID, order.EventID, order.Status, order.TotalAmount, order.Currency, order.ExpiresAt).
+		Scan(&order.ID, &order.CreatedAt, &order.UpdatedAt)
+	if err != nil {
+		return false, err
+	}
+
+	result, err := tx.Exec(`
+		UPDATE resale_listings l SET order_id = $1, updated_at = $2
+		WHERE l.id = $3 AND l.status = $4
+			AND NOT EXISTS (SELECT 1 FROM orders o WHERE o.id = l.order_id AND o.status IN (`+resaleTakenOrderStatuses+`))
+			AND (EXISTS (SELECT 1 FROM waitlist_entries w WHERE w.resale_listing_id = l.id AND w.status = $6 AND w.user_id = $5)
+				OR NOT EXISTS (SELECT 1 FROM waitlist_entries w WHERE w.user_id <> $5 AND (
+					(w.status = $6 AND w.resale_listing_id = l.id)
+					OR (w.status = $7 AND w.user_id <> l.seller_user_id
+						AND w.ticket_type_id = (SELECT ticket_type_id FROM tickets WHERE id = l.ticket_id)))))`,
+		order.ID, time.Now(), listingID, model.ResaleStatusActive, order.UserID,
+		model.WaitlistStatusOffered, model.WaitlistStatusWaiting)
+	if err != nil {
+		return false, err
+	}
+	if affected, err := result.RowsAffected(); err != nil || affected != 1 {
+		return false, err
+	}
+
+	for i := range order.Items {
+		item := &order.Items[i]
+		item.OrderID = order.ID
+		err = tx.QueryRow(`
+			INSERT INTO order_items (order_id, ticket_type_id, resale_listing_id, quantity, unit_price, subtotal)
+			VALUES ($1, $2, $3, $4, $5, $6)
+			RETURNING id, created_at`,
+			item.OrderID, item.TicketTypeID, item.ResaleListingID, item.Quantity, item.UnitPrice, item.Subtotal).
+			Scan(&item.ID, &item.CreatedAt)
+		if err != nil {
+			return false, err
+		}
+		if err := insertAttendees(tx, item); err != nil {
+			return false, err
+		}
+	}
+
+	return true, tx.Commit()
+}
+
+// CompleteSale marks the listing sold to the order's buyer and replaces the
+// seller's ticket with newTicket in one transaction. It returns false when
+// the listing is no longer reserved by the order or the seller's ticket is
+// no longer valid.
+func (r *ResaleRepository) CompleteSale(listingID, orderID uuid.UUID, newTicket *model.Ticket) (bool, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	var ticketID, sellerID uuid.UUID
+	err = tx.QueryRow(`
+		UPDATE resale_listings SET status = $1, buyer_user_id = $2, sold_at = $3, payout_status = $4, updated_at = $3
+		WHERE id = $5 AND status = $6 AND order_id = $7
+		RETURNING ticket_id, seller_user_id`,
+		model.ResaleStatusSold, newTicket.UserID, now, model.PayoutStatusPending, listingID, model.ResaleStatusActive, orderID).
+		Scan(&ticketID, &sellerID)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	if ok, err := reissueTicket(tx, ticketID, sellerID, newTicket); !ok || err != nil {
+		return false, err
+	}
+
+	if _, err := tx.Exec(`UPDATE resale_listings SET new_ticket_id = $1 WHERE id = $2`, newTicket.ID, listingID); err != nil {
+		return false, err
+	}
+
+	return true, tx.Commit()
+}
+
+// WithdrawStaleListings takes active listings off sale once their ticket is
+// used or otherwise no longer valid, the event is cancelled or over, or
+// resale closes for the event, and cancels buyers' unpaid orders for them.
+// It returns how many listings were withdrawn.
+func (r *ResaleRepository) WithdrawStaleListings(now time.Time) (int, error) {
+	query := `
+		WITH stale AS (
+			SELECT l.id,
+				CASE
+					WHEN e.status = '` + model.EventStatusCancelled + `' THEN '` + model.ResaleWithdrawnCancelled + `'
+					WHEN t.status = '` + model.TicketStatusUsed + `' THEN '` + model.ResaleWithdrawnTicketUsed + `'
+					WHEN t.status <> '` + model.TicketStatusValid + `' THEN '` + model.ResaleWithdrawnTicketGone + `'
+					ELSE '` + model.ResaleWithdrawnClosed + `'
+				END AS reason
+			FROM resale_listings l
+			JOIN tickets t ON t.id = l.ticket_id
+			JOIN events e ON e.id = l.event_id
+			LEFT JOIN resale_policies p ON p.event_id = l.event_id
+			WHERE l.status = $1 AND (
+				e.status IN ('` + model.EventStatusCancelled + `', '` + model.EventStatusFinished + `')
+				OR t.status <> '` + model.TicketStatusValid + `'
+				OR p.enabled IS NOT TRUE
+				OR e.start_time - make_interval(mins => COALESCE(p.close_minutes_before_start, 0)) <= $2
+			)
+		), withdrawn AS (
+			UPDATE resale_listings l SET status = $3, withdraw_reason = stale.reason, withdrawn_at = $2, updated_at = $2
+			FROM stale WHERE l.id = stale.id
+			RETURNING l.id, l.order_id
+		), cancelled AS (
+			UPDATE orders SET status = $4, updated_at = $2
+			WHERE id IN (SELECT order_id FROM withdrawn) AND status IN (` + resaleOpenOrderStatuses + `)
+		)
+		SELECT COUNT(*) FROM withdrawn`
+
+	var withdrawn int
+	err := r.db.QueryRow(query, model.ResaleStatusActive, now, model.ResaleStatusWithdrawn, model.OrderStatusCancelled).
+		Scan(&withdrawn)
+	return withdrawn, err
+}
+
+// MarkPayoutPaid records that a sold listing's payout reached the seller. It
+// returns false when the payout is not pending.
+func (r *ResaleRepository) MarkPayoutPaid(id uuid.UUID, reference string) (bool, error) {
+	now := time.Now()
+	result, err := r.db.Exec(`
+		UPDATE resale_listings SET payout_status = $1, payout_reference = $2, paid_out_at = $3, updated_at = $3
+		WHERE id = $4 AND status = $5 AND payout_status = $6`,
+		model.PayoutStatusPaid, reference, now, id, model.ResaleStatusSold, model.PayoutStatusPending)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected == 1, err
+}
@@ -0,0 +1,301 @@
+package repository
+
+import (
+	"database/sql"
+	"e-ticketing/internal/model"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+type SeatRepository struct {
+	db *sql.DB
+}
+
+func NewSeatRepository(db *sql.DB) *SeatRepository {
+	return &SeatRepository{db: db}
+}
+
+const seatColumns = `id, event_id, section_id, row_label, number, x, y, ticket_type_id, status, hold_id`
+
+func scanSeat(row interface{ Scan(...interface{}) error }) (*model.Seat, error) {
+	s := &model.Seat{}
+	var holdID uuid.NullUUID
+	err := row.Scan(&s.ID, &s.EventID, &s.SectionID, &s.Row, &s.Number, &s.X, &s.Y, &s.TicketTypeID, &s.Status, &holdID)
+	if err != nil {
+		return nil, err
+	}
+	if holdID.Valid {
+		s.HoldID = &holdID.UUID
+	}
+	return s, nil
+}
+
+// ReplaceSeatMap swaps the event's seat map for a new one and sets the quota
+// of every tier to its number of sellable seats. It returns false when a
+// tier, or a previously seated ticket type, already has tickets held or
+// sold, because replacing seats then would orphan them, and a *CapacityError
+// when the new quotas do not fit in the venue.
+func (r *SeatRepository) ReplaceSeatMap(eventID uuid.UUID, sections []model.SeatSection, quotas map[uuid.UUID]int) (bool, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	tiers := make([]uuid.UUID, 0, len(quotas))
+	seated := 0
+	for id, quota := range quotas {
+		tiers = append(tiers, id)
+		seated += quota
+	}
+
+	// Lock the event before its ticket types, in the same order as tier changes
+	if err := checkVenueCapacity(tx, eventID, tiers, seated); err != nil {
+		return false, err
+	}
+
+	// Lock the event's ticket types so no hold slips in during the swap
+	var busy bool
+	err = tx.QueryRow(`
+		SELECT COALESCE(BOOL_OR(available <> quota), FALSE) FROM (
+			SELECT available, quota FROM ticket_types
+			WHERE event_id = $1 AND (reserved_seating OR id = ANY($2))
+			FOR UPDATE
+		) t`, eventID, pq.Array(tiers)).Scan(&busy)
+	if err != nil {
+		return false, err
+	}
+	if busy {
+		return false, nil
+	}
+
+	if _, err := tx.Exec(`DELETE FROM seat_sections WHERE event_id = $1`, eventID); err != nil {
+		return false, err
+	}
+
+	for i := range sections {
+		section := &sections[i]
+		if _, err := tx.Exec(`INSERT INTO seat_sections (id, event_id, name, sort_order) VALUES ($1, $2, $3, $4)`,
+			section.ID, eventID, section.Name, section.SortOrder); err != nil {
+			return false, err
+		}
+	}
+
+	// Stadium maps run to tens of thousands of seats, so stream them with COPY
+	stmt, err := tx.Prepare(pq.CopyIn("seats", "id", "event_id", "section_id", "row_label", "number", "x", "y", "ticket_type_id", "status"))
+	if err != nil {
+		return false, err
+	}
+	for _, section := range sections {
+		for _, seat := range section.Seats {
+			if _, err := stmt.Exec(seat.ID, eventID, section.ID, seat.Row, seat.Number, seat.X, seat.Y, seat.TicketTypeID, seat.Status); err != nil {
+				stmt.Close()
+				return false, err
+			}
+		}
+	}
+	if _, err := stmt.Exec(); err != nil {
+		stmt.Close()
+		return false, err
+	}
+	if err := stmt.Close(); err != nil {
+		return false, err
+	}
+
+	now := time.Now()
+	if _, err := tx.Exec(`
+		UPDATE ticket_types SET reserved_seating = FALSE, updated_at = $1
+		WHERE event_id = $2 AND reserved_seating AND NOT (id = ANY($3))`,
+		now, eventID, pq.Array(tiers)); err != nil {
+		return false, err
+	}
+	for id, quota := range quotas {
+		if _, err := tx.Exec(`
+			UPDATE ticket_types SET quota = $1, available = $1, reserved_seating = TRUE,
+				min_per_order = LEAST(min_per_order, $1), max_per_order = LEAST(max_per_order, $1),
+				updated_at = $2
+			WHERE id = $3`, quota, now, id); err != nil {
+			return false, err
+		}
+	}
+
+	// One notification for the whole map instead of one per seat
+	if _, err := tx.Exec(`SELECT pg_notify($1, json_build_object('event_id', $2::uuid, 'type', $3::text)::text)`,
+		AvailabilityChannel, eventID, model.AvailabilitySeatMap); err != nil {
+		return false, err
+	}
+
+	return true, tx.Commit()
+}
+
+// GetSeatMap returns the event's sections in display order with their seats.
+func (r *SeatRepository) GetSeatMap(eventID uuid.UUID) ([]model.SeatSection, error) {
+	rows, err := r.db.Query(`SELECT id, event_id, name, sort_order FROM seat_sections WHERE event_id = $1 ORDER BY sort_order, name`, eventID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sections := []model.SeatSection{}
+	index := map[uuid.UUID]int{}
+	for rows.Next() {
+		var section model.SeatSection
+		if err := rows.Scan(&section.ID, &section.EventID, &section.Name, &section.SortOrder); err != nil {
+			return nil, err
+		}
+		section.Seats = []model.Seat{}
+		index[section.ID] = len(sections)
+		sections = append(sections, section)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	seatRows, err := r.db.Query(`SELECT `+seatColumns+` FROM seats WHERE event_id = $1 ORDER BY row_label, length(number), number`, eventID)
+	if err != nil {
+		return nil, err
+	}
+	defer seatRows.Close()
+
+	for seatRows.Next() {
+		seat, err := scanSeat(seatRows)
+		if err != nil {
+			return nil, err
+		}
+		if i, ok := index[seat.SectionID]; ok {
+			sections[i].Seats = append(sections[i].Seats, *seat)
+		}
+	}
+	return sections, seatRows.Err()
+}
+
+func (r *SeatRepository) GetSeatsByIDs(ids []uuid.UUID) ([]model.Seat, error) {
+	rows, err := r.db.Query(`SELECT `+seatColumns+` FROM seats WHERE id = ANY($1)`, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	seats := []model.Seat{}
+	for rows.Next() {
+		seat, err := scanSeat(rows)
+		if err != nil {
+			return nil, err
+		}
+		seats = append(seats, *seat)
+	}
+	return seats, rows.Err()
+}
+
+// CreateSeatHolds claims the seats and places one inventory hold per tier in
+// a single transaction. Claiming is a conditional update on the seat rows,
+// so of two buyers picking the same seat only the first succeeds; the other
+// gets false and nothing is held. It returns a *PurchaseLimitError when the
+// seats would exceed limit.
+func (r *SeatRepository) CreateSeatHolds(userID uuid.UUID, seatsByTier map[uuid.UUID][]uuid.UUID, expiresAt time.Time, limit *model.HoldLimit) ([]model.InventoryHold, bool, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, false, err
+	}
+	defer tx.Rollback()
+
+	if err := checkHoldLimit(tx, limit); err != nil {
+		return nil, false, err
+	}
+
+	// A fixed lock order keeps overlapping requests from deadlocking
+	tierIDs := make([]uuid.UUID, 0, len(seatsByTier))
+	for id := range seatsByTier {
+		tierIDs = append(tierIDs, id)
+	}
+	sort.Slice(tierIDs, func(i, j int) bool { return tierIDs[i].String() < tierIDs[j].String() })
+
+	now := time.Now()
+	holds := []model.InventoryHold{}
+	for _, tierID := range tierIDs {
+		seatIDs := seatsByTier[tierID]
+		if ok, err := claimSeats(tx, tierID, seatIDs, now); err != nil || !ok {
+			return nil, false, err
+		}
+
+		result, err := tx.Exec(`
+			UPDATE ticket_types SET available = available - $1, updated_at = $2
+			WHERE id = $3 AND `+publicStock+` >= $1`,
+			len(seatIDs), now, tierID)
+		if err != nil {
+			return nil, false, err
+		}
+		if affected, err := result.RowsAffected(); err != nil || affected == 0 {
+			return nil, false, err
+		}
+
+		hold := model.InventoryHold{
+			UserID:       userID,
+			TicketTypeID: tierID,
+			Quantity:     len(seatIDs),
+			Status:       model.HoldStatusActive,
+			ExpiresAt:    expiresAt,
+		}
+		if err := insertHold(tx, &hold); err != nil {
+			return nil, false, err
+		}
+		if err := attachSeats(tx, hold.ID, seatIDs); err != nil {
+			return nil, false, err
+		}
+		holds = append(holds, hold)
+	}
+
+	return holds, true, tx.Commit()
+}
+
+// claimSeats marks the seats of one tier held. It returns false when any of
+// them is no longer available.
+func claimSeats(tx *sql.Tx, tierID uuid.UUID, seatIDs []uuid.UUID, now time.Time) (bool, error) {
+	result, err := tx.Exec(`
+		UPDATE seats SET status = $1, updated_at = $2
+		WHERE id IN (SELECT id FROM seats WHERE id = ANY($3) ORDER BY id FOR UPDATE)
+			AND ticket_type_id = $4 AND status = $5`,
+		model.SeatStatusHeld, now, pq.Array(seatIDs), tierID, model.SeatStatusAvailable)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected == int64(len(seatIDs)), err
+}
+
+// attachSeats links claimed seats to their hold.
+func attachSeats(tx *sql.Tx, holdID uuid.UUID, seatIDs []uuid.UUID) error {
+	_, err := tx.Exec(`UPDATE seats SET hold_id = $1 WHERE id = ANY($2)`, holdID, pq.Array(seatIDs))
+	return err
+}
+
+// GetSeatsByHolds returns the seats reserved by each hold, in seat order.
+func (r *SeatRepository) GetSeatsByHolds(holdIDs []uuid.UUID) (map[uuid.UUID][]model.OrderSeat, error) {
+	return seatsByHolds(r.db, holdIDs)
+}
+
+func seatsByHolds(db *sql.DB, holdIDs []uuid.UUID) (map[uuid.UUID][]model.OrderSeat, error) {
+	rows, err := db.Query(`
+		SELECT s.hold_id, s.id, sec.name, s.row_label, s.number
+		FROM seats s JOIN seat_sections sec ON sec.id = s.section_id
+		WHERE s.hold_id = ANY($1)
+		ORDER BY sec.sort_order, s.row_label, length(s.number), s.number`, pq.Array(holdIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	seats := map[uuid.UUID][]model.OrderSeat{}
+	for rows.Next() {
+		var holdID uuid.UUID
+		var seat model.OrderSeat
+		if err := rows.Scan(&holdID, &seat.SeatID, &seat.Section, &seat.Row, &seat.Number); err != nil {
+			return nil, err
+		}
+		seats[holdID] = append(seats[holdID], seat)
+	}
+	return seats, rows.Err()
+}
@@ -0,0 +1,238 @@
+package repository
+
+import (
+	"database/sql"
+	"e-ticketing/internal/model"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+type TicketRepository struct {
+	db *sql.DB
+}
+
+func NewTicketRepository(db *sql.DB) *TicketRepository {
+	return &TicketRepository{db: db}
+}
+
+// Ticket reads join the ticket type and event so a ticket can be shown
+// without further lookups.
+const ticketColumns = `t.id, t.code, t.order_id, t.order_item_id, t.sequence, t.event_id, t.ticket_type_id, t.user_id,
+	t.holder_name, t.status, t.used_at, t.created_at, t.updated_at, tt.name, e.title, e.start_time,
+	t.seat_id, COALESCE(sec.name, ''), COALESCE(s.row_label, ''), COALESCE(s.number, ''),
+	a.id, COALESCE(a.name, ''), COALESCE(a.id_type, ''), COALESCE(a.id_number_masked, ''), COALESCE(a.email, ''),
+	COALESCE(a.phone, ''), a.birth_date, t.previous_ticket_id`
+
+const ticketFrom = `tickets t
+	JOIN ticket_types tt ON tt.id = t.ticket_type_id
+	JOIN events e ON e.id = t.event_id
+	LEFT JOIN seats s ON s.id = t.seat_id
+	LEFT JOIN seat_sections sec ON sec.id = s.section_id
+	LEFT JOIN order_attendees a ON a.order_item_id = t.order_item_id AND a.sequence = t.sequence AND t.status <> 'transferred'`
+
+// ticketInTransit matches a ticket t that is on its way to someone else
+// through an active resale listing or a pending transfer.
+const ticketInTransit = `(EXISTS (SELECT 1 FROM resale_listings l WHERE l.ticket_id = t.id AND l.status = '` + model.ResaleStatusActive + `')
+	OR EXISTS (SELECT 1 FROM ticket_transfers tr WHERE tr.ticket_id = t.id AND tr.status = '` + model.TransferStatusPending + `'))`
+
+func scanTicket(row interface{ Scan(...interface{}) error }) (*model.Ticket, error) {
+	t := &model.Ticket{}
+	var usedAt, startTime sql.NullTime
+	var seatID uuid.NullUUID
+	var seat model.OrderSeat
+	var attendeeID uuid.NullUUID
+	var attendee model.Attendee
+	var birthDate sql.NullTime
+	var previousID uuid.NullUUID
+	err := row.Scan(&t.ID, &t.Code, &t.OrderID, &t.OrderItemID, &t.Sequence, &t.EventID, &t.TicketTypeID, &t.UserID,
+		&t.HolderName, &t.Status, &usedAt, &t.CreatedAt, &t.UpdatedAt, &t.TicketTypeName, &t.EventTitle, &startTime,
+		&seatID, &seat.Section, &seat.Row, &seat.Number,
+		&attendeeID, &attendee.Name, &attendee.IDType, &attendee.IDNumber, &attendee.Email,
+		&attendee.Phone, &birthDate, &previousID)
+	if err != nil {
+		return nil, err
+	}
+	if attendeeID.Valid {
+		attendee.Sequence = t.Sequence
+		if birthDate.Valid {
+			attendee.BirthDate = &birthDate.Time
+		}
+		t.Attendee = &attendee
+	}
+	if previousID.Valid {
+		t.PreviousTicketID = &previousID.UUID
+	}
+	if seatID.Valid {
+		t.SeatID = &seatID.UUID
+		seat.SeatID = seatID.UUID
+		t.Seat = &seat
+	}
+	if usedAt.Valid {
+		t.UsedAt = &usedAt.Time
+	}
+	if startTime.Valid {
+		t.EventStartTime = &startTime.Time
+	}
+	return t, nil
+}
+
+// CreateTickets inserts the tickets in one transaction and marks their seats
+// sold. Tickets whose (order_item_id, sequence) already exist are skipped,
+// so issuing an order again only fills in what is missing. It returns the
+// number inserted.
+func (r *TicketRepository) CreateTickets(tickets []model.Ticket) (int, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	inserted := 0
+	seatIDs := []uuid.UUID{}
+	for i := range tickets {
+		t := &tickets[i]
+		if t.SeatID != nil {
+			seatIDs = append(seatIDs, *t.SeatID)
+		}
+
+		err := tx.QueryRow(`
+			INSERT INTO tickets (code, order_id, order_item_id, sequence, event_id, ticket_type_id, user_id, holder_name, status, seat_id)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+			ON CONFLICT (order_item_id, sequence) WHERE previous_ticket_id IS NULL DO NOTHING
+			RETURNING id, created_at, updated_at`,
+			t.Code, t.OrderID, t.OrderItemID, t.Sequence, t.EventID, t.TicketTypeID, t.UserID, t.HolderName, t.Status, t.SeatID).
+			Scan(&t.ID, &t.CreatedAt, &t.UpdatedAt)
+		if err == sql.ErrNoRows {
+			continue
+		}
+		if err != nil {
+			return 0, err
+		}
+		inserted++
+	}
+
+	if len(seatIDs) > 0 {
+		if _, err := tx.Exec(`UPDATE seats SET status = $1, updated_at = $2 WHERE id = ANY($3)`,
+			model.SeatStatusSold, time.Now(), pq.Array(seatIDs)); err != nil {
+			return 0, err
+		}
+	}
+
+	return inserted, tx.Commit()
+}
+
+// reissueTicket voids a valid ticket of fromUserID by marking it transferred
+// and inserts t in its place for the same event, type and seat. t keeps the
+// old ticket's order, item and sequence unless it sets its own. It returns
+// false when the old ticket is no longer valid in fromUserID's hands.
+func reissueTicket(tx *sql.Tx, oldTicketID, fromUserID uuid.UUID, t *model.Ticket) (bool, error) {
+	now := time.Now()
+	result, err := tx.Exec(`
+		UPDATE tickets SET status = $1, updated_at = $2
+		WHERE id = $3 AND user_id = $4 AND status = $5`,
+		model.TicketStatusTransferred, now, oldTicketID, fromUserID, model.TicketStatusValid)
+	if err != nil {
+		return false, err
+	}
+	if affected, err := result.RowsAffected(); err != nil || affected != 1 {
+		return false, err
+	}
+
+	var orderID, orderItemID, sequence interface{}
+	if t.OrderID != uuid.Nil {
+		orderID = t.OrderID
+	}
+	if t.OrderItemID != uuid.Nil {
+		orderItemID = t.OrderItemID
+	}
+	if t.Sequence > 0 {
+		sequence = t.Sequence
+	}
+
+	var seatID uuid.NullUUID
+	err = tx.QueryRow(`
+		INSERT INTO tickets (code, order_id, order_item_id, sequence, event_id, ticket_type_id, user_id, holder_name,
+			status, seat_id, previous_ticket_id)
+		SELECT $1, COALESCE($2::uuid, order_id), COALESCE($3::uuid, order_item_id), COALESCE($4::int, sequence),
+			event_id, ticket_type_id, $5, $6, $7, seat_id, id
+		FROM tickets WHERE id = $8
+		RETURNING id, order_id, order_item_id, sequence, event_id, ticket_type_id, status, seat_id, created_at, updated_at`,
+		t.Code, orderID, orderItemID, sequence, t.UserID, t.HolderName, model.TicketStatusValid, oldTicketID).
+		Scan(&t.ID, &t.OrderID, &t.OrderItemID, &t.Sequence, &t.EventID, &t.TicketTypeID, &t.Status, &seatID,
+			&t.CreatedAt, &t.UpdatedAt)
+	if err != nil {
+		return false, err
+	}
+	if seatID.Valid {
+		t.SeatID = &seatID.UUID
+	}
+	t.PreviousTicketID = &oldTicketID
+	return true, nil
+}
+
+func (r *TicketRepository) GetTicketByID(id uuid.UUID) (*model.Ticket, error) {
+	query := `SELECT ` + ticketColumns + ` FROM ` + ticketFrom + ` WHERE t.id = $1`
+	return scanTicket(r.db.QueryRow(query, id))
+}
+
+func (r *TicketRepository) GetTicketByCode(code string) (*model.Ticket, error) {
+	query := `SELECT ` + ticketColumns + ` FROM ` + ticketFrom + ` WHERE t.code = $1`
+	return scanTicket(r.db.QueryRow(query, code))
+}
+
+func (r *TicketRepository) CountTicketsByOrder(orderID uuid.UUID) (int, error) {
+	var count int
+	err := r.db.QueryRow(`SELECT COUNT(*) FROM tickets WHERE order_id = $1`, orderID).Scan(&count)
+	return count, err
+}
+
+func (r *TicketRepository) ListTickets(filter *model.TicketFilter) ([]model.Ticket, int, error) {
+	conditions := []string{"1 = 1"}
+	args := []interface{}{}
+
+	addCondition := func(clause string, value interface{}) {
+		args = append(args, value)
+		conditions = append(conditions, fmt.Sprintf(clause, len(args)))
+	}
+
+	if filter.UserID != nil {
+		addCondition("t.user_id = $%d", *filter.UserID)
+	}
+	if filter.EventID != nil {
+		addCondition("t.event_id = $%d", *filter.EventID)
+	}
+	if filter.Status != "" {
+		addCondition("t.status = $%d", filter.Status)
+	}
+
+	where := strings.Join(conditions, " AND ")
+
+	var total int
+	if err := r.db.QueryRow(`SELECT COUNT(*) FROM tickets t WHERE `+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	args = append(args, filter.Limit, filter.Offset)
+	query := fmt.Sprintf(`SELECT %s FROM %s WHERE %s ORDER BY e.start_time, t.created_at, t.sequence LIMIT $%d OFFSET $%d`,
+		ticketColumns, ticketFrom, where, len(args)-1, len(args))
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	tickets := []model.Ticket{}
+	for rows.Next() {
+		t, err := scanTicket(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		tickets = append(tickets, *t)
+	}
+	return tickets, total, rows.Err()
+}
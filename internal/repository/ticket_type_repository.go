@@ -0,0 +1,166 @@
+package repository
+
+import (
+	"database/sql"
+	"e-ticketing/internal/model"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+type TicketTypeRepository struct {
+	db *sql.DB
+}
+
+func NewTicketTypeRepository(db *sql.DB) *TicketTypeRepository {
+	return &TicketTypeRepository{db: db}
+}
+
+const ticketTypeColumns = `id, event_id, name, COALESCE(description, ''), price, currency, quota, available,
+	min_per_order, max_per_order, sales_start, sales_end, visibility, sort_order, reserved_seating, created_at, updated_at`
+
+func scanTicketType(row interface{ Scan(...interface{}) error }) (*model.TicketType, error) {
+	t := &model.TicketType{}
+	err := row.Scan(&t.ID, &t.EventID, &t.Name, &t.Description, &t.Price, &t.Currency, &t.Quota, &t.Available,
+		&t.MinPerOrder, &t.MaxPerOrder, &t.SalesStart, &t.SalesEnd, &t.Visibility, &t.SortOrder,
+		&t.ReservedSeating, &t.CreatedAt, &t.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// CapacityError is returned when an event's total quota would exceed the
+// capacity of its venue.
+type CapacityError struct {
+	Total    int
+	Capacity int
+}
+
+func (e *CapacityError) Error() string {
+	return fmt.Sprintf("total quota %d exceeds venue capacity %d", e.Total, e.Capacity)
+}
+
+// checkVenueCapacity locks the event row, so changes to its ticket types and
+// venue are checked one at a time, and returns a *CapacityError when the
+// quotas of its ticket types other than exclude, plus extra, do not fit in
+// its venue. Events without a venue have no limit.
+func checkVenueCapacity(tx *sql.Tx, eventID uuid.UUID, exclude []uuid.UUID, extra int) error {
+	var capacity sql.NullInt64
+	err := tx.QueryRow(`
+		SELECT v.capacity FROM events e LEFT JOIN venues v ON v.id = e.venue_id
+		WHERE e.id = $1 FOR UPDATE OF e`, eventID).Scan(&capacity)
+	if err != nil || !capacity.Valid {
+		return err
+	}
+
+	var total int
+	err = tx.QueryRow(`
+		SELECT COALESCE(SUM(quota), 0) FROM ticket_types WHERE event_id = $1 AND NOT (id = ANY($2))`,
+		eventID, pq.Array(exclude)).Scan(&total)
+	if err != nil {
+		return err
+	}
+	if total+extra > int(capacity.Int64) {
+		return &CapacityError{Total: total + extra, Capacity: int(capacity.Int64)}
+	}
+	return nil
+}
+
+// CreateTicketType inserts a ticket type with its whole quota available. It
+// returns a *CapacityError when the quota does not fit in the venue.
+func (r *TicketTypeRepository) CreateTicketType(t *model.TicketType) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := checkVenueCapacity(tx, t.EventID, nil, t.Quota); err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO ticket_types (event_id, name, description, price, currency, quota, available, min_per_order, max_per_order,
+			sales_start, sales_end, visibility, sort_order)
+		VALUES ($1, $2, $3, $4, $5, $6, $6, $7, $8, $9, $10, $11, $12)
+		RETURNING id, available, created_at, updated_at`
+
+	err = tx.QueryRow(query, t.EventID, t.Name, t.Description, t.Price, t.Currency, t.Quota, t.MinPerOrder, t.MaxPerOrder,
+		t.SalesStart, t.SalesEnd, t.Visibility, t.SortOrder).
+		Scan(&t.ID, &t.Available, &t.CreatedAt, &t.UpdatedAt)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *TicketTypeRepository) GetTicketTypeByID(id uuid.UUID) (*model.TicketType, error) {
+	query := `SELECT ` + ticketTypeColumns + ` FROM ticket_types WHERE id = $1`
+	return scanTicketType(r.db.QueryRow(query, id))
+}
+
+func (r *TicketTypeRepository) GetTicketTypesByEvent(eventID uuid.UUID, includeHidden bool) ([]model.TicketType, error) {
+	query := `SELECT ` + ticketTypeColumns + ` FROM ticket_types WHERE event_id = $1`
+	args := []interface{}{eventID}
+	if !includeHidden {
+		query += ` AND visibility = $2`
+		args = append(args, model.TicketVisibilityPublic)
+	}
+	query += ` ORDER BY sort_order, price`
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ticketTypes := []model.TicketType{}
+	for rows.Next() {
+		t, err := scanTicketType(rows)
+		if err != nil {
+			return nil, err
+		}
+		ticketTypes = append(ticketTypes, *t)
+	}
+	return ticketTypes, rows.Err()
+}
+
+// UpdateTicketType shifts available stock by the quota change. It returns
+// sql.ErrNoRows when the new quota is below what is already sold or held,
+// and a *CapacityError when it does not fit in the venue.
+func (r *TicketTypeRepository) UpdateTicketType(t *model.TicketType) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := checkVenueCapacity(tx, t.EventID, []uuid.UUID{t.ID}, t.Quota); err != nil {
+		return err
+	}
+
+	query := `
+		UPDATE ticket_types SET name = $1, description = $2, price = $3, currency = $4,
+			available = available + ($5 - quota), quota = $5,
+			min_per_order = $6, max_per_order = $7, sales_start = $8, sales_end = $9, visibility = $10,
+			sort_order = $11, updated_at = $12
+		WHERE id = $13 AND available + ($5 - quota) >= 0
+		RETURNING available, updated_at`
+
+	err = tx.QueryRow(query, t.Name, t.Description, t.Price, t.Currency, t.Quota,
+		t.MinPerOrder, t.MaxPerOrder, t.SalesStart, t.SalesEnd, t.Visibility,
+		t.SortOrder, time.Now(), t.ID).
+		Scan(&t.Available, &t.UpdatedAt)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *TicketTypeRepository) DeleteTicketType(id uuid.UUID) error {
+	_, err := r.db.Exec(`DELETE FROM ticket_types WHERE id = $1`, id)
+	return err
+}
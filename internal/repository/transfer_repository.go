@@ -0,0 +1,281 @@
+package repository
+
+import (
+	"database/sql"
+	"e-ticketing/internal/model"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type TransferRepository struct {
+	db *sql.DB
+}
+
+func NewTransferRepository(db *sql.DB) *TransferRepository {
+	return &TransferRepository{db: db}
+}
+
+const transferPolicyColumns = `event_id, enabled, opens_at, close_minutes_before_start, max_transfers_per_ticket,
+	created_at, updated_at`
+
+func scanTransferPolicy(row interface{ Scan(...interface{}) error }) (*model.TransferPolicy, error) {
+	p := &model.TransferPolicy{}
+	var opensAt sql.NullTime
+	err := row.Scan(&p.EventID, &p.Enabled, &opensAt, &p.CloseMinutesBeforeStart, &p.MaxTransfersPerTicket,
+		&p.CreatedAt, &p.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if opensAt.Valid {
+		p.OpensAt = &opensAt.Time
+	}
+	return p, nil
+}
+
+// Transfer reads join the sender, ticket type and event for display.
+const transferColumns = `tr.id, tr.ticket_id, tr.event_id, tr.from_user_id, u.name, tr.to_email, tr.to_phone, tr.to_user_id,
+	tr.message, tr.status, tr.new_ticket_id, tr.expires_at, tr.responded_at, tr.created_at, tr.updated_at, tt.name, e.title`
+
+const transferFrom = `ticket_transfers tr
+	JOIN users u ON u.id = tr.from_user_id
+	JOIN tickets t ON t.id = tr.ticket_id
+	JOIN ticket_types tt ON tt.id = t.ticket_type_id
+	JOIN events e ON e.id = tr.event_id`
+
+func scanTransfer(row interface{ Scan(...interface{}) error }) (*model.TicketTransfer, error) {
+	tr := &model.TicketTransfer{}
+	var toUserID, newTicketID uuid.NullUUID
+	var respondedAt sql.NullTime
+	err := row.Scan(&tr.ID, &tr.TicketID, &tr.EventID, &tr.FromUserID, &tr.FromName, &tr.ToEmail, &tr.ToPhone, &toUserID,
+		&tr.Message, &tr.Status, &newTicketID, &tr.ExpiresAt, &respondedAt, &tr.CreatedAt, &tr.UpdatedAt,
+		&tr.TicketTypeName, &tr.EventTitle)
+	if err != nil {
+		return nil, err
+	}
+	if toUserID.Valid {
+		tr.ToUserID = &toUserID.UUID
+	}
+	if newTicketID.Valid {
+		tr.NewTicketID = &newTicketID.UUID
+	}
+	if respondedAt.Valid {
+		tr.RespondedAt = &respondedAt.Time
+	}
+	return tr, nil
+}
+
+func (r *TransferRepository) SavePolicy(p *model.TransferPolicy) error {
+	query := `
+		INSERT INTO transfer_policies (event_id, enabled, opens_at, close_minutes_before_start, max_transfers_per_ticket)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (event_id) DO UPDATE SET enabled = $2, opens_at = $3, close_minutes_before_start = $4,
+			max_transfers_per_ticket = $5, updated_at = $6
+		RETURNING created_at, updated_at`
+
+	return r.db.QueryRow(query, p.EventID, p.Enabled, p.OpensAt, p.CloseMinutesBeforeStart, p.MaxTransfersPerTicket, time.Now()).
+		Scan(&p.CreatedAt, &p.UpdatedAt)
+}
+
+func (r *TransferRepository) GetPolicy(eventID uuid.UUID) (*model.TransferPolicy, error) {
+	query := `SELECT ` + transferPolicyColumns + ` FROM transfer_policies WHERE event_id = $1`
+	return scanTransferPolicy(r.db.QueryRow(query, eventID))
+}
+
+// CreateTransfer inserts a pending transfer. A ticket may only have one
+// pending transfer; a second one fails with a unique violation. It returns
+// sql.ErrNoRows when the ticket is listed for resale.
+func (r *TransferRepository) CreateTransfer(tr *model.TicketTransfer) error {
+	query := `
+		INSERT INTO ticket_transfers (ticket_id, event_id, from_user_id, to_email, to_phone, message, status, expires_at)
+		SELECT $1, $2, $3, $4, $5, $6, $7, $8
+		WHERE NOT EXISTS (SELECT 1 FROM resale_listings WHERE ticket_id = $1 AND status = $9)
+		RETURNING id, created_at, updated_at`
+
+	return r.db.QueryRow(query, tr.TicketID, tr.EventID, tr.FromUserID, tr.ToEmail, tr.ToPhone, tr.Message,
+		tr.Status, tr.ExpiresAt, model.ResaleStatusActive).
+		Scan(&tr.ID, &tr.CreatedAt, &tr.UpdatedAt)
+}
+
+func (r *TransferRepository) GetTransfer(id uuid.UUID) (*model.TicketTransfer, error) {
+	query := `SELECT ` + transferColumns + ` FROM ` + transferFrom + ` WHERE tr.id = $1`
+	return scanTransfer(r.db.QueryRow(query, id))
+}
+
+func (r *TransferRepository) ListTransfers(filter *model.TransferFilter) ([]model.TicketTransfer, int, error) {
+	args := []interface{}{filter.UserID, filter.Email, filter.Phone, model.TransferStatusPending}
+	outgoing := `tr.from_user_id = $1`
+	incoming := `(tr.to_user_id = $1 OR (tr.status = $4 AND ((lower(tr.to_email) = lower($2) AND $2 <> '') OR (tr.to_phone = $3 AND $3 <> ''))))`
+
+	conditions := []string{}
+	switch filter.Direction {
+	case "outgoing":
+		conditions = append(conditions, outgoing)
+	case "incoming":
+		conditions = append(conditions, incoming)
+	default:
+		conditions = append(conditions, "("+outgoing+" OR "+incoming+")")
+	}
+	if filter.Status != "" {
+		args = append(args, filter.Status)
+		conditions = append(conditions, fmt.Sprintf("tr.status = $%d", len(args)))
+	}
+	where := strings.Join(conditions, " AND ")
+
+	var total int
+	if err := r.db.QueryRow(`SELECT COUNT(*) FROM ticket_transfers tr WHERE `+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	args = append(args, filter.Limit, filter.Offset)
+	query := fmt.Sprintf(`SELECT %s FROM %s WHERE %s ORDER BY tr.created_at DESC LIMIT $%d OFFSET $%d`,
+		transferColumns, transferFrom, where, len(args)-1, len(args))
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	transfers := []model.TicketTransfer{}
+	for rows.Next() {
+		tr, err := scanTransfer(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		transfers = append(transfers, *tr)
+	}
+	return transfers, total, rows.Err()
+}
+
+// CloseTransfer moves a pending transfer to a final status without a new
+// ticket. It returns false when the transfer was no longer pending.
+func (r *TransferRepository) CloseTransfer(id uuid.UUID, status string) (bool, error) {
+	now := time.Now()
+	result, err := r.db.Exec(`
+		UPDATE ticket_transfers SET status = $1, responded_at = $2, updated_at = $2
+		WHERE id = $3 AND status = $4`,
+		status, now, id, model.TransferStatusPending)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected == 1, err
+}
+
+// ExpireTransfers closes pending transfers past their deadline and returns
+// how many were expired.
+func (r *TransferRepository) ExpireTransfers(now time.Time) (int64, error) {
+	result, err := r.db.Exec(`
+		UPDATE ticket_transfers SET status = $1, updated_at = $2
+		WHERE status = $3 AND expires_at <= $2`,
+		model.TransferStatusExpired, now, model.TransferStatusPending)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// AcceptTransfer hands the ticket to the recipient in one transaction: the
+// transfer is accepted, the old ticket marked transferred and a new ticket
+// with newTicket's code and holder issued for the same item, sequence and
+// seat. The holder details of the old ticket are replaced by attendee, or
+// dropped when it is nil. It returns false when the transfer is no longer
+// pending or the ticket is no longer valid in the sender's hands.
+func (r *TransferRepository) AcceptTransfer(tr *model.TicketTransfer, newTicket *model.Ticket, attendee *model.Attendee) (bool, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	result, err := tx.Exec(`
+		UPDATE ticket_transfers SET status = $1, to_user_id = $2, responded_at = $3, updated_at = $3
+		WHERE id = $4 AND status = $5 AND expires_at > $3`,
+		model.TransferStatusAccepted, newTicket.UserID, now, tr.ID, model.TransferStatusPending)
+	if err != nil {
+		return false, err
+	}
+	if affected, err := result.RowsAffected(); err != nil || affected != 1 {
+		return false, err
+	}
+
+	if ok, err := reissueTicket(tx, tr.TicketID, tr.FromUserID, newTicket); !ok || err != nil {
+		return false, err
+	}
+
+	if _, err := tx.Exec(`UPDATE ticket_transfers SET new_ticket_id = $1 WHERE id = $2`, newTicket.ID, tr.ID); err != nil {
+		return false, err
+	}
+
+	if _, err := tx.Exec(`DELETE FROM order_attendees WHERE order_item_id = $1 AND sequence = $2`,
+		newTicket.OrderItemID, newTicket.Sequence); err != nil {
+		return false, err
+	}
+	if attendee != nil {
+		attendee.Sequence = newTicket.Sequence
+		if err := insertAttendee(tx, newTicket.OrderItemID, attendee); err != nil {
+			return false, err
+		}
+	}
+
+	return true, tx.Commit()
+}
+
+// CountTransfers returns how many times the admission behind a ticket has
+// changed hands, i.e. the number of tickets it replaced.
+func (r *TransferRepository) CountTransfers(ticketID uuid.UUID) (int, error) {
+	var count int
+	err := r.db.QueryRow(`
+		WITH RECURSIVE chain AS (
+			SELECT id, previous_ticket_id FROM tickets WHERE id = $1
+			UNION ALL
+			SELECT t.id, t.previous_ticket_id FROM tickets t JOIN chain c ON t.id = c.previous_ticket_id
+		)
+		SELECT COUNT(*) - 1 FROM chain`, ticketID).Scan(&count)
+	return count, err
+}
+
+// OwnershipHistory returns every ticket issued for the same admission as
+// ticketID, from the purchase to the current holder.
+func (r *TransferRepository) OwnershipHistory(ticketID uuid.UUID) ([]model.TicketOwnership, error) {
+	rows, err := r.db.Query(`
+		WITH RECURSIVE up AS (
+			SELECT id, previous_ticket_id FROM tickets WHERE id = $1
+			UNION ALL
+			SELECT t.id, t.previous_ticket_id FROM tickets t JOIN up ON t.id = up.previous_ticket_id
+		), chain AS (
+			SELECT t.id, 0 AS depth FROM tickets t
+			WHERE t.id = (SELECT id FROM up WHERE previous_ticket_id IS NULL)
+			UNION ALL
+			SELECT t.id, c.depth + 1 FROM tickets t JOIN chain c ON t.previous_ticket_id = c.id
+		)
+		SELECT t.id, t.code, t.user_id, t.holder_name, t.status, tr.id, t.created_at
+		FROM chain c
+		JOIN tickets t ON t.id = c.id
+		LEFT JOIN ticket_transfers tr ON tr.new_ticket_id = t.id
+		ORDER BY c.depth`, ticketID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	history := []model.TicketOwnership{}
+	for rows.Next() {
+		var o model.TicketOwnership
+		var transferID uuid.NullUUID
+		if err := rows.Scan(&o.TicketID, &o.Code, &o.UserID, &o.HolderName, &o.Status, &transferID, &o.AcquiredAt); err != nil {
+			return nil, err
+		}
+		o.Source = model.OwnershipPurchase
+		if transferID.Valid {
+			o.Source = model.OwnershipTransfer
+			o.TransferID = &transferID.UUID
+		}
+		history = append(history, o)
+	}
+	return history, rows.Err()
+}
@@ -20,19 +20,19 @@ func (r *UserRepository) CreateUser(user *model.User) error {
 	query := `
 		INSERT INTO users (name, email, phone, password, is_verified)
 		VALUES ($1, $2, $3, $4, $5)
-		RETURNING id, created_at, updated_at`
+		RETURNING id, role, created_at, updated_at`
 
 	return r.db.QueryRow(query, user.Name, user.Email, user.Phone, user.Password, false).
-		Scan(&user.ID, &user.CreatedAt, &user.UpdatedAt)
+		Scan(&user.ID, &user.Role, &user.CreatedAt, &user.UpdatedAt)
 }
 
 func (r *UserRepository) GetUserByEmail(email string) (*model.User, error) {
 	user := &model.User{}
-	query := `SELECT id, name, email, phone, password, is_verified, verification_method, created_at, updated_at FROM users WHERE email = $1`
+	query := `SELECT id, name, email, phone, password, is_verified, COALESCE(verification_method, '') as verification_method, role, created_at, updated_at FROM users WHERE email = $1`
 
 	err := r.db.QueryRow(query, email).Scan(
 		&user.ID, &user.Name, &user.Email, &user.Phone, &user.Password,
-		&user.IsVerified, &user.VerificationMethod, &user.CreatedAt, &user.UpdatedAt,
+		&user.IsVerified, &user.VerificationMethod, &user.Role, &user.CreatedAt, &user.UpdatedAt,
 	)
 	if err != nil {
 		return nil, err
@@ -42,11 +42,11 @@ func (r *UserRepository) GetUserByEmail(email string) (*model.User, error) {
 
 func (r *UserRepository) GetUserByID(id uuid.UUID) (*model.User, error) {
 	user := &model.User{}
-	query := `SELECT id, name, email, phone, password, is_verified, COALESCE(verification_method, '') as verification_method, created_at, updated_at FROM users WHERE id = $1`
+	query := `SELECT id, name, email, phone, password, is_verified, COALESCE(verification_method, '') as verification_method, role, created_at, updated_at FROM users WHERE id = $1`
 
 	err := r.db.QueryRow(query, id).Scan(
 		&user.ID, &user.Name, &user.Email, &user.Phone, &user.Password,
-		&user.IsVerified, &user.VerificationMethod, &user.CreatedAt, &user.UpdatedAt,
+		&user.IsVerified, &user.VerificationMethod, &user.Role, &user.CreatedAt, &user.UpdatedAt,
 	)
 	if err != nil {
 		return nil, err
@@ -60,6 +60,35 @@ func (r *UserRepository) UpdateUserVerification(userID uuid.UUID, isVerified boo
 	return err
 }
 
+// SetStepUpAt records a completed OTP step-up.
+func (r *UserRepository) SetStepUpAt(userID uuid.UUID, at time.Time) error {
+	query := `UPDATE users SET step_up_at = $1 WHERE id = $2`
+	_, err := r.db.Exec(query, at, userID)
+	return err
+}
+
+// GetStepUpAt returns the last OTP step-up, or nil if there never was one.
+func (r *UserRepository) GetStepUpAt(userID uuid.UUID) (*time.Time, error) {
+	var at sql.NullTime
+	if err := r.db.QueryRow(`SELECT step_up_at FROM users WHERE id = $1`, userID).Scan(&at); err != nil {
+		return nil, err
+	}
+	if !at.Valid {
+		return nil, nil
+	}
+	return &at.Time, nil
+}
+
+func (r *UserRepository) UpdateUserRole(userID uuid.UUID, role string) (bool, error) {
+	query := `UPDATE users SET role = $1, updated_at = $2 WHERE id = $3`
+	result, err := r.db.Exec(query, role, time.Now(), userID)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected == 1, err
+}
+
 func (r *UserRepository) EmailExists(email string) (bool, error) {
 	var exists bool
 	query := `SELECT EXISTS(SELECT 1 FROM users WHERE email = $1)`
@@ -0,0 +1,176 @@
+package repository
+
+import (
+	"database/sql"
+	"e-ticketing/internal/model"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+type VenueRepository struct {
+	db *sql.DB
+}
+
+func NewVenueRepository(db *sql.DB) *VenueRepository {
+	return &VenueRepository{db: db}
+}
+
+const venueColumns = `id, created_by, name, address, city, province, latitude, longitude, capacity, timezone,
+	facilities, COALESCE(map_image_url, ''), created_at, updated_at`
+
+// haversineSQL returns a SQL expression for the great-circle distance in km
+// between the latitude/longitude columns and the given placeholder args.
+func haversineSQL(latCol, lngCol, latArg, lngArg string) string {
+	return fmt.Sprintf(`(6371 * 2 * ASIN(SQRT(
+		POWER(SIN(RADIANS(%[1]s - %[3]s) / 2), 2) +
+		COS(RADIANS(%[3]s)) * COS(RADIANS(%[1]s)) * POWER(SIN(RADIANS(%[2]s - %[4]s) / 2), 2))))`,
+		latCol, lngCol, latArg, lngArg)
+}
+
+func scanVenue(row interface{ Scan(...interface{}) error }, extra ...interface{}) (*model.Venue, error) {
+	v := &model.Venue{}
+	dest := []interface{}{&v.ID, &v.CreatedBy, &v.Name, &v.Address, &v.City, &v.Province, &v.Latitude, &v.Longitude,
+		&v.Capacity, &v.Timezone, pq.Array(&v.Facilities), &v.MapImageURL, &v.CreatedAt, &v.UpdatedAt}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return nil, err
+	}
+	if v.Facilities == nil {
+		v.Facilities = []string{}
+	}
+	return v, nil
+}
+
+func (r *VenueRepository) CreateVenue(v *model.Venue) error {
+	query := `
+		INSERT INTO venues (created_by, name, address, city, province, latitude, longitude, capacity, timezone, facilities, map_image_url)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		RETURNING id, created_at, updated_at`
+
+	return r.db.QueryRow(query, v.CreatedBy, v.Name, v.Address, v.City, v.Province, v.Latitude, v.Longitude,
+		v.Capacity, v.Timezone, pq.Array(v.Facilities), v.MapImageURL).
+		Scan(&v.ID, &v.CreatedAt, &v.UpdatedAt)
+}
+
+func (r *VenueRepository) GetVenueByID(id uuid.UUID) (*model.Venue, error) {
+	query := `SELECT ` + venueColumns + ` FROM venues WHERE id = $1`
+	return scanVenue(r.db.QueryRow(query, id))
+}
+
+// UpdateVenue saves the venue and, in the same transaction, checks that the
+// ticket types of every event held there still fit, returning a
+// *CapacityError otherwise.
+func (r *VenueRepository) UpdateVenue(v *model.Venue) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		UPDATE venues SET name = $1, address = $2, city = $3, province = $4, latitude = $5, longitude = $6,
+			capacity = $7, timezone = $8, facilities = $9, map_image_url = $10, updated_at = $11
+		WHERE id = $12
+		RETURNING updated_at`
+
+	err = tx.QueryRow(query, v.Name, v.Address, v.City, v.Province, v.Latitude, v.Longitude,
+		v.Capacity, v.Timezone, pq.Array(v.Facilities), v.MapImageURL, time.Now(), v.ID).
+		Scan(&v.UpdatedAt)
+	if err != nil {
+		return err
+	}
+
+	rows, err := tx.Query(`SELECT id FROM events WHERE venue_id = $1 ORDER BY id`, v.ID)
+	if err != nil {
+		return err
+	}
+	eventIDs := []uuid.UUID{}
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return err
+		}
+		eventIDs = append(eventIDs, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, id := range eventIDs {
+		if err := checkVenueCapacity(tx, id, nil, 0); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (r *VenueRepository) DeleteVenue(id uuid.UUID) error {
+	_, err := r.db.Exec(`DELETE FROM venues WHERE id = $1`, id)
+	return err
+}
+
+// ListVenues filters by city/province/name and, when Near is set, by
+// haversine distance ordered nearest first.
+func (r *VenueRepository) ListVenues(filter *model.VenueFilter) ([]model.Venue, int, error) {
+	conditions := []string{"1 = 1"}
+	args := []interface{}{}
+
+	addCondition := func(clause string, value interface{}) {
+		args = append(args, value)
+		conditions = append(conditions, fmt.Sprintf(clause, len(args)))
+	}
+
+	if filter.City != "" {
+		addCondition("city ILIKE $%d", filter.City)
+	}
+	if filter.Province != "" {
+		addCondition("province ILIKE $%d", filter.Province)
+	}
+	if filter.Search != "" {
+		addCondition("name ILIKE $%d", "%"+filter.Search+"%")
+	}
+
+	distance := "NULL::DOUBLE PRECISION"
+	orderBy := "name"
+	if filter.Near != nil {
+		args = append(args, filter.Near.Latitude, filter.Near.Longitude)
+		distance = haversineSQL("latitude", "longitude", fmt.Sprintf("$%d", len(args)-1), fmt.Sprintf("$%d", len(args)))
+		addCondition(distance+" <= $%d", filter.Near.RadiusKm)
+		orderBy = "distance_km"
+	}
+
+	where := strings.Join(conditions, " AND ")
+
+	var total int
+	if err := r.db.QueryRow(`SELECT COUNT(*) FROM venues WHERE `+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query := fmt.Sprintf(`SELECT %s, %s AS distance_km FROM venues WHERE %s ORDER BY %s LIMIT $%d OFFSET $%d`,
+		venueColumns, distance, where, orderBy, len(args)+1, len(args)+2)
+
+	rows, err := r.db.Query(query, append(args, filter.Limit, filter.Offset)...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	venues := []model.Venue{}
+	for rows.Next() {
+		var distanceKm sql.NullFloat64
+		v, err := scanVenue(rows, &distanceKm)
+		if err != nil {
+			return nil, 0, err
+		}
+		if distanceKm.Valid {
+			v.DistanceKm = &distanceKm.Float64
+		}
+		venues = append(venues, *v)
+	}
+	return venues, total, rows.Err()
+}
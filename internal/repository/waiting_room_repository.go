@@ -0,0 +1,303 @@
+package repository
+
+import (
+	"database/sql"
+	"e-ticketing/internal/model"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type WaitingRoomRepository struct {
+	db *sql.DB
+}
+
+func NewWaitingRoomRepository(db *sql.DB) *WaitingRoomRepository {
+	return &WaitingRoomRepository{db: db}
+}
+
+const waitingRoomColumns = `event_id, enabled, paused, opens_at, admission_rate, admission_window_minutes, shuffled,
+	next_position, admitted_position, last_admission_at, created_at, updated_at`
+
+func scanWaitingRoom(row interface{ Scan(...interface{}) error }) (*model.WaitingRoom, error) {
+	w := &model.WaitingRoom{}
+	var lastAdmission sql.NullTime
+	err := row.Scan(&w.EventID, &w.Enabled, &w.Paused, &w.OpensAt, &w.AdmissionRate, &w.AdmissionWindowMinutes, &w.Shuffled,
+		&w.NextPosition, &w.AdmittedPosition, &lastAdmission, &w.CreatedAt, &w.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if lastAdmission.Valid {
+		w.LastAdmissionAt = &lastAdmission.Time
+	}
+	return w, nil
+}
+
+const queueEntryColumns = `id, event_id, user_id, position, status, joined_at, admitted_at, admission_expires_at`
+
+func scanQueueEntry(row interface{ Scan(...interface{}) error }) (*model.QueueEntry, error) {
+	q := &model.QueueEntry{}
+	var position sql.NullInt64
+	var admittedAt, expiresAt sql.NullTime
+	err := row.Scan(&q.ID, &q.EventID, &q.UserID, &position, &q.Status, &q.JoinedAt, &admittedAt, &expiresAt)
+	if err != nil {
+		return nil, err
+	}
+	if position.Valid {
+		q.Position = &position.Int64
+	}
+	if admittedAt.Valid {
+		q.AdmittedAt = &admittedAt.Time
+	}
+	if expiresAt.Valid {
+		q.AdmissionExpiresAt = &expiresAt.Time
+	}
+	return q, nil
+}
+
+// SaveWaitingRoom creates or reconfigures the event's room. The queue itself
+// is left as is, so reconfiguring during an on-sale keeps everyone's place.
+func (r *WaitingRoomRepository) SaveWaitingRoom(w *model.WaitingRoom) error {
+	query := `
+		INSERT INTO waiting_rooms (event_id, enabled, opens_at, admission_rate, admission_window_minutes)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (event_id) DO UPDATE SET enabled = $2, opens_at = $3, admission_rate = $4,
+			admission_window_minutes = $5, updated_at = $6
+		RETURNING ` + waitingRoomColumns
+
+	saved, err := scanWaitingRoom(r.db.QueryRow(query, w.EventID, w.Enabled, w.OpensAt, w.AdmissionRate,
+		w.AdmissionWindowMinutes, time.Now()))
+	if err != nil {
+		return err
+	}
+	*w = *saved
+	return nil
+}
+
+func (r *WaitingRoomRepository) GetWaitingRoom(eventID uuid.UUID) (*model.WaitingRoom, error) {
+	query := `SELECT ` + waitingRoomColumns + ` FROM waiting_rooms WHERE event_id = $1`
+	return scanWaitingRoom(r.db.QueryRow(query, eventID))
+}
+
+// SetPaused pauses or resumes admissions. Resuming restarts the admission
+// clock so the paused time does not turn into a burst of admissions.
+func (r *WaitingRoomRepository) SetPaused(eventID uuid.UUID, paused bool) (*model.WaitingRoom, error) {
+	query := `
+		UPDATE waiting_rooms SET paused = $1, updated_at = $2,
+			last_admission_at = CASE WHEN NOT $1 AND shuffled THEN $2 ELSE last_admission_at END
+		WHERE event_id = $3
+		RETURNING ` + waitingRoomColumns
+	return scanWaitingRoom(r.db.QueryRow(query, paused, time.Now(), eventID))
+}
+
+func (r *WaitingRoomRepository) CountEntriesByStatus(eventID uuid.UUID) (map[string]int, error) {
+	rows, err := r.db.Query(`SELECT status, COUNT(*) FROM queue_entries WHERE event_id = $1 GROUP BY status`, eventID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := map[string]int{}
+	for rows.Next() {
+		var status string
+		var count int
+		if err := rows.Scan(&status, &count); err != nil {
+			return nil, err
+		}
+		counts[status] = count
+	}
+	return counts, rows.Err()
+}
+
+// JoinQueue returns the user's entry, creating it at the back of the queue.
+// An expired entry is sent to the back again. Joining locks the room row, so
+// positions are handed out in order and never race the opening shuffle.
+func (r *WaitingRoomRepository) JoinQueue(eventID, userID uuid.UUID) (*model.QueueEntry, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var shuffled bool
+	if err := tx.QueryRow(`SELECT shuffled FROM waiting_rooms WHERE event_id = $1 FOR UPDATE`, eventID).Scan(&shuffled); err != nil {
+		return nil, err
+	}
+
+	existing, err := scanQueueEntry(tx.QueryRow(`SELECT `+queueEntryColumns+` FROM queue_entries WHERE event_id = $1 AND user_id = $2`, eventID, userID))
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	}
+	if existing != nil && existing.Status != model.QueueStatusExpired {
+		return existing, nil
+	}
+
+	// Before opening everyone is unplaced; the shuffle assigns positions
+	var position *int64
+	if shuffled {
+		var next int64
+		if err := tx.QueryRow(`
+			UPDATE waiting_rooms SET next_position = next_position + 1, updated_at = $1
+			WHERE event_id = $2 RETURNING next_position`, time.Now(), eventID).Scan(&next); err != nil {
+			return nil, err
+		}
+		position = &next
+	}
+
+	query := `
+		INSERT INTO queue_entries (event_id, user_id, position, status, joined_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (event_id, user_id) DO UPDATE SET position = $3, status = $4, joined_at = $5,
+			admitted_at = NULL, admission_expires_at = NULL
+		RETURNING ` + queueEntryColumns
+	entry, err := scanQueueEntry(tx.QueryRow(query, eventID, userID, position, model.QueueStatusWaiting, time.Now()))
+	if err != nil {
+		return nil, err
+	}
+	return entry, tx.Commit()
+}
+
+func (r *WaitingRoomRepository) GetQueueEntry(eventID, userID uuid.UUID) (*model.QueueEntry, error) {
+	query := `SELECT ` + queueEntryColumns + ` FROM queue_entries WHERE event_id = $1 AND user_id = $2`
+	return scanQueueEntry(r.db.QueryRow(query, eventID, userID))
+}
+
+func (r *WaitingRoomRepository) GetQueueEntryByID(id uuid.UUID) (*model.QueueEntry, error) {
+	query := `SELECT ` + queueEntryColumns + ` FROM queue_entries WHERE id = $1`
+	return scanQueueEntry(r.db.QueryRow(query, id))
+}
+
+// OpenRooms shuffles the early joiners of every room whose opening time has
+// passed. Everyone who joined before opening gets a random position, so
+// there is no advantage in hammering the join endpoint early.
+func (r *WaitingRoomRepository) OpenRooms(now time.Time) (int, error) {
+	rows, err := r.db.Query(`SELECT event_id FROM waiting_rooms WHERE NOT shuffled AND opens_at <= $1`, now)
+	if err != nil {
+		return 0, err
+	}
+	eventIDs := []uuid.UUID{}
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		eventIDs = append(eventIDs, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	opened := 0
+	for _, eventID := range eventIDs {
+		ok, err := r.openRoom(eventID, now)
+		if err != nil {
+			return opened, err
+		}
+		if ok {
+			opened++
+		}
+	}
+	return opened, nil
+}
+
+func (r *WaitingRoomRepository) openRoom(eventID uuid.UUID, now time.Time) (bool, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	// SKIP LOCKED leaves the room to whichever instance got there first
+	var id uuid.UUID
+	err = tx.QueryRow(`
+		SELECT event_id FROM waiting_rooms
+		WHERE event_id = $1 AND NOT shuffled AND opens_at <= $2
+		FOR UPDATE SKIP LOCKED`, eventID, now).Scan(&id)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	var placed int64
+	err = tx.QueryRow(`
+		WITH ranked AS (
+			SELECT id, ROW_NUMBER() OVER (ORDER BY random()) AS position
+			FROM queue_entries WHERE event_id = $1 AND position IS NULL
+		), placed AS (
+			UPDATE queue_entries q SET position = ranked.position
+			FROM ranked WHERE q.id = ranked.id
+			RETURNING q.id
+		)
+		SELECT COUNT(*) FROM placed`, eventID).Scan(&placed)
+	if err != nil {
+		return false, err
+	}
+
+	if _, err := tx.Exec(`
+		UPDATE waiting_rooms SET shuffled = TRUE, next_position = $1, last_admission_at = $2, updated_at = $2
+		WHERE event_id = $3`, placed, now, eventID); err != nil {
+		return false, err
+	}
+	return true, tx.Commit()
+}
+
+// AdmitQueued advances the admission cursor of every open, unpaused room by
+// its rate, admits the entries the cursor passed and expires admissions
+// whose window closed. The cursor never runs past the back of the queue, so
+// a quiet room does not bank admissions for a later rush. It returns the
+// number of entries admitted.
+func (r *WaitingRoomRepository) AdmitQueued(now time.Time) (int64, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
+		WITH credit AS (
+			SELECT event_id, admission_rate, next_position, admitted_position, last_admission_at,
+				FLOOR(admission_rate * EXTRACT(EPOCH FROM ($1 - last_admission_at)) / 60)::bigint AS n
+			FROM waiting_rooms
+			WHERE enabled AND NOT paused AND shuffled
+			FOR UPDATE
+		)
+		UPDATE waiting_rooms w SET
+			admitted_position = LEAST(c.admitted_position + c.n, c.next_position),
+			last_admission_at = CASE
+				WHEN c.admitted_position + c.n >= c.next_position THEN $1
+				ELSE c.last_admission_at + make_interval(secs => c.n * 60.0 / c.admission_rate)
+			END,
+			updated_at = $1
+		FROM credit c
+		WHERE w.event_id = c.event_id AND c.n > 0`, now)
+	if err != nil {
+		return 0, err
+	}
+
+	result, err := tx.Exec(`
+		UPDATE queue_entries q SET status = $1, admitted_at = $2,
+			admission_expires_at = $2 + make_interval(mins => w.admission_window_minutes)
+		FROM waiting_rooms w
+		WHERE q.event_id = w.event_id AND q.status = $3 AND q.position <= w.admitted_position`,
+		model.QueueStatusAdmitted, now, model.QueueStatusWaiting)
+	if err != nil {
+		return 0, err
+	}
+	admitted, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	if _, err := tx.Exec(`
+		UPDATE queue_entries SET status = $1
+		WHERE status = $2 AND admission_expires_at <= $3`,
+		model.QueueStatusExpired, model.QueueStatusAdmitted, now); err != nil {
+		return 0, err
+	}
+
+	return admitted, tx.Commit()
+}
@@ -0,0 +1,415 @@
+package repository
+
+import (
+	"database/sql"
+	"e-ticketing/internal/model"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type WaitlistRepository struct {
+	db *sql.DB
+}
+
+func NewWaitlistRepository(db *sql.DB) *WaitlistRepository {
+	return &WaitlistRepository{db: db}
+}
+
+// Entry reads join the ticket type and event for display, and count the
+// waiting entries ahead in the queue.
+const waitlistColumns = `w.id, w.event_id, w.ticket_type_id, w.user_id, w.quantity, w.status, w.offered_quantity,
+	w.resale_listing_id, w.offered_at, w.offer_expires_at, w.claimed_at, w.created_at, w.updated_at, tt.name, e.title,
+	CASE WHEN w.status = '` + model.WaitlistStatusWaiting + `' THEN (
+		SELECT COUNT(*) FROM waitlist_entries a
+		WHERE a.ticket_type_id = w.ticket_type_id AND a.status = w.status AND a.created_at < w.created_at
+	) ELSE 0 END`
+
+const waitlistFrom = `waitlist_entries w
+	JOIN ticket_types tt ON tt.id = w.ticket_type_id
+	JOIN events e ON e.id = w.event_id`
+
+func scanWaitlistEntry(row interface{ Scan(...interface{}) error }) (*model.WaitlistEntry, error) {
+	e := &model.WaitlistEntry{}
+	var resaleListingID uuid.NullUUID
+	var offeredAt, offerExpiresAt, claimedAt sql.NullTime
+	err := row.Scan(&e.ID, &e.EventID, &e.TicketTypeID, &e.UserID, &e.Quantity, &e.Status, &e.OfferedQuantity,
+		&resaleListingID, &offeredAt, &offerExpiresAt, &claimedAt, &e.CreatedAt, &e.UpdatedAt, &e.TicketTypeName, &e.EventTitle,
+		&e.Ahead)
+	if err != nil {
+		return nil, err
+	}
+	if resaleListingID.Valid {
+		e.ResaleListingID = &resaleListingID.UUID
+	}
+	if offeredAt.Valid {
+		e.OfferedAt = &offeredAt.Time
+	}
+	if offerExpiresAt.Valid {
+		e.OfferExpiresAt = &offerExpiresAt.Time
+	}
+	if claimedAt.Valid {
+		e.ClaimedAt = &claimedAt.Time
+	}
+	return e, nil
+}
+
+func (r *WaitlistRepository) CreateEntry(e *model.WaitlistEntry) error {
+	query := `
+		INSERT INTO waitlist_entries (event_id, ticket_type_id, user_id, quantity, status)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at, updated_at`
+
+	return r.db.QueryRow(query, e.EventID, e.TicketTypeID, e.UserID, e.Quantity, e.Status).
+		Scan(&e.ID, &e.CreatedAt, &e.UpdatedAt)
+}
+
+func (r *WaitlistRepository) GetEntry(id uuid.UUID) (*model.WaitlistEntry, error) {
+	query := `SELECT ` + waitlistColumns + ` FROM ` + waitlistFrom + ` WHERE w.id = $1`
+	return scanWaitlistEntry(r.db.QueryRow(query, id))
+}
+
+func (r *WaitlistRepository) ListEntries(filter *model.WaitlistFilter) ([]model.WaitlistEntry, int, error) {
+	args := []interface{}{filter.UserID}
+	where := `w.user_id = $1`
+	if filter.Status != "" {
+		args = append(args, filter.Status)
+		where += fmt.Sprintf(" AND w.status = $%d", len(args))
+	}
+
+	var total int
+	if err := r.db.QueryRow(`SELECT COUNT(*) FROM waitlist_entries w WHERE `+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	args = append(args, filter.Limit, filter.Offset)
+	query := fmt.Sprintf(`SELECT %s FROM %s WHERE %s ORDER BY w.created_at DESC LIMIT $%d OFFSET $%d`,
+		waitlistColumns, waitlistFrom, where, len(args)-1, len(args))
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	entries := []model.WaitlistEntry{}
+	for rows.Next() {
+		e, err := scanWaitlistEntry(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		entries = append(entries, *e)
+	}
+	return entries, total, rows.Err()
+}
+
+// WaitingQuantity is the number of tickets of the type that waiting entries
+// have asked for.
+func (r *WaitlistRepository) WaitingQuantity(ticketTypeID uuid.UUID) (int, error) {
+	var quantity int
+	err := r.db.QueryRow(`
+		SELECT COALESCE(SUM(quantity), 0) FROM waitlist_entries WHERE ticket_type_id = $1 AND status = $2`,
+		ticketTypeID, model.WaitlistStatusWaiting).Scan(&quantity)
+	return quantity, err
+}
+
+// Stats summarises the waitlist of every ticket type of an event.
+func (r *WaitlistRepository) Stats(eventID uuid.UUID) ([]model.WaitlistStats, error) {
+	rows, err := r.db.Query(`
+		SELECT tt.id, tt.name,
+			COUNT(w.id) FILTER (WHERE w.status = $2), COALESCE(SUM(w.quantity) FILTER (WHERE w.status = $2), 0),
+			COUNT(w.id) FILTER (WHERE w.status = $3), COALESCE(SUM(w.offered_quantity) FILTER (WHERE w.status = $3), 0),
+			COUNT(w.id) FILTER (WHERE w.status = $4)
+		FROM ticket_types tt LEFT JOIN waitlist_entries w ON w.ticket_type_id = tt.id
+		WHERE tt.event_id = $1
+		GROUP BY tt.id, tt.name, tt.sort_order
+		ORDER BY tt.sort_order, tt.name`,
+		eventID, model.WaitlistStatusWaiting, model.WaitlistStatusOffered, model.WaitlistStatusClaimed)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	stats := []model.WaitlistStats{}
+	for rows.Next() {
+		var s model.WaitlistStats
+		if err := rows.Scan(&s.TicketTypeID, &s.TicketTypeName, &s.Waiting, &s.WaitingQuantity,
+			&s.Offered, &s.OfferedQuantity, &s.Claimed); err != nil {
+			return nil, err
+		}
+		stats = append(stats, s)
+	}
+	return stats, rows.Err()
+}
+
+// offeredStock is the stock an offered entry set aside from its ticket type;
+// offers of a resale listing set none aside.
+const offeredStock = `CASE WHEN resale_listing_id IS NULL THEN offered_quantity ELSE 0 END`
+
+// CancelEntry leaves the waitlist for the user and gives back the stock of
+// an open offer. It returns false when the entry was no longer waiting or
+// offered.
+func (r *WaitlistRepository) CancelEntry(id, userID uuid.UUID) (bool, error) {
+	query := `
+		WITH cancelled AS (
+			UPDATE waitlist_entries SET status = $1, updated_at = $2
+			WHERE id = $3 AND user_id = $4 AND status IN ($5, $6)
+			RETURNING ticket_type_id, ` + offeredStock + ` AS quantity
+		), returned AS (
+			UPDATE ticket_types t SET available = t.available + cancelled.quantity, updated_at = $2
+			FROM cancelled WHERE t.id = cancelled.ticket_type_id AND cancelled.quantity > 0
+		)
+		SELECT COUNT(*) FROM cancelled`
+
+	var cancelled int
+	err := r.db.QueryRow(query, model.WaitlistStatusCancelled, time.Now(), id, userID,
+		model.WaitlistStatusWaiting, model.WaitlistStatusOffered).Scan(&cancelled)
+	return cancelled == 1, err
+}
+
+// ExpireOffers expires every offer past its deadline and gives the stock set
+// aside back in a single statement, so it can be offered to the next in
+// line. It returns the number of offers expired.
+func (r *WaitlistRepository) ExpireOffers(now time.Time) (int, error) {
+	query := `
+		WITH expired AS (
+			UPDATE waitlist_entries SET status = $1, updated_at = $2
+			WHERE status = $3 AND offer_expires_at <= $2
+			RETURNING ticket_type_id, ` + offeredStock + ` AS quantity
+		), totals AS (
+			SELECT ticket_type_id, SUM(quantity) AS quantity FROM expired GROUP BY ticket_type_id
+		), returned AS (
+			UPDATE ticket_types t SET available = t.available + totals.quantity, updated_at = $2
+			FROM totals WHERE t.id = totals.ticket_type_id AND totals.quantity > 0
+		)
+		SELECT COUNT(*) FROM expired`
+
+	var expired int
+	err := r.db.QueryRow(query, model.WaitlistStatusExpired, now, model.WaitlistStatusOffered).Scan(&expired)
+	return expired, err
+}
+
+// TicketTypesToOffer lists the ticket types that have stock free and buyers
+// waiting for it, within the sales window of an event still selling.
+func (r *WaitlistRepository) TicketTypesToOffer(now time.Time) ([]uuid.UUID, error) {
+	rows, err := r.db.Query(`
+		SELECT tt.id FROM ticket_types tt JOIN events e ON e.id = tt.event_id
+		WHERE tt.available > 0 AND e.status IN ($1, $2) AND tt.sales_start <= $3 AND tt.sales_end > $3
+			AND EXISTS (SELECT 1 FROM waitlist_entries w WHERE w.ticket_type_id = tt.id AND w.status = $4)`,
+		model.EventStatusOnSale, model.EventStatusSoldOut, now, model.WaitlistStatusWaiting)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := []uuid.UUID{}
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// OfferStock sets the ticket type's free stock aside for waiting entries in
+// join order, offering each up to the quantity it asked for until
+// expiresAt. It returns the IDs of the entries offered.
+func (r *WaitlistRepository) OfferStock(ticketTypeID uuid.UUID, now, expiresAt time.Time) ([]uuid.UUID, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var available int
+	if err := tx.QueryRow(`SELECT available FROM ticket_types WHERE id = $1 FOR UPDATE`, ticketTypeID).Scan(&available); err != nil {
+		return nil, err
+	}
+
+	rows, err := tx.Query(`
+		SELECT id, quantity FROM waitlist_entries
+		WHERE ticket_type_id = $1 AND status = $2
+		ORDER BY created_at FOR UPDATE`,
+		ticketTypeID, model.WaitlistStatusWaiting)
+	if err != nil {
+		return nil, err
+	}
+	type waiting struct {
+		id       uuid.UUID
+		quantity int
+	}
+	queue := []waiting{}
+	for rows.Next() {
+		var w waiting
+		if err := rows.Scan(&w.id, &w.quantity); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		queue = append(queue, w)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	offered := []uuid.UUID{}
+	total := 0
+	for _, w := range queue {
+		if available-total <= 0 {
+			break
+		}
+		quantity := min(w.quantity, available-total)
+		_, err := tx.Exec(`
+			UPDATE waitlist_entries SET status = $1, offered_quantity = $2, offered_at = $3, offer_expires_at = $4,
+				updated_at = $3
+			WHERE id = $5`,
+			model.WaitlistStatusOffered, quantity, now, expiresAt, w.id)
+		if err != nil {
+			return nil, err
+		}
+		offered = append(offered, w.id)
+		total += quantity
+	}
+
+	if total > 0 {
+		_, err := tx.Exec(`UPDATE ticket_types SET available = available - $1, updated_at = $2 WHERE id = $3`,
+			total, now, ticketTypeID)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return offered, tx.Commit()
+}
+
+// ListingsToOffer lists active resale listings that nobody is buying or has
+// been offered, whose ticket type has buyers other than the seller waiting.
+// Only ID, TicketTypeID and SellerUserID are set.
+func (r *WaitlistRepository) ListingsToOffer() ([]model.ResaleListing, error) {
+	rows, err := r.db.Query(`
+		SELECT l.id, t.ticket_type_id, l.seller_user_id
+		FROM resale_listings l JOIN tickets t ON t.id = l.ticket_id
+		WHERE l.status = $1
+			AND NOT EXISTS (SELECT 1 FROM orders o WHERE o.id = l.order_id AND o.status IN (`+resaleTakenOrderStatuses+`))
+			AND NOT EXISTS (SELECT 1 FROM waitlist_entries w WHERE w.resale_listing_id = l.id AND w.status = $2)
+			AND EXISTS (SELECT 1 FROM waitlist_entries w
+				WHERE w.ticket_type_id = t.ticket_type_id AND w.status = $3 AND w.user_id <> l.seller_user_id)
+		ORDER BY l.created_at`,
+		model.ResaleStatusActive, model.WaitlistStatusOffered, model.WaitlistStatusWaiting)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	listings := []model.ResaleListing{}
+	for rows.Next() {
+		var l model.ResaleListing
+		if err := rows.Scan(&l.ID, &l.TicketTypeID, &l.SellerUserID); err != nil {
+			return nil, err
+		}
+		listings = append(listings, l)
+	}
+	return listings, rows.Err()
+}
+
+// OfferListing offers a resale listing to the first waiting entry of its
+// ticket type that is not the seller's, until expiresAt. It returns false
+// when nobody is waiting or the listing was offered meanwhile.
+func (r *WaitlistRepository) OfferListing(listing *model.ResaleListing, now, expiresAt time.Time) (uuid.UUID, bool, error) {
+	var id uuid.UUID
+	err := r.db.QueryRow(`
+		UPDATE waitlist_entries SET status = $1, offered_quantity = 1, resale_listing_id = $2, offered_at = $3,
+			offer_expires_at = $4, updated_at = $3
+		WHERE id = (
+			SELECT id FROM waitlist_entries
+			WHERE ticket_type_id = $5 AND status = $6 AND user_id <> $7
+			ORDER BY created_at LIMIT 1 FOR UPDATE SKIP LOCKED
+		) AND NOT EXISTS (SELECT 1 FROM waitlist_entries WHERE resale_listing_id = $2 AND status = $1)
+		RETURNING id`,
+		model.WaitlistStatusOffered, listing.ID, now, expiresAt, listing.TicketTypeID,
+		model.WaitlistStatusWaiting, listing.SellerUserID).Scan(&id)
+	if err == sql.ErrNoRows {
+		return uuid.Nil, false, nil
+	}
+	return id, err == nil, err
+}
+
+// ClaimOffer turns an open stock offer into an active hold for the user in
+// one transaction. Without seatIDs the hold takes the whole offered quantity;
+// with them it claims those seats and gives the rest of the offer back. It
+// returns false when the offer is no longer open, or when the seats exceed
+// the offer or were taken, and a *PurchaseLimitError when the hold would
+// exceed limit.
+func (r *WaitlistRepository) ClaimOffer(entryID uuid.UUID, hold *model.InventoryHold, seatIDs []uuid.UUID, limit *model.HoldLimit) (bool, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	if err := checkHoldLimit(tx, limit); err != nil {
+		return false, err
+	}
+
+	now := time.Now()
+	var offered int
+	err = tx.QueryRow(`
+		UPDATE waitlist_entries SET status = $1, claimed_at = $2, updated_at = $2
+		WHERE id = $3 AND user_id = $4 AND ticket_type_id = $5 AND status = $6 AND offer_expires_at > $2
+			AND resale_listing_id IS NULL
+		RETURNING offered_quantity`,
+		model.WaitlistStatusClaimed, now, entryID, hold.UserID, hold.TicketTypeID, model.WaitlistStatusOffered).
+		Scan(&offered)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	hold.Quantity = offered
+	if len(seatIDs) > 0 {
+		if len(seatIDs) > offered {
+			return false, nil
+		}
+		if ok, err := claimSeats(tx, hold.TicketTypeID, seatIDs, now); err != nil || !ok {
+			return false, err
+		}
+		hold.Quantity = len(seatIDs)
+	}
+
+	if err := insertHold(tx, hold); err != nil {
+		return false, err
+	}
+	if len(seatIDs) > 0 {
+		if err := attachSeats(tx, hold.ID, seatIDs); err != nil {
+			return false, err
+		}
+	}
+	if unused := offered - hold.Quantity; unused > 0 {
+		_, err := tx.Exec(`UPDATE ticket_types SET available = available + $1, updated_at = $2 WHERE id = $3`,
+			unused, now, hold.TicketTypeID)
+		if err != nil {
+			return false, err
+		}
+	}
+
+	return true, tx.Commit()
+}
+
+// ClaimResaleOffer marks an offer of a resale listing claimed once the user
+// has ordered it. It returns false when the offer was no longer open.
+func (r *WaitlistRepository) ClaimResaleOffer(entryID, userID uuid.UUID) (bool, error) {
+	now := time.Now()
+	result, err := r.db.Exec(`
+		UPDATE waitlist_entries SET status = $1, claimed_at = $2, updated_at = $2
+		WHERE id = $3 AND user_id = $4 AND status = $5 AND resale_listing_id IS NOT NULL`,
+		model.WaitlistStatusClaimed, now, entryID, userID, model.WaitlistStatusOffered)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected == 1, err
+}
@@ -0,0 +1,311 @@
+package service
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"e-ticketing/config"
+	"e-ticketing/internal/model"
+	"e-ticketing/internal/repository"
+	"e-ticketing/pkg/utils"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const devAttendeeKeyID = "dev"
+
+var (
+	ktpPattern      = regexp.MustCompile(`^[0-9]{16}$`)
+	passportPattern = regexp.MustCompile(`^[A-Z0-9]{6,9}$`)
+)
+
+// AttendeeService manages the holder details events ask for per ticket. ID
+// numbers are encrypted at rest and only ever shown masked.
+type AttendeeService struct {
+	attendeeRepo *repository.AttendeeRepository
+	eventSvc     *EventService
+	keyID        string
+	keys         map[string][]byte
+}
+
+func NewAttendeeService(attendeeRepo *repository.AttendeeRepository, eventSvc *EventService, cfg *config.Config) (*AttendeeService, error) {
+	keyID := cfg.AttendeeKeyID
+	keys := map[string][]byte{}
+	for kid, encoded := range cfg.AttendeeKeys {
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil || len(key) != 32 {
+			return nil, fmt.Errorf("kunci enkripsi peserta %s harus 32 byte base64", kid)
+		}
+		keys[kid] = key
+	}
+
+	// Development gets a key derived from the JWT secret so checkout works
+	// without extra setup
+	if len(keys) == 0 {
+		if cfg.AppEnv == "production" {
+			return nil, errors.New("ATTENDEE_ENCRYPTION_KEYS wajib diisi di production")
+		}
+		key := sha256.Sum256([]byte("attendee:" + cfg.JWTSecret))
+		keys[devAttendeeKeyID] = key[:]
+		keyID = devAttendeeKeyID
+	}
+	if keyID == "" && len(keys) == 1 {
+		for kid := range keys {
+			keyID = kid
+		}
+	}
+	if _, ok := keys[keyID]; !ok {
+		return nil, fmt.Errorf("kunci enkripsi peserta aktif %q tidak ditemukan", keyID)
+	}
+
+	return &AttendeeService{
+		attendeeRepo: attendeeRepo,
+		eventSvc:     eventSvc,
+		keyID:        keyID,
+		keys:         keys,
+	}, nil
+}
+
+func (s *AttendeeService) ConfigureRequirements(actor model.Actor, eventID uuid.UUID, req *model.ConfigureAttendeeRequirementsRequest) (*model.AttendeeRequirements, error) {
+	event, err := s.eventSvc.AuthorizeEvent(actor, eventID)
+	if err != nil {
+		return nil, err
+	}
+	if event.Status == model.EventStatusCancelled || event.Status == model.EventStatusFinished {
+		return nil, errors.New("event yang sudah dibatalkan atau selesai tidak dapat diubah")
+	}
+
+	requirements := &model.AttendeeRequirements{
+		EventID:          eventID,
+		RequireName:      req.RequireName,
+		RequireIDNumber:  req.RequireIDNumber,
+		RequireEmail:     req.RequireEmail,
+		RequirePhone:     req.RequirePhone,
+		RequireBirthDate: req.RequireBirthDate,
+	}
+	if err := s.attendeeRepo.SaveRequirements(requirements); err != nil {
+		return nil, err
+	}
+	return requirements, nil
+}
+
+// GetRequirements shows what a public event asks for, or any event to its
+// managers, so buyers can prepare the details before checkout.
+func (s *AttendeeService) GetRequirements(actor model.Actor, eventID uuid.UUID) (*model.AttendeeRequirements, error) {
+	managed := false
+	if !actor.IsAnonymous() {
+		if _, err := s.eventSvc.AuthorizeEvent(actor, eventID); err == nil {
+			managed = true
+		}
+	}
+	if !managed {
+		if _, err := s.eventSvc.GetPublicEvent(eventID); err != nil {
+			return nil, err
+		}
+	}
+	return s.requirements(eventID)
+}
+
+// AttachAttendees validates the checkout's holder details against the
+// event's requirements and sets them, encrypted, on the order items. When
+// the event requires any detail, every ticket needs an attendee.
+func (s *AttendeeService) AttachAttendees(eventID uuid.UUID, items []model.OrderItem, inputs []model.AttendeeInput) error {
+	requirements, err := s.requirements(eventID)
+	if err != nil {
+		return err
+	}
+
+	byHold := map[uuid.UUID][]model.AttendeeInput{}
+	for _, input := range inputs {
+		holdID, err := uuid.Parse(input.HoldID)
+		if err != nil {
+			return errors.New("hold ID peserta tidak valid")
+		}
+		byHold[holdID] = append(byHold[holdID], input)
+	}
+
+	idNumbers := map[string]bool{}
+	matched := 0
+	for i := range items {
+		item := &items[i]
+		itemInputs := byHold[*item.HoldID]
+		matched += len(itemInputs)
+		if len(itemInputs) > item.Quantity {
+			return fmt.Errorf("data peserta melebihi jumlah tiket pada hold %s", *item.HoldID)
+		}
+		if requirements.Any() && len(itemInputs) != item.Quantity {
+			return fmt.Errorf("event ini mewajibkan data peserta untuk setiap tiket, hold %s membutuhkan %d peserta", *item.HoldID, item.Quantity)
+		}
+
+		item.Attendees = make([]model.Attendee, 0, len(itemInputs))
+		for seq, input := range itemInputs {
+			attendee, err := s.attendee(requirements, input.AttendeeDetails, seq+1)
+			if err != nil {
+				return fmt.Errorf("peserta %d pada hold %s: %w", seq+1, *item.HoldID, err)
+			}
+			if attendee.IDNumberEncrypted != "" {
+				key := attendee.IDType + ":" + normalizeIDNumber(input.IDNumber)
+				if idNumbers[key] {
+					return errors.New("nomor identitas yang sama dipakai lebih dari satu tiket")
+				}
+				idNumbers[key] = true
+			}
+			item.Attendees = append(item.Attendees, *attendee)
+		}
+	}
+	if matched != len(inputs) {
+		return errors.New("data peserta merujuk hold yang tidak ada di order ini")
+	}
+	return nil
+}
+
+// PrepareAttendee validates the details a new holder gives for a ticket. It
+// returns nil when none are given and the event asks for none.
+func (s *AttendeeService) PrepareAttendee(eventID uuid.UUID, details *model.AttendeeDetails, sequence int) (*model.Attendee, error) {
+	requirements, err := s.requirements(eventID)
+	if err != nil {
+		return nil, err
+	}
+	if details == nil {
+		if requirements.Any() {
+			return nil, errors.New("event ini mewajibkan data peserta untuk setiap tiket")
+		}
+		return nil, nil
+	}
+	return s.attendee(requirements, *details, sequence)
+}
+
+// MatchIDNumber reports whether presented is the ID number recorded for the
+// ticket's holder. ok is false when the ticket has no ID number to match.
+func (s *AttendeeService) MatchIDNumber(ticket *model.Ticket, presented string) (match, ok bool, err error) {
+	if ticket.Attendee == nil || ticket.Attendee.IDType == "" {
+		return false, false, nil
+	}
+	encrypted, err := s.attendeeRepo.GetEncryptedIDNumber(ticket.OrderItemID, ticket.Sequence)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return false, false, nil
+		}
+		return false, false, err
+	}
+	recorded, err := s.decrypt(encrypted)
+	if err != nil {
+		return false, false, err
+	}
+	return recorded == normalizeIDNumber(presented), true, nil
+}
+
+// RequiresIDCheck reports whether the gate should check the holder's ID.
+func (s *AttendeeService) RequiresIDCheck(eventID uuid.UUID) (bool, error) {
+	requirements, err := s.requirements(eventID)
+	if err != nil {
+		return false, err
+	}
+	return requirements.RequireIDNumber, nil
+}
+
+func (s *AttendeeService) attendee(requirements *model.AttendeeRequirements, input model.AttendeeDetails, sequence int) (*model.Attendee, error) {
+	a := &model.Attendee{
+		Sequence: sequence,
+		Name:     strings.TrimSpace(input.Name),
+		Email:    strings.TrimSpace(input.Email),
+		Phone:    strings.TrimSpace(input.Phone),
+	}
+
+	if requirements.RequireName && a.Name == "" {
+		return nil, errors.New("nama wajib diisi")
+	}
+	if requirements.RequireEmail && a.Email == "" {
+		return nil, errors.New("email wajib diisi")
+	}
+	if requirements.RequirePhone && a.Phone == "" {
+		return nil, errors.New("nomor telepon wajib diisi")
+	}
+
+	if input.BirthDate != "" {
+		birthDate, err := time.Parse("2006-01-02", input.BirthDate)
+		if err != nil || birthDate.After(time.Now()) {
+			return nil, errors.New("tanggal lahir tidak valid")
+		}
+		a.BirthDate = &birthDate
+	} else if requirements.RequireBirthDate {
+		return nil, errors.New("tanggal lahir wajib diisi")
+	}
+
+	idNumber := normalizeIDNumber(input.IDNumber)
+	if idNumber == "" && input.IDType == "" {
+		if requirements.RequireIDNumber {
+			return nil, errors.New("jenis dan nomor identitas wajib diisi")
+		}
+		return a, nil
+	}
+	if idNumber == "" || input.IDType == "" {
+		return nil, errors.New("jenis dan nomor identitas harus diisi bersamaan")
+	}
+	switch input.IDType {
+	case model.IDTypeKTP:
+		if !ktpPattern.MatchString(idNumber) {
+			return nil, errors.New("NIK KTP harus 16 digit")
+		}
+	case model.IDTypePassport:
+		if !passportPattern.MatchString(idNumber) {
+			return nil, errors.New("nomor paspor tidak valid")
+		}
+	}
+
+	encrypted, err := s.encrypt(idNumber)
+	if err != nil {
+		return nil, err
+	}
+	a.IDType = input.IDType
+	a.IDNumber = utils.MaskString(idNumber, 0, 4)
+	a.IDNumberEncrypted = encrypted
+	return a, nil
+}
+
+// requirements returns the event's requirements, or empty ones.
+func (s *AttendeeService) requirements(eventID uuid.UUID) (*model.AttendeeRequirements, error) {
+	requirements, err := s.attendeeRepo.GetRequirements(eventID)
+	if err == sql.ErrNoRows {
+		return &model.AttendeeRequirements{EventID: eventID}, nil
+	}
+	return requirements, err
+}
+
+// encrypt returns "kid:ciphertext" so the key can be rotated.
+func (s *AttendeeService) encrypt(value string) (string, error) {
+	sealed, err := utils.EncryptField(s.keys[s.keyID], value)
+	if err != nil {
+		return "", err
+	}
+	return s.keyID + ":" + sealed, nil
+}
+
+func (s *AttendeeService) decrypt(value string) (string, error) {
+	kid, sealed, ok := strings.Cut(value, ":")
+	if !ok {
+		return "", utils.ErrInvalidCiphertext
+	}
+	key, ok := s.keys[kid]
+	if !ok {
+		return "", fmt.Errorf("kunci enkripsi peserta %s tidak tersedia", kid)
+	}
+	return utils.DecryptField(key, sealed)
+}
+
+// normalizeIDNumber drops the spaces, dots and dashes people type into ID
+// numbers.
+func normalizeIDNumber(value string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '.', '-':
+			return -1
+		}
+		return r
+	}, strings.ToUpper(strings.TrimSpace(value)))
+}
@@ -158,7 +158,7 @@ func (s *AuthService) VerifyEmailToken(token string) (*model.VerificationRespons
 	otp, err := s.userRepo.GetValidOTPByToken(token)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, errors.New("token tidak valid atau sudah expired")
+			return nil, ErrInvalidVerificationToken
 		}
 		return nil, err
 	}
@@ -185,4 +185,97 @@ func (s *AuthService) ResendOTP(req *model.ResendOTPRequest, baseURL string) err
 		Method: req.Method,
 	}
 	return s.SelectVerificationMethod(selectReq, baseURL)
-}
\ No newline at end of file
+}
+
+// RequestStepUp sends a fresh OTP to a verified account, for events that ask
+// buyers to confirm themselves again before checkout.
+func (s *AuthService) RequestStepUp(actor model.Actor, req *model.RequestStepUpRequest) error {
+	user, err := s.userRepo.GetUserByID(actor.UserID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return ErrUserNotFound
+		}
+		return err
+	}
+	if !user.IsVerified {
+		return errors.New("akun belum terverifikasi")
+	}
+
+	// Invalidate old OTPs
+	s.userRepo.InvalidateOldOTPs(user.ID)
+
+	otpCode := utils.GenerateOTP(6)
+	otp := &model.OTPVerification{
+		UserID:    user.ID,
+		OTPCode:   otpCode,
+		Token:     utils.GenerateToken(),
+		Method:    req.Method,
+		ExpiresAt: time.Now().Add(time.Duration(s.config.OTPExpiryMinutes) * time.Minute),
+	}
+	if err := s.userRepo.CreateOTP(otp); err != nil {
+		return err
+	}
+
+	switch req.Method {
+	case "email":
+		if err := s.emailSvc.SendOTPEmail(user.Email, otpCode, user.Name); err != nil {
+			return errors.New("gagal mengirim email: " + err.Error())
+		}
+	case "whatsapp":
+		if err := s.whatsappSvc.SendOTP(user.Phone, otpCode, user.Name); err != nil {
+			return errors.New("gagal mengirim WhatsApp: " + err.Error())
+		}
+	}
+	return nil
+}
+
+// VerifyStepUp checks the OTP and records when the actor last stepped up.
+func (s *AuthService) VerifyStepUp(actor model.Actor, req *model.VerifyStepUpRequest) (*model.StepUpResponse, error) {
+	otp, err := s.userRepo.GetValidOTP(actor.UserID, req.OTP)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.New("OTP tidak valid atau sudah expired")
+		}
+		return nil, err
+	}
+
+	if err := s.userRepo.MarkOTPAsUsed(otp.ID); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if err := s.userRepo.SetStepUpAt(actor.UserID, now); err != nil {
+		return nil, err
+	}
+	return &model.StepUpResponse{StepUpAt: now}, nil
+}
+
+func (s *AuthService) Login(req *model.LoginRequest) (*model.LoginResponse, error) {
+	user, err := s.userRepo.GetUserByEmail(req.Email)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.New("email atau password salah")
+		}
+		return nil, err
+	}
+
+	if !utils.CheckPasswordHash(req.Password, user.Password) {
+		return nil, errors.New("email atau password salah")
+	}
+
+	if !user.IsVerified {
+		return nil, errors.New("akun belum terverifikasi")
+	}
+
+	expiry := time.Duration(s.config.JWTExpiryHours) * time.Hour
+	token, err := utils.GenerateJWT(user.ID.String(), user.Role, s.config.JWTSecret, expiry)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.LoginResponse{
+		Token:     token,
+		ExpiresAt: time.Now().Add(expiry),
+		User:      user,
+	}, nil
+}
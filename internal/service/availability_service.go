@@ -0,0 +1,147 @@
+package service
+
+import (
+	"e-ticketing/config"
+	"e-ticketing/internal/model"
+	"e-ticketing/internal/repository"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// AvailabilityService fans availability changes out to the buyers watching
+// each event.
+//
+// Backpressure: every subscriber has a fixed buffer. Publishing never blocks;
+// when a subscriber's buffer is full the event is dropped for it and it is
+// flagged as lagged. Its stream then discards whatever is still buffered and
+// sends a single resync event, so a slow client costs at most one buffer of
+// memory and catches up with one refetch instead of a backlog.
+type AvailabilityService struct {
+	listener      *repository.AvailabilityListener
+	ticketTypeSvc *TicketTypeService
+	config        *config.Config
+
+	mu          sync.RWMutex
+	subscribers map[uuid.UUID]map[*AvailabilitySubscription]struct{}
+}
+
+func NewAvailabilityService(listener *repository.AvailabilityListener, ticketTypeSvc *TicketTypeService, cfg *config.Config) *AvailabilityService {
+	return &AvailabilityService{
+		listener:      listener,
+		ticketTypeSvc: ticketTypeSvc,
+		config:        cfg,
+		subscribers:   map[uuid.UUID]map[*AvailabilitySubscription]struct{}{},
+	}
+}
+
+// AvailabilitySubscription is one open stream.
+type AvailabilitySubscription struct {
+	eventID uuid.UUID
+	events  chan model.AvailabilityEvent
+	lagged  chan struct{}
+}
+
+func (s *AvailabilitySubscription) Events() <-chan model.AvailabilityEvent {
+	return s.events
+}
+
+// Lagged fires when events were dropped because the buffer was full.
+func (s *AvailabilitySubscription) Lagged() <-chan struct{} {
+	return s.lagged
+}
+
+// Drain discards buffered events, which a resync makes obsolete.
+func (s *AvailabilitySubscription) Drain() {
+	for {
+		select {
+		case <-s.events:
+		default:
+			return
+		}
+	}
+}
+
+// Start relays database notifications to subscribers until stop is closed.
+func (s *AvailabilityService) Start(stop <-chan struct{}) {
+	go s.listener.Run(s.Publish, stop)
+}
+
+// Subscribe opens a stream for an event the actor may see and returns the
+// current stock of its ticket types as the starting point. The stream is
+// registered before the snapshot is read, so no change falls in between.
+func (s *AvailabilityService) Subscribe(actor model.Actor, eventID uuid.UUID) (*AvailabilitySubscription, []model.StockLevel, error) {
+	sub := &AvailabilitySubscription{
+		eventID: eventID,
+		events:  make(chan model.AvailabilityEvent, s.config.AvailabilityStreamBuffer),
+		lagged:  make(chan struct{}, 1),
+	}
+
+	s.mu.Lock()
+	subs := s.subscribers[eventID]
+	if len(subs) >= s.config.AvailabilityMaxSubscribersPerEvent {
+		s.mu.Unlock()
+		return nil, nil, ErrUnavailable
+	}
+	if subs == nil {
+		subs = map[*AvailabilitySubscription]struct{}{}
+		s.subscribers[eventID] = subs
+	}
+	subs[sub] = struct{}{}
+	s.mu.Unlock()
+
+	ticketTypes, err := s.ticketTypeSvc.ListTicketTypes(actor, eventID)
+	if err != nil {
+		s.Unsubscribe(sub)
+		return nil, nil, err
+	}
+
+	snapshot := make([]model.StockLevel, 0, len(ticketTypes))
+	for _, t := range ticketTypes {
+		snapshot = append(snapshot, model.StockLevel{TicketTypeID: t.ID, Available: t.Available})
+	}
+	return sub, snapshot, nil
+}
+
+func (s *AvailabilityService) Unsubscribe(sub *AvailabilitySubscription) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	subs := s.subscribers[sub.eventID]
+	delete(subs, sub)
+	if len(subs) == 0 {
+		delete(s.subscribers, sub.eventID)
+	}
+}
+
+// Publish delivers an event to the event's subscribers without blocking. An
+// event without an event ID (sent after a listener reconnect) makes every
+// subscriber resync.
+func (s *AvailabilityService) Publish(event model.AvailabilityEvent) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if event.EventID == uuid.Nil {
+		for _, subs := range s.subscribers {
+			for sub := range subs {
+				sub.markLagged()
+			}
+		}
+		return
+	}
+
+	for sub := range s.subscribers[event.EventID] {
+		select {
+		case sub.events <- event:
+		default:
+			sub.markLagged()
+		}
+	}
+}
+
+func (s *AvailabilitySubscription) markLagged() {
+	select {
+	case s.lagged <- struct{}{}:
+	default:
+	}
+}
@@ -0,0 +1,446 @@
+package service
+
+import (
+	"database/sql"
+	"e-ticketing/internal/model"
+	"e-ticketing/internal/repository"
+	"e-ticketing/pkg/ticketqr"
+	"errors"
+	"fmt"
+	"html"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// manifestTTL bounds how long a scanner may keep admitting from a
+// downloaded manifest before it must fetch a fresh one.
+const manifestTTL = 12 * time.Hour
+
+type CheckInService struct {
+	checkInRepo  *repository.CheckInRepository
+	userRepo     *repository.UserRepository
+	ticketSvc    *TicketService
+	qrSvc        *TicketQRService
+	eventSvc     *EventService
+	organizerSvc *OrganizerService
+	attendeeSvc  *AttendeeService
+	notifSvc     *NotificationService
+}
+
+func NewCheckInService(checkInRepo *repository.CheckInRepository, userRepo *repository.UserRepository, ticketSvc *TicketService, qrSvc *TicketQRService, eventSvc *EventService, organizerSvc *OrganizerService, attendeeSvc *AttendeeService, notifSvc *NotificationService) *CheckInService {
+	return &CheckInService{
+		checkInRepo:  checkInRepo,
+		userRepo:     userRepo,
+		ticketSvc:    ticketSvc,
+		qrSvc:        qrSvc,
+		eventSvc:     eventSvc,
+		organizerSvc: organizerSvc,
+		attendeeSvc:  attendeeSvc,
+		notifSvc:     notifSvc,
+	}
+}
+
+// RegisterDevice adds a scanner to one gate of an event managed by the actor.
+func (s *CheckInService) RegisterDevice(actor model.Actor, eventID uuid.UUID, req *model.RegisterGateDeviceRequest) (*model.GateDevice, error) {
+	if _, err := s.eventSvc.AuthorizeEvent(actor, eventID); err != nil {
+		return nil, err
+	}
+
+	device := &model.GateDevice{
+		EventID:      eventID,
+		Name:         req.Name,
+		Gate:         req.Gate,
+		RegisteredBy: actor.UserID,
+	}
+	if err := s.checkInRepo.CreateGateDevice(device); err != nil {
+		if repository.IsUniqueViolation(err) {
+			return nil, fmt.Errorf("nama perangkat sudah digunakan untuk event ini: %w", ErrConflict)
+		}
+		return nil, err
+	}
+	return device, nil
+}
+
+func (s *CheckInService) ListDevices(actor model.Actor, eventID uuid.UUID) ([]model.GateDevice, error) {
+	if _, err := s.eventSvc.AuthorizeEvent(actor, eventID); err != nil {
+		return nil, err
+	}
+	return s.checkInRepo.ListGateDevices(eventID)
+}
+
+// DeactivateDevice stops a lost or retired scanner from admitting anyone.
+// Its past check-ins stay attributed to it.
+func (s *CheckInService) DeactivateDevice(actor model.Actor, eventID, deviceID uuid.UUID) error {
+	if _, err := s.eventSvc.AuthorizeEvent(actor, eventID); err != nil {
+		return err
+	}
+
+	device, err := s.getDevice(deviceID)
+	if err != nil {
+		return err
+	}
+	if device.EventID != eventID {
+		return ErrGateDeviceNotFound
+	}
+	return s.checkInRepo.DeactivateGateDevice(deviceID)
+}
+
+// Scan verifies a QR payload and admits the ticket once. A scan that cannot
+// admit the ticket is not an error: it is reported as a duplicate (with the
+// first check-in) or a rejection so the gate can show the reason.
+func (s *CheckInService) Scan(actor model.Actor, req *model.ScanRequest) (*model.ScanResult, error) {
+	device, err := s.activeDevice(req.DeviceID)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+
+	claims, err := s.qrSvc.Verify(req.Payload)
+	if err != nil {
+		return rejected(err.Error(), nil), nil
+	}
+	if claims.EventID != device.EventID.String() {
+		return rejected("tiket untuk event lain", nil), nil
+	}
+
+	ticketID, err := uuid.Parse(claims.TicketID)
+	if err != nil {
+		return rejected("tiket tidak ditemukan", nil), nil
+	}
+	ticket, err := s.ticketSvc.GetTicket(ticketID)
+	if err != nil {
+		if errors.Is(err, ErrTicketNotFound) {
+			return rejected("tiket tidak ditemukan", nil), nil
+		}
+		return nil, err
+	}
+
+	// A rename or transfer re-issues the QR, so older codes stop working
+	if claims.HolderName != ticket.HolderName {
+		return rejected("QR sudah tidak berlaku, minta pemegang membuka ulang tiketnya", ticket), nil
+	}
+
+	// Named tickets: staff may enter the number of the ID presented, and a
+	// mismatch keeps the ticket unused
+	idCheck, err := s.attendeeSvc.RequiresIDCheck(ticket.EventID)
+	if err != nil {
+		return nil, err
+	}
+	var idMatch *bool
+	if req.IDNumber != "" {
+		match, ok, err := s.attendeeSvc.MatchIDNumber(ticket, req.IDNumber)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			idMatch = &match
+		}
+	}
+
+	var result *model.ScanResult
+	if idMatch != nil && !*idMatch {
+		result = rejected("nomor identitas tidak cocok dengan data tiket", ticket)
+	} else if result, err = s.admit(actor, device, ticket, now); err != nil {
+		return nil, err
+	}
+	if result.Ticket != nil {
+		result.Ticket.IDCheckRequired = idCheck
+		result.Ticket.IDMatch = idMatch
+	}
+	return result, nil
+}
+
+// admit checks the ticket in unless it was used already, is not valid or is
+// on its way to someone else through resale or a transfer.
+func (s *CheckInService) admit(actor model.Actor, device *model.GateDevice, ticket *model.Ticket, now time.Time) (*model.ScanResult, error) {
+	switch ticket.Status {
+	case model.TicketStatusValid:
+	case model.TicketStatusUsed:
+		return s.duplicate(ticket)
+	default:
+		return rejected(fmt.Sprintf("tiket berstatus %s", ticket.Status), ticket), nil
+	}
+
+	checkIn, admitted, err := s.checkInRepo.CheckInTicket(ticket.ID, device, actor.UserID, now)
+	if errors.Is(err, repository.ErrTicketInTransit) {
+		return rejected("tiket sedang dijual ulang atau ditransfer", ticket), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !admitted {
+		// Lost the race against another gate scanning the same ticket
+		return s.duplicate(ticket)
+	}
+
+	ticket.Status = model.TicketStatusUsed
+	return &model.ScanResult{
+		Result:  model.ScanAdmitted,
+		Ticket:  scannedTicket(ticket),
+		CheckIn: checkIn,
+	}, nil
+}
+
+// Manifest exports the tickets of the device's event as a signed document
+// so the scanner can keep admitting tickets when the network is down.
+func (s *CheckInService) Manifest(deviceID string) (*ticketqr.Document, error) {
+	device, err := s.activeDevice(deviceID)
+	if err != nil {
+		return nil, err
+	}
+
+	valid, used, err := s.checkInRepo.ListManifestTickets(device.EventID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	return s.qrSvc.SignDocument(&model.CheckInManifest{
+		EventID:     device.EventID,
+		DeviceID:    device.ID,
+		Gate:        device.Gate,
+		GeneratedAt: now.Unix(),
+		ExpiresAt:   now.Add(manifestTTL).Unix(),
+		Valid:       ticketqr.EncodeIDSet(idBytes(valid)),
+		Used:        ticketqr.EncodeIDSet(idBytes(used)),
+		ValidCount:  len(valid),
+		UsedCount:   len(used),
+	})
+}
+
+// SyncScans uploads scans a device made while offline. Scans are applied in
+// scan time order and re-uploading a batch is harmless. Newly found
+// suspicious scans are reported to the organizer by email.
+func (s *CheckInService) SyncScans(actor model.Actor, req *model.SyncScansRequest) (*model.SyncResult, error) {
+	device, err := s.activeDevice(req.DeviceID)
+	if err != nil {
+		return nil, err
+	}
+
+	inputs := make([]model.OfflineScanInput, len(req.Scans))
+	copy(inputs, req.Scans)
+	sort.SliceStable(inputs, func(i, j int) bool {
+		return inputs[i].ScannedAt.Before(inputs[j].ScannedAt)
+	})
+
+	result := &model.SyncResult{Scans: []model.OfflineScan{}}
+	suspicious := []model.OfflineScan{}
+	for _, in := range inputs {
+		ticketID, err := uuid.Parse(in.TicketID)
+		if err != nil {
+			return nil, errors.New("ticket ID tidak valid")
+		}
+
+		scan := &model.OfflineScan{
+			GateDeviceID: device.ID,
+			EventID:      device.EventID,
+			TicketID:     ticketID,
+			ClientScanID: in.ClientScanID,
+			ScannedBy:    actor.UserID,
+			// Postgres keeps microseconds; truncating keeps comparisons stable
+			ScannedAt: in.ScannedAt.UTC().Truncate(time.Microsecond),
+		}
+		created, err := s.checkInRepo.SyncOfflineScan(scan)
+		if err != nil {
+			return nil, err
+		}
+
+		result.Processed++
+		switch scan.Outcome {
+		case model.ScanAdmitted:
+			result.Admitted++
+		case model.ScanDuplicate:
+			result.Duplicates++
+		default:
+			result.Rejected++
+		}
+		if scan.Suspicious {
+			result.Suspicious++
+			if created {
+				suspicious = append(suspicious, *scan)
+			}
+		}
+		result.Scans = append(result.Scans, *scan)
+	}
+
+	if len(suspicious) > 0 {
+		go s.reportSuspicious(device, suspicious)
+	}
+	return result, nil
+}
+
+// SuspiciousScans lists flagged offline scans for the event's organizer.
+func (s *CheckInService) SuspiciousScans(actor model.Actor, eventID uuid.UUID, req *model.PaginationQuery) (*model.PaginatedResponse, error) {
+	if _, err := s.eventSvc.AuthorizeEvent(actor, eventID); err != nil {
+		return nil, err
+	}
+	req.Normalize()
+
+	scans, total, err := s.checkInRepo.ListSuspiciousScans(eventID, req.Limit, req.Offset())
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.PaginatedResponse{
+		Items: scans,
+		Pagination: model.PaginationMeta{
+			Page:  req.Page,
+			Limit: req.Limit,
+			Total: total,
+		},
+	}, nil
+}
+
+// Attendance is visible to gate staff and to the event's organizer.
+func (s *CheckInService) Attendance(actor model.Actor, eventID uuid.UUID) (*model.Attendance, error) {
+	if actor.CanCheckIn() {
+		if _, err := s.eventSvc.GetEvent(eventID); err != nil {
+			return nil, err
+		}
+	} else if _, err := s.eventSvc.AuthorizeEvent(actor, eventID); err != nil {
+		return nil, err
+	}
+	return s.checkInRepo.GetAttendance(eventID)
+}
+
+// activeDevice loads a scanner that may still admit tickets and records that
+// it was seen.
+func (s *CheckInService) activeDevice(rawID string) (*model.GateDevice, error) {
+	deviceID, err := uuid.Parse(rawID)
+	if err != nil {
+		return nil, ErrGateDeviceNotFound
+	}
+	device, err := s.getDevice(deviceID)
+	if err != nil {
+		return nil, err
+	}
+	if !device.IsActive {
+		return nil, fmt.Errorf("perangkat gate tidak aktif: %w", ErrForbidden)
+	}
+
+	if err := s.checkInRepo.TouchGateDevice(device.ID, time.Now()); err != nil {
+		log.Printf("Failed to update gate device last seen: %v", err)
+	}
+	return device, nil
+}
+
+// reportSuspicious notifies the organizer owner, at the organizer's address
+// when it has one, about scans that look like a copied ticket.
+func (s *CheckInService) reportSuspicious(device *model.GateDevice, scans []model.OfflineScan) {
+	event, err := s.eventSvc.GetEvent(device.EventID)
+	if err != nil {
+		log.Printf("Failed to report suspicious scans: %v", err)
+		return
+	}
+	organizer, err := s.organizerSvc.GetOrganizer(event.OrganizerID)
+	if err != nil {
+		log.Printf("Failed to report suspicious scans: %v", err)
+		return
+	}
+
+	owner, err := s.userRepo.GetUserByID(organizer.OwnerID)
+	if err != nil {
+		log.Printf("Failed to report suspicious scans: %v", err)
+		return
+	}
+	recipient := *owner
+	if organizer.Email != "" {
+		recipient.Email = organizer.Email
+	}
+
+	var rows strings.Builder
+	for _, scan := range scans {
+		fmt.Fprintf(&rows, "<tr><td>%s</td><td>%s</td><td>%s</td></tr>",
+			scan.TicketID, scan.ScannedAt.Format("2006-01-02 15:04:05"), html.EscapeString(scan.Reason))
+	}
+
+	body := fmt.Sprintf(`
+		<html>
+		<body style="font-family: Arial, sans-serif;">
+			<h2>Scan Mencurigakan Terdeteksi</h2>
+			<p>Perangkat <strong>%s</strong> di gate <strong>%s</strong> untuk event <strong>%s</strong> mengunggah %d scan offline yang mencurigakan, misalnya tiket yang sama dipakai di gate berbeda.</p>
+			<table border="1" cellpadding="6" style="border-collapse: collapse;">
+				<tr><th>Tiket</th><th>Waktu Scan</th><th>Keterangan</th></tr>
+				%s
+			</table>
+			<p>Salam,<br>Tim E-Ticketing</p>
+		</body>
+		</html>
+	`, html.EscapeString(device.Name), html.EscapeString(device.Gate), html.EscapeString(event.Title), len(scans), rows.String())
+
+	whatsapp := fmt.Sprintf(
+		"Halo!\n\nPerangkat *%s* di gate *%s* untuk *%s* mengunggah %d scan offline yang mencurigakan. Periksa riwayat check-in event ini.\n\n- Tim E-Ticketing",
+		device.Name, device.Gate, event.Title, len(scans),
+	)
+
+	if err := s.notifSvc.Notify(&recipient, &model.Notification{
+		Category:     model.NotificationTransactional,
+		Template:     "suspicious_scans",
+		Subject:      fmt.Sprintf("Scan mencurigakan - %s", event.Title),
+		EmailBody:    body,
+		WhatsAppBody: whatsapp,
+	}); err != nil {
+		log.Printf("Failed to report suspicious scans: %v", err)
+	}
+}
+
+func idBytes(ids []uuid.UUID) [][16]byte {
+	out := make([][16]byte, len(ids))
+	for i, id := range ids {
+		out[i] = id
+	}
+	return out
+}
+
+func (s *CheckInService) getDevice(id uuid.UUID) (*model.GateDevice, error) {
+	device, err := s.checkInRepo.GetGateDevice(id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrGateDeviceNotFound
+		}
+		return nil, err
+	}
+	return device, nil
+}
+
+func (s *CheckInService) duplicate(ticket *model.Ticket) (*model.ScanResult, error) {
+	first, err := s.checkInRepo.GetCheckInByTicket(ticket.ID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return rejected("tiket sudah digunakan", ticket), nil
+		}
+		return nil, err
+	}
+
+	ticket.Status = model.TicketStatusUsed
+	return &model.ScanResult{
+		Result:       model.ScanDuplicate,
+		Reason:       fmt.Sprintf("tiket sudah discan pada %s di %s", first.ScannedAt.Format("15:04:05"), first.Gate),
+		Ticket:       scannedTicket(ticket),
+		FirstCheckIn: first,
+	}, nil
+}
+
+func rejected(reason string, ticket *model.Ticket) *model.ScanResult {
+	result := &model.ScanResult{Result: model.ScanRejected, Reason: reason}
+	if ticket != nil {
+		result.Ticket = scannedTicket(ticket)
+	}
+	return result
+}
+
+func scannedTicket(t *model.Ticket) *model.ScannedTicket {
+	return &model.ScannedTicket{
+		ID:             t.ID,
+		Code:           t.Code,
+		HolderName:     t.HolderName,
+		TicketTypeName: t.TicketTypeName,
+		EventTitle:     t.EventTitle,
+		Status:         t.Status,
+		Attendee:       t.Attendee,
+	}
+}
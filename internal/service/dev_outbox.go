@@ -0,0 +1,141 @@
+package service
+
+import (
+	"e-ticketing/internal/model"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DevOutbox replaces real SMTP/WhatsApp delivery in development. Every
+// rendered message is written as a JSON file so the registration flow can be
+// completed offline and inspected through GET /dev/outbox.
+type DevOutbox struct {
+	dir string
+	mu  sync.Mutex
+}
+
+func NewDevOutbox(dir string) (*DevOutbox, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &DevOutbox{dir: dir}, nil
+}
+
+func (o *DevOutbox) Write(msg *model.OutboxMessage) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	msg.ID = uuid.New().String()
+	msg.CreatedAt = time.Now()
+
+	data, err := json.MarshalIndent(msg, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	// Zero-padded nanosecond prefix keeps lexical order equal to send order
+	name := fmt.Sprintf("%020d-%s-%s.json", msg.CreatedAt.UnixNano(), msg.Channel, msg.ID[:8])
+	return os.WriteFile(filepath.Join(o.dir, name), data, 0o644)
+}
+
+// Prune deletes messages written before cutoff and returns how many.
+func (o *DevOutbox) Prune(cutoff time.Time) (int, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	entries, err := os.ReadDir(o.dir)
+	if err != nil {
+		return 0, err
+	}
+
+	deleted := 0
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".json") {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil || !info.ModTime().Before(cutoff) {
+			continue
+		}
+		if err := os.Remove(filepath.Join(o.dir, e.Name())); err != nil {
+			return deleted, err
+		}
+		deleted++
+	}
+	return deleted, nil
+}
+
+// StartPruner deletes messages older than retention once per interval until
+// stop is closed, so OTPs and verification links do not pile up on disk.
+func (o *DevOutbox) StartPruner(retention, interval time.Duration, stop <-chan struct{}) {
+	if retention <= 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			cutoff := time.Now().Add(-retention)
+			if deleted, err := o.Prune(cutoff); err != nil {
+				log.Printf("Failed to prune dev outbox: %v", err)
+			} else if deleted > 0 {
+				log.Printf("Pruned %d dev outbox messages older than %s", deleted, cutoff.Format(time.RFC3339))
+			}
+
+			select {
+			case <-ticker.C:
+			case <-stop:
+				return
+			}
+		}
+	}()
+}
+
+// List returns the newest messages first, optionally only those sent to the
+// given recipient.
+func (o *DevOutbox) List(limit int, recipient string) ([]model.OutboxMessage, error) {
+	entries, err := os.ReadDir(o.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(entries))
+	for _, e := range entries {
+		if !e.IsDir() && strings.HasSuffix(e.Name(), ".json") {
+			names = append(names, e.Name())
+		}
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(names)))
+
+	messages := []model.OutboxMessage{}
+	for _, name := range names {
+		if len(messages) >= limit {
+			break
+		}
+
+		data, err := os.ReadFile(filepath.Join(o.dir, name))
+		if err != nil {
+			continue
+		}
+		var msg model.OutboxMessage
+		if err := json.Unmarshal(data, &msg); err != nil {
+			continue
+		}
+		if recipient != "" && !strings.EqualFold(msg.Recipient, recipient) {
+			continue
+		}
+		messages = append(messages, msg)
+	}
+	return messages, nil
+}
@@ -0,0 +1,300 @@
+package service
+
+import (
+	"e-ticketing/config"
+	"errors"
+	"log"
+	"net/mail"
+	"net/textproto"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"gopkg.in/gomail.v2"
+)
+
+const emailIdleTimeout = 60 * time.Second
+
+var (
+	ErrEmailQueueFull   = errors.New("antrian email penuh")
+	ErrEmailSenderClose = errors.New("email sender sudah ditutup")
+	ErrEmailTimeout     = errors.New("pengiriman email timeout")
+)
+
+// emailJob is a single message waiting in the send queue. The result channel
+// is buffered so a worker never blocks on a caller that already gave up.
+type emailJob struct {
+	message *gomail.Message
+	result  chan error
+}
+
+// EmailSenderStats is a snapshot of the sender queue and delivery counters.
+type EmailSenderStats struct {
+	QueueDepth    int   `json:"queue_depth"`
+	QueueCapacity int   `json:"queue_capacity"`
+	Workers       int   `json:"workers"`
+	ActiveConns   int64 `json:"active_connections"`
+	Sent          int64 `json:"sent"`
+	Failed        int64 `json:"failed"`
+	Reconnects    int64 `json:"reconnects"`
+}
+
+// EmailSender keeps a pool of authenticated SMTP connections and drains a
+// shared send queue. Each worker owns one connection, sends queued messages in
+// batches over it and redials when the server drops the connection.
+type EmailSender struct {
+	dialer    *gomail.Dialer
+	queue     chan *emailJob
+	limiter   <-chan time.Time
+	ticker    *time.Ticker
+	batchSize int
+	timeout   time.Duration
+	workers   int
+
+	activeConns int64
+	sent        int64
+	failed      int64
+	reconnects  int64
+
+	mu     sync.RWMutex
+	closed bool
+	wg     sync.WaitGroup
+}
+
+func NewEmailSender(cfg *config.Config) *EmailSender {
+	workers := cfg.SMTPPoolSize
+	if workers < 1 {
+		workers = 1
+	}
+	batchSize := cfg.SMTPBatchSize
+	if batchSize < 1 {
+		batchSize = 1
+	}
+	queueSize := cfg.SMTPQueueSize
+	if queueSize < 1 {
+		queueSize = 1
+	}
+
+	s := &EmailSender{
+		dialer:    gomail.NewDialer(cfg.SMTPHost, cfg.SMTPPort, cfg.SMTPUser, cfg.SMTPPassword),
+		queue:     make(chan *emailJob, queueSize),
+		batchSize: batchSize,
+		timeout:   time.Duration(cfg.SMTPSendTimeout) * time.Second,
+		workers:   workers,
+	}
+
+	// Provider rate limit is shared by every connection in the pool. A limit
+	// above one message per nanosecond is no limit at all.
+	if cfg.SMTPRateLimit > 0 {
+		if interval := time.Second / time.Duration(cfg.SMTPRateLimit); interval > 0 {
+			s.ticker = time.NewTicker(interval)
+			s.limiter = s.ticker.C
+		}
+	}
+
+	for i := 0; i < workers; i++ {
+		s.wg.Add(1)
+		go s.worker()
+	}
+
+	return s
+}
+
+// Send queues the message and waits until a worker has delivered it, so
+// callers still see SMTP errors just like with DialAndSend.
+func (s *EmailSender) Send(m *gomail.Message) error {
+	job := &emailJob{message: m, result: make(chan error, 1)}
+
+	s.mu.RLock()
+	if s.closed {
+		s.mu.RUnlock()
+		return ErrEmailSenderClose
+	}
+	select {
+	case s.queue <- job:
+	default:
+		s.mu.RUnlock()
+		return ErrEmailQueueFull
+	}
+	s.mu.RUnlock()
+
+	if s.timeout <= 0 {
+		return <-job.result
+	}
+
+	select {
+	case err := <-job.result:
+		return err
+	case <-time.After(s.timeout):
+		return ErrEmailTimeout
+	}
+}
+
+// Stats returns the current queue depth and delivery counters.
+func (s *EmailSender) Stats() EmailSenderStats {
+	return EmailSenderStats{
+		QueueDepth:    len(s.queue),
+		QueueCapacity: cap(s.queue),
+		Workers:       s.workers,
+		ActiveConns:   atomic.LoadInt64(&s.activeConns),
+		Sent:          atomic.LoadInt64(&s.sent),
+		Failed:        atomic.LoadInt64(&s.failed),
+		Reconnects:    atomic.LoadInt64(&s.reconnects),
+	}
+}
+
+// Close stops accepting new messages, flushes the queue and closes every
+// pooled connection.
+func (s *EmailSender) Close() {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return
+	}
+	s.closed = true
+	close(s.queue)
+	s.mu.Unlock()
+
+	s.wg.Wait()
+	if s.ticker != nil {
+		s.ticker.Stop()
+	}
+}
+
+func (s *EmailSender) worker() {
+	defer s.wg.Done()
+
+	var conn gomail.SendCloser
+	defer func() {
+		if conn != nil {
+			conn.Close()
+			atomic.AddInt64(&s.activeConns, -1)
+		}
+	}()
+
+	idle := time.NewTimer(emailIdleTimeout)
+	defer idle.Stop()
+
+	for {
+		var job *emailJob
+		select {
+		case j, ok := <-s.queue:
+			if !ok {
+				return
+			}
+			job = j
+		case <-idle.C:
+			// Servers drop idle sessions anyway, so release them first
+			if conn != nil {
+				conn.Close()
+				conn = nil
+				atomic.AddInt64(&s.activeConns, -1)
+			}
+			idle.Reset(emailIdleTimeout)
+			continue
+		}
+
+		batch := []*emailJob{job}
+	fill:
+		for len(batch) < s.batchSize {
+			select {
+			case next, ok := <-s.queue:
+				if !ok {
+					break fill
+				}
+				batch = append(batch, next)
+			default:
+				break fill
+			}
+		}
+
+		for _, j := range batch {
+			if s.limiter != nil {
+				<-s.limiter
+			}
+			j.result <- s.deliver(&conn, j.message)
+		}
+		idle.Reset(emailIdleTimeout)
+	}
+}
+
+// deliver sends over the worker connection, dialing lazily and retrying once
+// on a fresh connection when the pooled one turns out to be dead or the
+// server answers with a transient 4xx. A permanent 5xx rejection, e.g. of an
+// unknown recipient, is not resent.
+func (s *EmailSender) deliver(conn *gomail.SendCloser, m *gomail.Message) error {
+	from, to, err := envelope(m)
+	if err != nil {
+		atomic.AddInt64(&s.failed, 1)
+		return err
+	}
+
+	for attempt := 0; attempt < 2; attempt++ {
+		if *conn == nil {
+			*conn, err = s.dialer.Dial()
+			if err != nil {
+				*conn = nil
+				continue
+			}
+			atomic.AddInt64(&s.activeConns, 1)
+			if attempt > 0 {
+				atomic.AddInt64(&s.reconnects, 1)
+			}
+		}
+
+		err = (*conn).Send(from, to, m)
+		if err == nil {
+			atomic.AddInt64(&s.sent, 1)
+			return nil
+		}
+
+		// The session may be left mid-transaction either way, so start the
+		// next message on a fresh connection
+		(*conn).Close()
+		*conn = nil
+		atomic.AddInt64(&s.activeConns, -1)
+
+		if isPermanentSMTPError(err) {
+			break
+		}
+		log.Printf("SMTP send failed, reconnecting: %v", err)
+	}
+
+	atomic.AddInt64(&s.failed, 1)
+	return err
+}
+
+// isPermanentSMTPError reports whether the server rejected the message with
+// a 5xx reply, which resending will not change.
+func isPermanentSMTPError(err error) bool {
+	var reply *textproto.Error
+	return errors.As(err, &reply) && reply.Code >= 500
+}
+
+// envelope returns the SMTP sender and recipients of the message, as
+// gomail.Send would, without hiding the server's reply behind its own error.
+func envelope(m *gomail.Message) (string, []string, error) {
+	from := m.GetHeader("Sender")
+	if len(from) == 0 {
+		from = m.GetHeader("From")
+	}
+	if len(from) == 0 {
+		return "", nil, errors.New(`email tidak memiliki header "From"`)
+	}
+	sender, err := mail.ParseAddress(from[0])
+	if err != nil {
+		return "", nil, err
+	}
+
+	to := []string{}
+	for _, field := range []string{"To", "Cc", "Bcc"} {
+		for _, raw := range m.GetHeader(field) {
+			addr, err := mail.ParseAddress(raw)
+			if err != nil {
+				return "", nil, err
+			}
+			to = append(to, addr.Address)
+		}
+	}
+	return sender.Address, to, nil
+}
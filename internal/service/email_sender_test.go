@@ -0,0 +1,244 @@
+package service
+
+import (
+	"bufio"
+	"e-ticketing/config"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"gopkg.in/gomail.v2"
+)
+
+// fakeSMTPServer speaks just enough SMTP for the sender: no TLS, no auth.
+// Recipients listed in replies get that reply to RCPT once, then 250.
+type fakeSMTPServer struct {
+	listener net.Listener
+
+	mu          sync.Mutex
+	replies     map[string][]string
+	connections int
+	rcptTries   map[string]int
+	delivered   []string
+}
+
+func newFakeSMTPServer(t *testing.T, replies map[string][]string) *fakeSMTPServer {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	if replies == nil {
+		replies = map[string][]string{}
+	}
+	srv := &fakeSMTPServer{listener: listener, replies: replies, rcptTries: map[string]int{}}
+	go srv.serve()
+	t.Cleanup(func() { listener.Close() })
+	return srv
+}
+
+func (srv *fakeSMTPServer) config() *config.Config {
+	host, port, _ := net.SplitHostPort(srv.listener.Addr().String())
+	portNum, _ := strconv.Atoi(port)
+	return &config.Config{
+		SMTPHost:      host,
+		SMTPPort:      portNum,
+		SMTPPoolSize:  1,
+		SMTPQueueSize: 10,
+		SMTPBatchSize: 5,
+	}
+}
+
+func (srv *fakeSMTPServer) serve() {
+	for {
+		conn, err := srv.listener.Accept()
+		if err != nil {
+			return
+		}
+		srv.mu.Lock()
+		srv.connections++
+		srv.mu.Unlock()
+		go srv.handle(conn)
+	}
+}
+
+func (srv *fakeSMTPServer) handle(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	reply := func(line string) { conn.Write([]byte(line + "\r\n")) }
+
+	reply("220 fake ESMTP")
+	var rcpt string
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		cmd := strings.ToUpper(strings.TrimSpace(line))
+		switch {
+		case strings.HasPrefix(cmd, "EHLO"), strings.HasPrefix(cmd, "HELO"):
+			reply("250 fake")
+		case strings.HasPrefix(cmd, "MAIL FROM"):
+			reply("250 ok")
+		case strings.HasPrefix(cmd, "RCPT TO"):
+			rcpt = strings.ToLower(strings.Trim(strings.TrimSpace(line)[len("RCPT TO:"):], "<>"))
+			srv.mu.Lock()
+			srv.rcptTries[rcpt]++
+			answer := "250 ok"
+			if queued := srv.replies[rcpt]; len(queued) > 0 {
+				answer = queued[0]
+				srv.replies[rcpt] = queued[1:]
+			}
+			srv.mu.Unlock()
+			reply(answer)
+		case cmd == "DATA":
+			reply("354 go ahead")
+			for {
+				data, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if data == ".\r\n" {
+					break
+				}
+			}
+			srv.mu.Lock()
+			srv.delivered = append(srv.delivered, rcpt)
+			srv.mu.Unlock()
+			reply("250 queued")
+		case cmd == "RSET", cmd == "NOOP":
+			reply("250 ok")
+		case cmd == "QUIT":
+			reply("221 bye")
+			return
+		default:
+			reply("502 not implemented")
+		}
+	}
+}
+
+func (srv *fakeSMTPServer) stats() (connections int, rcptTries map[string]int, delivered []string) {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	tries := map[string]int{}
+	for k, v := range srv.rcptTries {
+		tries[k] = v
+	}
+	return srv.connections, tries, append([]string(nil), srv.delivered...)
+}
+
+func testMessage(to string) *gomail.Message {
+	m := gomail.NewMessage()
+	m.SetHeader("From", "noreply@example.com")
+	m.SetHeader("To", to)
+	m.SetHeader("Subject", "Test")
+	m.SetBody("text/plain", "hello")
+	return m
+}
+
+func TestEmailSenderReusesPooledConnection(t *testing.T) {
+	srv := newFakeSMTPServer(t, nil)
+	sender := NewEmailSender(srv.config())
+	defer sender.Close()
+
+	for i := 0; i < 3; i++ {
+		if err := sender.Send(testMessage("user" + strconv.Itoa(i) + "@example.com")); err != nil {
+			t.Fatalf("send %d: %v", i, err)
+		}
+	}
+
+	connections, _, delivered := srv.stats()
+	if connections != 1 {
+		t.Errorf("connections = %d, want 1", connections)
+	}
+	if len(delivered) != 3 {
+		t.Errorf("delivered = %d, want 3", len(delivered))
+	}
+	if stats := sender.Stats(); stats.Sent != 3 || stats.Failed != 0 {
+		t.Errorf("stats = %+v, want 3 sent and none failed", stats)
+	}
+}
+
+func TestEmailSenderDoesNotResendPermanentRejection(t *testing.T) {
+	srv := newFakeSMTPServer(t, map[string][]string{
+		"unknown@example.com": {"550 no such user"},
+	})
+	sender := NewEmailSender(srv.config())
+	defer sender.Close()
+
+	if err := sender.Send(testMessage("unknown@example.com")); err == nil {
+		t.Fatal("send to rejected recipient succeeded")
+	}
+
+	_, tries, delivered := srv.stats()
+	if tries["unknown@example.com"] != 1 {
+		t.Errorf("RCPT attempts = %d, want 1", tries["unknown@example.com"])
+	}
+	if len(delivered) != 0 {
+		t.Errorf("delivered = %v, want none", delivered)
+	}
+	if stats := sender.Stats(); stats.Failed != 1 || stats.Reconnects != 0 {
+		t.Errorf("stats = %+v, want 1 failed and no reconnects", stats)
+	}
+
+	// The worker keeps going with the next message
+	if err := sender.Send(testMessage("user@example.com")); err != nil {
+		t.Fatalf("send after rejection: %v", err)
+	}
+}
+
+func TestEmailSenderRetriesTransientFailure(t *testing.T) {
+	srv := newFakeSMTPServer(t, map[string][]string{
+		"busy@example.com": {"451 try again later"},
+	})
+	sender := NewEmailSender(srv.config())
+	defer sender.Close()
+
+	if err := sender.Send(testMessage("busy@example.com")); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+
+	connections, tries, delivered := srv.stats()
+	if tries["busy@example.com"] != 2 {
+		t.Errorf("RCPT attempts = %d, want 2", tries["busy@example.com"])
+	}
+	if connections != 2 {
+		t.Errorf("connections = %d, want 2", connections)
+	}
+	if len(delivered) != 1 {
+		t.Errorf("delivered = %d, want 1", len(delivered))
+	}
+	if stats := sender.Stats(); stats.Reconnects != 1 || stats.Sent != 1 {
+		t.Errorf("stats = %+v, want 1 reconnect and 1 sent", stats)
+	}
+}
+
+func TestEmailSenderFailsWhenServerUnreachable(t *testing.T) {
+	srv := newFakeSMTPServer(t, nil)
+	cfg := srv.config()
+	srv.listener.Close()
+
+	sender := NewEmailSender(cfg)
+	defer sender.Close()
+
+	if err := sender.Send(testMessage("user@example.com")); err == nil {
+		t.Fatal("send without a server succeeded")
+	}
+	if stats := sender.Stats(); stats.Failed != 1 || stats.ActiveConns != 0 {
+		t.Errorf("stats = %+v, want 1 failed and no open connections", stats)
+	}
+}
+
+func TestEmailSenderIgnoresUnreachableRateLimit(t *testing.T) {
+	srv := newFakeSMTPServer(t, nil)
+	cfg := srv.config()
+	cfg.SMTPRateLimit = 2_000_000_000
+
+	sender := NewEmailSender(cfg)
+	defer sender.Close()
+
+	if err := sender.Send(testMessage("user@example.com")); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+}
@@ -2,25 +2,27 @@ package service
 
 import (
 	"e-ticketing/config"
+	"e-ticketing/internal/model"
 	"fmt"
+	"time"
 
 	"gopkg.in/gomail.v2"
 )
 
 type EmailService struct {
 	config *config.Config
+	sender *EmailSender
+	logSvc *NotificationLogService
+	outbox *DevOutbox
 }
 
-func NewEmailService(cfg *config.Config) *EmailService {
-	return &EmailService{config: cfg}
+// NewEmailService delivers through sender, or through outbox when it is
+// non-nil (development delivery mode).
+func NewEmailService(cfg *config.Config, sender *EmailSender, logSvc *NotificationLogService, outbox *DevOutbox) *EmailService {
+	return &EmailService{config: cfg, sender: sender, logSvc: logSvc, outbox: outbox}
 }
 
 func (s *EmailService) SendOTPEmail(to, otp, name string) error {
-	m := gomail.NewMessage()
-	m.SetHeader("From", s.config.SMTPFrom)
-	m.SetHeader("To", to)
-	m.SetHeader("Subject", "Kode OTP Verifikasi - E-Ticketing")
-
 	body := fmt.Sprintf(`
 		<html>
 		<body style="font-family: Arial, sans-serif; padding: 20px;">
@@ -37,19 +39,10 @@ func (s *EmailService) SendOTPEmail(to, otp, name string) error {
 		</html>
 	`, name, otp, s.config.OTPExpiryMinutes)
 
-	m.SetBody("text/html", body)
-
-	d := gomail.NewDialer(s.config.SMTPHost, s.config.SMTPPort, s.config.SMTPUser, s.config.SMTPPassword)
-
-	return d.DialAndSend(m)
+	return s.deliver(to, "otp", "Kode OTP Verifikasi - E-Ticketing", body, nil, map[string]string{"otp": otp})
 }
 
 func (s *EmailService) SendVerificationLinkEmail(to, link, name string) error {
-	m := gomail.NewMessage()
-	m.SetHeader("From", s.config.SMTPFrom)
-	m.SetHeader("To", to)
-	m.SetHeader("Subject", "Verifikasi Email - E-Ticketing")
-
 	body := fmt.Sprintf(`
 		<html>
 		<body style="font-family: Arial, sans-serif; padding: 20px;">
@@ -69,9 +62,49 @@ func (s *EmailService) SendVerificationLinkEmail(to, link, name string) error {
 		</html>
 	`, name, link, link, s.config.OTPExpiryMinutes)
 
+	return s.deliver(to, "verification_link", "Verifikasi Email - E-Ticketing", body, nil, map[string]string{"link": link})
+}
+
+// SendEmail sends an arbitrary HTML email. Extra headers are applied as-is,
+// e.g. List-Unsubscribe for marketing mail.
+func (s *EmailService) SendEmail(to, template, subject, body string, headers map[string]string) error {
+	return s.deliver(to, template, subject, body, headers, nil)
+}
+
+// deliver sends through the pooled sender, or writes to the development
+// outbox when enabled, and records the attempt in the notification log.
+func (s *EmailService) deliver(to, template, subject, body string, headers, meta map[string]string) error {
+	startedAt := time.Now()
+
+	if s.outbox != nil {
+		err := s.outbox.Write(&model.OutboxMessage{
+			Channel:   model.ChannelEmail,
+			Template:  template,
+			Recipient: to,
+			Subject:   subject,
+			Body:      body,
+			Meta:      meta,
+		})
+		s.logSvc.Record(model.ChannelEmail, template, to, startedAt, "outbox", err)
+		return err
+	}
+
+	m := gomail.NewMessage()
+	m.SetHeader("From", s.config.SMTPFrom)
+	m.SetHeader("To", to)
+	m.SetHeader("Subject", subject)
+	for key, value := range headers {
+		m.SetHeader(key, value)
+	}
 	m.SetBody("text/html", body)
 
-	d := gomail.NewDialer(s.config.SMTPHost, s.config.SMTPPort, s.config.SMTPUser, s.config.SMTPPassword)
+	err := s.sender.Send(m)
 
-	return d.DialAndSend(m)
-}
\ No newline at end of file
+	response := "accepted"
+	if err != nil {
+		response = ""
+	}
+	s.logSvc.Record(model.ChannelEmail, template, to, startedAt, response, err)
+
+	return err
+}
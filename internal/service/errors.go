@@ -0,0 +1,43 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Sentinel errors that handlers translate into HTTP status codes. Domain
+// specific variants wrap them so errors.Is keeps working.
+var (
+	ErrNotFound  = errors.New("tidak ditemukan")
+	ErrForbidden = errors.New("akses ditolak")
+	ErrConflict  = errors.New("konflik data")
+	// ErrUnavailable means the server is at capacity; retry later
+	ErrUnavailable = errors.New("layanan sedang penuh, coba lagi nanti")
+)
+
+var (
+	ErrOrganizerNotFound     = fmt.Errorf("organizer %w", ErrNotFound)
+	ErrEventNotFound         = fmt.Errorf("event %w", ErrNotFound)
+	ErrVenueNotFound         = fmt.Errorf("venue %w", ErrNotFound)
+	ErrTicketTypeNotFound    = fmt.Errorf("tipe tiket %w", ErrNotFound)
+	ErrHoldNotFound          = fmt.Errorf("hold %w", ErrNotFound)
+	ErrInsufficientStock     = fmt.Errorf("stok tiket tidak mencukupi: %w", ErrConflict)
+	ErrOrderNotFound         = fmt.Errorf("order %w", ErrNotFound)
+	ErrPaymentNotFound       = fmt.Errorf("pembayaran %w", ErrNotFound)
+	ErrTicketNotFound        = fmt.Errorf("tiket %w", ErrNotFound)
+	ErrUserNotFound          = fmt.Errorf("user %w", ErrNotFound)
+	ErrGateDeviceNotFound    = fmt.Errorf("perangkat gate %w", ErrNotFound)
+	ErrWaitingRoomNotFound   = fmt.Errorf("waiting room %w", ErrNotFound)
+	ErrQueueEntryNotFound    = fmt.Errorf("antrean %w", ErrNotFound)
+	ErrPurchaseLimit         = fmt.Errorf("batas pembelian tercapai: %w", ErrForbidden)
+	ErrStepUpRequired        = fmt.Errorf("verifikasi OTP ulang diperlukan sebelum checkout: %w", ErrForbidden)
+	ErrTransferNotFound      = fmt.Errorf("transfer %w", ErrNotFound)
+	ErrResaleListingNotFound = fmt.Errorf("listing resale %w", ErrNotFound)
+	ErrWaitlistEntryNotFound = fmt.Errorf("daftar tunggu %w", ErrNotFound)
+	ErrPromotionNotFound     = fmt.Errorf("promo %w", ErrNotFound)
+	ErrRefundNotFound        = fmt.Errorf("refund %w", ErrNotFound)
+)
+
+// ErrInvalidVerificationToken means an email verification link was already
+// used, expired or never existed.
+var ErrInvalidVerificationToken = errors.New("token tidak valid atau sudah expired")
@@ -0,0 +1,257 @@
+package service
+
+import (
+	"database/sql"
+	"e-ticketing/internal/model"
+	"e-ticketing/internal/repository"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type EventService struct {
+	eventRepo    *repository.EventRepository
+	organizerSvc *OrganizerService
+	venueSvc     *VenueService
+}
+
+func NewEventService(eventRepo *repository.EventRepository, organizerSvc *OrganizerService, venueSvc *VenueService) *EventService {
+	return &EventService{
+		eventRepo:    eventRepo,
+		organizerSvc: organizerSvc,
+		venueSvc:     venueSvc,
+	}
+}
+
+func (s *EventService) CreateEvent(actor model.Actor, req *model.CreateEventRequest) (*model.Event, error) {
+	organizerID, err := uuid.Parse(req.OrganizerID)
+	if err != nil {
+		return nil, errors.New("organizer ID tidak valid")
+	}
+
+	if _, err := s.organizerSvc.Authorize(actor, organizerID); err != nil {
+		return nil, err
+	}
+
+	venue, err := s.resolveVenue(req.VenueID)
+	if err != nil {
+		return nil, err
+	}
+
+	event := &model.Event{
+		OrganizerID: organizerID,
+		Title:       req.Title,
+		Description: req.Description,
+		Category:    req.Category,
+		BannerURL:   req.BannerURL,
+		StartTime:   req.StartTime,
+		EndTime:     req.EndTime,
+		Timezone:    req.Timezone,
+		Status:      model.EventStatusDraft,
+	}
+	if venue != nil {
+		event.VenueID = &venue.ID
+	}
+
+	if err := s.eventRepo.CreateEvent(event); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+func (s *EventService) GetEvent(id uuid.UUID) (*model.Event, error) {
+	event, err := s.eventRepo.GetEventByID(id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrEventNotFound
+		}
+		return nil, err
+	}
+	return event, nil
+}
+
+// GetPublicEvent hides draft events from buyers.
+func (s *EventService) GetPublicEvent(id uuid.UUID) (*model.Event, error) {
+	event, err := s.GetEvent(id)
+	if err != nil {
+		return nil, err
+	}
+	if !model.IsPublicEventStatus(event.Status) {
+		return nil, ErrEventNotFound
+	}
+	return event, nil
+}
+
+// AuthorizeEvent returns the event when the actor manages its organizer.
+func (s *EventService) AuthorizeEvent(actor model.Actor, id uuid.UUID) (*model.Event, error) {
+	event, err := s.GetEvent(id)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.organizerSvc.Authorize(actor, event.OrganizerID); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+func (s *EventService) UpdateEvent(actor model.Actor, id uuid.UUID, req *model.UpdateEventRequest) (*model.Event, error) {
+	event, err := s.AuthorizeEvent(actor, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if event.Status == model.EventStatusCancelled || event.Status == model.EventStatusFinished {
+		return nil, errors.New("event yang sudah dibatalkan atau selesai tidak dapat diubah")
+	}
+
+	venue, err := s.resolveVenue(req.VenueID)
+	if err != nil {
+		return nil, err
+	}
+
+	event.VenueID = nil
+	if venue != nil {
+		event.VenueID = &venue.ID
+	}
+	event.Title = req.Title
+	event.Description = req.Description
+	event.Category = req.Category
+	event.BannerURL = req.BannerURL
+	event.StartTime = req.StartTime
+	event.EndTime = req.EndTime
+	event.Timezone = req.Timezone
+
+	// Moving to a smaller venue must still fit every ticket type
+	if err := s.eventRepo.UpdateEvent(event); err != nil {
+		return nil, capacityError(err)
+	}
+	return event, nil
+}
+
+func (s *EventService) UpdateEventStatus(actor model.Actor, id uuid.UUID, status string) (*model.Event, error) {
+	event, err := s.AuthorizeEvent(actor, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.transition(event, status)
+}
+
+// transition moves an event along the status lifecycle.
+func (s *EventService) transition(event *model.Event, status string) (*model.Event, error) {
+	if !model.CanTransitionEvent(event.Status, status) {
+		return nil, fmt.Errorf("status event tidak dapat diubah dari %s ke %s", event.Status, status)
+	}
+
+	updated, err := s.eventRepo.UpdateEventStatus(event.ID, event.Status, status)
+	if err != nil {
+		return nil, err
+	}
+	if !updated {
+		return nil, fmt.Errorf("status event sudah berubah: %w", ErrConflict)
+	}
+
+	event.Status = status
+	event.UpdatedAt = time.Now()
+	return event, nil
+}
+
+func (s *EventService) DeleteEvent(actor model.Actor, id uuid.UUID) error {
+	event, err := s.AuthorizeEvent(actor, id)
+	if err != nil {
+		return err
+	}
+
+	if event.Status != model.EventStatusDraft {
+		return errors.New("hanya event draft yang dapat dihapus")
+	}
+
+	return s.eventRepo.DeleteEvent(id)
+}
+
+func (s *EventService) ListPublicEvents(req *model.ListEventsRequest) (*model.PaginatedResponse, error) {
+	if req.Status == model.EventStatusDraft {
+		return nil, errors.New("status tidak valid")
+	}
+
+	statuses := []string{
+		model.EventStatusPublished, model.EventStatusOnSale, model.EventStatusSoldOut,
+		model.EventStatusCancelled, model.EventStatusFinished,
+	}
+	if req.Status != "" {
+		statuses = []string{req.Status}
+	}
+
+	return s.listEvents(req, statuses)
+}
+
+// ListOrganizerEvents includes drafts and is limited to organizer managers.
+func (s *EventService) ListOrganizerEvents(actor model.Actor, organizerID uuid.UUID, req *model.ListEventsRequest) (*model.PaginatedResponse, error) {
+	if _, err := s.organizerSvc.Authorize(actor, organizerID); err != nil {
+		return nil, err
+	}
+
+	req.OrganizerID = organizerID.String()
+	var statuses []string
+	if req.Status != "" {
+		statuses = []string{req.Status}
+	}
+
+	return s.listEvents(req, statuses)
+}
+
+func (s *EventService) listEvents(req *model.ListEventsRequest, statuses []string) (*model.PaginatedResponse, error) {
+	req.Normalize()
+
+	filter := &model.EventFilter{
+		Category: req.Category,
+		Statuses: statuses,
+		Search:   req.Search,
+		City:     req.City,
+		Near:     req.Geo(),
+		Limit:    req.Limit,
+		Offset:   req.Offset(),
+	}
+
+	if req.OrganizerID != "" {
+		organizerID, err := uuid.Parse(req.OrganizerID)
+		if err != nil {
+			return nil, errors.New("organizer ID tidak valid")
+		}
+		filter.OrganizerID = &organizerID
+	}
+
+	if req.From != "" {
+		from, _ := time.Parse("2006-01-02", req.From)
+		filter.StartsAfter = &from
+	}
+
+	events, total, err := s.eventRepo.ListEvents(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.PaginatedResponse{
+		Items: events,
+		Pagination: model.PaginationMeta{
+			Page:  req.Page,
+			Limit: req.Limit,
+			Total: total,
+		},
+	}, nil
+}
+
+// resolveVenue loads the optional venue referenced by a request.
+func (s *EventService) resolveVenue(raw string) (*model.Venue, error) {
+	if raw == "" {
+		return nil, nil
+	}
+
+	venueID, err := uuid.Parse(raw)
+	if err != nil {
+		return nil, errors.New("venue ID tidak valid")
+	}
+	return s.venueSvc.GetVenue(venueID)
+}
@@ -0,0 +1,150 @@
+package service
+
+import (
+	"database/sql"
+	"e-ticketing/config"
+	"e-ticketing/internal/model"
+	"e-ticketing/internal/repository"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type InventoryService struct {
+	inventoryRepo    *repository.InventoryRepository
+	userRepo         *repository.UserRepository
+	ticketTypeSvc    *TicketTypeService
+	eventSvc         *EventService
+	waitingRoomSvc   *WaitingRoomService
+	purchaseLimitSvc *PurchaseLimitService
+	config           *config.Config
+}
+
+func NewInventoryService(inventoryRepo *repository.InventoryRepository, userRepo *repository.UserRepository, ticketTypeSvc *TicketTypeService, eventSvc *EventService, waitingRoomSvc *WaitingRoomService, purchaseLimitSvc *PurchaseLimitService, cfg *config.Config) *InventoryService {
+	return &InventoryService{
+		inventoryRepo:    inventoryRepo,
+		userRepo:         userRepo,
+		ticketTypeSvc:    ticketTypeSvc,
+		eventSvc:         eventSvc,
+		waitingRoomSvc:   waitingRoomSvc,
+		purchaseLimitSvc: purchaseLimitSvc,
+		config:           cfg,
+	}
+}
+
+// PlaceHold reserves tickets for the actor for HoldDurationMinutes.
+func (s *InventoryService) PlaceHold(actor model.Actor, req *model.CreateHoldRequest) (*model.InventoryHold, error) {
+	ticketTypeID, err := uuid.Parse(req.TicketTypeID)
+	if err != nil {
+		return nil, errors.New("ticket type ID tidak valid")
+	}
+
+	user, err := s.userRepo.GetUserByID(actor.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if !user.IsVerified {
+		return nil, errors.New("akun belum terverifikasi")
+	}
+
+	ticketType, err := s.ticketTypeSvc.GetTicketType(ticketTypeID)
+	if err != nil {
+		return nil, err
+	}
+	if ticketType.ReservedSeating {
+		return nil, errors.New("tipe tiket ini memakai tempat duduk bernomor, pilih kursi melalui seat map")
+	}
+
+	event, err := s.eventSvc.GetEvent(ticketType.EventID)
+	if err != nil {
+		return nil, err
+	}
+	if event.Status != model.EventStatusOnSale {
+		return nil, errors.New("event belum atau tidak sedang dijual")
+	}
+	if err := s.waitingRoomSvc.CheckAdmission(actor, event.ID, req.QueueToken); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if !ticketType.IsOnSale(now) {
+		return nil, errors.New("tipe tiket di luar periode penjualan")
+	}
+	if req.Quantity < ticketType.MinPerOrder || req.Quantity > ticketType.MaxPerOrder {
+		return nil, fmt.Errorf("jumlah tiket harus antara %d dan %d", ticketType.MinPerOrder, ticketType.MaxPerOrder)
+	}
+	limit, err := s.purchaseLimitSvc.HoldLimit(actor, event.ID, req.Quantity, req.DeviceID)
+	if err != nil {
+		return nil, err
+	}
+
+	hold := &model.InventoryHold{
+		UserID:       actor.UserID,
+		TicketTypeID: ticketTypeID,
+		Quantity:     req.Quantity,
+		Status:       model.HoldStatusActive,
+		ExpiresAt:    now.Add(time.Duration(s.config.HoldDurationMinutes) * time.Minute),
+	}
+
+	ok, err := s.inventoryRepo.CreateHold(hold, limit)
+	if err != nil {
+		return nil, holdError(err)
+	}
+	if !ok {
+		return nil, ErrInsufficientStock
+	}
+	return hold, nil
+}
+
+func (s *InventoryService) GetHold(actor model.Actor, id uuid.UUID) (*model.InventoryHold, error) {
+	hold, err := s.inventoryRepo.GetHoldByID(id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrHoldNotFound
+		}
+		return nil, err
+	}
+	if hold.UserID != actor.UserID {
+		return nil, ErrHoldNotFound
+	}
+	return hold, nil
+}
+
+// ReleaseHold gives the reserved stock back before the hold expires.
+func (s *InventoryService) ReleaseHold(actor model.Actor, id uuid.UUID) error {
+	if _, err := s.GetHold(actor, id); err != nil {
+		return err
+	}
+
+	released, err := s.inventoryRepo.ReleaseHold(id, model.HoldStatusReleased)
+	if err != nil {
+		return err
+	}
+	if !released {
+		return errors.New("hold sudah tidak aktif")
+	}
+	return nil
+}
+
+// StartHoldSweeper periodically returns the stock of expired holds until
+// stop is closed.
+func (s *InventoryService) StartHoldSweeper(interval time.Duration, stop <-chan struct{}) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				if _, err := s.inventoryRepo.ReleaseExpiredHolds(time.Now()); err != nil {
+					log.Printf("Failed to release expired holds: %v", err)
+				}
+			case <-stop:
+				return
+			}
+		}
+	}()
+}
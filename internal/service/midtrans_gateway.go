@@ -0,0 +1,193 @@
+package service
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"e-ticketing/config"
+	"e-ticketing/internal/model"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"time"
+)
+
+// midtransEnabledPayments maps our payment methods to Snap enabled_payments.
+var midtransEnabledPayments = map[string]string{
+	"bca":                        "bca_va",
+	"bni":                        "bni_va",
+	"bri":                        "bri_va",
+	"permata":                    "permata_va",
+	"mandiri":                    "echannel",
+	model.PaymentMethodQRIS:      "other_qris",
+	model.PaymentMethodGoPay:     "gopay",
+	model.PaymentMethodShopeePay: "shopeepay",
+}
+
+// MidtransGateway creates transactions through Snap and queries status and
+// verifies notifications through the Core API conventions.
+type MidtransGateway struct {
+	config *config.Config
+	client *http.Client
+}
+
+func NewMidtransGateway(cfg *config.Config) *MidtransGateway {
+	return &MidtransGateway{
+		config: cfg,
+		client: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+func (g *MidtransGateway) Name() string {
+	return "midtrans"
+}
+
+func (g *MidtransGateway) CreateTransaction(req *model.ChargeRequest) (*model.ChargeResult, error) {
+	enabled := midtransEnabledPayments[req.Method]
+	if req.Method == model.PaymentMethodBankTransfer {
+		enabled = midtransEnabledPayments[req.Bank]
+	}
+	if enabled == "" {
+		return nil, fmt.Errorf("metode pembayaran tidak didukung: %s", req.Method)
+	}
+
+	payload := map[string]interface{}{
+		"transaction_details": map[string]interface{}{
+			"order_id":     req.ExternalID,
+			"gross_amount": req.Amount,
+		},
+		"customer_details": map[string]interface{}{
+			"first_name": req.CustomerName,
+			"email":      req.CustomerEmail,
+			"phone":      req.CustomerPhone,
+		},
+		"enabled_payments": []string{enabled},
+		"expiry": map[string]interface{}{
+			"unit":     "minute",
+			"duration": int(math.Max(1, math.Ceil(time.Until(req.ExpiresAt).Minutes()))),
+		},
+	}
+
+	var result struct {
+		Token       string   `json:"token"`
+		RedirectURL string   `json:"redirect_url"`
+		ErrorMsgs   []string `json:"error_messages"`
+	}
+	if err := g.do(http.MethodPost, g.config.MidtransSnapURL+"/snap/v1/transactions", payload, &result); err != nil {
+		return nil, err
+	}
+	if result.Token == "" {
+		return nil, fmt.Errorf("midtrans error: %v", result.ErrorMsgs)
+	}
+
+	return &model.ChargeResult{
+		Token:       result.Token,
+		RedirectURL: result.RedirectURL,
+	}, nil
+}
+
+func (g *MidtransGateway) GetStatus(externalID string) (*model.GatewayStatus, error) {
+	var raw json.RawMessage
+	if err := g.do(http.MethodGet, g.config.MidtransAPIURL+"/v2/"+externalID+"/status", nil, &raw); err != nil {
+		return nil, err
+	}
+
+	var n midtransNotification
+	if err := json.Unmarshal(raw, &n); err != nil {
+		return nil, err
+	}
+	if n.StatusCode == "404" {
+		return &model.GatewayStatus{ExternalID: externalID, Status: model.PaymentStatusPending, Raw: string(raw)}, nil
+	}
+	return n.toGatewayStatus(string(raw))
+}
+
+// ParseNotification rejects everything when no server key is configured,
+// since the signature would then be computable by anyone.
+func (g *MidtransGateway) ParseNotification(body []byte) (*model.GatewayStatus, error) {
+	if g.config.MidtransServerKey == "" {
+		return nil, ErrInvalidSignature
+	}
+
+	var n midtransNotification
+	if err := json.Unmarshal(body, &n); err != nil {
+		return nil, err
+	}
+
+	expected := midtransSignature(n.OrderID, n.StatusCode, n.GrossAmount, g.config.MidtransServerKey)
+	if !hmac.Equal([]byte(expected), []byte(n.SignatureKey)) {
+		return nil, ErrInvalidSignature
+	}
+
+	return n.toGatewayStatus(string(body))
+}
+
+// Refund uses the Core API refund endpoint. Midtrans only refunds card and
+// e-wallet payments this way; virtual account payments come back as an error
+// and have to be paid back by hand.
+func (g *MidtransGateway) Refund(req *model.ChargeRefund) (*model.RefundResult, error) {
+	payload := map[string]interface{}{
+		"refund_key": req.RefundKey,
+		"amount":     req.Amount,
+		"reason":     req.Reason,
+	}
+
+	var raw json.RawMessage
+	if err := g.do(http.MethodPost, g.config.MidtransAPIURL+"/v2/"+req.ExternalID+"/refund", payload, &raw); err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		StatusCode    string `json:"status_code"`
+		StatusMessage string `json:"status_message"`
+		TransactionID string `json:"transaction_id"`
+		RefundKey     string `json:"refund_key"`
+	}
+	if err := json.Unmarshal(raw, &result); err != nil {
+		return nil, err
+	}
+	if result.StatusCode != "200" {
+		return nil, fmt.Errorf("midtrans error: %s %s", result.StatusCode, result.StatusMessage)
+	}
+
+	return &model.RefundResult{
+		Reference: result.TransactionID + ":" + result.RefundKey,
+		Raw:       string(raw),
+	}, nil
+}
+
+func (g *MidtransGateway) do(method, url string, payload, out interface{}) error {
+	var body io.Reader
+	if payload != nil {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewBuffer(data)
+	}
+
+	req, err := http.NewRequest(method, url, body)
+	if err != nil {
+		return err
+	}
+	req.SetBasicAuth(g.config.MidtransServerKey, "")
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return fmt.Errorf("midtrans API error: status %d", resp.StatusCode)
+	}
+
+	return json.Unmarshal(data, out)
+}
@@ -0,0 +1,149 @@
+package service
+
+import (
+	"database/sql"
+	"e-ticketing/config"
+	"e-ticketing/internal/model"
+	"e-ticketing/internal/repository"
+	"e-ticketing/pkg/utils"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const maxProviderResponseLength = 1000
+
+type NotificationLogService struct {
+	notifRepo *repository.NotificationRepository
+	userRepo  *repository.UserRepository
+	config    *config.Config
+}
+
+func NewNotificationLogService(notifRepo *repository.NotificationRepository, userRepo *repository.UserRepository, cfg *config.Config) *NotificationLogService {
+	return &NotificationLogService{
+		notifRepo: notifRepo,
+		userRepo:  userRepo,
+		config:    cfg,
+	}
+}
+
+// Record stores a send attempt. Logging must never break delivery, so
+// failures are only written to the application log.
+func (s *NotificationLogService) Record(channel, template, recipient string, startedAt time.Time, response string, sendErr error) {
+	if s == nil {
+		return
+	}
+
+	entry := &model.NotificationLog{
+		Channel:          channel,
+		Template:         template,
+		RecipientHash:    utils.HashIdentifier(s.config.IdentifierHashKey, recipient),
+		Status:           model.DeliverySent,
+		ProviderResponse: response,
+		LatencyMs:        time.Since(startedAt).Milliseconds(),
+	}
+
+	if channel == model.ChannelEmail {
+		entry.RecipientMasked = utils.MaskEmail(recipient)
+	} else {
+		entry.RecipientMasked = utils.MaskPhone(recipient)
+	}
+
+	if sendErr != nil {
+		entry.Status = model.DeliveryFailed
+		if entry.ProviderResponse == "" {
+			entry.ProviderResponse = sendErr.Error()
+		}
+	}
+	entry.ProviderResponse = utils.TruncateString(entry.ProviderResponse, maxProviderResponseLength)
+
+	if err := s.notifRepo.CreateLog(entry); err != nil {
+		log.Printf("Failed to record notification log: %v", err)
+	}
+}
+
+func (s *NotificationLogService) Search(req *model.SearchNotificationLogsRequest) (*model.PaginatedResponse, error) {
+	req.Normalize()
+
+	filter := &model.NotificationLogFilter{
+		Channel: req.Channel,
+		Status:  req.Status,
+		Limit:   req.Limit,
+		Offset:  req.Offset(),
+	}
+
+	// Logs only keep hashed recipients, so a user filter is resolved to the
+	// hashes of that user's email and phone
+	if req.UserID != "" {
+		userID, err := uuid.Parse(req.UserID)
+		if err != nil {
+			return nil, errors.New("user ID tidak valid")
+		}
+		user, err := s.userRepo.GetUserByID(userID)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return nil, errors.New("user tidak ditemukan")
+			}
+			return nil, err
+		}
+		filter.RecipientHashes = []string{
+			utils.HashIdentifier(s.config.IdentifierHashKey, user.Email),
+			utils.HashIdentifier(s.config.IdentifierHashKey, user.Phone),
+		}
+	}
+
+	if req.From != "" {
+		from, _ := time.Parse("2006-01-02", req.From)
+		filter.From = &from
+	}
+	if req.To != "" {
+		// Date range is inclusive of the whole "to" day
+		to, _ := time.Parse("2006-01-02", req.To)
+		to = to.AddDate(0, 0, 1)
+		filter.To = &to
+	}
+
+	logs, total, err := s.notifRepo.SearchLogs(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.PaginatedResponse{
+		Items: logs,
+		Pagination: model.PaginationMeta{
+			Page:  req.Page,
+			Limit: req.Limit,
+			Total: total,
+		},
+	}, nil
+}
+
+// StartRetentionPruner deletes logs older than the configured retention once
+// per interval until stop is closed.
+func (s *NotificationLogService) StartRetentionPruner(interval time.Duration, stop <-chan struct{}) {
+	if s.config.NotificationLogRetentionDays <= 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			cutoff := time.Now().AddDate(0, 0, -s.config.NotificationLogRetentionDays)
+			if deleted, err := s.notifRepo.PruneLogs(cutoff); err != nil {
+				log.Printf("Failed to prune notification logs: %v", err)
+			} else if deleted > 0 {
+				log.Printf("Pruned %d notification logs older than %s", deleted, cutoff.Format(time.RFC3339))
+			}
+
+			select {
+			case <-ticker.C:
+			case <-stop:
+				return
+			}
+		}
+	}()
+}
@@ -0,0 +1,221 @@
+package service
+
+import (
+	"database/sql"
+	"e-ticketing/config"
+	"e-ticketing/internal/model"
+	"e-ticketing/internal/repository"
+	"e-ticketing/pkg/utils"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Unsubscribe tokens only ever opt out of marketing, and stop working after
+// unsubscribeLinkValidity so a leaked link is not good forever.
+const (
+	unsubscribeTokenPurpose = "unsubscribe:" + model.NotificationMarketing
+	unsubscribeLinkValidity = 90 * 24 * time.Hour
+)
+
+type NotificationService struct {
+	notifRepo   *repository.NotificationRepository
+	emailSvc    *EmailService
+	whatsappSvc *WhatsAppService
+	config      *config.Config
+}
+
+func NewNotificationService(notifRepo *repository.NotificationRepository, emailSvc *EmailService, whatsappSvc *WhatsAppService, cfg *config.Config) *NotificationService {
+	return &NotificationService{
+		notifRepo:   notifRepo,
+		emailSvc:    emailSvc,
+		whatsappSvc: whatsappSvc,
+		config:      cfg,
+	}
+}
+
+func (s *NotificationService) GetPreference(userID uuid.UUID) (*model.NotificationPreference, error) {
+	pref, err := s.notifRepo.GetPreference(userID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return model.DefaultNotificationPreference(userID), nil
+		}
+		return nil, err
+	}
+	return pref, nil
+}
+
+func (s *NotificationService) UpdatePreference(userID uuid.UUID, req *model.UpdateNotificationPreferenceRequest) (*model.NotificationPreference, error) {
+	pref, err := s.GetPreference(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.TransactionalEnabled != nil {
+		pref.TransactionalEnabled = *req.TransactionalEnabled
+	}
+	if req.MarketingOptIn != nil {
+		pref.MarketingOptIn = *req.MarketingOptIn
+	}
+	if req.PreferredChannel != "" {
+		pref.PreferredChannel = req.PreferredChannel
+	}
+	if req.Timezone != "" {
+		pref.Timezone = req.Timezone
+	}
+	if req.QuietHoursStart != nil {
+		pref.QuietHoursStart = *req.QuietHoursStart
+	}
+	if req.QuietHoursEnd != nil {
+		pref.QuietHoursEnd = *req.QuietHoursEnd
+	}
+
+	// Quiet hours are either fully set or fully cleared
+	if (pref.QuietHoursStart == "") != (pref.QuietHoursEnd == "") {
+		return nil, errors.New("quiet hours harus memiliki jam mulai dan jam selesai")
+	}
+	// Stored zero-padded, e.g. 9:00 becomes 09:00
+	for _, v := range []*string{&pref.QuietHoursStart, &pref.QuietHoursEnd} {
+		if *v == "" {
+			continue
+		}
+		t, err := time.Parse("15:04", *v)
+		if err != nil {
+			return nil, errors.New("format quiet hours harus HH:MM")
+		}
+		*v = t.Format("15:04")
+	}
+
+	if err := s.notifRepo.UpsertPreference(pref); err != nil {
+		return nil, err
+	}
+	return pref, nil
+}
+
+// Notify delivers a notification through the channel the user prefers.
+// Security notifications bypass every preference; transactional ones respect
+// the opt-out and avoid WhatsApp during quiet hours; marketing requires an
+// explicit opt-in and is dropped, not queued, during quiet hours. Suppressed
+// messages are dropped silently. Recipients without an email address, such
+// as an unregistered transfer recipient, are reached on WhatsApp.
+func (s *NotificationService) Notify(user *model.User, n *model.Notification) error {
+	pref, err := s.GetPreference(user.ID)
+	if err != nil {
+		return err
+	}
+
+	channel := pref.PreferredChannel
+	switch {
+	case channel == model.ChannelWhatsApp && (user.Phone == "" || n.WhatsAppBody == ""):
+		channel = model.ChannelEmail
+	case channel == model.ChannelEmail && user.Email == "":
+		channel = model.ChannelWhatsApp
+	}
+
+	switch n.Category {
+	case model.NotificationSecurity:
+	case model.NotificationTransactional:
+		if !pref.TransactionalEnabled {
+			return nil
+		}
+		if user.Email != "" && s.inQuietHours(pref) {
+			channel = model.ChannelEmail
+		}
+	case model.NotificationMarketing:
+		if !pref.MarketingOptIn || s.inQuietHours(pref) {
+			return nil
+		}
+	default:
+		return fmt.Errorf("kategori notifikasi tidak dikenal: %s", n.Category)
+	}
+
+	if channel == model.ChannelWhatsApp {
+		body := n.WhatsAppBody
+		if n.Category == model.NotificationMarketing {
+			body += "\n\nBerhenti berlangganan: " + s.UnsubscribeLink(user.ID)
+		}
+		return s.whatsappSvc.SendMessage(user.Phone, n.Template, body)
+	}
+
+	var headers map[string]string
+	body := n.EmailBody
+	if n.Category == model.NotificationMarketing {
+		link := s.UnsubscribeLink(user.ID)
+		body += fmt.Sprintf(`<p style="font-size: 12px; color: #999;">Tidak ingin menerima email promosi? <a href="%s">Berhenti berlangganan</a></p>`, link)
+		headers = map[string]string{
+			"List-Unsubscribe":      "<" + link + ">",
+			"List-Unsubscribe-Post": "List-Unsubscribe=One-Click",
+		}
+	}
+	return s.emailSvc.SendEmail(user.Email, n.Template, n.Subject, body, headers)
+}
+
+// UnsubscribeLink returns a signed one-click unsubscribe URL for marketing.
+func (s *NotificationService) UnsubscribeLink(userID uuid.UUID) string {
+	token := utils.GenerateSignedToken(s.config.JWTSecret, userID.String(), unsubscribeTokenPurpose,
+		time.Now().Add(unsubscribeLinkValidity))
+	return fmt.Sprintf("%s/api/v1/notifications/unsubscribe?token=%s", s.config.AppBaseURL, url.QueryEscape(token))
+}
+
+// VerifyUnsubscribeToken checks an unsubscribe token without acting on it.
+func (s *NotificationService) VerifyUnsubscribeToken(token string) error {
+	_, err := s.unsubscribeUser(token)
+	return err
+}
+
+// Unsubscribe opts the token's user out of marketing.
+func (s *NotificationService) Unsubscribe(token string) error {
+	userID, err := s.unsubscribeUser(token)
+	if err != nil {
+		return err
+	}
+	return s.notifRepo.SetMarketingOptIn(userID, false)
+}
+
+func (s *NotificationService) unsubscribeUser(token string) (uuid.UUID, error) {
+	subject, err := utils.VerifySignedToken(s.config.JWTSecret, token, unsubscribeTokenPurpose)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	userID, err := uuid.Parse(subject)
+	if err != nil {
+		return uuid.Nil, errors.New("token tidak valid")
+	}
+	return userID, nil
+}
+
+func (s *NotificationService) inQuietHours(pref *model.NotificationPreference) bool {
+	if pref.QuietHoursStart == "" || pref.QuietHoursEnd == "" {
+		return false
+	}
+
+	loc, err := time.LoadLocation(pref.Timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+	start, err := time.Parse("15:04", pref.QuietHoursStart)
+	if err != nil {
+		return false
+	}
+	end, err := time.Parse("15:04", pref.QuietHoursEnd)
+	if err != nil {
+		return false
+	}
+
+	// Compared as minutes since midnight, so unpadded values such as 9:00
+	// stored before they were normalised still order correctly
+	t := time.Now().In(loc)
+	now := t.Hour()*60 + t.Minute()
+	from := start.Hour()*60 + start.Minute()
+	to := end.Hour()*60 + end.Minute()
+
+	// Windows such as 22:00-07:00 wrap around midnight
+	if from <= to {
+		return now >= from && now < to
+	}
+	return now >= from || now < to
+}
@@ -0,0 +1,299 @@
+package service
+
+import (
+	"database/sql"
+	"e-ticketing/config"
+	"e-ticketing/internal/model"
+	"e-ticketing/internal/repository"
+	"e-ticketing/pkg/utils"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type OrderService struct {
+	orderRepo        *repository.OrderRepository
+	userRepo         *repository.UserRepository
+	inventorySvc     *InventoryService
+	ticketTypeSvc    *TicketTypeService
+	eventSvc         *EventService
+	purchaseLimitSvc *PurchaseLimitService
+	attendeeSvc      *AttendeeService
+	promoSvc         *PromoService
+	config           *config.Config
+}
+
+func NewOrderService(orderRepo *repository.OrderRepository, userRepo *repository.UserRepository, inventorySvc *InventoryService, ticketTypeSvc *TicketTypeService, eventSvc *EventService, purchaseLimitSvc *PurchaseLimitService, attendeeSvc *AttendeeService, promoSvc *PromoService, cfg *config.Config) *OrderService {
+	return &OrderService{
+		orderRepo:        orderRepo,
+		userRepo:         userRepo,
+		inventorySvc:     inventorySvc,
+		ticketTypeSvc:    ticketTypeSvc,
+		eventSvc:         eventSvc,
+		purchaseLimitSvc: purchaseLimitSvc,
+		attendeeSvc:      attendeeSvc,
+		promoSvc:         promoSvc,
+		config:           cfg,
+	}
+}
+
+// CreateOrder turns the actor's active holds into a pending order priced at
+// the current ticket type prices, less the promo code's discount.
+func (s *OrderService) CreateOrder(actor model.Actor, req *model.CreateOrderRequest) (*model.Order, error) {
+	order, holdIDs, redemption, err := s.priceOrder(actor, req)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.purchaseLimitSvc.CheckCheckout(actor, order.EventID, req.DeviceID); err != nil {
+		return nil, err
+	}
+	if err := s.attendeeSvc.AttachAttendees(order.EventID, order.Items, req.Attendees); err != nil {
+		return nil, err
+	}
+
+	ok, err := s.orderRepo.CreateOrderFromHolds(order, holdIDs, redemption)
+	if err != nil {
+		switch err {
+		case repository.ErrPromoLimitReached:
+			return nil, fmt.Errorf("kuota kode promo sudah habis: %w", ErrConflict)
+		case repository.ErrPromoUserLimitReached:
+			return nil, fmt.Errorf("batas penggunaan kode promo per akun tercapai: %w", ErrConflict)
+		}
+		return nil, err
+	}
+	if !ok {
+		return nil, fmt.Errorf("hold sudah tidak aktif, silakan pesan ulang: %w", ErrConflict)
+	}
+
+	// Reload so reserved seating items carry their seats
+	return s.GetOrder(order.ID)
+}
+
+// PreviewOrder prices the holds and promo code as CreateOrder would, without
+// placing the order, so buyers can check a code before checkout.
+func (s *OrderService) PreviewOrder(actor model.Actor, req *model.CreateOrderRequest) (*model.Order, error) {
+	order, _, _, err := s.priceOrder(actor, req)
+	if err != nil {
+		return nil, err
+	}
+	return order, nil
+}
+
+// priceOrder builds an unsaved order from the actor's holds and applies the
+// promo code. The redemption is nil without a code.
+func (s *OrderService) priceOrder(actor model.Actor, req *model.CreateOrderRequest) (*model.Order, []uuid.UUID, *model.PromoRedemption, error) {
+	user, err := s.userRepo.GetUserByID(actor.UserID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if !user.IsVerified {
+		return nil, nil, nil, errors.New("akun belum terverifikasi")
+	}
+
+	now := time.Now()
+	order := &model.Order{
+		OrderNumber: utils.GenerateOrderNumber(),
+		UserID:      actor.UserID,
+		Status:      model.OrderStatusPending,
+		ExpiresAt:   now.Add(time.Duration(s.config.OrderPaymentMinutes) * time.Minute),
+	}
+
+	holdIDs := make([]uuid.UUID, 0, len(req.HoldIDs))
+	seen := map[uuid.UUID]bool{}
+	for _, raw := range req.HoldIDs {
+		holdID, err := uuid.Parse(raw)
+		if err != nil {
+			return nil, nil, nil, errors.New("hold ID tidak valid")
+		}
+		if seen[holdID] {
+			return nil, nil, nil, errors.New("hold ID duplikat")
+		}
+		seen[holdID] = true
+
+		hold, err := s.inventorySvc.GetHold(actor, holdID)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		if !hold.IsActive(now) {
+			return nil, nil, nil, errors.New("hold sudah tidak aktif, silakan pesan ulang")
+		}
+
+		ticketType, err := s.ticketTypeSvc.GetTicketType(hold.TicketTypeID)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+
+		if order.EventID == uuid.Nil {
+			order.EventID = ticketType.EventID
+			order.Currency = ticketType.Currency
+		}
+		if ticketType.EventID != order.EventID {
+			return nil, nil, nil, errors.New("semua tiket dalam satu order harus dari event yang sama")
+		}
+		if ticketType.Currency != order.Currency {
+			return nil, nil, nil, errors.New("semua tiket dalam satu order harus memiliki mata uang yang sama")
+		}
+
+		subtotal := ticketType.Price * int64(hold.Quantity)
+		order.Items = append(order.Items, model.OrderItem{
+			TicketTypeID: ticketType.ID,
+			HoldID:       &hold.ID,
+			Quantity:     hold.Quantity,
+			UnitPrice:    ticketType.Price,
+			Subtotal:     subtotal,
+		})
+		order.TotalAmount += subtotal
+		holdIDs = append(holdIDs, holdID)
+	}
+
+	event, err := s.eventSvc.GetEvent(order.EventID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if event.Status != model.EventStatusOnSale {
+		return nil, nil, nil, errors.New("event belum atau tidak sedang dijual")
+	}
+
+	if req.PromoCode == "" {
+		return order, holdIDs, nil, nil
+	}
+	redemption, err := s.promoSvc.Apply(actor, order, req.PromoCode)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return order, holdIDs, redemption, nil
+}
+
+func (s *OrderService) GetOrder(id uuid.UUID) (*model.Order, error) {
+	order, err := s.orderRepo.GetOrderByID(id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrOrderNotFound
+		}
+		return nil, err
+	}
+	return order, nil
+}
+
+// GetMyOrder returns the order only to the user who placed it.
+func (s *OrderService) GetMyOrder(actor model.Actor, id uuid.UUID) (*model.Order, error) {
+	order, err := s.GetOrder(id)
+	if err != nil {
+		return nil, err
+	}
+	if order.UserID != actor.UserID {
+		return nil, ErrOrderNotFound
+	}
+	return order, nil
+}
+
+func (s *OrderService) ListMyOrders(actor model.Actor, req *model.ListOrdersRequest) (*model.PaginatedResponse, error) {
+	req.Normalize()
+
+	orders, total, err := s.orderRepo.ListOrdersByUser(actor.UserID, req.Status, req.Limit, req.Offset())
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.PaginatedResponse{
+		Items: orders,
+		Pagination: model.PaginationMeta{
+			Page:  req.Page,
+			Limit: req.Limit,
+			Total: total,
+		},
+	}, nil
+}
+
+// CancelOrder lets the buyer abandon an unpaid order and frees its stock.
+func (s *OrderService) CancelOrder(actor model.Actor, id uuid.UUID) (*model.Order, error) {
+	order, err := s.GetMyOrder(actor, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !model.CanTransitionOrder(order.Status, model.OrderStatusCancelled) {
+		return nil, fmt.Errorf("order dengan status %s tidak dapat dibatalkan", order.Status)
+	}
+
+	closed, err := s.orderRepo.CloseOrder(id, model.OrderStatusCancelled)
+	if err != nil {
+		return nil, err
+	}
+	if !closed {
+		return nil, fmt.Errorf("status order sudah berubah: %w", ErrConflict)
+	}
+
+	return s.GetOrder(id)
+}
+
+// TransitionOrder applies a status change coming from another subsystem
+// (e.g. payments). It returns false without error when the order is already
+// past the from state, so repeated calls are idempotent.
+func (s *OrderService) TransitionOrder(id uuid.UUID, to string) (bool, error) {
+	order, err := s.GetOrder(id)
+	if err != nil {
+		return false, err
+	}
+	if order.Status == to {
+		return false, nil
+	}
+	if !model.CanTransitionOrder(order.Status, to) {
+		return false, fmt.Errorf("status order tidak dapat diubah dari %s ke %s", order.Status, to)
+	}
+
+	switch to {
+	case model.OrderStatusExpired, model.OrderStatusCancelled:
+		return s.orderRepo.CloseOrder(id, to)
+	default:
+		return s.orderRepo.UpdateOrderStatus(id, []string{order.Status}, to)
+	}
+}
+
+// MarkPaid moves the order to paid, checking the instrument limit, if any,
+// in the same transaction. It returns false when the order was already paid
+// and ErrPurchaseLimit when the instrument is over its limit.
+func (s *OrderService) MarkPaid(id uuid.UUID, limit *model.InstrumentLimit) (bool, error) {
+	order, err := s.GetOrder(id)
+	if err != nil {
+		return false, err
+	}
+	if order.Status == model.OrderStatusPaid {
+		return false, nil
+	}
+	if !model.CanTransitionOrder(order.Status, model.OrderStatusPaid) {
+		return false, fmt.Errorf("status order tidak dapat diubah dari %s ke %s", order.Status, model.OrderStatusPaid)
+	}
+
+	paid, err := s.orderRepo.MarkOrderPaid(id, order.Status, limit)
+	if err != nil {
+		return false, holdError(err)
+	}
+	return paid, nil
+}
+
+// StartExpirySweeper expires unpaid orders past their payment deadline and
+// returns their stock until stop is closed.
+func (s *OrderService) StartExpirySweeper(interval time.Duration, stop <-chan struct{}) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				ids, err := s.orderRepo.ExpireOrders(time.Now())
+				if err != nil {
+					log.Printf("Failed to expire orders: %v", err)
+				} else if len(ids) > 0 {
+					log.Printf("Expired %d unpaid orders", len(ids))
+				}
+			case <-stop:
+				return
+			}
+		}
+	}()
+}
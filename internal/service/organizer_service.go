@@ -0,0 +1,78 @@
+package service
+
+import (
+	"database/sql"
+	"e-ticketing/internal/model"
+	"e-ticketing/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+type OrganizerService struct {
+	organizerRepo *repository.OrganizerRepository
+}
+
+func NewOrganizerService(organizerRepo *repository.OrganizerRepository) *OrganizerService {
+	return &OrganizerService{organizerRepo: organizerRepo}
+}
+
+func (s *OrganizerService) CreateOrganizer(actor model.Actor, req *model.CreateOrganizerRequest) (*model.Organizer, error) {
+	organizer := &model.Organizer{
+		OwnerID:     actor.UserID,
+		Name:        req.Name,
+		Description: req.Description,
+		Email:       req.Email,
+		Phone:       req.Phone,
+		LogoURL:     req.LogoURL,
+	}
+
+	if err := s.organizerRepo.CreateOrganizer(organizer); err != nil {
+		return nil, err
+	}
+	return organizer, nil
+}
+
+func (s *OrganizerService) GetOrganizer(id uuid.UUID) (*model.Organizer, error) {
+	organizer, err := s.organizerRepo.GetOrganizerByID(id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrOrganizerNotFound
+		}
+		return nil, err
+	}
+	return organizer, nil
+}
+
+func (s *OrganizerService) GetMyOrganizers(actor model.Actor) ([]model.Organizer, error) {
+	return s.organizerRepo.GetOrganizersByOwner(actor.UserID)
+}
+
+func (s *OrganizerService) UpdateOrganizer(actor model.Actor, id uuid.UUID, req *model.UpdateOrganizerRequest) (*model.Organizer, error) {
+	organizer, err := s.Authorize(actor, id)
+	if err != nil {
+		return nil, err
+	}
+
+	organizer.Name = req.Name
+	organizer.Description = req.Description
+	organizer.Email = req.Email
+	organizer.Phone = req.Phone
+	organizer.LogoURL = req.LogoURL
+
+	if err := s.organizerRepo.UpdateOrganizer(organizer); err != nil {
+		return nil, err
+	}
+	return organizer, nil
+}
+
+// Authorize returns the organizer when the actor owns it or is an admin.
+func (s *OrganizerService) Authorize(actor model.Actor, organizerID uuid.UUID) (*model.Organizer, error) {
+	organizer, err := s.GetOrganizer(organizerID)
+	if err != nil {
+		return nil, err
+	}
+	if organizer.OwnerID != actor.UserID && !actor.IsAdmin() {
+		return nil, ErrForbidden
+	}
+	return organizer, nil
+}
@@ -0,0 +1,102 @@
+package service
+
+import (
+	"crypto/sha512"
+	"e-ticketing/internal/model"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var ErrInvalidSignature = errors.New("signature notifikasi tidak valid")
+
+// PaymentGateway is implemented by every payment provider adapter.
+type PaymentGateway interface {
+	// Name identifies the gateway in stored payments and callback URLs.
+	Name() string
+	// CreateTransaction registers a charge and returns payment instructions.
+	CreateTransaction(req *model.ChargeRequest) (*model.ChargeResult, error)
+	// GetStatus queries the current state of a transaction.
+	GetStatus(externalID string) (*model.GatewayStatus, error)
+	// ParseNotification verifies a callback body and normalises it.
+	ParseNotification(body []byte) (*model.GatewayStatus, error)
+	// Refund pays part or all of a settled transaction back to the payer.
+	Refund(req *model.ChargeRefund) (*model.RefundResult, error)
+}
+
+// midtransNotification is the Midtrans HTTP notification / status payload.
+// The simulator emits the same shape so both share one parsing path.
+type midtransNotification struct {
+	OrderID           string `json:"order_id"`
+	TransactionID     string `json:"transaction_id"`
+	TransactionStatus string `json:"transaction_status"`
+	FraudStatus       string `json:"fraud_status"`
+	StatusCode        string `json:"status_code"`
+	GrossAmount       string `json:"gross_amount"`
+	SignatureKey      string `json:"signature_key"`
+	Bank              string `json:"bank,omitempty"`
+	MaskedCard        string `json:"masked_card,omitempty"`
+}
+
+// midtransSignature is SHA512(order_id + status_code + gross_amount + server_key).
+func midtransSignature(orderID, statusCode, grossAmount, serverKey string) string {
+	sum := sha512.Sum512([]byte(orderID + statusCode + grossAmount + serverKey))
+	return hex.EncodeToString(sum[:])
+}
+
+// toGatewayStatus maps a Midtrans transaction status onto payment statuses.
+func (n *midtransNotification) toGatewayStatus(raw string) (*model.GatewayStatus, error) {
+	amount, err := parseGrossAmount(n.GrossAmount)
+	if err != nil {
+		return nil, err
+	}
+
+	status := model.PaymentStatusPending
+	switch n.TransactionStatus {
+	case "capture":
+		if n.FraudStatus == "" || n.FraudStatus == "accept" {
+			status = model.PaymentStatusPaid
+		}
+	case "settlement":
+		status = model.PaymentStatusPaid
+	case "deny", "cancel", "failure":
+		status = model.PaymentStatusFailed
+	case "expire":
+		status = model.PaymentStatusExpired
+	case "refund", "partial_refund":
+		status = model.PaymentStatusRefunded
+	}
+
+	// Only card payments identify the payer; transfers and wallets do not
+	instrument := ""
+	if n.MaskedCard != "" {
+		instrument = "card:" + n.Bank + ":" + n.MaskedCard
+	}
+
+	return &model.GatewayStatus{
+		ExternalID: n.OrderID,
+		Reference:  n.TransactionID,
+		Status:     status,
+		Amount:     amount,
+		Instrument: instrument,
+		Raw:        raw,
+	}, nil
+}
+
+// parseGrossAmount accepts "150000" and "150000.00" (rupiah has no minor unit).
+func parseGrossAmount(value string) (int64, error) {
+	whole := value
+	if i := strings.IndexByte(value, '.'); i >= 0 {
+		if strings.Trim(value[i+1:], "0") != "" {
+			return 0, fmt.Errorf("gross_amount tidak valid: %s", value)
+		}
+		whole = value[:i]
+	}
+	return strconv.ParseInt(whole, 10, 64)
+}
+
+func formatGrossAmount(amount int64) string {
+	return strconv.FormatInt(amount, 10) + ".00"
+}
@@ -0,0 +1,308 @@
+package service
+
+import (
+	"database/sql"
+	"e-ticketing/config"
+	"e-ticketing/internal/model"
+	"e-ticketing/internal/repository"
+	"e-ticketing/pkg/utils"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type PaymentService struct {
+	paymentRepo      *repository.PaymentRepository
+	userRepo         *repository.UserRepository
+	orderSvc         *OrderService
+	ticketSvc        *TicketService
+	purchaseLimitSvc *PurchaseLimitService
+	resaleSvc        *ResaleService
+	refundSvc        *RefundService
+	gateway          PaymentGateway
+	config           *config.Config
+}
+
+func NewPaymentService(paymentRepo *repository.PaymentRepository, userRepo *repository.UserRepository, orderSvc *OrderService, ticketSvc *TicketService, purchaseLimitSvc *PurchaseLimitService, resaleSvc *ResaleService, refundSvc *RefundService, gateway PaymentGateway, cfg *config.Config) *PaymentService {
+	return &PaymentService{
+		paymentRepo:      paymentRepo,
+		userRepo:         userRepo,
+		orderSvc:         orderSvc,
+		ticketSvc:        ticketSvc,
+		purchaseLimitSvc: purchaseLimitSvc,
+		resaleSvc:        resaleSvc,
+		refundSvc:        refundSvc,
+		gateway:          gateway,
+		config:           cfg,
+	}
+}
+
+// CreatePayment starts a payment for an unpaid order. An unexpired pending
+// payment with the same method is returned as is, so retried requests do not
+// open a second charge.
+func (s *PaymentService) CreatePayment(actor model.Actor, orderID uuid.UUID, req *model.CreatePaymentRequest) (*model.Payment, error) {
+	order, err := s.orderSvc.GetMyOrder(actor, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if order.Status != model.OrderStatusPending && order.Status != model.OrderStatusAwaitingPayment {
+		return nil, fmt.Errorf("order dengan status %s tidak dapat dibayar", order.Status)
+	}
+	now := time.Now()
+	if !order.ExpiresAt.After(now) {
+		return nil, errors.New("batas waktu pembayaran order sudah lewat")
+	}
+
+	existing, err := s.paymentRepo.GetPaymentsByOrder(order.ID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range existing {
+		p := &existing[i]
+		if p.Status == model.PaymentStatusPending && p.Method == req.Method && p.Bank == req.Bank &&
+			(p.ExpiresAt == nil || p.ExpiresAt.After(now)) {
+			return p, nil
+		}
+	}
+
+	user, err := s.userRepo.GetUserByID(actor.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	payment := &model.Payment{
+		ID:       uuid.New(),
+		OrderID:  order.ID,
+		Gateway:  s.gateway.Name(),
+		Method:   req.Method,
+		Bank:     req.Bank,
+		Status:   model.PaymentStatusPending,
+		Amount:   order.TotalAmount,
+		Currency: order.Currency,
+	}
+	payment.ExternalID = payment.ID.String()
+
+	// The charge expires together with the order
+	expiresAt := order.ExpiresAt
+	payment.ExpiresAt = &expiresAt
+
+	result, err := s.gateway.CreateTransaction(&model.ChargeRequest{
+		ExternalID:    payment.ExternalID,
+		Amount:        payment.Amount,
+		Currency:      payment.Currency,
+		Method:        payment.Method,
+		Bank:          payment.Bank,
+		CustomerName:  user.Name,
+		CustomerEmail: user.Email,
+		CustomerPhone: user.Phone,
+		ExpiresAt:     expiresAt,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("gagal membuat transaksi di payment gateway: %w", err)
+	}
+	payment.GatewayReference = result.Reference
+	payment.PaymentToken = result.Token
+	payment.RedirectURL = result.RedirectURL
+	payment.VANumber = result.VANumber
+	payment.QRString = result.QRString
+
+	if err := s.paymentRepo.CreatePayment(payment); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.orderSvc.TransitionOrder(order.ID, model.OrderStatusAwaitingPayment); err != nil {
+		return nil, err
+	}
+	return payment, nil
+}
+
+func (s *PaymentService) GetPayment(id uuid.UUID) (*model.Payment, error) {
+	payment, err := s.paymentRepo.GetPaymentByID(id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrPaymentNotFound
+		}
+		return nil, err
+	}
+	return payment, nil
+}
+
+// GetMyPayment returns the payment only to the user who owns its order.
+func (s *PaymentService) GetMyPayment(actor model.Actor, id uuid.UUID) (*model.Payment, error) {
+	payment, err := s.GetPayment(id)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.orderSvc.GetMyOrder(actor, payment.OrderID); err != nil {
+		if errors.Is(err, ErrOrderNotFound) {
+			return nil, ErrPaymentNotFound
+		}
+		return nil, err
+	}
+	return payment, nil
+}
+
+// RefreshStatus asks the gateway for the latest state, for when a callback
+// was missed.
+func (s *PaymentService) RefreshStatus(actor model.Actor, id uuid.UUID) (*model.Payment, error) {
+	payment, err := s.GetMyPayment(actor, id)
+	if err != nil {
+		return nil, err
+	}
+	if payment.Status != model.PaymentStatusPending {
+		return payment, nil
+	}
+
+	status, err := s.gateway.GetStatus(payment.ExternalID)
+	if err != nil {
+		return nil, fmt.Errorf("gagal mengambil status dari payment gateway: %w", err)
+	}
+	if err := s.apply(payment, status); err != nil {
+		return nil, err
+	}
+	return s.GetPayment(id)
+}
+
+// HandleNotification processes a gateway callback. The signature is checked
+// by the gateway adapter; replayed or out-of-order callbacks are ignored
+// because a payment only leaves the pending state once.
+func (s *PaymentService) HandleNotification(gatewayName string, body []byte) error {
+	if gatewayName != s.gateway.Name() {
+		return fmt.Errorf("payment gateway %w", ErrNotFound)
+	}
+
+	status, err := s.gateway.ParseNotification(body)
+	if err != nil {
+		return err
+	}
+
+	payment, err := s.paymentRepo.GetPaymentByExternalID(status.ExternalID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return ErrPaymentNotFound
+		}
+		return err
+	}
+	return s.apply(payment, status)
+}
+
+// Simulate drives the simulator gateway for the actor's own payment and feeds
+// the signed notification it produces through the regular callback path.
+func (s *PaymentService) Simulate(actor model.Actor, id uuid.UUID, req *model.SimulatePaymentRequest) (*model.Payment, error) {
+	simulator, ok := s.gateway.(*SimulatorGateway)
+	if !ok {
+		return nil, errors.New("simulasi hanya tersedia untuk simulator gateway")
+	}
+
+	payment, err := s.GetMyPayment(actor, id)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := simulator.Simulate(payment.ExternalID, req.Result, payment.Amount, req.MaskedCard)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.HandleNotification(simulator.Name(), body); err != nil {
+		return nil, err
+	}
+	return s.GetPayment(id)
+}
+
+func (s *PaymentService) apply(payment *model.Payment, status *model.GatewayStatus) error {
+	if status.Status == model.PaymentStatusPending {
+		return nil
+	}
+	if status.Amount != payment.Amount {
+		return fmt.Errorf("nominal pembayaran tidak sesuai: %d != %d", status.Amount, payment.Amount)
+	}
+
+	instrumentHash := ""
+	if status.Instrument != "" {
+		instrumentHash = utils.HashIdentifier(s.config.IdentifierHashKey, status.Instrument)
+	}
+	updated, err := s.paymentRepo.UpdatePaymentStatus(payment.ID, model.PaymentStatusPending, status.Status, status.Reference, instrumentHash, status.Raw)
+	if err != nil {
+		return err
+	}
+	if status.Status != model.PaymentStatusPaid || (!updated && payment.Status != model.PaymentStatusPaid) {
+		return nil
+	}
+
+	order, err := s.orderSvc.GetOrder(payment.OrderID)
+	if err != nil {
+		return err
+	}
+	if order.Status != model.OrderStatusPaid && !model.CanTransitionOrder(order.Status, model.OrderStatusPaid) {
+		// Money arrived after the order expired or was cancelled
+		log.Printf("Payment %s settled but order %s is %s", payment.ID, order.ID, order.Status)
+		return s.refundSvc.RefundPayment(payment, order, fmt.Sprintf("pembayaran diterima saat order berstatus %s", order.Status))
+	}
+
+	limit, err := s.purchaseLimitSvc.InstrumentLimit(order, status.Instrument)
+	if err != nil {
+		return err
+	}
+	if _, err := s.orderSvc.MarkPaid(order.ID, limit); err != nil {
+		if !errors.Is(err, ErrPurchaseLimit) {
+			return err
+		}
+		// The card already bought its limit; the tickets go back on sale
+		// and the money is paid back
+		log.Printf("Payment %s exceeds the instrument limit, cancelling order %s: %v", payment.ID, order.ID, err)
+		if _, err := s.orderSvc.TransitionOrder(order.ID, model.OrderStatusCancelled); err != nil {
+			log.Printf("Failed to cancel order %s: %v", order.ID, err)
+		}
+		return s.refundSvc.RefundPayment(payment, order, "instrumen pembayaran sudah mencapai batas pembelian tiket event ini")
+	}
+	if err := s.refundDuplicates(order); err != nil {
+		return err
+	}
+
+	// Issuing is idempotent, so a failure here is returned to make the
+	// gateway retry the callback, which fills in the missing tickets
+	if _, err := s.ticketSvc.IssueTickets(payment.OrderID); err != nil {
+		return fmt.Errorf("gagal menerbitkan tiket: %w", err)
+	}
+	if err := s.resaleSvc.CompleteSales(order); err != nil {
+		return fmt.Errorf("gagal menerbitkan tiket resale: %w", err)
+	}
+	return nil
+}
+
+// refundDuplicates pays back every settled payment of the order but the
+// first, for buyers who paid one order twice, e.g. with two methods. Each
+// settling callback runs it, so the payment that settles last sees the
+// others even when two settle at once.
+func (s *PaymentService) refundDuplicates(order *model.Order) error {
+	payments, err := s.paymentRepo.GetPaymentsByOrder(order.ID)
+	if err != nil {
+		return err
+	}
+
+	var first *model.Payment
+	for i := range payments {
+		p := &payments[i]
+		if p.PaidAt == nil {
+			continue
+		}
+		if first == nil || p.PaidAt.Before(*first.PaidAt) ||
+			(p.PaidAt.Equal(*first.PaidAt) && p.ID.String() < first.ID.String()) {
+			first = p
+		}
+	}
+
+	for i := range payments {
+		p := &payments[i]
+		if p == first || p.Status != model.PaymentStatusPaid {
+			continue
+		}
+		if err := s.refundSvc.RefundPayment(p, order, "order sudah dibayar dengan pembayaran lain"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
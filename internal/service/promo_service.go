@@ -0,0 +1,345 @@
+package service
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"e-ticketing/internal/model"
+	"e-ticketing/internal/repository"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// promoCodeAlphabet leaves out look-alike characters (0/O, 1/I)
+	promoCodeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+	// promoCodeSuffixLength random characters follow the prefix of a
+	// generated code (40 random bits)
+	promoCodeSuffixLength = 8
+	// generateAttempts bounds the rounds spent replacing codes that collided
+	generateAttempts = 5
+)
+
+type PromoService struct {
+	promoRepo     *repository.PromoRepository
+	ticketTypeSvc *TicketTypeService
+	eventSvc      *EventService
+}
+
+func NewPromoService(promoRepo *repository.PromoRepository, ticketTypeSvc *TicketTypeService, eventSvc *EventService) *PromoService {
+	return &PromoService{
+		promoRepo:     promoRepo,
+		ticketTypeSvc: ticketTypeSvc,
+		eventSvc:      eventSvc,
+	}
+}
+
+func (s *PromoService) CreatePromotion(actor model.Actor, eventID uuid.UUID, req *model.CreatePromotionRequest) (*model.Promotion, error) {
+	if _, err := s.eventSvc.AuthorizeEvent(actor, eventID); err != nil {
+		return nil, err
+	}
+
+	promo := &model.Promotion{EventID: eventID, Active: true}
+	if err := s.apply(promo, req); err != nil {
+		return nil, err
+	}
+	if err := s.promoRepo.CreatePromotion(promo); err != nil {
+		return nil, err
+	}
+	return promo, nil
+}
+
+func (s *PromoService) UpdatePromotion(actor model.Actor, eventID, id uuid.UUID, req *model.UpdatePromotionRequest) (*model.Promotion, error) {
+	promo, err := s.authorizedPromotion(actor, eventID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.apply(promo, req); err != nil {
+		return nil, err
+	}
+	if err := s.promoRepo.UpdatePromotion(promo); err != nil {
+		return nil, err
+	}
+	return promo, nil
+}
+
+func (s *PromoService) GetPromotion(actor model.Actor, eventID, id uuid.UUID) (*model.Promotion, error) {
+	return s.authorizedPromotion(actor, eventID, id)
+}
+
+func (s *PromoService) ListPromotions(actor model.Actor, eventID uuid.UUID, req *model.ListPromotionsRequest) (*model.PaginatedResponse, error) {
+	if _, err := s.eventSvc.AuthorizeEvent(actor, eventID); err != nil {
+		return nil, err
+	}
+	req.Normalize()
+
+	promotions, total, err := s.promoRepo.ListPromotions(eventID, req.Limit, req.Offset())
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.PaginatedResponse{
+		Items: promotions,
+		Pagination: model.PaginationMeta{
+			Page:  req.Page,
+			Limit: req.Limit,
+			Total: total,
+		},
+	}, nil
+}
+
+// CreateCode adds a code of the organizer's choosing, e.g. a shared
+// campaign code.
+func (s *PromoService) CreateCode(actor model.Actor, eventID, promotionID uuid.UUID, req *model.CreatePromoCodeRequest) (*model.PromoCode, error) {
+	if _, err := s.authorizedPromotion(actor, eventID, promotionID); err != nil {
+		return nil, err
+	}
+
+	code := &model.PromoCode{
+		PromotionID: promotionID,
+		EventID:     eventID,
+		Code:        normalizePromoCode(req.Code),
+		MaxUses:     req.MaxUses,
+	}
+	if err := s.promoRepo.CreateCode(code); err != nil {
+		if repository.IsUniqueViolation(err) {
+			return nil, fmt.Errorf("kode promo sudah dipakai di event ini: %w", ErrConflict)
+		}
+		return nil, err
+	}
+	return code, nil
+}
+
+// GenerateCodes creates single-use codes in bulk, e.g. for handing out to
+// partners one code per person.
+func (s *PromoService) GenerateCodes(actor model.Actor, eventID, promotionID uuid.UUID, req *model.GeneratePromoCodesRequest) ([]model.PromoCode, error) {
+	if _, err := s.authorizedPromotion(actor, eventID, promotionID); err != nil {
+		return nil, err
+	}
+
+	prefix := normalizePromoCode(req.Prefix)
+	singleUse := 1
+	codes := []model.PromoCode{}
+	for attempt := 0; attempt < generateAttempts && len(codes) < req.Count; attempt++ {
+		batch := make([]model.PromoCode, 0, req.Count-len(codes))
+		for i := len(codes); i < req.Count; i++ {
+			batch = append(batch, model.PromoCode{
+				PromotionID: promotionID,
+				EventID:     eventID,
+				Code:        prefix + generatePromoSuffix(),
+				MaxUses:     &singleUse,
+			})
+		}
+		created, err := s.promoRepo.CreateCodes(batch)
+		if err != nil {
+			return nil, err
+		}
+		codes = append(codes, created...)
+	}
+	if len(codes) < req.Count {
+		return nil, fmt.Errorf("hanya %d dari %d kode promo berhasil dibuat, coba lagi", len(codes), req.Count)
+	}
+	return codes, nil
+}
+
+func (s *PromoService) ListCodes(actor model.Actor, eventID, promotionID uuid.UUID, req *model.ListPromoCodesRequest) (*model.PaginatedResponse, error) {
+	if _, err := s.authorizedPromotion(actor, eventID, promotionID); err != nil {
+		return nil, err
+	}
+	req.Normalize()
+
+	codes, total, err := s.promoRepo.ListCodes(promotionID, req.Limit, req.Offset())
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.PaginatedResponse{
+		Items: codes,
+		Pagination: model.PaginationMeta{
+			Page:  req.Page,
+			Limit: req.Limit,
+			Total: total,
+		},
+	}, nil
+}
+
+// Apply evaluates the code against the actor's order and prices the
+// discount into it. The returned redemption must be recorded with the
+// order, which is where the usage limits are enforced atomically; the checks
+// here only give early, specific errors.
+func (s *PromoService) Apply(actor model.Actor, order *model.Order, rawCode string) (*model.PromoRedemption, error) {
+	code, err := s.promoRepo.GetCode(order.EventID, normalizePromoCode(rawCode))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.New("kode promo tidak valid")
+		}
+		return nil, err
+	}
+	promo, err := s.promoRepo.GetPromotion(code.PromotionID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !promo.IsValid(time.Now()) {
+		return nil, errors.New("kode promo tidak berlaku saat ini")
+	}
+	if (promo.UsageLimit != nil && promo.UsageCount >= *promo.UsageLimit) ||
+		(code.MaxUses != nil && code.UsageCount >= *code.MaxUses) {
+		return nil, fmt.Errorf("kuota kode promo sudah habis: %w", ErrConflict)
+	}
+	if promo.PerUserLimit != nil {
+		used, err := s.promoRepo.CountUserRedemptions(promo.ID, actor.UserID)
+		if err != nil {
+			return nil, err
+		}
+		if used >= *promo.PerUserLimit {
+			return nil, fmt.Errorf("batas penggunaan kode promo per akun tercapai: %w", ErrConflict)
+		}
+	}
+
+	if err := applyPromotion(promo, order); err != nil {
+		return nil, err
+	}
+	order.PromoCode = code.Code
+
+	return &model.PromoRedemption{
+		PromotionID:    promo.ID,
+		PromoCodeID:    code.ID,
+		UserID:         actor.UserID,
+		DiscountAmount: order.DiscountAmount,
+	}, nil
+}
+
+// applyPromotion works out the promotion's discount on the order's eligible
+// items and takes it off the total. The discount is spread over the
+// eligible items in proportion to their subtotals, so each item knows what
+// was actually paid for it.
+func applyPromotion(promo *model.Promotion, order *model.Order) error {
+	var subtotal, eligible int64
+	for _, item := range order.Items {
+		subtotal += item.Subtotal
+		if promo.AppliesTo(item.TicketTypeID) {
+			eligible += item.Subtotal
+		}
+	}
+	if eligible == 0 {
+		return errors.New("kode promo tidak berlaku untuk tiket yang dipilih")
+	}
+	if subtotal < promo.MinOrderAmount {
+		return fmt.Errorf("kode promo hanya berlaku untuk pembelian minimal %d %s", promo.MinOrderAmount, order.Currency)
+	}
+
+	discount := promo.DiscountValue
+	if promo.DiscountType == model.DiscountTypePercentage {
+		discount = eligible * promo.DiscountValue / 100
+		if promo.MaxDiscount != nil && discount > *promo.MaxDiscount {
+			discount = *promo.MaxDiscount
+		}
+	}
+	if discount > eligible {
+		discount = eligible
+	}
+
+	// The last eligible item absorbs the rounding remainder
+	remaining := discount
+	last := -1
+	for i := range order.Items {
+		item := &order.Items[i]
+		if !promo.AppliesTo(item.TicketTypeID) {
+			continue
+		}
+		item.DiscountAmount = discount * item.Subtotal / eligible
+		remaining -= item.DiscountAmount
+		last = i
+	}
+	order.Items[last].DiscountAmount += remaining
+
+	order.DiscountAmount = discount
+	order.TotalAmount = subtotal - discount
+	return nil
+}
+
+// apply copies the request onto the promotion and validates it against the
+// event's ticket types.
+func (s *PromoService) apply(promo *model.Promotion, req *model.CreatePromotionRequest) error {
+	if req.DiscountType == model.DiscountTypePercentage && req.DiscountValue > 100 {
+		return errors.New("diskon persentase maksimal 100")
+	}
+	if req.DiscountType == model.DiscountTypeFixed && req.MaxDiscount != nil {
+		return errors.New("batas diskon hanya untuk diskon persentase")
+	}
+	if req.StartsAt != nil && req.EndsAt != nil && !req.EndsAt.After(*req.StartsAt) {
+		return errors.New("waktu berakhir promo harus setelah waktu mulai")
+	}
+
+	ticketTypeIDs := make([]uuid.UUID, 0, len(req.TicketTypeIDs))
+	seen := map[uuid.UUID]bool{}
+	for _, raw := range req.TicketTypeIDs {
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			return errors.New("ticket type ID tidak valid")
+		}
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		ticketType, err := s.ticketTypeSvc.GetTicketType(id)
+		if err != nil {
+			return err
+		}
+		if ticketType.EventID != promo.EventID {
+			return ErrTicketTypeNotFound
+		}
+		ticketTypeIDs = append(ticketTypeIDs, id)
+	}
+
+	promo.Name = req.Name
+	promo.DiscountType = req.DiscountType
+	promo.DiscountValue = req.DiscountValue
+	promo.MaxDiscount = req.MaxDiscount
+	promo.MinOrderAmount = req.MinOrderAmount
+	promo.UsageLimit = req.UsageLimit
+	promo.PerUserLimit = req.PerUserLimit
+	promo.StartsAt = req.StartsAt
+	promo.EndsAt = req.EndsAt
+	promo.TicketTypeIDs = ticketTypeIDs
+	if req.Active != nil {
+		promo.Active = *req.Active
+	}
+	return nil
+}
+
+func (s *PromoService) authorizedPromotion(actor model.Actor, eventID, id uuid.UUID) (*model.Promotion, error) {
+	if _, err := s.eventSvc.AuthorizeEvent(actor, eventID); err != nil {
+		return nil, err
+	}
+	promo, err := s.promoRepo.GetPromotion(id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrPromotionNotFound
+		}
+		return nil, err
+	}
+	if promo.EventID != eventID {
+		return nil, ErrPromotionNotFound
+	}
+	return promo, nil
+}
+
+func normalizePromoCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+func generatePromoSuffix() string {
+	suffix := make([]byte, promoCodeSuffixLength)
+	for i := range suffix {
+		num, _ := rand.Int(rand.Reader, big.NewInt(int64(len(promoCodeAlphabet))))
+		suffix[i] = promoCodeAlphabet[num.Int64()]
+	}
+	return string(suffix)
+}
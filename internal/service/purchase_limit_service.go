@@ -0,0 +1,222 @@
+package service
+
+import (
+	"database/sql"
+	"e-ticketing/config"
+	"e-ticketing/internal/model"
+	"e-ticketing/internal/repository"
+	"e-ticketing/pkg/utils"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// defaultStepUpWindow is how recent an OTP step-up must be, in minutes.
+const defaultStepUpWindow = 15
+
+// PurchaseLimitService enforces an event's anti-scalping policy and collects
+// the device and instrument signals organizers review.
+type PurchaseLimitService struct {
+	purchaseLimitRepo *repository.PurchaseLimitRepository
+	userRepo          *repository.UserRepository
+	eventSvc          *EventService
+	config            *config.Config
+}
+
+func NewPurchaseLimitService(purchaseLimitRepo *repository.PurchaseLimitRepository, userRepo *repository.UserRepository, eventSvc *EventService, cfg *config.Config) *PurchaseLimitService {
+	return &PurchaseLimitService{
+		purchaseLimitRepo: purchaseLimitRepo,
+		userRepo:          userRepo,
+		eventSvc:          eventSvc,
+		config:            cfg,
+	}
+}
+
+func (s *PurchaseLimitService) ConfigurePolicy(actor model.Actor, eventID uuid.UUID, req *model.ConfigurePurchasePolicyRequest) (*model.PurchasePolicy, error) {
+	event, err := s.eventSvc.AuthorizeEvent(actor, eventID)
+	if err != nil {
+		return nil, err
+	}
+	if event.Status == model.EventStatusCancelled || event.Status == model.EventStatusFinished {
+		return nil, errors.New("event yang sudah dibatalkan atau selesai tidak dapat diubah")
+	}
+
+	policy := &model.PurchasePolicy{
+		EventID:                 eventID,
+		MaxTicketsPerUser:       req.MaxTicketsPerUser,
+		MaxTicketsPerPhone:      req.MaxTicketsPerPhone,
+		MaxTicketsPerInstrument: req.MaxTicketsPerInstrument,
+		RequireStepUp:           req.RequireStepUp,
+		StepUpWindowMinutes:     req.StepUpWindowMinutes,
+	}
+	if policy.StepUpWindowMinutes == 0 {
+		policy.StepUpWindowMinutes = defaultStepUpWindow
+	}
+
+	if err := s.purchaseLimitRepo.SavePolicy(policy); err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
+
+// GetPolicy shows the rules of a public event, or of any event to its
+// managers, so buyers know the limits before checkout.
+func (s *PurchaseLimitService) GetPolicy(actor model.Actor, eventID uuid.UUID) (*model.PurchasePolicy, error) {
+	managed := false
+	if !actor.IsAnonymous() {
+		if _, err := s.eventSvc.AuthorizeEvent(actor, eventID); err == nil {
+			managed = true
+		}
+	}
+	if !managed {
+		if _, err := s.eventSvc.GetPublicEvent(eventID); err != nil {
+			return nil, err
+		}
+	}
+	return s.policy(eventID)
+}
+
+// HoldLimit returns the per-user and per-phone limits a hold of quantity
+// more tickets must stay within, and records the device the request came
+// from. The repository enforces them in the transaction that places the
+// hold, so concurrent requests cannot both fit under a limit; it is nil when
+// the event sets neither limit.
+func (s *PurchaseLimitService) HoldLimit(actor model.Actor, eventID uuid.UUID, quantity int, deviceID string) (*model.HoldLimit, error) {
+	s.recordSignal(eventID, actor.UserID, model.SignalDevice, deviceID)
+
+	policy, err := s.policy(eventID)
+	if err != nil {
+		return nil, err
+	}
+	if policy.MaxTicketsPerUser == 0 && policy.MaxTicketsPerPhone == 0 {
+		return nil, nil
+	}
+	return &model.HoldLimit{
+		EventID:     eventID,
+		UserID:      actor.UserID,
+		Quantity:    quantity,
+		MaxPerUser:  policy.MaxTicketsPerUser,
+		MaxPerPhone: policy.MaxTicketsPerPhone,
+	}, nil
+}
+
+// CheckCheckout requires a recent OTP step-up when the policy asks for one.
+func (s *PurchaseLimitService) CheckCheckout(actor model.Actor, eventID uuid.UUID, deviceID string) error {
+	s.recordSignal(eventID, actor.UserID, model.SignalDevice, deviceID)
+
+	policy, err := s.policy(eventID)
+	if err != nil {
+		return err
+	}
+	if !policy.RequireStepUp {
+		return nil
+	}
+
+	stepUpAt, err := s.userRepo.GetStepUpAt(actor.UserID)
+	if err != nil {
+		return err
+	}
+	window := time.Duration(policy.StepUpWindowMinutes) * time.Minute
+	if stepUpAt == nil || time.Since(*stepUpAt) > window {
+		return ErrStepUpRequired
+	}
+	return nil
+}
+
+// InstrumentLimit returns the per-instrument limit the order must stay within
+// once the gateway reports which card or account paid, or nil when there is
+// none. The limit is checked when the order is marked paid.
+func (s *PurchaseLimitService) InstrumentLimit(order *model.Order, instrument string) (*model.InstrumentLimit, error) {
+	if instrument == "" {
+		return nil, nil
+	}
+	hash := utils.HashIdentifier(s.config.IdentifierHashKey, instrument)
+	s.recordSignal(order.EventID, order.UserID, model.SignalInstrument, instrument)
+
+	policy, err := s.policy(order.EventID)
+	if err != nil {
+		return nil, err
+	}
+	if policy.MaxTicketsPerInstrument == 0 {
+		return nil, nil
+	}
+
+	quantity := 0
+	for _, item := range order.Items {
+		quantity += item.Quantity
+	}
+	return &model.InstrumentLimit{
+		EventID:        order.EventID,
+		OrderID:        order.ID,
+		InstrumentHash: hash,
+		Quantity:       quantity,
+		Max:            policy.MaxTicketsPerInstrument,
+	}, nil
+}
+
+// ListClusters returns accounts sharing a device or payment instrument for
+// the organizer to review. Contact details are masked.
+func (s *PurchaseLimitService) ListClusters(actor model.Actor, eventID uuid.UUID) ([]model.PurchaseCluster, error) {
+	if _, err := s.eventSvc.AuthorizeEvent(actor, eventID); err != nil {
+		return nil, err
+	}
+
+	clusters, err := s.purchaseLimitRepo.ListClusters(eventID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range clusters {
+		clusters[i].ID = clusters[i].ID[:12]
+		for j := range clusters[i].Accounts {
+			account := &clusters[i].Accounts[j]
+			account.Email = utils.MaskEmail(account.Email)
+			account.Phone = utils.MaskPhone(account.Phone)
+		}
+	}
+	return clusters, nil
+}
+
+// policy returns the event's policy, or an empty one without limits.
+func (s *PurchaseLimitService) policy(eventID uuid.UUID) (*model.PurchasePolicy, error) {
+	policy, err := s.purchaseLimitRepo.GetPolicy(eventID)
+	if err == sql.ErrNoRows {
+		return &model.PurchasePolicy{EventID: eventID}, nil
+	}
+	return policy, err
+}
+
+// recordSignal is best effort; a failed write must not block a purchase.
+func (s *PurchaseLimitService) recordSignal(eventID, userID uuid.UUID, kind, value string) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return
+	}
+	if err := s.purchaseLimitRepo.RecordSignal(eventID, userID, kind, utils.HashIdentifier(s.config.IdentifierHashKey, value)); err != nil {
+		log.Printf("Failed to record %s signal for user %s: %v", kind, userID, err)
+	}
+}
+
+// holdError turns a limit the repository enforced while placing a hold or
+// paying an order into ErrPurchaseLimit; other errors are returned as is.
+func holdError(err error) error {
+	var limitErr *repository.PurchaseLimitError
+	if !errors.As(err, &limitErr) {
+		return err
+	}
+	scope := "akun"
+	switch limitErr.Scope {
+	case repository.LimitScopePhone:
+		scope = "nomor telepon"
+	case repository.LimitScopeInstrument:
+		scope = "instrumen pembayaran"
+	}
+	return limitError(scope, limitErr.Limit, limitErr.Current)
+}
+
+func limitError(scope string, limit, current int) error {
+	return fmt.Errorf("maksimal %d tiket per %s untuk event ini, sudah %d: %w", limit, scope, current, ErrPurchaseLimit)
+}
@@ -0,0 +1,587 @@
+package service
+
+import (
+	"database/sql"
+	"e-ticketing/internal/model"
+	"e-ticketing/internal/repository"
+	"errors"
+	"fmt"
+	"html"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RefundService handles buyers' refund requests. The event's organizer or an
+// admin approves them, which voids the tickets and returns them to stock
+// before the money is paid back through the payment gateway.
+type RefundService struct {
+	refundRepo  *repository.RefundRepository
+	paymentRepo *repository.PaymentRepository
+	userRepo    *repository.UserRepository
+	orderSvc    *OrderService
+	eventSvc    *EventService
+	notifSvc    *NotificationService
+	gateway     PaymentGateway
+}
+
+func NewRefundService(refundRepo *repository.RefundRepository, paymentRepo *repository.PaymentRepository, userRepo *repository.UserRepository, orderSvc *OrderService, eventSvc *EventService, notifSvc *NotificationService, gateway PaymentGateway) *RefundService {
+	return &RefundService{
+		refundRepo:  refundRepo,
+		paymentRepo: paymentRepo,
+		userRepo:    userRepo,
+		orderSvc:    orderSvc,
+		eventSvc:    eventSvc,
+		notifSvc:    notifSvc,
+		gateway:     gateway,
+	}
+}
+
+func (s *RefundService) ConfigurePolicy(actor model.Actor, eventID uuid.UUID, req *model.ConfigureRefundPolicyRequest) (*model.RefundPolicy, error) {
+	event, err := s.eventSvc.AuthorizeEvent(actor, eventID)
+	if err != nil {
+		return nil, err
+	}
+	if event.Status == model.EventStatusCancelled || event.Status == model.EventStatusFinished {
+		return nil, errors.New("event yang sudah dibatalkan atau selesai tidak dapat diubah")
+	}
+
+	policy := &model.RefundPolicy{
+		EventID:                  eventID,
+		Enabled:                  *req.Enabled,
+		DeadlineHoursBeforeStart: req.DeadlineHoursBeforeStart,
+		FeePercent:               req.FeePercent,
+	}
+	if err := s.refundRepo.SavePolicy(policy); err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
+
+// GetPolicy shows the refund rules of a public event, or of any event to its
+// managers.
+func (s *RefundService) GetPolicy(actor model.Actor, eventID uuid.UUID) (*model.RefundPolicy, error) {
+	managed := false
+	if !actor.IsAnonymous() {
+		if _, err := s.eventSvc.AuthorizeEvent(actor, eventID); err == nil {
+			managed = true
+		}
+	}
+	if !managed {
+		if _, err := s.eventSvc.GetPublicEvent(eventID); err != nil {
+			return nil, err
+		}
+	}
+	return s.policy(eventID)
+}
+
+// RequestRefund asks for the listed tickets of the actor's paid order, or all
+// of its refundable ones, to be paid back. Each ticket is priced at what was
+// actually paid for it after the order's discount.
+func (s *RefundService) RequestRefund(actor model.Actor, orderID uuid.UUID, req *model.CreateRefundRequest) (*model.Refund, error) {
+	order, err := s.orderSvc.GetMyOrder(actor, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if order.Status != model.OrderStatusPaid {
+		return nil, fmt.Errorf("order dengan status %s tidak dapat direfund", order.Status)
+	}
+
+	event, err := s.eventSvc.GetEvent(order.EventID)
+	if err != nil {
+		return nil, err
+	}
+	policy, err := s.policy(event.ID)
+	if err != nil {
+		return nil, err
+	}
+	feePercent, err := refundTerms(policy, event, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	refundable, err := s.refundRepo.RefundableTickets(order.ID, actor.UserID)
+	if err != nil {
+		return nil, err
+	}
+	tickets := refundable
+	if len(req.TicketIDs) > 0 {
+		byID := map[uuid.UUID]model.Ticket{}
+		for _, t := range refundable {
+			byID[t.ID] = t
+		}
+		tickets = make([]model.Ticket, 0, len(req.TicketIDs))
+		seen := map[uuid.UUID]bool{}
+		for _, raw := range req.TicketIDs {
+			id, err := uuid.Parse(raw)
+			if err != nil {
+				return nil, errors.New("ticket ID tidak valid")
+			}
+			if seen[id] {
+				continue
+			}
+			seen[id] = true
+			ticket, ok := byID[id]
+			if !ok {
+				return nil, fmt.Errorf("tiket %s tidak dapat direfund: tiket sudah dipakai, dibatalkan, dijual atau sedang ditransfer", id)
+			}
+			tickets = append(tickets, ticket)
+		}
+	}
+	if len(tickets) == 0 {
+		return nil, errors.New("tidak ada tiket yang dapat direfund pada order ini")
+	}
+
+	items := map[uuid.UUID]*model.OrderItem{}
+	for i := range order.Items {
+		items[order.Items[i].ID] = &order.Items[i]
+	}
+
+	refund := &model.Refund{
+		OrderID:  order.ID,
+		EventID:  order.EventID,
+		UserID:   actor.UserID,
+		Status:   model.RefundStatusRequested,
+		Reason:   req.Reason,
+		Currency: order.Currency,
+	}
+	for _, t := range tickets {
+		item, ok := items[t.OrderItemID]
+		if !ok {
+			return nil, fmt.Errorf("tiket %s tidak dapat direfund", t.ID)
+		}
+		amount := ticketPaidAmount(item, t.Sequence)
+		refund.Items = append(refund.Items, model.RefundItem{TicketID: t.ID, Amount: amount})
+		refund.Amount += amount
+	}
+	refund.FeeAmount = refund.Amount * int64(feePercent) / 100
+	refund.RefundAmount = refund.Amount - refund.FeeAmount
+
+	if err := s.refundRepo.CreateRefund(refund); err != nil {
+		if repository.IsUniqueViolation(err) {
+			return nil, fmt.Errorf("order ini masih memiliki pengajuan refund yang menunggu persetujuan: %w", ErrConflict)
+		}
+		return nil, err
+	}
+	return s.getRefund(refund.ID)
+}
+
+func (s *RefundService) ListMyRefunds(actor model.Actor, req *model.ListRefundsRequest) (*model.PaginatedResponse, error) {
+	return s.list(&model.RefundFilter{UserID: &actor.UserID, Status: req.Status}, req)
+}
+
+// GetMyRefund returns a refund only to the buyer who requested it.
+func (s *RefundService) GetMyRefund(actor model.Actor, id uuid.UUID) (*model.Refund, error) {
+	refund, err := s.getRefund(id)
+	if err != nil {
+		return nil, err
+	}
+	if refund.UserID != actor.UserID {
+		return nil, ErrRefundNotFound
+	}
+	return refund, nil
+}
+
+// CancelRefund withdraws the actor's request before it is reviewed.
+func (s *RefundService) CancelRefund(actor model.Actor, id uuid.UUID) (*model.Refund, error) {
+	refund, err := s.GetMyRefund(actor, id)
+	if err != nil {
+		return nil, err
+	}
+	if refund.Status != model.RefundStatusRequested {
+		return nil, fmt.Errorf("refund berstatus %s tidak dapat dibatalkan", refund.Status)
+	}
+
+	cancelled, err := s.refundRepo.CancelRefund(id, actor.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if !cancelled {
+		return nil, fmt.Errorf("status refund sudah berubah: %w", ErrConflict)
+	}
+	return s.getRefund(id)
+}
+
+func (s *RefundService) ListEventRefunds(actor model.Actor, eventID uuid.UUID, req *model.ListRefundsRequest) (*model.PaginatedResponse, error) {
+	if _, err := s.eventSvc.AuthorizeEvent(actor, eventID); err != nil {
+		return nil, err
+	}
+	return s.list(&model.RefundFilter{EventID: &eventID, Status: req.Status}, req)
+}
+
+func (s *RefundService) GetEventRefund(actor model.Actor, eventID, id uuid.UUID) (*model.Refund, error) {
+	return s.authorizedRefund(actor, eventID, id)
+}
+
+// ApproveRefund voids the refund's tickets, returns them to stock and pays
+// the money back. Approving a refund whose payout failed tries the gateway
+// again. A gateway failure leaves the refund failed for a retry or for an
+// admin to settle by hand.
+func (s *RefundService) ApproveRefund(actor model.Actor, eventID, id uuid.UUID) (*model.Refund, error) {
+	refund, err := s.authorizedRefund(actor, eventID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	switch refund.Status {
+	case model.RefundStatusRequested:
+		approved, err := s.refundRepo.ApproveRefund(id, actor.UserID)
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("sebagian tiket sudah dipakai, dijual atau ditransfer sehingga refund tidak dapat disetujui: %w", ErrConflict)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if !approved {
+			return nil, fmt.Errorf("status refund sudah berubah: %w", ErrConflict)
+		}
+	case model.RefundStatusFailed:
+		retried, err := s.refundRepo.RetryRefund(id)
+		if err != nil {
+			return nil, err
+		}
+		if !retried {
+			return nil, fmt.Errorf("status refund sudah berubah: %w", ErrConflict)
+		}
+	default:
+		return nil, fmt.Errorf("refund berstatus %s tidak dapat disetujui", refund.Status)
+	}
+
+	return s.payBack(id)
+}
+
+func (s *RefundService) RejectRefund(actor model.Actor, eventID, id uuid.UUID, req *model.RejectRefundRequest) (*model.Refund, error) {
+	refund, err := s.authorizedRefund(actor, eventID, id)
+	if err != nil {
+		return nil, err
+	}
+	if refund.Status != model.RefundStatusRequested {
+		return nil, fmt.Errorf("refund berstatus %s tidak dapat ditolak", refund.Status)
+	}
+
+	rejected, err := s.refundRepo.RejectRefund(id, actor.UserID, req.Reason)
+	if err != nil {
+		return nil, err
+	}
+	if !rejected {
+		return nil, fmt.Errorf("status refund sudah berubah: %w", ErrConflict)
+	}
+
+	refund, err = s.getRefund(id)
+	if err != nil {
+		return nil, err
+	}
+	s.notifyOutcome(refund)
+	return refund, nil
+}
+
+// ListRefunds lists refunds across events, e.g. failed ones for admins to
+// settle.
+func (s *RefundService) ListRefunds(req *model.ListRefundsRequest) (*model.PaginatedResponse, error) {
+	return s.list(&model.RefundFilter{Status: req.Status}, req)
+}
+
+// CompleteRefund records a failed refund as paid back outside the gateway.
+func (s *RefundService) CompleteRefund(id uuid.UUID, req *model.CompleteRefundRequest) (*model.Refund, error) {
+	if _, err := s.getRefund(id); err != nil {
+		return nil, err
+	}
+	completed, err := s.refundRepo.CompleteRefund(id, model.RefundStatusFailed, req.Reference)
+	if err != nil {
+		return nil, err
+	}
+	if !completed {
+		return nil, fmt.Errorf("refund tidak sedang menunggu penyelesaian manual: %w", ErrConflict)
+	}
+
+	refund, err := s.getRefund(id)
+	if err != nil {
+		return nil, err
+	}
+	s.notifyOutcome(refund)
+	return refund, nil
+}
+
+// RefundPayment pays back a whole payment that cannot go towards its order,
+// e.g. one that settled after the order expired or a second payment of an
+// order already paid. It needs no review and is idempotent per payment; if
+// the gateway fails, the refund stays failed for an admin to settle.
+func (s *RefundService) RefundPayment(payment *model.Payment, order *model.Order, reason string) error {
+	refund := &model.Refund{
+		OrderID:      order.ID,
+		PaymentID:    &payment.ID,
+		EventID:      order.EventID,
+		UserID:       order.UserID,
+		Status:       model.RefundStatusApproved,
+		Reason:       reason,
+		Amount:       payment.Amount,
+		RefundAmount: payment.Amount,
+		Currency:     payment.Currency,
+	}
+	if err := s.refundRepo.CreateRefund(refund); err != nil {
+		if repository.IsUniqueViolation(err) {
+			return nil
+		}
+		return err
+	}
+
+	log.Printf("Refunding payment %s of order %s: %s", payment.ID, order.ID, reason)
+	_, err := s.payBack(refund.ID)
+	return err
+}
+
+// RefundUndelivered pays back a paid resale item whose listing could not be
+// delivered, e.g. because it was sold or withdrawn while the buyer paid. Like
+// RefundPayment it needs no review and is idempotent per item.
+func (s *RefundService) RefundUndelivered(order *model.Order, item *model.OrderItem, reason string) error {
+	amount := item.Subtotal - item.DiscountAmount
+	refund := &model.Refund{
+		OrderID:      order.ID,
+		OrderItemID:  &item.ID,
+		EventID:      order.EventID,
+		UserID:       order.UserID,
+		Status:       model.RefundStatusApproved,
+		Reason:       reason,
+		Amount:       amount,
+		RefundAmount: amount,
+		Currency:     order.Currency,
+	}
+	if err := s.refundRepo.CreateRefund(refund); err != nil {
+		if repository.IsUniqueViolation(err) {
+			return nil
+		}
+		return err
+	}
+
+	log.Printf("Refunding undelivered item %s of order %s: %s", item.ID, order.ID, reason)
+	_, err := s.payBack(refund.ID)
+	return err
+}
+
+// payBack sends an approved refund's money back through the gateway that
+// took the payment. Refunds of free tickets complete without it.
+func (s *RefundService) payBack(id uuid.UUID) (*model.Refund, error) {
+	refund, err := s.getRefund(id)
+	if err != nil {
+		return nil, err
+	}
+
+	reference := ""
+	if refund.RefundAmount > 0 {
+		result, err := s.gatewayRefund(refund)
+		if err != nil {
+			log.Printf("Refund %s of order %s failed: %v", refund.ID, refund.OrderID, err)
+			if _, err := s.refundRepo.FailRefund(id, err.Error()); err != nil {
+				return nil, err
+			}
+			return s.getRefund(id)
+		}
+		reference = result.Reference
+	}
+
+	if _, err := s.refundRepo.CompleteRefund(id, model.RefundStatusApproved, reference); err != nil {
+		return nil, err
+	}
+	refund, err = s.getRefund(id)
+	if err != nil {
+		return nil, err
+	}
+	s.notifyOutcome(refund)
+	return refund, nil
+}
+
+func (s *RefundService) gatewayRefund(refund *model.Refund) (*model.RefundResult, error) {
+	payment, err := s.refundedPayment(refund)
+	if err != nil {
+		return nil, err
+	}
+	if payment.Gateway != s.gateway.Name() {
+		return nil, fmt.Errorf("order dibayar melalui %s yang tidak aktif", payment.Gateway)
+	}
+
+	return s.gateway.Refund(&model.ChargeRefund{
+		ExternalID: payment.ExternalID,
+		RefundKey:  refund.ID.String(),
+		Amount:     refund.RefundAmount,
+		Reason:     refund.Reason,
+	})
+}
+
+// refundedPayment returns the payment a refund goes back to: its own for a
+// payment refund, otherwise the one that paid the order.
+func (s *RefundService) refundedPayment(refund *model.Refund) (*model.Payment, error) {
+	if refund.PaymentID != nil {
+		payment, err := s.paymentRepo.GetPaymentByID(*refund.PaymentID)
+		if err == sql.ErrNoRows {
+			return nil, errors.New("pembayaran order tidak ditemukan")
+		}
+		return payment, err
+	}
+
+	payments, err := s.paymentRepo.GetPaymentsByOrder(refund.OrderID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range payments {
+		if payments[i].Status == model.PaymentStatusPaid {
+			return &payments[i], nil
+		}
+	}
+	return nil, errors.New("pembayaran order tidak ditemukan")
+}
+
+func (s *RefundService) list(filter *model.RefundFilter, req *model.ListRefundsRequest) (*model.PaginatedResponse, error) {
+	req.Normalize()
+	filter.Limit = req.Limit
+	filter.Offset = req.Offset()
+
+	refunds, total, err := s.refundRepo.ListRefunds(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.PaginatedResponse{
+		Items: refunds,
+		Pagination: model.PaginationMeta{
+			Page:  req.Page,
+			Limit: req.Limit,
+			Total: total,
+		},
+	}, nil
+}
+
+func (s *RefundService) getRefund(id uuid.UUID) (*model.Refund, error) {
+	refund, err := s.refundRepo.GetRefund(id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrRefundNotFound
+		}
+		return nil, err
+	}
+	return refund, nil
+}
+
+func (s *RefundService) authorizedRefund(actor model.Actor, eventID, id uuid.UUID) (*model.Refund, error) {
+	if _, err := s.eventSvc.AuthorizeEvent(actor, eventID); err != nil {
+		return nil, err
+	}
+	refund, err := s.getRefund(id)
+	if err != nil {
+		return nil, err
+	}
+	if refund.EventID != eventID {
+		return nil, ErrRefundNotFound
+	}
+	return refund, nil
+}
+
+// policy returns the event's policy, or the default: no refunds until the
+// organizer allows them.
+func (s *RefundService) policy(eventID uuid.UUID) (*model.RefundPolicy, error) {
+	policy, err := s.refundRepo.GetPolicy(eventID)
+	if err == sql.ErrNoRows {
+		return &model.RefundPolicy{EventID: eventID}, nil
+	}
+	return policy, err
+}
+
+// refundTerms checks that the event's tickets may be refunded at now and
+// returns the fee percentage. A cancelled event refunds in full.
+func refundTerms(policy *model.RefundPolicy, event *model.Event, now time.Time) (int, error) {
+	if event.Status == model.EventStatusCancelled {
+		return 0, nil
+	}
+	if !policy.Enabled {
+		return 0, fmt.Errorf("event ini tidak menerima refund: %w", ErrForbidden)
+	}
+	if event.Status == model.EventStatusFinished {
+		return 0, errors.New("event sudah selesai")
+	}
+	deadline := event.StartTime.Add(-time.Duration(policy.DeadlineHoursBeforeStart) * time.Hour)
+	if !now.Before(deadline) {
+		return 0, errors.New("batas waktu pengajuan refund untuk event ini sudah lewat")
+	}
+	return policy.FeePercent, nil
+}
+
+// ticketPaidAmount is the share of the item's discounted subtotal paid for
+// its sequence-th ticket. The first tickets absorb the rounding remainder so
+// the shares add up to the item.
+func ticketPaidAmount(item *model.OrderItem, sequence int) int64 {
+	paid := item.Subtotal - item.DiscountAmount
+	quantity := int64(item.Quantity)
+	share := paid / quantity
+	if int64(sequence) <= paid%quantity {
+		share++
+	}
+	return share
+}
+
+// notifyOutcome tells the buyer a refund was paid back or rejected. It is
+// best effort; the outcome also shows in the buyer's refunds.
+func (s *RefundService) notifyOutcome(refund *model.Refund) {
+	user, err := s.userRepo.GetUserByID(refund.UserID)
+	if err != nil {
+		log.Printf("Failed to notify refund %s outcome: %v", refund.ID, err)
+		return
+	}
+
+	var template, subject, content, text string
+	switch {
+	case refund.Status == model.RefundStatusRefunded && (refund.PaymentID != nil || refund.OrderItemID != nil):
+		template = "refund_completed"
+		subject = fmt.Sprintf("Pembayaran order %s dikembalikan", refund.OrderNumber)
+		content = fmt.Sprintf(`
+			<p>Pembayaran sebesar <strong>%s %d</strong> untuk order <strong>%s</strong> (%s) sudah dikembalikan.</p>
+			<p>Alasan: %s</p>
+			<p>Dana akan diterima sesuai waktu proses metode pembayaran Anda.</p>`,
+			refund.Currency, refund.RefundAmount, html.EscapeString(refund.OrderNumber), html.EscapeString(refund.EventTitle),
+			html.EscapeString(refund.Reason))
+		text = fmt.Sprintf("Pembayaran sebesar *%s %d* untuk order *%s* (%s) sudah dikembalikan.\n\nAlasan: %s",
+			refund.Currency, refund.RefundAmount, refund.OrderNumber, refund.EventTitle, refund.Reason)
+	case refund.Status == model.RefundStatusRefunded:
+		template = "refund_completed"
+		subject = fmt.Sprintf("Refund order %s berhasil", refund.OrderNumber)
+		content = fmt.Sprintf(`
+			<p>Refund untuk %d tiket pada order <strong>%s</strong> (%s) sudah diproses.</p>
+			<p>Harga tiket: %s %d<br>Biaya refund: %s %d<br>Dana yang dikembalikan: <strong>%s %d</strong></p>
+			<p>Tiket yang direfund sudah tidak berlaku. Dana akan diterima sesuai waktu proses metode pembayaran Anda.</p>`,
+			len(refund.Items), html.EscapeString(refund.OrderNumber), html.EscapeString(refund.EventTitle),
+			refund.Currency, refund.Amount, refund.Currency, refund.FeeAmount, refund.Currency, refund.RefundAmount)
+		text = fmt.Sprintf("Refund order *%s* (%s) sudah diproses. Dana yang dikembalikan: *%s %d*.",
+			refund.OrderNumber, refund.EventTitle, refund.Currency, refund.RefundAmount)
+	case refund.Status == model.RefundStatusRejected:
+		template = "refund_rejected"
+		subject = fmt.Sprintf("Pengajuan refund order %s ditolak", refund.OrderNumber)
+		content = fmt.Sprintf(`
+			<p>Pengajuan refund untuk order <strong>%s</strong> (%s) ditolak oleh penyelenggara.</p>
+			<p>Alasan: %s</p>
+			<p>Tiket Anda tetap berlaku.</p>`,
+			html.EscapeString(refund.OrderNumber), html.EscapeString(refund.EventTitle), html.EscapeString(refund.RejectReason))
+		text = fmt.Sprintf("Pengajuan refund order *%s* (%s) ditolak oleh penyelenggara.\n\nAlasan: %s",
+			refund.OrderNumber, refund.EventTitle, refund.RejectReason)
+	default:
+		return
+	}
+
+	body := fmt.Sprintf(`
+		<html>
+		<body style="font-family: Arial, sans-serif; padding: 20px;">
+			<h2>Halo %s!</h2>
+			%s
+			<p>Salam,<br>Tim E-Ticketing</p>
+		</body>
+		</html>
+	`, html.EscapeString(user.Name), content)
+
+	if err := s.notifSvc.Notify(user, &model.Notification{
+		Category:     model.NotificationTransactional,
+		Template:     template,
+		Subject:      subject,
+		EmailBody:    body,
+		WhatsAppBody: fmt.Sprintf("Halo %s!\n\n%s\n\n- Tim E-Ticketing", user.Name, text),
+	}); err != nil {
+		log.Printf("Failed to notify refund %s outcome: %v", refund.ID, err)
+	}
+}
@@ -0,0 +1,540 @@
+package service
+
+import (
+	"database/sql"
+	"e-ticketing/config"
+	"e-ticketing/internal/model"
+	"e-ticketing/internal/repository"
+	"e-ticketing/pkg/utils"
+	"errors"
+	"fmt"
+	"html"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ResaleService runs the official resale marketplace. Buyers pay through a
+// normal order; once it is paid the seller's ticket is voided and reissued
+// to the buyer, and the seller is owed the price minus the platform fee.
+type ResaleService struct {
+	resaleRepo       *repository.ResaleRepository
+	userRepo         *repository.UserRepository
+	ticketSvc        *TicketService
+	orderSvc         *OrderService
+	eventSvc         *EventService
+	purchaseLimitSvc *PurchaseLimitService
+	attendeeSvc      *AttendeeService
+	refundSvc        *RefundService
+	notifSvc         *NotificationService
+	config           *config.Config
+}
+
+func NewResaleService(resaleRepo *repository.ResaleRepository, userRepo *repository.UserRepository, ticketSvc *TicketService, orderSvc *OrderService, eventSvc *EventService, purchaseLimitSvc *PurchaseLimitService, attendeeSvc *AttendeeService, refundSvc *RefundService, notifSvc *NotificationService, cfg *config.Config) *ResaleService {
+	return &ResaleService{
+		resaleRepo:       resaleRepo,
+		userRepo:         userRepo,
+		ticketSvc:        ticketSvc,
+		orderSvc:         orderSvc,
+		eventSvc:         eventSvc,
+		purchaseLimitSvc: purchaseLimitSvc,
+		attendeeSvc:      attendeeSvc,
+		refundSvc:        refundSvc,
+		notifSvc:         notifSvc,
+		config:           cfg,
+	}
+}
+
+func (s *ResaleService) ConfigurePolicy(actor model.Actor, eventID uuid.UUID, req *model.ConfigureResalePolicyRequest) (*model.ResalePolicy, error) {
+	event, err := s.eventSvc.AuthorizeEvent(actor, eventID)
+	if err != nil {
+		return nil, err
+	}
+	if event.Status == model.EventStatusCancelled || event.Status == model.EventStatusFinished {
+		return nil, errors.New("event yang sudah dibatalkan atau selesai tidak dapat diubah")
+	}
+
+	policy := &model.ResalePolicy{
+		EventID:                 eventID,
+		Enabled:                 *req.Enabled,
+		MaxPricePercent:         req.MaxPricePercent,
+		CloseMinutesBeforeStart: req.CloseMinutesBeforeStart,
+	}
+	if err := s.resaleRepo.SavePolicy(policy); err != nil {
+		return nil, err
+	}
+	policy.FeePercent = s.config.ResaleFeePercent
+	return policy, nil
+}
+
+// GetPolicy shows the resale rules of a public event, or of any event to its
+// managers.
+func (s *ResaleService) GetPolicy(actor model.Actor, eventID uuid.UUID) (*model.ResalePolicy, error) {
+	if err := s.viewEvent(actor, eventID); err != nil {
+		return nil, err
+	}
+	return s.policy(eventID)
+}
+
+// ListOffers lists an event's active listings, cheapest first, without
+// revealing who is selling.
+func (s *ResaleService) ListOffers(actor model.Actor, eventID uuid.UUID, req *model.ListResaleListingsRequest) (*model.PaginatedResponse, error) {
+	req.Normalize()
+
+	if err := s.viewEvent(actor, eventID); err != nil {
+		return nil, err
+	}
+
+	filter := &model.ResaleListingFilter{
+		EventID: &eventID,
+		Status:  model.ResaleStatusActive,
+		Limit:   req.Limit,
+		Offset:  req.Offset(),
+	}
+	if req.TicketTypeID != "" {
+		ticketTypeID, err := uuid.Parse(req.TicketTypeID)
+		if err != nil {
+			return nil, errors.New("ticket type ID tidak valid")
+		}
+		filter.TicketTypeID = &ticketTypeID
+	}
+
+	listings, total, err := s.resaleRepo.ListListings(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	offers := make([]model.ResaleOffer, len(listings))
+	for i, l := range listings {
+		offers[i] = model.ResaleOffer{
+			ID:             l.ID,
+			EventID:        l.EventID,
+			TicketTypeID:   l.TicketTypeID,
+			TicketTypeName: l.TicketTypeName,
+			Price:          l.Price,
+			FaceValue:      l.FaceValue,
+			Currency:       l.Currency,
+			Reserved:       l.Reserved,
+			Seat:           l.Seat,
+			CreatedAt:      l.CreatedAt,
+		}
+	}
+
+	return &model.PaginatedResponse{
+		Items: offers,
+		Pagination: model.PaginationMeta{
+			Page:  req.Page,
+			Limit: req.Limit,
+			Total: total,
+		},
+	}, nil
+}
+
+// CreateListing puts one of the actor's valid tickets up for resale at no
+// more than the event's cap on its face value.
+func (s *ResaleService) CreateListing(actor model.Actor, ticketID uuid.UUID, req *model.CreateResaleListingRequest) (*model.ResaleListing, error) {
+	ticket, err := s.ticketSvc.GetMyTicket(actor, ticketID)
+	if err != nil {
+		return nil, err
+	}
+	if ticket.Status != model.TicketStatusValid {
+		return nil, fmt.Errorf("tiket berstatus %s tidak dapat dijual kembali", ticket.Status)
+	}
+
+	event, err := s.eventSvc.GetEvent(ticket.EventID)
+	if err != nil {
+		return nil, err
+	}
+	policy, err := s.policy(event.ID)
+	if err != nil {
+		return nil, err
+	}
+	if err := resaleWindow(policy, event, time.Now()); err != nil {
+		return nil, err
+	}
+
+	faceValue, currency, err := s.resaleRepo.FaceValue(ticket.ID)
+	if err != nil {
+		return nil, err
+	}
+	if faceValue == 0 {
+		return nil, errors.New("tiket gratis tidak dapat dijual kembali")
+	}
+	maxPrice := faceValue * int64(policy.MaxPricePercent) / 100
+	if req.Price > maxPrice {
+		return nil, fmt.Errorf("harga jual maksimal %s %d (%d%% dari harga asli)", currency, maxPrice, policy.MaxPricePercent)
+	}
+
+	fee := req.Price * int64(policy.FeePercent) / 100
+	listing := &model.ResaleListing{
+		TicketID:     ticket.ID,
+		EventID:      ticket.EventID,
+		SellerUserID: actor.UserID,
+		Price:        req.Price,
+		FaceValue:    faceValue,
+		FeeAmount:    fee,
+		PayoutAmount: req.Price - fee,
+		Currency:     currency,
+		Status:       model.ResaleStatusActive,
+	}
+	if err := s.resaleRepo.CreateListing(listing); err != nil {
+		if repository.IsUniqueViolation(err) {
+			return nil, fmt.Errorf("tiket ini sudah dijual di resale: %w", ErrConflict)
+		}
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("tiket ini sedang dalam proses transfer: %w", ErrConflict)
+		}
+		return nil, err
+	}
+	return s.getListing(listing.ID)
+}
+
+func (s *ResaleService) ListMyListings(actor model.Actor, req *model.ListMyResaleListingsRequest) (*model.PaginatedResponse, error) {
+	req.Normalize()
+
+	listings, total, err := s.resaleRepo.ListListings(&model.ResaleListingFilter{
+		SellerID: &actor.UserID,
+		Status:   req.Status,
+		Limit:    req.Limit,
+		Offset:   req.Offset(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.PaginatedResponse{
+		Items: listings,
+		Pagination: model.PaginationMeta{
+			Page:  req.Page,
+			Limit: req.Limit,
+			Total: total,
+		},
+	}, nil
+}
+
+// GetMyListing returns a listing only to its seller.
+func (s *ResaleService) GetMyListing(actor model.Actor, id uuid.UUID) (*model.ResaleListing, error) {
+	listing, err := s.getListing(id)
+	if err != nil {
+		return nil, err
+	}
+	if listing.SellerUserID != actor.UserID {
+		return nil, ErrResaleListingNotFound
+	}
+	return listing, nil
+}
+
+// WithdrawListing takes the actor's listing off sale unless a buyer is
+// already paying for it.
+func (s *ResaleService) WithdrawListing(actor model.Actor, id uuid.UUID) (*model.ResaleListing, error) {
+	listing, err := s.GetMyListing(actor, id)
+	if err != nil {
+		return nil, err
+	}
+	if listing.Status != model.ResaleStatusActive {
+		return nil, fmt.Errorf("listing berstatus %s tidak dapat ditarik", listing.Status)
+	}
+
+	withdrawn, err := s.resaleRepo.WithdrawListing(id, actor.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if !withdrawn {
+		return nil, fmt.Errorf("listing sedang dalam proses pembelian: %w", ErrConflict)
+	}
+	return s.getListing(id)
+}
+
+// BuyListing reserves a listing for the actor with a pending order at the
+// listing price, to be paid like any other order. Purchase limits and holder
+// details apply as for new tickets.
+func (s *ResaleService) BuyListing(actor model.Actor, id uuid.UUID, req *model.BuyResaleListingRequest) (*model.Order, error) {
+	user, err := s.userRepo.GetUserByID(actor.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if !user.IsVerified {
+		return nil, errors.New("akun belum terverifikasi")
+	}
+
+	listing, err := s.getListing(id)
+	if err != nil {
+		return nil, err
+	}
+	if listing.Status != model.ResaleStatusActive {
+		return nil, ErrResaleListingNotFound
+	}
+	if listing.SellerUserID == actor.UserID {
+		return nil, errors.New("tidak dapat membeli tiket yang Anda jual sendiri")
+	}
+	if listing.Reserved {
+		return nil, fmt.Errorf("tiket ini sedang dibeli orang lain: %w", ErrConflict)
+	}
+
+	event, err := s.eventSvc.GetEvent(listing.EventID)
+	if err != nil {
+		return nil, err
+	}
+	policy, err := s.policy(event.ID)
+	if err != nil {
+		return nil, err
+	}
+	if err := resaleWindow(policy, event, time.Now()); err != nil {
+		return nil, err
+	}
+
+	limit, err := s.purchaseLimitSvc.HoldLimit(actor, event.ID, 1, req.DeviceID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.purchaseLimitSvc.CheckCheckout(actor, event.ID, req.DeviceID); err != nil {
+		return nil, err
+	}
+	attendee, err := s.attendeeSvc.PrepareAttendee(event.ID, req.Attendee, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	item := model.OrderItem{
+		TicketTypeID:    listing.TicketTypeID,
+		ResaleListingID: &listing.ID,
+		Quantity:        1,
+		UnitPrice:       listing.Price,
+		Subtotal:        listing.Price,
+	}
+	if attendee != nil {
+		item.Attendees = []model.Attendee{*attendee}
+	}
+	order := &model.Order{
+		OrderNumber: utils.GenerateOrderNumber(),
+		UserID:      actor.UserID,
+		EventID:     event.ID,
+		Status:      model.OrderStatusPending,
+		TotalAmount: listing.Price,
+		Currency:    listing.Currency,
+		ExpiresAt:   time.Now().Add(time.Duration(s.config.OrderPaymentMinutes) * time.Minute),
+		Items:       []model.OrderItem{item},
+	}
+
+	ok, err := s.resaleRepo.CreateResaleOrder(order, listing.ID, limit)
+	if err != nil {
+		return nil, holdError(err)
+	}
+	if !ok {
+		return nil, fmt.Errorf("tiket ini sudah tidak tersedia atau sedang ditawarkan ke daftar tunggu: %w", ErrConflict)
+	}
+	return s.orderSvc.GetOrder(order.ID)
+}
+
+// CompleteSales reissues the resold tickets of a paid order to its buyer. It
+// is safe to call repeatedly. A listing that can no longer be delivered, e.g.
+// because the ticket was used meanwhile, is logged and left for a refund of
+// the order.
+func (s *ResaleService) CompleteSales(order *model.Order) error {
+	for i := range order.Items {
+		item := &order.Items[i]
+		if item.ResaleListingID == nil {
+			continue
+		}
+		listing, err := s.getListing(*item.ResaleListingID)
+		if err != nil {
+			return err
+		}
+		if listing.Status == model.ResaleStatusSold && listing.OrderID != nil && *listing.OrderID == order.ID {
+			continue
+		}
+
+		holderName := ""
+		if len(item.Attendees) > 0 {
+			holderName = item.Attendees[0].Name
+		}
+		if holderName == "" {
+			buyer, err := s.userRepo.GetUserByID(order.UserID)
+			if err != nil {
+				return err
+			}
+			holderName = buyer.Name
+		}
+
+		newTicket := &model.Ticket{
+			OrderID:     order.ID,
+			OrderItemID: item.ID,
+			Sequence:    1,
+			UserID:      order.UserID,
+			HolderName:  holderName,
+		}
+		sold := false
+		for attempt := 1; ; attempt++ {
+			newTicket.Code = utils.GenerateTicketCode()
+			sold, err = s.resaleRepo.CompleteSale(listing.ID, order.ID, newTicket)
+			if repository.IsTicketCodeConflict(err) && attempt < issueAttempts {
+				continue
+			}
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if !sold {
+			log.Printf("Resale listing %s could not be delivered to paid order %s", listing.ID, order.ID)
+			if err := s.refundSvc.RefundUndelivered(order, item, "tiket resale sudah tidak tersedia saat pembayaran diterima"); err != nil {
+				return err
+			}
+			continue
+		}
+		s.notifySeller(listing)
+	}
+	return nil
+}
+
+// ListPayouts lists sold listings by payout status for settling with sellers.
+func (s *ResaleService) ListPayouts(req *model.ListResalePayoutsRequest) (*model.PaginatedResponse, error) {
+	req.Normalize()
+
+	listings, total, err := s.resaleRepo.ListListings(&model.ResaleListingFilter{
+		Status:       model.ResaleStatusSold,
+		PayoutStatus: req.PayoutStatus,
+		Limit:        req.Limit,
+		Offset:       req.Offset(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.PaginatedResponse{
+		Items: listings,
+		Pagination: model.PaginationMeta{
+			Page:  req.Page,
+			Limit: req.Limit,
+			Total: total,
+		},
+	}, nil
+}
+
+// MarkPayoutPaid records the transfer reference of a seller's payout.
+func (s *ResaleService) MarkPayoutPaid(id uuid.UUID, req *model.MarkResalePayoutRequest) (*model.ResaleListing, error) {
+	if _, err := s.getListing(id); err != nil {
+		return nil, err
+	}
+	paid, err := s.resaleRepo.MarkPayoutPaid(id, req.Reference)
+	if err != nil {
+		return nil, err
+	}
+	if !paid {
+		return nil, fmt.Errorf("listing tidak memiliki payout yang menunggu pembayaran: %w", ErrConflict)
+	}
+	return s.getListing(id)
+}
+
+// StartWithdrawSweeper withdraws listings whose ticket was used or whose
+// event was cancelled or closed for resale until stop is closed.
+func (s *ResaleService) StartWithdrawSweeper(interval time.Duration, stop <-chan struct{}) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				withdrawn, err := s.resaleRepo.WithdrawStaleListings(time.Now())
+				if err != nil {
+					log.Printf("Failed to withdraw stale resale listings: %v", err)
+				} else if withdrawn > 0 {
+					log.Printf("Withdrew %d stale resale listings", withdrawn)
+				}
+			case <-stop:
+				return
+			}
+		}
+	}()
+}
+
+func (s *ResaleService) getListing(id uuid.UUID) (*model.ResaleListing, error) {
+	listing, err := s.resaleRepo.GetListing(id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrResaleListingNotFound
+		}
+		return nil, err
+	}
+	return listing, nil
+}
+
+func (s *ResaleService) viewEvent(actor model.Actor, eventID uuid.UUID) error {
+	if !actor.IsAnonymous() {
+		if _, err := s.eventSvc.AuthorizeEvent(actor, eventID); err == nil {
+			return nil
+		}
+	}
+	_, err := s.eventSvc.GetPublicEvent(eventID)
+	return err
+}
+
+// policy returns the event's policy, or the default: resale off until the
+// organizer sets a cap.
+func (s *ResaleService) policy(eventID uuid.UUID) (*model.ResalePolicy, error) {
+	policy, err := s.resaleRepo.GetPolicy(eventID)
+	if err == sql.ErrNoRows {
+		policy, err = &model.ResalePolicy{EventID: eventID, MaxPricePercent: 100}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	policy.FeePercent = s.config.ResaleFeePercent
+	return policy, nil
+}
+
+// resaleWindow checks that tickets of the event may be listed or bought at
+// now.
+func resaleWindow(policy *model.ResalePolicy, event *model.Event, now time.Time) error {
+	if !policy.Enabled {
+		return fmt.Errorf("event ini tidak membuka resale tiket: %w", ErrForbidden)
+	}
+	if event.Status != model.EventStatusOnSale && event.Status != model.EventStatusSoldOut {
+		return fmt.Errorf("resale tiket event berstatus %s tidak tersedia", event.Status)
+	}
+	closesAt := event.StartTime.Add(-time.Duration(policy.CloseMinutesBeforeStart) * time.Minute)
+	if !now.Before(closesAt) {
+		return errors.New("masa resale tiket untuk event ini sudah ditutup")
+	}
+	return nil
+}
+
+// notifySeller is best effort; the sale and payout also show in the seller's
+// listings.
+func (s *ResaleService) notifySeller(listing *model.ResaleListing) {
+	seller, err := s.userRepo.GetUserByID(listing.SellerUserID)
+	if err != nil {
+		log.Printf("Failed to notify resale listing %s seller: %v", listing.ID, err)
+		return
+	}
+
+	body := fmt.Sprintf(`
+		<html>
+		<body style="font-family: Arial, sans-serif; padding: 20px;">
+			<h2>Halo %s!</h2>
+			<p>Tiket <strong>%s</strong> untuk <strong>%s</strong> yang Anda jual di resale sudah terjual.</p>
+			<p>Harga jual: %s %d<br>Biaya layanan: %s %d<br>Dana yang akan Anda terima: <strong>%s %d</strong></p>
+			<p>Tiket dan QR code lama Anda sudah tidak berlaku.</p>
+			<p>Salam,<br>Tim E-Ticketing</p>
+		</body>
+		</html>
+	`, html.EscapeString(seller.Name), html.EscapeString(listing.TicketTypeName), html.EscapeString(listing.EventTitle),
+		listing.Currency, listing.Price, listing.Currency, listing.FeeAmount, listing.Currency, listing.PayoutAmount)
+
+	text := fmt.Sprintf(
+		"Halo %s!\n\nTiket *%s* untuk *%s* yang Anda jual di resale sudah terjual. Dana yang akan Anda terima: *%s %d*.\n\n- Tim E-Ticketing",
+		seller.Name, listing.TicketTypeName, listing.EventTitle, listing.Currency, listing.PayoutAmount,
+	)
+
+	if err := s.notifSvc.Notify(seller, &model.Notification{
+		Category:     model.NotificationTransactional,
+		Template:     "resale_sold",
+		Subject:      fmt.Sprintf("Tiket %s Anda terjual", listing.EventTitle),
+		EmailBody:    body,
+		WhatsAppBody: text,
+	}); err != nil {
+		log.Printf("Failed to notify resale listing %s seller: %v", listing.ID, err)
+	}
+}
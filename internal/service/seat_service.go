@@ -0,0 +1,335 @@
+package service
+
+import (
+	"e-ticketing/config"
+	"e-ticketing/internal/model"
+	"e-ticketing/internal/repository"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// maxSeatsPerMap bounds a single import; the largest stadiums stay well below.
+const maxSeatsPerMap = 100000
+
+type SeatService struct {
+	seatRepo         *repository.SeatRepository
+	ticketTypeRepo   *repository.TicketTypeRepository
+	userRepo         *repository.UserRepository
+	eventSvc         *EventService
+	waitingRoomSvc   *WaitingRoomService
+	purchaseLimitSvc *PurchaseLimitService
+	config           *config.Config
+}
+
+func NewSeatService(seatRepo *repository.SeatRepository, ticketTypeRepo *repository.TicketTypeRepository, userRepo *repository.UserRepository, eventSvc *EventService, waitingRoomSvc *WaitingRoomService, purchaseLimitSvc *PurchaseLimitService, cfg *config.Config) *SeatService {
+	return &SeatService{
+		seatRepo:         seatRepo,
+		ticketTypeRepo:   ticketTypeRepo,
+		userRepo:         userRepo,
+		eventSvc:         eventSvc,
+		waitingRoomSvc:   waitingRoomSvc,
+		purchaseLimitSvc: purchaseLimitSvc,
+		config:           cfg,
+	}
+}
+
+// ImportSeatMap replaces the event's seat map. Every seat is priced by the
+// ticket type named in its tier, and each tier's quota becomes its number of
+// sellable seats.
+func (s *SeatService) ImportSeatMap(actor model.Actor, eventID uuid.UUID, req *model.SeatMapImport) (*model.SeatMap, error) {
+	event, err := s.eventSvc.AuthorizeEvent(actor, eventID)
+	if err != nil {
+		return nil, err
+	}
+	if event.Status == model.EventStatusCancelled || event.Status == model.EventStatusFinished {
+		return nil, errors.New("event yang sudah dibatalkan atau selesai tidak dapat diubah")
+	}
+
+	ticketTypes, err := s.ticketTypeRepo.GetTicketTypesByEvent(eventID, true)
+	if err != nil {
+		return nil, err
+	}
+	tiersByName := map[string]*model.TicketType{}
+	for i := range ticketTypes {
+		tiersByName[strings.ToLower(ticketTypes[i].Name)] = &ticketTypes[i]
+	}
+
+	sections := make([]model.SeatSection, 0, len(req.Sections))
+	quotas := map[uuid.UUID]int{}
+	sectionNames := map[string]bool{}
+	total := 0
+	for i, sectionReq := range req.Sections {
+		name := strings.TrimSpace(sectionReq.Name)
+		if sectionNames[strings.ToLower(name)] {
+			return nil, fmt.Errorf("section %s duplikat", name)
+		}
+		sectionNames[strings.ToLower(name)] = true
+
+		section := model.SeatSection{ID: uuid.New(), EventID: eventID, Name: name, SortOrder: i}
+		seen := map[string]bool{}
+		for _, seatReq := range sectionReq.Seats {
+			row := strings.TrimSpace(seatReq.Row)
+			number := strings.TrimSpace(seatReq.Number)
+			key := strings.ToLower(row) + "/" + strings.ToLower(number)
+			if seen[key] {
+				return nil, fmt.Errorf("kursi %s baris %s nomor %s duplikat", name, row, number)
+			}
+			seen[key] = true
+
+			tier, ok := tiersByName[strings.ToLower(strings.TrimSpace(seatReq.Tier))]
+			if !ok {
+				return nil, fmt.Errorf("tier %s bukan tipe tiket event ini", seatReq.Tier)
+			}
+
+			// A tier with any seat is seated, even if every seat is blocked;
+			// its quota counts only the seats that can be sold
+			if _, seated := quotas[tier.ID]; !seated {
+				quotas[tier.ID] = 0
+			}
+			status := model.SeatStatusAvailable
+			if seatReq.Blocked {
+				status = model.SeatStatusBlocked
+			} else {
+				quotas[tier.ID]++
+			}
+
+			section.Seats = append(section.Seats, model.Seat{
+				ID:           uuid.New(),
+				EventID:      eventID,
+				SectionID:    section.ID,
+				Row:          row,
+				Number:       number,
+				X:            seatReq.X,
+				Y:            seatReq.Y,
+				TicketTypeID: tier.ID,
+				Status:       status,
+			})
+			total++
+		}
+		sections = append(sections, section)
+	}
+	if total > maxSeatsPerMap {
+		return nil, fmt.Errorf("seat map maksimal %d kursi", maxSeatsPerMap)
+	}
+	for _, t := range ticketTypes {
+		if quota, ok := quotas[t.ID]; ok && quota == 0 {
+			return nil, fmt.Errorf("tier %s tidak memiliki kursi yang dapat dijual", t.Name)
+		}
+	}
+
+	ok, err := s.seatRepo.ReplaceSeatMap(eventID, sections, quotas)
+	if err != nil {
+		return nil, capacityError(err)
+	}
+	if !ok {
+		return nil, fmt.Errorf("seat map tidak dapat diganti karena sudah ada kursi yang di-hold atau terjual: %w", ErrConflict)
+	}
+	return s.seatMap(eventID)
+}
+
+// GetSeatMap returns seat availability of a public event, or of any event to
+// its managers.
+func (s *SeatService) GetSeatMap(actor model.Actor, eventID uuid.UUID) (*model.SeatMap, error) {
+	managed := false
+	if !actor.IsAnonymous() {
+		if _, err := s.eventSvc.AuthorizeEvent(actor, eventID); err == nil {
+			managed = true
+		}
+	}
+	if !managed {
+		if _, err := s.eventSvc.GetPublicEvent(eventID); err != nil {
+			return nil, err
+		}
+	}
+	return s.seatMap(eventID)
+}
+
+func (s *SeatService) seatMap(eventID uuid.UUID) (*model.SeatMap, error) {
+	sections, err := s.seatRepo.GetSeatMap(eventID)
+	if err != nil {
+		return nil, err
+	}
+	ticketTypes, err := s.ticketTypeRepo.GetTicketTypesByEvent(eventID, true)
+	if err != nil {
+		return nil, err
+	}
+
+	seatMap := &model.SeatMap{EventID: eventID, Sections: sections, Tiers: []model.SeatTier{}}
+	for _, t := range ticketTypes {
+		if t.ReservedSeating {
+			seatMap.Tiers = append(seatMap.Tiers, model.SeatTier{TicketTypeID: t.ID, Name: t.Name, Price: t.Price, Currency: t.Currency})
+		}
+	}
+	for _, section := range sections {
+		for _, seat := range section.Seats {
+			seatMap.TotalSeats++
+			if seat.Status == model.SeatStatusAvailable {
+				seatMap.AvailableSeats++
+			}
+		}
+	}
+	return seatMap, nil
+}
+
+// PlaceSeatHold reserves specific seats for HoldDurationMinutes. Seats of
+// different tiers get one hold each, so the result feeds straight into
+// order creation.
+func (s *SeatService) PlaceSeatHold(actor model.Actor, eventID uuid.UUID, req *model.CreateSeatHoldRequest) ([]model.InventoryHold, error) {
+	user, err := s.userRepo.GetUserByID(actor.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if !user.IsVerified {
+		return nil, errors.New("akun belum terverifikasi")
+	}
+
+	event, err := s.eventSvc.GetEvent(eventID)
+	if err != nil {
+		return nil, err
+	}
+	if event.Status != model.EventStatusOnSale {
+		return nil, errors.New("event belum atau tidak sedang dijual")
+	}
+	if err := s.waitingRoomSvc.CheckAdmission(actor, event.ID, req.QueueToken); err != nil {
+		return nil, err
+	}
+
+	seatIDs := make([]uuid.UUID, 0, len(req.SeatIDs))
+	unique := map[uuid.UUID]bool{}
+	for _, raw := range req.SeatIDs {
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			return nil, errors.New("seat ID tidak valid")
+		}
+		if unique[id] {
+			return nil, errors.New("seat ID tidak boleh duplikat")
+		}
+		unique[id] = true
+		seatIDs = append(seatIDs, id)
+	}
+
+	seats, err := s.seatRepo.GetSeatsByIDs(seatIDs)
+	if err != nil {
+		return nil, err
+	}
+	if len(seats) != len(seatIDs) {
+		return nil, fmt.Errorf("kursi %w", ErrNotFound)
+	}
+
+	seatsByTier := map[uuid.UUID][]uuid.UUID{}
+	for _, seat := range seats {
+		if seat.EventID != eventID {
+			return nil, fmt.Errorf("kursi %w", ErrNotFound)
+		}
+		if seat.Status != model.SeatStatusAvailable {
+			return nil, fmt.Errorf("kursi %s%s sudah tidak tersedia: %w", seat.Row, seat.Number, ErrConflict)
+		}
+		seatsByTier[seat.TicketTypeID] = append(seatsByTier[seat.TicketTypeID], seat.ID)
+	}
+
+	now := time.Now()
+	for tierID, ids := range seatsByTier {
+		tier, err := s.ticketTypeRepo.GetTicketTypeByID(tierID)
+		if err != nil {
+			return nil, err
+		}
+		if !tier.IsOnSale(now) {
+			return nil, fmt.Errorf("tipe tiket %s di luar periode penjualan", tier.Name)
+		}
+		if len(ids) < tier.MinPerOrder || len(ids) > tier.MaxPerOrder {
+			return nil, fmt.Errorf("jumlah kursi %s harus antara %d dan %d", tier.Name, tier.MinPerOrder, tier.MaxPerOrder)
+		}
+	}
+
+	limit, err := s.purchaseLimitSvc.HoldLimit(actor, eventID, len(seatIDs), req.DeviceID)
+	if err != nil {
+		return nil, err
+	}
+
+	expiresAt := now.Add(time.Duration(s.config.HoldDurationMinutes) * time.Minute)
+	holds, ok, err := s.seatRepo.CreateSeatHolds(actor.UserID, seatsByTier, expiresAt, limit)
+	if err != nil {
+		return nil, holdError(err)
+	}
+	if !ok {
+		return nil, fmt.Errorf("kursi sudah dipilih pembeli lain: %w", ErrConflict)
+	}
+	return holds, nil
+}
+
+// ParseSeatMapCSV reads the CSV import format: a header row of
+// section,row,number,x,y,tier with an optional blocked column, then one
+// seat per line. Sections keep the order they first appear in.
+func ParseSeatMapCSV(r io.Reader) (*model.SeatMapImport, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, errors.New("file CSV kosong atau tidak valid")
+	}
+	columns := map[string]int{}
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, name := range []string{"section", "row", "number", "x", "y", "tier"} {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("kolom %s tidak ada di header CSV", name)
+		}
+	}
+	blockedColumn, hasBlocked := columns["blocked"]
+
+	result := &model.SeatMapImport{}
+	sectionIndex := map[string]int{}
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("baris %d: %v", line, err)
+		}
+
+		x, err := strconv.ParseFloat(strings.TrimSpace(record[columns["x"]]), 64)
+		if err != nil {
+			return nil, fmt.Errorf("baris %d: koordinat x tidak valid", line)
+		}
+		y, err := strconv.ParseFloat(strings.TrimSpace(record[columns["y"]]), 64)
+		if err != nil {
+			return nil, fmt.Errorf("baris %d: koordinat y tidak valid", line)
+		}
+		seat := model.SeatImport{
+			Row:    strings.TrimSpace(record[columns["row"]]),
+			Number: strings.TrimSpace(record[columns["number"]]),
+			X:      x,
+			Y:      y,
+			Tier:   strings.TrimSpace(record[columns["tier"]]),
+		}
+		if hasBlocked {
+			switch strings.ToLower(strings.TrimSpace(record[blockedColumn])) {
+			case "", "0", "false", "no":
+			case "1", "true", "yes":
+				seat.Blocked = true
+			default:
+				return nil, fmt.Errorf("baris %d: nilai blocked tidak valid", line)
+			}
+		}
+
+		name := strings.TrimSpace(record[columns["section"]])
+		i, ok := sectionIndex[name]
+		if !ok {
+			i = len(result.Sections)
+			sectionIndex[name] = i
+			result.Sections = append(result.Sections, model.SeatSectionImport{Name: name})
+		}
+		result.Sections[i].Seats = append(result.Sections[i].Seats, seat)
+	}
+	return result, nil
+}
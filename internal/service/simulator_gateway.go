@@ -0,0 +1,177 @@
+package service
+
+import (
+	"crypto/hmac"
+	"e-ticketing/config"
+	"e-ticketing/internal/model"
+	"e-ticketing/pkg/utils"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// simulatorOutcomes maps a simulate request to the Midtrans status it emits.
+var simulatorOutcomes = map[string]struct{ transactionStatus, statusCode string }{
+	"success": {"settlement", "200"},
+	"fail":    {"deny", "202"},
+	"expire":  {"expire", "407"},
+}
+
+type simulatedTransaction struct {
+	amount            int64
+	refunded          int64
+	refundKeys        map[string]bool
+	transactionStatus string
+	statusCode        string
+	maskedCard        string
+}
+
+// SimulatorGateway is an in-process gateway for development and automated
+// flows. It hands out fake VA numbers and QR strings and, when told to
+// succeed, fail or expire a payment, produces a notification signed exactly
+// like a Midtrans callback.
+type SimulatorGateway struct {
+	config       *config.Config
+	mu           sync.Mutex
+	transactions map[string]*simulatedTransaction
+}
+
+func NewSimulatorGateway(cfg *config.Config) *SimulatorGateway {
+	return &SimulatorGateway{
+		config:       cfg,
+		transactions: map[string]*simulatedTransaction{},
+	}
+}
+
+func (g *SimulatorGateway) Name() string {
+	return "simulator"
+}
+
+func (g *SimulatorGateway) CreateTransaction(req *model.ChargeRequest) (*model.ChargeResult, error) {
+	g.mu.Lock()
+	g.transactions[req.ExternalID] = &simulatedTransaction{amount: req.Amount, transactionStatus: "pending", statusCode: "201"}
+	g.mu.Unlock()
+
+	result := &model.ChargeResult{
+		Reference: "SIM-" + strings.ToUpper(utils.GenerateToken()[:12]),
+		Token:     utils.GenerateToken()[:32],
+	}
+
+	switch req.Method {
+	case model.PaymentMethodBankTransfer:
+		result.VANumber = "8808" + utils.GenerateOTP(12)
+	case model.PaymentMethodQRIS:
+		result.QRString = "00020101021226570011ID.SIMULATOR" + req.ExternalID
+	default:
+		result.RedirectURL = fmt.Sprintf("%s/dev/payments/%s", g.config.AppBaseURL, req.ExternalID)
+	}
+	return result, nil
+}
+
+func (g *SimulatorGateway) GetStatus(externalID string) (*model.GatewayStatus, error) {
+	g.mu.Lock()
+	tx, ok := g.transactions[externalID]
+	g.mu.Unlock()
+	if !ok {
+		return &model.GatewayStatus{ExternalID: externalID, Status: model.PaymentStatusPending}, nil
+	}
+
+	n := g.notification(externalID, tx)
+	raw, _ := json.Marshal(n)
+	return n.toGatewayStatus(string(raw))
+}
+
+func (g *SimulatorGateway) ParseNotification(body []byte) (*model.GatewayStatus, error) {
+	var n midtransNotification
+	if err := json.Unmarshal(body, &n); err != nil {
+		return nil, err
+	}
+
+	expected := midtransSignature(n.OrderID, n.StatusCode, n.GrossAmount, g.serverKey())
+	if !hmac.Equal([]byte(expected), []byte(n.SignatureKey)) {
+		return nil, ErrInvalidSignature
+	}
+	return n.toGatewayStatus(string(body))
+}
+
+// Refund pays back a settled transaction. Repeating a refund key is a no-op,
+// as with a real gateway.
+func (g *SimulatorGateway) Refund(req *model.ChargeRefund) (*model.RefundResult, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	tx, ok := g.transactions[req.ExternalID]
+	if !ok {
+		// Transactions do not survive restarts; assume it was settled
+		tx = &simulatedTransaction{amount: req.Amount, transactionStatus: "settlement", statusCode: "200"}
+		g.transactions[req.ExternalID] = tx
+	}
+	if tx.transactionStatus != "settlement" && tx.transactionStatus != "partial_refund" {
+		return nil, fmt.Errorf("transaksi berstatus %s tidak dapat direfund", tx.transactionStatus)
+	}
+
+	result := &model.RefundResult{Reference: "SIM-REFUND-" + req.RefundKey}
+	if tx.refundKeys[req.RefundKey] {
+		return result, nil
+	}
+	if tx.refunded+req.Amount > tx.amount {
+		return nil, errors.New("nominal refund melebihi sisa transaksi")
+	}
+
+	if tx.refundKeys == nil {
+		tx.refundKeys = map[string]bool{}
+	}
+	tx.refundKeys[req.RefundKey] = true
+	tx.refunded += req.Amount
+	tx.transactionStatus = "partial_refund"
+	if tx.refunded == tx.amount {
+		tx.transactionStatus = "refund"
+	}
+	return result, nil
+}
+
+// Simulate settles, fails or expires a transaction and returns the signed
+// notification body a real gateway would POST to the callback URL. A masked
+// card makes it look like a card payment.
+func (g *SimulatorGateway) Simulate(externalID, result string, amount int64, maskedCard string) ([]byte, error) {
+	outcome, ok := simulatorOutcomes[result]
+	if !ok {
+		return nil, errors.New("hasil simulasi tidak dikenal")
+	}
+
+	g.mu.Lock()
+	tx, ok := g.transactions[externalID]
+	if !ok {
+		// Transactions do not survive restarts, so recreate from the stored payment
+		tx = &simulatedTransaction{amount: amount}
+		g.transactions[externalID] = tx
+	}
+	tx.transactionStatus = outcome.transactionStatus
+	tx.statusCode = outcome.statusCode
+	tx.maskedCard = maskedCard
+	g.mu.Unlock()
+
+	return json.Marshal(g.notification(externalID, tx))
+}
+
+func (g *SimulatorGateway) notification(externalID string, tx *simulatedTransaction) *midtransNotification {
+	gross := formatGrossAmount(tx.amount)
+	return &midtransNotification{
+		OrderID:           externalID,
+		TransactionID:     "SIM-" + externalID,
+		TransactionStatus: tx.transactionStatus,
+		StatusCode:        tx.statusCode,
+		GrossAmount:       gross,
+		SignatureKey:      midtransSignature(externalID, tx.statusCode, gross, g.serverKey()),
+		MaskedCard:        tx.maskedCard,
+	}
+}
+
+func (g *SimulatorGateway) serverKey() string {
+	if g.config.MidtransServerKey != "" {
+		return g.config.MidtransServerKey
+	}
+	return g.config.JWTSecret
+}
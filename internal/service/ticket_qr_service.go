@@ -0,0 +1,166 @@
+package service
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"e-ticketing/config"
+	"e-ticketing/internal/model"
+	"e-ticketing/pkg/ticketqr"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/skip2/go-qrcode"
+)
+
+const devTicketKeyID = "dev"
+
+// TicketQRService produces signed QR payloads for tickets and renders them.
+// Verification only needs the public keys, which are published so gate
+// scanners can check codes offline.
+type TicketQRService struct {
+	ticketSvc *TicketService
+	eventSvc  *EventService
+	signer    *ticketqr.Signer
+	verifier  *ticketqr.Verifier
+	keys      map[string]ed25519.PublicKey
+	config    *config.Config
+}
+
+func NewTicketQRService(ticketSvc *TicketService, eventSvc *EventService, cfg *config.Config) (*TicketQRService, error) {
+	keyID := cfg.TicketSigningKeyID
+	privateKeys := map[string]ed25519.PrivateKey{}
+	for kid, encoded := range cfg.TicketSigningKeys {
+		key, err := ticketqr.ParsePrivateKey(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("kunci QR %s: %w", kid, err)
+		}
+		privateKeys[kid] = key
+	}
+
+	// Development gets a key derived from the JWT secret so QR codes work
+	// without extra setup
+	if len(privateKeys) == 0 {
+		if cfg.AppEnv == "production" {
+			return nil, errors.New("TICKET_SIGNING_KEYS wajib diisi di production")
+		}
+		seed := sha256.Sum256([]byte("ticket-qr:" + cfg.JWTSecret))
+		privateKeys[devTicketKeyID] = ed25519.NewKeyFromSeed(seed[:])
+		keyID = devTicketKeyID
+	}
+	if keyID == "" && len(privateKeys) == 1 {
+		for kid := range privateKeys {
+			keyID = kid
+		}
+	}
+
+	active, ok := privateKeys[keyID]
+	if !ok {
+		return nil, fmt.Errorf("TICKET_SIGNING_KEY_ID %q tidak ada di TICKET_SIGNING_KEYS", keyID)
+	}
+	signer, err := ticketqr.NewSigner(keyID, active)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := map[string]ed25519.PublicKey{}
+	for kid, key := range privateKeys {
+		keys[kid] = key.Public().(ed25519.PublicKey)
+	}
+	for kid, encoded := range cfg.TicketVerifyKeys {
+		key, err := ticketqr.ParsePublicKey(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("kunci verifikasi QR %s: %w", kid, err)
+		}
+		keys[kid] = key
+	}
+
+	return &TicketQRService{
+		ticketSvc: ticketSvc,
+		eventSvc:  eventSvc,
+		signer:    signer,
+		verifier:  ticketqr.NewVerifier(keys),
+		keys:      keys,
+		config:    cfg,
+	}, nil
+}
+
+// Payload signs a QR payload for one of the actor's valid tickets. It stays
+// valid until TicketQRValidityHours after the event ends.
+func (s *TicketQRService) Payload(actor model.Actor, ticketID uuid.UUID) (string, error) {
+	ticket, err := s.ticketSvc.GetMyTicket(actor, ticketID)
+	if err != nil {
+		return "", err
+	}
+	if ticket.Status != model.TicketStatusValid {
+		return "", fmt.Errorf("tiket dengan status %s tidak memiliki QR", ticket.Status)
+	}
+
+	event, err := s.eventSvc.GetEvent(ticket.EventID)
+	if err != nil {
+		return "", err
+	}
+
+	return s.signer.Sign(ticketqr.Claims{
+		TicketID:   ticket.ID.String(),
+		EventID:    ticket.EventID.String(),
+		HolderName: ticket.HolderName,
+		IssuedAt:   time.Now().Unix(),
+		ExpiresAt:  event.EndTime.Add(time.Duration(s.config.TicketQRValidityHours) * time.Hour).Unix(),
+	})
+}
+
+// Verify checks a scanned payload's signature and validity window.
+func (s *TicketQRService) Verify(payload string) (*ticketqr.Claims, error) {
+	return s.verifier.Verify(strings.TrimSpace(payload), time.Now())
+}
+
+// SignDocument signs data handed to scanners, such as offline manifests,
+// with the same key as the QR codes.
+func (s *TicketQRService) SignDocument(v interface{}) (*ticketqr.Document, error) {
+	return s.signer.SignDocument(v)
+}
+
+// PublicKeys returns every key ID a scanner should accept, base64 encoded.
+func (s *TicketQRService) PublicKeys() map[string]string {
+	keys := make(map[string]string, len(s.keys))
+	for kid, key := range s.keys {
+		keys[kid] = ticketqr.EncodePublicKey(key)
+	}
+	return keys
+}
+
+func (s *TicketQRService) RenderPNG(payload string, size int) ([]byte, error) {
+	return qrcode.Encode(payload, qrcode.Medium, size)
+}
+
+// RenderSVG draws the QR matrix as one path, merging horizontal runs of dark
+// modules to keep the document small.
+func (s *TicketQRService) RenderSVG(payload string) ([]byte, error) {
+	code, err := qrcode.New(payload, qrcode.Medium)
+	if err != nil {
+		return nil, err
+	}
+	bitmap := code.Bitmap()
+	size := len(bitmap)
+
+	var path strings.Builder
+	for y, row := range bitmap {
+		for x := 0; x < len(row); x++ {
+			if !row[x] {
+				continue
+			}
+			start := x
+			for x < len(row) && row[x] {
+				x++
+			}
+			fmt.Fprintf(&path, "M%d %dh%dv1h-%dz", start, y, x-start, x-start)
+		}
+	}
+
+	svg := fmt.Sprintf(`<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 %d %d" shape-rendering="crispEdges">`+
+		`<rect width="100%%" height="100%%" fill="#fff"/><path fill="#000" d="%s"/></svg>`, size, size, path.String())
+	return []byte(svg), nil
+}
@@ -0,0 +1,160 @@
+package service
+
+import (
+	"database/sql"
+	"e-ticketing/internal/model"
+	"e-ticketing/internal/repository"
+	"e-ticketing/pkg/utils"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// issueAttempts bounds retries when a freshly generated code collides with an
+// existing one, which at 75 random bits should practically never happen.
+const issueAttempts = 3
+
+type TicketService struct {
+	ticketRepo *repository.TicketRepository
+	userRepo   *repository.UserRepository
+	orderSvc   *OrderService
+}
+
+func NewTicketService(ticketRepo *repository.TicketRepository, userRepo *repository.UserRepository, orderSvc *OrderService) *TicketService {
+	return &TicketService{
+		ticketRepo: ticketRepo,
+		userRepo:   userRepo,
+		orderSvc:   orderSvc,
+	}
+}
+
+// IssueTickets creates one ticket per purchased quantity of a paid order,
+// held in the named attendee's or else the buyer's name and assigned the
+// item's seats in seat order. It is safe to call repeatedly: tickets that
+// already exist are kept and only missing ones are created.
+func (s *TicketService) IssueTickets(orderID uuid.UUID) (int, error) {
+	order, err := s.orderSvc.GetOrder(orderID)
+	if err != nil {
+		return 0, err
+	}
+	if order.Status != model.OrderStatusPaid {
+		return 0, fmt.Errorf("order dengan status %s tidak dapat diterbitkan tiketnya", order.Status)
+	}
+
+	user, err := s.userRepo.GetUserByID(order.UserID)
+	if err != nil {
+		return 0, err
+	}
+
+	for attempt := 1; ; attempt++ {
+		tickets := []model.Ticket{}
+		for _, item := range order.Items {
+			// Resold tickets are reissued by ResaleService instead
+			if item.ResaleListingID != nil {
+				continue
+			}
+			for seq := 1; seq <= item.Quantity; seq++ {
+				var seatID *uuid.UUID
+				if seq <= len(item.Seats) {
+					seatID = &item.Seats[seq-1].SeatID
+				}
+				holderName := user.Name
+				if seq <= len(item.Attendees) && item.Attendees[seq-1].Name != "" {
+					holderName = item.Attendees[seq-1].Name
+				}
+				tickets = append(tickets, model.Ticket{
+					Code:         utils.GenerateTicketCode(),
+					OrderID:      order.ID,
+					OrderItemID:  item.ID,
+					Sequence:     seq,
+					EventID:      order.EventID,
+					TicketTypeID: item.TicketTypeID,
+					SeatID:       seatID,
+					UserID:       order.UserID,
+					HolderName:   holderName,
+					Status:       model.TicketStatusValid,
+				})
+			}
+		}
+
+		inserted, err := s.ticketRepo.CreateTickets(tickets)
+		if repository.IsTicketCodeConflict(err) && attempt < issueAttempts {
+			continue
+		}
+		return inserted, err
+	}
+}
+
+func (s *TicketService) GetTicket(id uuid.UUID) (*model.Ticket, error) {
+	ticket, err := s.ticketRepo.GetTicketByID(id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrTicketNotFound
+		}
+		return nil, err
+	}
+	return ticket, nil
+}
+
+// GetTicketByCode accepts codes as typed or scanned, with or without
+// separators.
+func (s *TicketService) GetTicketByCode(code string) (*model.Ticket, error) {
+	normalized := utils.NormalizeTicketCode(code)
+	if !utils.ValidTicketCode(normalized) {
+		return nil, errors.New("kode tiket tidak valid")
+	}
+
+	ticket, err := s.ticketRepo.GetTicketByCode(utils.FormatTicketCode(normalized))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrTicketNotFound
+		}
+		return nil, err
+	}
+	return ticket, nil
+}
+
+// GetMyTicket returns the ticket only to its current owner.
+func (s *TicketService) GetMyTicket(actor model.Actor, id uuid.UUID) (*model.Ticket, error) {
+	ticket, err := s.GetTicket(id)
+	if err != nil {
+		return nil, err
+	}
+	if ticket.UserID != actor.UserID {
+		return nil, ErrTicketNotFound
+	}
+	return ticket, nil
+}
+
+func (s *TicketService) ListMyTickets(actor model.Actor, req *model.ListTicketsRequest) (*model.PaginatedResponse, error) {
+	req.Normalize()
+
+	filter := &model.TicketFilter{
+		UserID: &actor.UserID,
+		Status: req.Status,
+		Limit:  req.Limit,
+		Offset: req.Offset(),
+	}
+	if req.EventID != "" {
+		eventID, err := uuid.Parse(req.EventID)
+		if err != nil {
+			return nil, errors.New("event ID tidak valid")
+		}
+		filter.EventID = &eventID
+	}
+
+	tickets, total, err := s.ticketRepo.ListTickets(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.PaginatedResponse{
+		Items: tickets,
+		Pagination: model.PaginationMeta{
+			Page:  req.Page,
+			Limit: req.Limit,
+			Total: total,
+		},
+	}, nil
+}
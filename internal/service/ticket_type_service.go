@@ -0,0 +1,178 @@
+package service
+
+import (
+	"database/sql"
+	"e-ticketing/internal/model"
+	"e-ticketing/internal/repository"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+type TicketTypeService struct {
+	ticketTypeRepo *repository.TicketTypeRepository
+	eventSvc       *EventService
+}
+
+func NewTicketTypeService(ticketTypeRepo *repository.TicketTypeRepository, eventSvc *EventService) *TicketTypeService {
+	return &TicketTypeService{
+		ticketTypeRepo: ticketTypeRepo,
+		eventSvc:       eventSvc,
+	}
+}
+
+func (s *TicketTypeService) CreateTicketType(actor model.Actor, eventID uuid.UUID, req *model.CreateTicketTypeRequest) (*model.TicketType, error) {
+	event, err := s.eventSvc.AuthorizeEvent(actor, eventID)
+	if err != nil {
+		return nil, err
+	}
+
+	ticketType := &model.TicketType{EventID: eventID}
+	if err := s.apply(event, ticketType, req); err != nil {
+		return nil, err
+	}
+
+	if err := s.ticketTypeRepo.CreateTicketType(ticketType); err != nil {
+		return nil, capacityError(err)
+	}
+	return ticketType, nil
+}
+
+func (s *TicketTypeService) UpdateTicketType(actor model.Actor, eventID, id uuid.UUID, req *model.UpdateTicketTypeRequest) (*model.TicketType, error) {
+	event, err := s.eventSvc.AuthorizeEvent(actor, eventID)
+	if err != nil {
+		return nil, err
+	}
+
+	ticketType, err := s.getForEvent(eventID, id)
+	if err != nil {
+		return nil, err
+	}
+	// Seated quotas follow the seat map
+	if ticketType.ReservedSeating && req.Quota != ticketType.Quota {
+		return nil, errors.New("kuota tipe tiket bernomor kursi diatur melalui import seat map")
+	}
+
+	if err := s.apply(event, ticketType, req); err != nil {
+		return nil, err
+	}
+
+	if err := s.ticketTypeRepo.UpdateTicketType(ticketType); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.New("kuota tidak boleh lebih kecil dari tiket yang sudah terjual atau di-hold")
+		}
+		return nil, capacityError(err)
+	}
+	return ticketType, nil
+}
+
+func (s *TicketTypeService) DeleteTicketType(actor model.Actor, eventID, id uuid.UUID) error {
+	if _, err := s.eventSvc.AuthorizeEvent(actor, eventID); err != nil {
+		return err
+	}
+
+	if _, err := s.getForEvent(eventID, id); err != nil {
+		return err
+	}
+
+	if err := s.ticketTypeRepo.DeleteTicketType(id); err != nil {
+		if repository.IsForeignKeyViolation(err) {
+			return fmt.Errorf("tipe tiket sudah memiliki transaksi: %w", ErrConflict)
+		}
+		return err
+	}
+	return nil
+}
+
+// ListTicketTypes returns every ticket type to event managers and only
+// public ones of public events to everyone else.
+func (s *TicketTypeService) ListTicketTypes(actor model.Actor, eventID uuid.UUID) ([]model.TicketType, error) {
+	if !actor.IsAnonymous() {
+		if _, err := s.eventSvc.AuthorizeEvent(actor, eventID); err == nil {
+			return s.ticketTypeRepo.GetTicketTypesByEvent(eventID, true)
+		}
+	}
+
+	if _, err := s.eventSvc.GetPublicEvent(eventID); err != nil {
+		return nil, err
+	}
+	return s.ticketTypeRepo.GetTicketTypesByEvent(eventID, false)
+}
+
+func (s *TicketTypeService) GetTicketType(id uuid.UUID) (*model.TicketType, error) {
+	ticketType, err := s.ticketTypeRepo.GetTicketTypeByID(id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrTicketTypeNotFound
+		}
+		return nil, err
+	}
+	return ticketType, nil
+}
+
+func (s *TicketTypeService) getForEvent(eventID, id uuid.UUID) (*model.TicketType, error) {
+	ticketType, err := s.GetTicketType(id)
+	if err != nil {
+		return nil, err
+	}
+	if ticketType.EventID != eventID {
+		return nil, ErrTicketTypeNotFound
+	}
+	return ticketType, nil
+}
+
+// apply copies the request onto the ticket type and validates it against the
+// event schedule. Venue capacity is checked when the ticket type is saved.
+func (s *TicketTypeService) apply(event *model.Event, ticketType *model.TicketType, req *model.CreateTicketTypeRequest) error {
+	if event.Status == model.EventStatusCancelled || event.Status == model.EventStatusFinished {
+		return errors.New("event yang sudah dibatalkan atau selesai tidak dapat diubah")
+	}
+
+	ticketType.Name = req.Name
+	ticketType.Description = req.Description
+	ticketType.Price = *req.Price
+	ticketType.Currency = req.Currency
+	ticketType.Quota = req.Quota
+	ticketType.MinPerOrder = req.MinPerOrder
+	ticketType.MaxPerOrder = req.MaxPerOrder
+	ticketType.SalesStart = req.SalesStart
+	ticketType.SalesEnd = req.SalesEnd
+	ticketType.Visibility = req.Visibility
+	ticketType.SortOrder = req.SortOrder
+
+	if ticketType.Currency == "" {
+		ticketType.Currency = model.DefaultCurrency
+	}
+	if ticketType.MinPerOrder == 0 {
+		ticketType.MinPerOrder = 1
+	}
+	if ticketType.MaxPerOrder == 0 {
+		ticketType.MaxPerOrder = 4
+	}
+	if ticketType.Visibility == "" {
+		ticketType.Visibility = model.TicketVisibilityPublic
+	}
+
+	if ticketType.MaxPerOrder < ticketType.MinPerOrder {
+		return errors.New("maksimal per order tidak boleh lebih kecil dari minimal per order")
+	}
+	if ticketType.MinPerOrder > ticketType.Quota {
+		return errors.New("minimal per order tidak boleh melebihi kuota")
+	}
+	if ticketType.SalesEnd.After(event.EndTime) {
+		return errors.New("periode penjualan tidak boleh melewati waktu selesai event")
+	}
+
+	return nil
+}
+
+// capacityError turns a repository capacity failure into a message for the
+// organizer.
+func capacityError(err error) error {
+	var capErr *repository.CapacityError
+	if !errors.As(err, &capErr) {
+		return err
+	}
+	return fmt.Errorf("total kuota tiket (%d) melebihi kapasitas venue (%d)", capErr.Total, capErr.Capacity)
+}
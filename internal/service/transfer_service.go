@@ -0,0 +1,518 @@
+package service
+
+import (
+	"database/sql"
+	"e-ticketing/config"
+	"e-ticketing/internal/model"
+	"e-ticketing/internal/repository"
+	"e-ticketing/pkg/utils"
+	"errors"
+	"fmt"
+	"html"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// transferOfferDuration is how long a recipient has to accept, unless the
+// transfer window closes earlier.
+const transferOfferDuration = 72 * time.Hour
+
+// TransferService moves tickets between users. Accepting a transfer voids the
+// sender's ticket and issues the recipient a new one with a fresh code, so
+// QR codes the sender kept stop working.
+type TransferService struct {
+	transferRepo *repository.TransferRepository
+	userRepo     *repository.UserRepository
+	ticketSvc    *TicketService
+	eventSvc     *EventService
+	attendeeSvc  *AttendeeService
+	notifSvc     *NotificationService
+	config       *config.Config
+}
+
+func NewTransferService(transferRepo *repository.TransferRepository, userRepo *repository.UserRepository, ticketSvc *TicketService, eventSvc *EventService, attendeeSvc *AttendeeService, notifSvc *NotificationService, cfg *config.Config) *TransferService {
+	return &TransferService{
+		transferRepo: transferRepo,
+		userRepo:     userRepo,
+		ticketSvc:    ticketSvc,
+		eventSvc:     eventSvc,
+		attendeeSvc:  attendeeSvc,
+		notifSvc:     notifSvc,
+		config:       cfg,
+	}
+}
+
+func (s *TransferService) ConfigurePolicy(actor model.Actor, eventID uuid.UUID, req *model.ConfigureTransferPolicyRequest) (*model.TransferPolicy, error) {
+	event, err := s.eventSvc.AuthorizeEvent(actor, eventID)
+	if err != nil {
+		return nil, err
+	}
+	if event.Status == model.EventStatusCancelled || event.Status == model.EventStatusFinished {
+		return nil, errors.New("event yang sudah dibatalkan atau selesai tidak dapat diubah")
+	}
+	if req.OpensAt != nil && !req.OpensAt.Before(event.StartTime) {
+		return nil, errors.New("transfer harus dibuka sebelum event dimulai")
+	}
+
+	policy := &model.TransferPolicy{
+		EventID:                 eventID,
+		Enabled:                 *req.Enabled,
+		OpensAt:                 req.OpensAt,
+		CloseMinutesBeforeStart: req.CloseMinutesBeforeStart,
+		MaxTransfersPerTicket:   req.MaxTransfersPerTicket,
+	}
+	if err := s.transferRepo.SavePolicy(policy); err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
+
+// GetPolicy shows the transfer rules of a public event, or of any event to
+// its managers.
+func (s *TransferService) GetPolicy(actor model.Actor, eventID uuid.UUID) (*model.TransferPolicy, error) {
+	managed := false
+	if !actor.IsAnonymous() {
+		if _, err := s.eventSvc.AuthorizeEvent(actor, eventID); err == nil {
+			managed = true
+		}
+	}
+	if !managed {
+		if _, err := s.eventSvc.GetPublicEvent(eventID); err != nil {
+			return nil, err
+		}
+	}
+	return s.policy(eventID)
+}
+
+// CreateTransfer offers the actor's ticket to whoever owns the email or
+// phone number, and tells them about it.
+func (s *TransferService) CreateTransfer(actor model.Actor, ticketID uuid.UUID, req *model.CreateTransferRequest) (*model.TicketTransfer, error) {
+	email := strings.TrimSpace(req.Email)
+	phone := utils.NormalizePhone(req.Phone)
+	if (email == "") == (phone == "") {
+		return nil, errors.New("isi salah satu: email atau nomor telepon penerima")
+	}
+
+	ticket, err := s.ticketSvc.GetMyTicket(actor, ticketID)
+	if err != nil {
+		return nil, err
+	}
+	if ticket.Status != model.TicketStatusValid {
+		return nil, fmt.Errorf("tiket berstatus %s tidak dapat ditransfer", ticket.Status)
+	}
+
+	sender, err := s.userRepo.GetUserByID(actor.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if (email != "" && strings.EqualFold(email, sender.Email)) || (phone != "" && phone == utils.NormalizePhone(sender.Phone)) {
+		return nil, errors.New("tiket tidak dapat ditransfer ke diri sendiri")
+	}
+
+	event, err := s.eventSvc.GetEvent(ticket.EventID)
+	if err != nil {
+		return nil, err
+	}
+	policy, err := s.policy(event.ID)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	closesAt, err := transferWindow(policy, event, now)
+	if err != nil {
+		return nil, err
+	}
+	if policy.MaxTransfersPerTicket > 0 {
+		count, err := s.transferRepo.CountTransfers(ticket.ID)
+		if err != nil {
+			return nil, err
+		}
+		if count >= policy.MaxTransfersPerTicket {
+			return nil, fmt.Errorf("tiket ini sudah mencapai batas %d kali transfer: %w", policy.MaxTransfersPerTicket, ErrForbidden)
+		}
+	}
+
+	expiresAt := now.Add(transferOfferDuration)
+	if closesAt.Before(expiresAt) {
+		expiresAt = closesAt
+	}
+	transfer := &model.TicketTransfer{
+		TicketID:   ticket.ID,
+		EventID:    ticket.EventID,
+		FromUserID: actor.UserID,
+		ToEmail:    email,
+		ToPhone:    phone,
+		Message:    strings.TrimSpace(req.Message),
+		Status:     model.TransferStatusPending,
+		ExpiresAt:  expiresAt,
+	}
+	if err := s.transferRepo.CreateTransfer(transfer); err != nil {
+		if repository.IsUniqueViolation(err) {
+			return nil, fmt.Errorf("tiket ini sedang dalam proses transfer: %w", ErrConflict)
+		}
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("tiket ini sedang dijual di resale: %w", ErrConflict)
+		}
+		return nil, err
+	}
+
+	transfer, err = s.getTransfer(transfer.ID)
+	if err != nil {
+		return nil, err
+	}
+	s.notifyRecipient(transfer, sender)
+	return transfer, nil
+}
+
+// GetTransfer returns a transfer to its sender or its recipient.
+func (s *TransferService) GetTransfer(actor model.Actor, id uuid.UUID) (*model.TicketTransfer, error) {
+	transfer, err := s.getTransfer(id)
+	if err != nil {
+		return nil, err
+	}
+	if transfer.FromUserID == actor.UserID {
+		return transfer, nil
+	}
+	user, err := s.userRepo.GetUserByID(actor.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if !isRecipient(transfer, user) {
+		return nil, ErrTransferNotFound
+	}
+	return transfer, nil
+}
+
+func (s *TransferService) ListTransfers(actor model.Actor, req *model.ListTransfersRequest) (*model.PaginatedResponse, error) {
+	req.Normalize()
+
+	user, err := s.userRepo.GetUserByID(actor.UserID)
+	if err != nil {
+		return nil, err
+	}
+	filter := &model.TransferFilter{
+		UserID:    actor.UserID,
+		Direction: req.Direction,
+		Status:    req.Status,
+		Limit:     req.Limit,
+		Offset:    req.Offset(),
+	}
+	// Only verified contacts may claim transfers addressed to them
+	if user.IsVerified {
+		filter.Email = user.Email
+		filter.Phone = utils.NormalizePhone(user.Phone)
+	}
+
+	transfers, total, err := s.transferRepo.ListTransfers(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.PaginatedResponse{
+		Items: transfers,
+		Pagination: model.PaginationMeta{
+			Page:  req.Page,
+			Limit: req.Limit,
+			Total: total,
+		},
+	}, nil
+}
+
+// AcceptTransfer issues the recipient a new ticket and voids the sender's.
+// The recipient must have a verified account matching the email or phone the
+// ticket was sent to.
+func (s *TransferService) AcceptTransfer(actor model.Actor, id uuid.UUID, req *model.AcceptTransferRequest) (*model.Ticket, error) {
+	transfer, user, err := s.incoming(actor, id)
+	if err != nil {
+		return nil, err
+	}
+	if !user.IsVerified {
+		return nil, errors.New("akun penerima harus terverifikasi sebelum menerima tiket")
+	}
+
+	event, err := s.eventSvc.GetEvent(transfer.EventID)
+	if err != nil {
+		return nil, err
+	}
+	policy, err := s.policy(event.ID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := transferWindow(policy, event, time.Now()); err != nil {
+		return nil, err
+	}
+
+	attendee, err := s.attendeeSvc.PrepareAttendee(event.ID, req.Attendee, 0)
+	if err != nil {
+		return nil, err
+	}
+	holderName := user.Name
+	if attendee != nil && attendee.Name != "" {
+		holderName = attendee.Name
+	}
+
+	newTicket := &model.Ticket{UserID: user.ID, HolderName: holderName}
+	for attempt := 1; ; attempt++ {
+		newTicket.Code = utils.GenerateTicketCode()
+		ok, err := s.transferRepo.AcceptTransfer(transfer, newTicket, attendee)
+		if repository.IsTicketCodeConflict(err) && attempt < issueAttempts {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, fmt.Errorf("transfer atau tiket sudah berubah: %w", ErrConflict)
+		}
+		break
+	}
+
+	s.notifySender(transfer, user)
+	return s.ticketSvc.GetTicket(newTicket.ID)
+}
+
+// DeclineTransfer lets the recipient turn the ticket down; the sender keeps it.
+func (s *TransferService) DeclineTransfer(actor model.Actor, id uuid.UUID) (*model.TicketTransfer, error) {
+	transfer, _, err := s.incoming(actor, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.close(transfer, model.TransferStatusDeclined)
+}
+
+// CancelTransfer withdraws a pending transfer made by the actor.
+func (s *TransferService) CancelTransfer(actor model.Actor, id uuid.UUID) (*model.TicketTransfer, error) {
+	transfer, err := s.getTransfer(id)
+	if err != nil {
+		return nil, err
+	}
+	if transfer.FromUserID != actor.UserID {
+		return nil, ErrTransferNotFound
+	}
+	return s.close(transfer, model.TransferStatusCancelled)
+}
+
+// TicketHistory returns the ownership chain of one of the actor's tickets.
+func (s *TransferService) TicketHistory(actor model.Actor, ticketID uuid.UUID) ([]model.TicketOwnership, error) {
+	ticket, err := s.ticketSvc.GetMyTicket(actor, ticketID)
+	if err != nil {
+		return nil, err
+	}
+	return s.transferRepo.OwnershipHistory(ticket.ID)
+}
+
+// EventTicketHistory returns the ownership chain of any ticket of an event
+// the actor manages.
+func (s *TransferService) EventTicketHistory(actor model.Actor, eventID, ticketID uuid.UUID) ([]model.TicketOwnership, error) {
+	if _, err := s.eventSvc.AuthorizeEvent(actor, eventID); err != nil {
+		return nil, err
+	}
+	ticket, err := s.ticketSvc.GetTicket(ticketID)
+	if err != nil {
+		return nil, err
+	}
+	if ticket.EventID != eventID {
+		return nil, ErrTicketNotFound
+	}
+	return s.transferRepo.OwnershipHistory(ticket.ID)
+}
+
+// StartExpirySweeper expires unanswered transfers until stop is closed.
+func (s *TransferService) StartExpirySweeper(interval time.Duration, stop <-chan struct{}) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				expired, err := s.transferRepo.ExpireTransfers(time.Now())
+				if err != nil {
+					log.Printf("Failed to expire ticket transfers: %v", err)
+				} else if expired > 0 {
+					log.Printf("Expired %d ticket transfers", expired)
+				}
+			case <-stop:
+				return
+			}
+		}
+	}()
+}
+
+// incoming loads a pending transfer addressed to the actor.
+func (s *TransferService) incoming(actor model.Actor, id uuid.UUID) (*model.TicketTransfer, *model.User, error) {
+	transfer, err := s.getTransfer(id)
+	if err != nil {
+		return nil, nil, err
+	}
+	user, err := s.userRepo.GetUserByID(actor.UserID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if transfer.FromUserID == user.ID || !isRecipient(transfer, user) {
+		return nil, nil, ErrTransferNotFound
+	}
+	if transfer.Status != model.TransferStatusPending || !transfer.ExpiresAt.After(time.Now()) {
+		return nil, nil, fmt.Errorf("transfer sudah tidak dapat diproses: %w", ErrConflict)
+	}
+	return transfer, user, nil
+}
+
+func (s *TransferService) close(transfer *model.TicketTransfer, status string) (*model.TicketTransfer, error) {
+	closed, err := s.transferRepo.CloseTransfer(transfer.ID, status)
+	if err != nil {
+		return nil, err
+	}
+	if !closed {
+		return nil, fmt.Errorf("transfer sudah tidak dapat diproses: %w", ErrConflict)
+	}
+	return s.getTransfer(transfer.ID)
+}
+
+func (s *TransferService) getTransfer(id uuid.UUID) (*model.TicketTransfer, error) {
+	transfer, err := s.transferRepo.GetTransfer(id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrTransferNotFound
+		}
+		return nil, err
+	}
+	return transfer, nil
+}
+
+// policy returns the event's policy, or the default: enabled until the event
+// starts, without a limit.
+func (s *TransferService) policy(eventID uuid.UUID) (*model.TransferPolicy, error) {
+	policy, err := s.transferRepo.GetPolicy(eventID)
+	if err == sql.ErrNoRows {
+		return &model.TransferPolicy{EventID: eventID, Enabled: true}, nil
+	}
+	return policy, err
+}
+
+// transferWindow checks that transfers are open at now and returns when
+// they close.
+func transferWindow(policy *model.TransferPolicy, event *model.Event, now time.Time) (time.Time, error) {
+	if !policy.Enabled {
+		return time.Time{}, fmt.Errorf("event ini tidak mengizinkan transfer tiket: %w", ErrForbidden)
+	}
+	if event.Status == model.EventStatusCancelled || event.Status == model.EventStatusFinished {
+		return time.Time{}, fmt.Errorf("tiket event berstatus %s tidak dapat ditransfer", event.Status)
+	}
+	if policy.OpensAt != nil && now.Before(*policy.OpensAt) {
+		return time.Time{}, fmt.Errorf("transfer tiket baru dibuka pada %s", policy.OpensAt.Format("2006-01-02 15:04"))
+	}
+	closesAt := event.StartTime.Add(-time.Duration(policy.CloseMinutesBeforeStart) * time.Minute)
+	if !now.Before(closesAt) {
+		return time.Time{}, errors.New("masa transfer tiket untuk event ini sudah ditutup")
+	}
+	return closesAt, nil
+}
+
+func isRecipient(transfer *model.TicketTransfer, user *model.User) bool {
+	if transfer.ToUserID != nil {
+		return *transfer.ToUserID == user.ID
+	}
+	if transfer.ToEmail != "" {
+		return strings.EqualFold(transfer.ToEmail, user.Email)
+	}
+	return transfer.ToPhone == utils.NormalizePhone(user.Phone)
+}
+
+// notifyRecipient is best effort; the transfer also shows up in the
+// recipient's incoming list once they sign in. Recipients without an account
+// get the message on the contact the sender entered.
+func (s *TransferService) notifyRecipient(transfer *model.TicketTransfer, sender *model.User) {
+	recipient := &model.User{Email: transfer.ToEmail, Phone: transfer.ToPhone}
+	if transfer.ToUserID != nil {
+		user, err := s.userRepo.GetUserByID(*transfer.ToUserID)
+		if err != nil {
+			log.Printf("Failed to notify transfer %s recipient: %v", transfer.ID, err)
+			return
+		}
+		recipient = user
+	}
+
+	contact := "email"
+	if transfer.ToPhone != "" {
+		contact = "nomor"
+	}
+	message := ""
+	if transfer.Message != "" {
+		message = fmt.Sprintf("\n\nPesan: %s", transfer.Message)
+	}
+	text := fmt.Sprintf(
+		"Halo!\n\n%s mengirimkan tiket *%s* untuk *%s* kepada Anda.%s\n\nMasuk ke akun E-Ticketing dengan %s ini untuk menerima tiket sebelum %s.\n\n- Tim E-Ticketing",
+		sender.Name, transfer.TicketTypeName, transfer.EventTitle, message, contact, transfer.ExpiresAt.Format("2006-01-02 15:04"),
+	)
+
+	body := fmt.Sprintf(`
+		<html>
+		<body style="font-family: Arial, sans-serif; padding: 20px;">
+			<h2>Anda menerima tiket!</h2>
+			<p><strong>%s</strong> mengirimkan tiket <strong>%s</strong> untuk <strong>%s</strong> kepada Anda.</p>
+			%s
+			<p>Masuk atau daftar di E-Ticketing dengan %s ini, lalu terima tiket sebelum <strong>%s</strong>.</p>
+			<p>Salam,<br>Tim E-Ticketing</p>
+		</body>
+		</html>
+	`, html.EscapeString(sender.Name), html.EscapeString(transfer.TicketTypeName), html.EscapeString(transfer.EventTitle),
+		transferMessageHTML(transfer.Message), contact, transfer.ExpiresAt.Format("2006-01-02 15:04"))
+
+	if err := s.notifSvc.Notify(recipient, &model.Notification{
+		Category:     model.NotificationTransactional,
+		Template:     "ticket_transfer",
+		Subject:      fmt.Sprintf("Tiket %s dari %s", transfer.EventTitle, sender.Name),
+		EmailBody:    body,
+		WhatsAppBody: text,
+	}); err != nil {
+		log.Printf("Failed to notify transfer %s recipient: %v", transfer.ID, err)
+	}
+}
+
+// notifySender tells the sender their ticket was accepted and is no longer
+// valid in their hands.
+func (s *TransferService) notifySender(transfer *model.TicketTransfer, recipient *model.User) {
+	sender, err := s.userRepo.GetUserByID(transfer.FromUserID)
+	if err != nil {
+		log.Printf("Failed to notify transfer %s sender: %v", transfer.ID, err)
+		return
+	}
+
+	body := fmt.Sprintf(`
+		<html>
+		<body style="font-family: Arial, sans-serif; padding: 20px;">
+			<h2>Halo %s!</h2>
+			<p>Tiket <strong>%s</strong> untuk <strong>%s</strong> sudah diterima oleh <strong>%s</strong>.</p>
+			<p>Tiket dan QR code lama Anda sudah tidak berlaku.</p>
+			<p>Salam,<br>Tim E-Ticketing</p>
+		</body>
+		</html>
+	`, html.EscapeString(sender.Name), html.EscapeString(transfer.TicketTypeName), html.EscapeString(transfer.EventTitle),
+		html.EscapeString(recipient.Name))
+
+	text := fmt.Sprintf(
+		"Halo %s!\n\nTiket *%s* untuk *%s* sudah diterima oleh %s. Tiket dan QR code lama Anda sudah tidak berlaku.\n\n- Tim E-Ticketing",
+		sender.Name, transfer.TicketTypeName, transfer.EventTitle, recipient.Name,
+	)
+
+	if err := s.notifSvc.Notify(sender, &model.Notification{
+		Category:     model.NotificationTransactional,
+		Template:     "ticket_transfer_accepted",
+		Subject:      fmt.Sprintf("Tiket %s sudah diterima", transfer.EventTitle),
+		EmailBody:    body,
+		WhatsAppBody: text,
+	}); err != nil {
+		log.Printf("Failed to notify transfer %s sender: %v", transfer.ID, err)
+	}
+}
+
+func transferMessageHTML(message string) string {
+	if message == "" {
+		return ""
+	}
+	return fmt.Sprintf(`<p style="background-color: #f4f4f4; padding: 12px;">%s</p>`, html.EscapeString(message))
+}
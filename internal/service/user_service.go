@@ -0,0 +1,34 @@
+package service
+
+import (
+	"e-ticketing/internal/model"
+	"e-ticketing/internal/repository"
+	"errors"
+
+	"github.com/google/uuid"
+)
+
+type UserService struct {
+	userRepo *repository.UserRepository
+}
+
+func NewUserService(userRepo *repository.UserRepository) *UserService {
+	return &UserService{userRepo: userRepo}
+}
+
+// UpdateUserRole lets an admin grant or revoke a role. The change applies to
+// tokens issued after the next login.
+func (s *UserService) UpdateUserRole(actor model.Actor, userID uuid.UUID, role string) error {
+	if actor.UserID == userID && role != model.RoleAdmin {
+		return errors.New("admin tidak dapat menurunkan role dirinya sendiri")
+	}
+
+	updated, err := s.userRepo.UpdateUserRole(userID, role)
+	if err != nil {
+		return err
+	}
+	if !updated {
+		return ErrUserNotFound
+	}
+	return nil
+}
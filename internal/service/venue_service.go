@@ -0,0 +1,158 @@
+package service
+
+import (
+	"database/sql"
+	"e-ticketing/internal/model"
+	"e-ticketing/internal/repository"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+type VenueService struct {
+	venueRepo     *repository.VenueRepository
+	eventRepo     *repository.EventRepository
+	organizerRepo *repository.OrganizerRepository
+}
+
+func NewVenueService(venueRepo *repository.VenueRepository, eventRepo *repository.EventRepository, organizerRepo *repository.OrganizerRepository) *VenueService {
+	return &VenueService{
+		venueRepo:     venueRepo,
+		eventRepo:     eventRepo,
+		organizerRepo: organizerRepo,
+	}
+}
+
+// CreateVenue is open to admins and to users who manage at least one organizer.
+func (s *VenueService) CreateVenue(actor model.Actor, req *model.CreateVenueRequest) (*model.Venue, error) {
+	if !actor.IsAdmin() {
+		organizers, err := s.organizerRepo.GetOrganizersByOwner(actor.UserID)
+		if err != nil {
+			return nil, err
+		}
+		if len(organizers) == 0 {
+			return nil, fmt.Errorf("hanya organizer yang dapat membuat venue: %w", ErrForbidden)
+		}
+	}
+
+	venue := &model.Venue{CreatedBy: actor.UserID}
+	applyVenueRequest(venue, req)
+
+	if err := s.venueRepo.CreateVenue(venue); err != nil {
+		return nil, err
+	}
+	return venue, nil
+}
+
+func (s *VenueService) GetVenue(id uuid.UUID) (*model.Venue, error) {
+	venue, err := s.venueRepo.GetVenueByID(id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrVenueNotFound
+		}
+		return nil, err
+	}
+	return venue, nil
+}
+
+func (s *VenueService) UpdateVenue(actor model.Actor, id uuid.UUID, req *model.UpdateVenueRequest) (*model.Venue, error) {
+	venue, err := s.authorize(actor, id)
+	if err != nil {
+		return nil, err
+	}
+
+	applyVenueRequest(venue, req)
+
+	// Shrinking a venue must still fit every event held there
+	if err := s.venueRepo.UpdateVenue(venue); err != nil {
+		return nil, capacityError(err)
+	}
+	return venue, nil
+}
+
+func (s *VenueService) DeleteVenue(actor model.Actor, id uuid.UUID) error {
+	if _, err := s.authorize(actor, id); err != nil {
+		return err
+	}
+
+	count, err := s.eventRepo.CountEventsByVenue(id)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return fmt.Errorf("venue masih digunakan oleh %d event: %w", count, ErrConflict)
+	}
+
+	return s.venueRepo.DeleteVenue(id)
+}
+
+func (s *VenueService) ListVenues(req *model.ListVenuesRequest) (*model.PaginatedResponse, error) {
+	req.Normalize()
+
+	return s.list(&model.VenueFilter{
+		City:     req.City,
+		Province: req.Province,
+		Search:   req.Search,
+		Limit:    req.Limit,
+		Offset:   req.Offset(),
+	}, req.PaginationQuery)
+}
+
+func (s *VenueService) NearbyVenues(req *model.NearbyVenuesRequest) (*model.PaginatedResponse, error) {
+	near := req.Geo()
+	if near == nil {
+		return nil, errors.New("lat dan lng wajib diisi")
+	}
+	req.Normalize()
+
+	return s.list(&model.VenueFilter{
+		Near:   near,
+		Limit:  req.Limit,
+		Offset: req.Offset(),
+	}, req.PaginationQuery)
+}
+
+func (s *VenueService) list(filter *model.VenueFilter, page model.PaginationQuery) (*model.PaginatedResponse, error) {
+	venues, total, err := s.venueRepo.ListVenues(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.PaginatedResponse{
+		Items: venues,
+		Pagination: model.PaginationMeta{
+			Page:  page.Page,
+			Limit: page.Limit,
+			Total: total,
+		},
+	}, nil
+}
+
+// authorize returns the venue when the actor created it or is an admin.
+func (s *VenueService) authorize(actor model.Actor, id uuid.UUID) (*model.Venue, error) {
+	venue, err := s.GetVenue(id)
+	if err != nil {
+		return nil, err
+	}
+	if venue.CreatedBy != actor.UserID && !actor.IsAdmin() {
+		return nil, ErrForbidden
+	}
+	return venue, nil
+}
+
+func applyVenueRequest(venue *model.Venue, req *model.CreateVenueRequest) {
+	venue.Name = req.Name
+	venue.Address = req.Address
+	venue.City = req.City
+	venue.Province = req.Province
+	venue.Latitude = *req.Latitude
+	venue.Longitude = *req.Longitude
+	venue.Capacity = req.Capacity
+	venue.Timezone = req.Timezone
+	venue.Facilities = req.Facilities
+	if venue.Facilities == nil {
+		venue.Facilities = []string{}
+	}
+	venue.MapImageURL = req.MapImageURL
+}
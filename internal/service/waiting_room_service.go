@@ -0,0 +1,250 @@
+package service
+
+import (
+	"database/sql"
+	"e-ticketing/config"
+	"e-ticketing/internal/model"
+	"e-ticketing/internal/repository"
+	"e-ticketing/pkg/utils"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	queueTokenPurpose = "queue"
+	// defaultAdmissionWindow is how long an admitted buyer may place holds
+	defaultAdmissionWindow = 15
+)
+
+type WaitingRoomService struct {
+	waitingRoomRepo *repository.WaitingRoomRepository
+	eventSvc        *EventService
+	config          *config.Config
+}
+
+func NewWaitingRoomService(waitingRoomRepo *repository.WaitingRoomRepository, eventSvc *EventService, cfg *config.Config) *WaitingRoomService {
+	return &WaitingRoomService{
+		waitingRoomRepo: waitingRoomRepo,
+		eventSvc:        eventSvc,
+		config:          cfg,
+	}
+}
+
+// ConfigureWaitingRoom puts a queue in front of the event's checkout, or
+// switches it off when Enabled is false.
+func (s *WaitingRoomService) ConfigureWaitingRoom(actor model.Actor, eventID uuid.UUID, req *model.ConfigureWaitingRoomRequest) (*model.WaitingRoom, error) {
+	event, err := s.eventSvc.AuthorizeEvent(actor, eventID)
+	if err != nil {
+		return nil, err
+	}
+	if event.Status == model.EventStatusCancelled || event.Status == model.EventStatusFinished {
+		return nil, errors.New("event yang sudah dibatalkan atau selesai tidak dapat diubah")
+	}
+	if !req.OpensAt.Before(event.EndTime) {
+		return nil, errors.New("waiting room harus dibuka sebelum event selesai")
+	}
+
+	room := &model.WaitingRoom{
+		EventID:                eventID,
+		Enabled:                *req.Enabled,
+		OpensAt:                req.OpensAt,
+		AdmissionRate:          req.AdmissionRate,
+		AdmissionWindowMinutes: req.AdmissionWindowMinutes,
+	}
+	if room.AdmissionWindowMinutes == 0 {
+		room.AdmissionWindowMinutes = defaultAdmissionWindow
+	}
+
+	if err := s.waitingRoomRepo.SaveWaitingRoom(room); err != nil {
+		return nil, err
+	}
+	return room, nil
+}
+
+// GetWaitingRoomStats returns the room with its queue counts.
+func (s *WaitingRoomService) GetWaitingRoomStats(actor model.Actor, eventID uuid.UUID) (*model.WaitingRoomStats, error) {
+	if _, err := s.eventSvc.AuthorizeEvent(actor, eventID); err != nil {
+		return nil, err
+	}
+
+	room, err := s.getRoom(eventID)
+	if err != nil {
+		return nil, err
+	}
+	counts, err := s.waitingRoomRepo.CountEntriesByStatus(eventID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.WaitingRoomStats{
+		WaitingRoom: *room,
+		Waiting:     counts[model.QueueStatusWaiting],
+		Admitted:    counts[model.QueueStatusAdmitted],
+		Expired:     counts[model.QueueStatusExpired],
+	}, nil
+}
+
+// SetPaused stops or resumes admissions; buyers keep their places.
+func (s *WaitingRoomService) SetPaused(actor model.Actor, eventID uuid.UUID, paused bool) (*model.WaitingRoom, error) {
+	if _, err := s.eventSvc.AuthorizeEvent(actor, eventID); err != nil {
+		return nil, err
+	}
+
+	room, err := s.waitingRoomRepo.SetPaused(eventID, paused)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrWaitingRoomNotFound
+		}
+		return nil, err
+	}
+	return room, nil
+}
+
+// JoinQueue places the actor in the event's waiting room. Joining again
+// returns the existing place.
+func (s *WaitingRoomService) JoinQueue(actor model.Actor, eventID uuid.UUID) (*model.QueueTicket, error) {
+	if _, err := s.eventSvc.GetPublicEvent(eventID); err != nil {
+		return nil, err
+	}
+	room, err := s.getRoom(eventID)
+	if err != nil {
+		return nil, err
+	}
+	if !room.Enabled {
+		return nil, errors.New("event ini tidak memakai waiting room")
+	}
+
+	entry, err := s.waitingRoomRepo.JoinQueue(eventID, actor.UserID)
+	if err != nil {
+		return nil, err
+	}
+	return s.queueTicket(room, entry), nil
+}
+
+// GetQueueTicket is what buyers poll for their position.
+func (s *WaitingRoomService) GetQueueTicket(actor model.Actor, eventID uuid.UUID) (*model.QueueTicket, error) {
+	room, err := s.getRoom(eventID)
+	if err != nil {
+		return nil, err
+	}
+
+	entry, err := s.waitingRoomRepo.GetQueueEntry(eventID, actor.UserID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrQueueEntryNotFound
+		}
+		return nil, err
+	}
+	return s.queueTicket(room, entry), nil
+}
+
+// CheckAdmission lets the actor through to checkout when the event has no
+// active waiting room, or when token belongs to their admitted entry.
+func (s *WaitingRoomService) CheckAdmission(actor model.Actor, eventID uuid.UUID, token string) error {
+	room, err := s.waitingRoomRepo.GetWaitingRoom(eventID)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if !room.Enabled {
+		return nil
+	}
+
+	denied := fmt.Errorf("antre di waiting room terlebih dahulu: %w", ErrForbidden)
+	if token == "" {
+		return denied
+	}
+	subject, err := utils.VerifySignedToken(s.config.JWTSecret, token, queueTokenPurpose)
+	if err != nil {
+		return denied
+	}
+	entryID, err := uuid.Parse(subject)
+	if err != nil {
+		return denied
+	}
+
+	// The token only names the entry; admission itself is checked live
+	entry, err := s.waitingRoomRepo.GetQueueEntryByID(entryID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return denied
+		}
+		return err
+	}
+	if entry.UserID != actor.UserID || entry.EventID != eventID {
+		return denied
+	}
+	if entry.Status != model.QueueStatusAdmitted || !entry.AdmissionExpiresAt.After(time.Now()) {
+		return fmt.Errorf("giliran checkout belum tiba atau sudah habis: %w", ErrForbidden)
+	}
+	return nil
+}
+
+// StartAdmitter opens rooms and admits queued buyers at their configured
+// rate until stop is closed.
+func (s *WaitingRoomService) StartAdmitter(interval time.Duration, stop <-chan struct{}) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				now := time.Now()
+				if opened, err := s.waitingRoomRepo.OpenRooms(now); err != nil {
+					log.Printf("Failed to open waiting rooms: %v", err)
+				} else if opened > 0 {
+					log.Printf("Opened %d waiting rooms", opened)
+				}
+				if _, err := s.waitingRoomRepo.AdmitQueued(now); err != nil {
+					log.Printf("Failed to admit queued buyers: %v", err)
+				}
+			case <-stop:
+				return
+			}
+		}
+	}()
+}
+
+func (s *WaitingRoomService) getRoom(eventID uuid.UUID) (*model.WaitingRoom, error) {
+	room, err := s.waitingRoomRepo.GetWaitingRoom(eventID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrWaitingRoomNotFound
+		}
+		return nil, err
+	}
+	return room, nil
+}
+
+// queueTicket signs a token for the entry. It is valid until the admission
+// window closes, or for a day while the buyer is still waiting.
+func (s *WaitingRoomService) queueTicket(room *model.WaitingRoom, entry *model.QueueEntry) *model.QueueTicket {
+	ticket := &model.QueueTicket{QueueEntry: *entry}
+
+	if entry.Position != nil && entry.Status == model.QueueStatusWaiting {
+		ticket.Ahead = *entry.Position - room.AdmittedPosition - 1
+		if ticket.Ahead < 0 {
+			ticket.Ahead = 0
+		}
+		ticket.EstimatedWaitSeconds = (ticket.Ahead + 1) * 60 / int64(room.AdmissionRate)
+	}
+	if entry.Position == nil && time.Now().Before(room.OpensAt) {
+		ticket.EstimatedWaitSeconds = int64(time.Until(room.OpensAt).Seconds())
+	}
+
+	expiresAt := time.Now().Add(24 * time.Hour)
+	if entry.Status == model.QueueStatusAdmitted && entry.AdmissionExpiresAt != nil {
+		expiresAt = *entry.AdmissionExpiresAt
+	}
+	if entry.Status != model.QueueStatusExpired {
+		ticket.Token = utils.GenerateSignedToken(s.config.JWTSecret, entry.ID.String(), queueTokenPurpose, expiresAt)
+	}
+	return ticket
+}
@@ -0,0 +1,394 @@
+package service
+
+import (
+	"database/sql"
+	"e-ticketing/config"
+	"e-ticketing/internal/model"
+	"e-ticketing/internal/repository"
+	"e-ticketing/pkg/utils"
+	"errors"
+	"fmt"
+	"html"
+	"log"
+	"net/url"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const waitlistTokenPurpose = "waitlist"
+
+type WaitlistService struct {
+	waitlistRepo     *repository.WaitlistRepository
+	userRepo         *repository.UserRepository
+	ticketTypeSvc    *TicketTypeService
+	eventSvc         *EventService
+	purchaseLimitSvc *PurchaseLimitService
+	resaleSvc        *ResaleService
+	notifSvc         *NotificationService
+	config           *config.Config
+}
+
+func NewWaitlistService(waitlistRepo *repository.WaitlistRepository, userRepo *repository.UserRepository, ticketTypeSvc *TicketTypeService, eventSvc *EventService, purchaseLimitSvc *PurchaseLimitService, resaleSvc *ResaleService, notifSvc *NotificationService, cfg *config.Config) *WaitlistService {
+	return &WaitlistService{
+		waitlistRepo:     waitlistRepo,
+		userRepo:         userRepo,
+		ticketTypeSvc:    ticketTypeSvc,
+		eventSvc:         eventSvc,
+		purchaseLimitSvc: purchaseLimitSvc,
+		resaleSvc:        resaleSvc,
+		notifSvc:         notifSvc,
+		config:           cfg,
+	}
+}
+
+// JoinWaitlist queues the actor for a ticket type the public can no longer
+// buy enough of. Freed stock is offered to the queue before anyone else.
+func (s *WaitlistService) JoinWaitlist(actor model.Actor, eventID uuid.UUID, req *model.JoinWaitlistRequest) (*model.WaitlistEntry, error) {
+	ticketTypeID, err := uuid.Parse(req.TicketTypeID)
+	if err != nil {
+		return nil, errors.New("ticket type ID tidak valid")
+	}
+
+	user, err := s.userRepo.GetUserByID(actor.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if !user.IsVerified {
+		return nil, errors.New("akun belum terverifikasi")
+	}
+
+	ticketType, err := s.ticketTypeSvc.GetTicketType(ticketTypeID)
+	if err != nil {
+		return nil, err
+	}
+	if ticketType.EventID != eventID {
+		return nil, ErrTicketTypeNotFound
+	}
+	event, err := s.eventSvc.GetEvent(eventID)
+	if err != nil {
+		return nil, err
+	}
+	if err := waitlistOpen(event, ticketType, time.Now()); err != nil {
+		return nil, err
+	}
+	if req.Quantity < ticketType.MinPerOrder || req.Quantity > ticketType.MaxPerOrder {
+		return nil, fmt.Errorf("jumlah tiket harus antara %d dan %d", ticketType.MinPerOrder, ticketType.MaxPerOrder)
+	}
+
+	waiting, err := s.waitlistRepo.WaitingQuantity(ticketTypeID)
+	if err != nil {
+		return nil, err
+	}
+	if ticketType.Available-waiting >= req.Quantity {
+		return nil, errors.New("tiket masih tersedia, silakan beli langsung")
+	}
+
+	entry := &model.WaitlistEntry{
+		EventID:      eventID,
+		TicketTypeID: ticketTypeID,
+		UserID:       actor.UserID,
+		Quantity:     req.Quantity,
+		Status:       model.WaitlistStatusWaiting,
+	}
+	if err := s.waitlistRepo.CreateEntry(entry); err != nil {
+		if repository.IsUniqueViolation(err) {
+			return nil, fmt.Errorf("Anda sudah berada di daftar tunggu tipe tiket ini: %w", ErrConflict)
+		}
+		return nil, err
+	}
+	return s.getEntry(entry.ID)
+}
+
+// EventStats shows the organizer how many buyers wait for each ticket type.
+func (s *WaitlistService) EventStats(actor model.Actor, eventID uuid.UUID) ([]model.WaitlistStats, error) {
+	if _, err := s.eventSvc.AuthorizeEvent(actor, eventID); err != nil {
+		return nil, err
+	}
+	return s.waitlistRepo.Stats(eventID)
+}
+
+func (s *WaitlistService) ListMyEntries(actor model.Actor, req *model.ListWaitlistRequest) (*model.PaginatedResponse, error) {
+	req.Normalize()
+
+	entries, total, err := s.waitlistRepo.ListEntries(&model.WaitlistFilter{
+		UserID: actor.UserID,
+		Status: req.Status,
+		Limit:  req.Limit,
+		Offset: req.Offset(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.PaginatedResponse{
+		Items: entries,
+		Pagination: model.PaginationMeta{
+			Page:  req.Page,
+			Limit: req.Limit,
+			Total: total,
+		},
+	}, nil
+}
+
+// CancelEntry leaves the waitlist. Tickets set aside for an open offer go
+// to the next in line.
+func (s *WaitlistService) CancelEntry(actor model.Actor, id uuid.UUID) (*model.WaitlistEntry, error) {
+	entry, err := s.getEntry(id)
+	if err != nil {
+		return nil, err
+	}
+	if entry.UserID != actor.UserID {
+		return nil, ErrWaitlistEntryNotFound
+	}
+
+	cancelled, err := s.waitlistRepo.CancelEntry(id, actor.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if !cancelled {
+		return nil, errors.New("daftar tunggu sudah tidak aktif")
+	}
+	return s.getEntry(id)
+}
+
+// GetOffer shows the offer behind a link from the offer email. The token
+// alone grants access, so the link can be opened before signing in.
+func (s *WaitlistService) GetOffer(token string) (*model.WaitlistEntry, error) {
+	return s.offerEntry(token)
+}
+
+// ClaimOffer turns the actor's offer into holds to check out as usual, or
+// into a pending order when the offer is a resale listing. Offers of a
+// reserved seating type pick up to the offered number of seats; seats not
+// picked go to the next in line.
+func (s *WaitlistService) ClaimOffer(actor model.Actor, req *model.ClaimWaitlistOfferRequest) (*model.WaitlistClaim, error) {
+	entry, err := s.offerEntry(req.Token)
+	if err != nil {
+		return nil, err
+	}
+	if entry.UserID != actor.UserID {
+		return nil, ErrWaitlistEntryNotFound
+	}
+	now := time.Now()
+	if entry.Status != model.WaitlistStatusOffered || !now.Before(*entry.OfferExpiresAt) {
+		return nil, errors.New("penawaran daftar tunggu sudah tidak berlaku")
+	}
+
+	if entry.ResaleListingID != nil {
+		order, err := s.resaleSvc.BuyListing(actor, *entry.ResaleListingID, &model.BuyResaleListingRequest{
+			Attendee: req.Attendee,
+			DeviceID: req.DeviceID,
+		})
+		if err != nil {
+			return nil, err
+		}
+		if _, err := s.waitlistRepo.ClaimResaleOffer(entry.ID, actor.UserID); err != nil {
+			log.Printf("Failed to mark waitlist entry %s claimed: %v", entry.ID, err)
+		}
+		return &model.WaitlistClaim{Order: order}, nil
+	}
+
+	ticketType, err := s.ticketTypeSvc.GetTicketType(entry.TicketTypeID)
+	if err != nil {
+		return nil, err
+	}
+	event, err := s.eventSvc.GetEvent(entry.EventID)
+	if err != nil {
+		return nil, err
+	}
+	if err := waitlistOpen(event, ticketType, now); err != nil {
+		return nil, err
+	}
+
+	seatIDs := make([]uuid.UUID, 0, len(req.SeatIDs))
+	unique := map[uuid.UUID]bool{}
+	for _, raw := range req.SeatIDs {
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			return nil, errors.New("seat ID tidak valid")
+		}
+		if unique[id] {
+			return nil, errors.New("seat ID tidak boleh duplikat")
+		}
+		unique[id] = true
+		seatIDs = append(seatIDs, id)
+	}
+	quantity := entry.OfferedQuantity
+	if ticketType.ReservedSeating {
+		if len(seatIDs) == 0 {
+			return nil, errors.New("tipe tiket ini memakai tempat duduk bernomor, pilih kursi melalui seat map")
+		}
+		if len(seatIDs) > entry.OfferedQuantity {
+			return nil, fmt.Errorf("maksimal %d kursi untuk penawaran ini", entry.OfferedQuantity)
+		}
+		quantity = len(seatIDs)
+	} else if len(seatIDs) > 0 {
+		return nil, errors.New("tipe tiket ini tidak memakai tempat duduk bernomor")
+	}
+
+	limit, err := s.purchaseLimitSvc.HoldLimit(actor, event.ID, quantity, req.DeviceID)
+	if err != nil {
+		return nil, err
+	}
+
+	hold := &model.InventoryHold{
+		UserID:       actor.UserID,
+		TicketTypeID: entry.TicketTypeID,
+		Status:       model.HoldStatusActive,
+		ExpiresAt:    now.Add(time.Duration(s.config.HoldDurationMinutes) * time.Minute),
+	}
+	ok, err := s.waitlistRepo.ClaimOffer(entry.ID, hold, seatIDs, limit)
+	if err != nil {
+		return nil, holdError(err)
+	}
+	if !ok {
+		return nil, fmt.Errorf("penawaran sudah tidak berlaku atau kursi sudah dipilih pembeli lain: %w", ErrConflict)
+	}
+	return &model.WaitlistClaim{Holds: []model.InventoryHold{*hold}}, nil
+}
+
+// StartOfferSweeper periodically expires unclaimed offers and offers freed
+// stock and new resale listings to the waitlist until stop is closed.
+func (s *WaitlistService) StartOfferSweeper(interval time.Duration, stop <-chan struct{}) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				s.processOffers(time.Now())
+			case <-stop:
+				return
+			}
+		}
+	}()
+}
+
+// processOffers expires offers first so the stock they held goes straight
+// to the next in line.
+func (s *WaitlistService) processOffers(now time.Time) {
+	if expired, err := s.waitlistRepo.ExpireOffers(now); err != nil {
+		log.Printf("Failed to expire waitlist offers: %v", err)
+	} else if expired > 0 {
+		log.Printf("Expired %d waitlist offers", expired)
+	}
+
+	expiresAt := now.Add(time.Duration(s.config.WaitlistOfferMinutes) * time.Minute)
+
+	ticketTypeIDs, err := s.waitlistRepo.TicketTypesToOffer(now)
+	if err != nil {
+		log.Printf("Failed to find freed stock for waitlists: %v", err)
+	}
+	for _, ticketTypeID := range ticketTypeIDs {
+		offered, err := s.waitlistRepo.OfferStock(ticketTypeID, now, expiresAt)
+		if err != nil {
+			log.Printf("Failed to offer ticket type %s to its waitlist: %v", ticketTypeID, err)
+			continue
+		}
+		for _, id := range offered {
+			s.notifyOffer(id)
+		}
+	}
+
+	listings, err := s.waitlistRepo.ListingsToOffer()
+	if err != nil {
+		log.Printf("Failed to find resale listings for waitlists: %v", err)
+	}
+	for i := range listings {
+		id, ok, err := s.waitlistRepo.OfferListing(&listings[i], now, expiresAt)
+		if err != nil {
+			log.Printf("Failed to offer resale listing %s to its waitlist: %v", listings[i].ID, err)
+			continue
+		}
+		if ok {
+			s.notifyOffer(id)
+		}
+	}
+}
+
+// offerEntry loads the entry a waitlist offer token names.
+func (s *WaitlistService) offerEntry(token string) (*model.WaitlistEntry, error) {
+	subject, err := utils.VerifySignedToken(s.config.JWTSecret, token, waitlistTokenPurpose)
+	if err != nil {
+		return nil, errors.New("link penawaran tidak valid atau sudah kedaluwarsa")
+	}
+	id, err := uuid.Parse(subject)
+	if err != nil {
+		return nil, errors.New("link penawaran tidak valid atau sudah kedaluwarsa")
+	}
+	return s.getEntry(id)
+}
+
+func (s *WaitlistService) getEntry(id uuid.UUID) (*model.WaitlistEntry, error) {
+	entry, err := s.waitlistRepo.GetEntry(id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrWaitlistEntryNotFound
+		}
+		return nil, err
+	}
+	return entry, nil
+}
+
+// waitlistOpen reports whether buyers may still wait for the ticket type:
+// the event must be selling, even if marked sold out, within the ticket
+// type's sales window.
+func waitlistOpen(event *model.Event, ticketType *model.TicketType, now time.Time) error {
+	if event.Status != model.EventStatusOnSale && event.Status != model.EventStatusSoldOut {
+		return errors.New("event belum atau tidak sedang dijual")
+	}
+	if !ticketType.IsOnSale(now) {
+		return errors.New("tipe tiket di luar periode penjualan")
+	}
+	return nil
+}
+
+// notifyOffer sends the buyer a link to claim their offer before it
+// expires. Failures are only logged; the offer stays open regardless.
+func (s *WaitlistService) notifyOffer(id uuid.UUID) {
+	entry, err := s.getEntry(id)
+	if err != nil {
+		log.Printf("Failed to notify waitlist offer %s: %v", id, err)
+		return
+	}
+	user, err := s.userRepo.GetUserByID(entry.UserID)
+	if err != nil {
+		log.Printf("Failed to notify waitlist offer %s: %v", id, err)
+		return
+	}
+
+	token := utils.GenerateSignedToken(s.config.JWTSecret, entry.ID.String(), waitlistTokenPurpose, *entry.OfferExpiresAt)
+	link := fmt.Sprintf("%s/api/v1/waitlist/offer?token=%s", s.config.AppBaseURL, url.QueryEscape(token))
+
+	body := fmt.Sprintf(`
+		<html>
+		<body style="font-family: Arial, sans-serif; padding: 20px;">
+			<h2>Halo %s!</h2>
+			<p>Tiket <strong>%s</strong> untuk <strong>%s</strong> yang Anda tunggu kini tersedia: <strong>%d tiket</strong> disisihkan khusus untuk Anda.</p>
+			<p>Ambil penawaran ini sebelum <strong>%s</strong>. Setelah itu tiket akan ditawarkan ke antrean berikutnya.</p>
+			<p style="text-align: center; margin: 30px 0;">
+				<a href="%s" style="background-color: #4CAF50; color: white; padding: 15px 30px; text-decoration: none; border-radius: 5px; font-size: 16px;">Lihat Penawaran</a>
+			</p>
+			<p>Salam,<br>Tim E-Ticketing</p>
+		</body>
+		</html>
+	`, html.EscapeString(user.Name), html.EscapeString(entry.TicketTypeName), html.EscapeString(entry.EventTitle),
+		entry.OfferedQuantity, entry.OfferExpiresAt.Format("2006-01-02 15:04"), html.EscapeString(link))
+
+	text := fmt.Sprintf(
+		"Halo %s!\n\nTiket *%s* untuk *%s* yang Anda tunggu kini tersedia: %d tiket disisihkan khusus untuk Anda sampai %s.\n\nAmbil penawaran: %s\n\n- Tim E-Ticketing",
+		user.Name, entry.TicketTypeName, entry.EventTitle, entry.OfferedQuantity, entry.OfferExpiresAt.Format("2006-01-02 15:04"), link,
+	)
+
+	if err := s.notifSvc.Notify(user, &model.Notification{
+		Category:     model.NotificationTransactional,
+		Template:     "waitlist_offer",
+		Subject:      fmt.Sprintf("Tiket %s tersedia untuk Anda", entry.EventTitle),
+		EmailBody:    body,
+		WhatsAppBody: text,
+	}); err != nil {
+		log.Printf("Failed to notify waitlist offer %s: %v", id, err)
+	}
+}
@@ -3,17 +3,24 @@ package service
 import (
 	"bytes"
 	"e-ticketing/config"
+	"e-ticketing/internal/model"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
+	"time"
 )
 
 type WhatsAppService struct {
 	config *config.Config
+	logSvc *NotificationLogService
+	outbox *DevOutbox
 }
 
-func NewWhatsAppService(cfg *config.Config) *WhatsAppService {
-	return &WhatsAppService{config: cfg}
+// NewWhatsAppService posts to the WhatsApp gateway, or writes to outbox when
+// it is non-nil (development delivery mode).
+func NewWhatsAppService(cfg *config.Config, logSvc *NotificationLogService, outbox *DevOutbox) *WhatsAppService {
+	return &WhatsAppService{config: cfg, logSvc: logSvc, outbox: outbox}
 }
 
 func (s *WhatsAppService) SendOTP(phone, otp, name string) error {
@@ -22,6 +29,35 @@ func (s *WhatsAppService) SendOTP(phone, otp, name string) error {
 		name, otp, s.config.OTPExpiryMinutes,
 	)
 
+	return s.send(phone, "otp", message, map[string]string{"otp": otp})
+}
+
+func (s *WhatsAppService) SendMessage(phone, template, message string) error {
+	return s.send(phone, template, message, nil)
+}
+
+func (s *WhatsAppService) send(phone, template, message string, meta map[string]string) error {
+	startedAt := time.Now()
+
+	if s.outbox != nil {
+		err := s.outbox.Write(&model.OutboxMessage{
+			Channel:   model.ChannelWhatsApp,
+			Template:  template,
+			Recipient: phone,
+			Body:      message,
+			Meta:      meta,
+		})
+		s.logSvc.Record(model.ChannelWhatsApp, template, phone, startedAt, "outbox", err)
+		return err
+	}
+
+	response, err := s.post(phone, message)
+	s.logSvc.Record(model.ChannelWhatsApp, template, phone, startedAt, response, err)
+	return err
+}
+
+// post calls the WhatsApp gateway and returns the raw provider response body.
+func (s *WhatsAppService) post(phone, message string) (string, error) {
 	payload := map[string]interface{}{
 		"target":  phone,
 		"message": message,
@@ -29,12 +65,12 @@ func (s *WhatsAppService) SendOTP(phone, otp, name string) error {
 
 	jsonPayload, err := json.Marshal(payload)
 	if err != nil {
-		return err
+		return "", err
 	}
 
 	req, err := http.NewRequest("POST", s.config.WAAPIUrl, bytes.NewBuffer(jsonPayload))
 	if err != nil {
-		return err
+		return "", err
 	}
 
 	req.Header.Set("Content-Type", "application/json")
@@ -43,13 +79,16 @@ func (s *WhatsAppService) SendOTP(phone, otp, name string) error {
 	client := &http.Client{}
 	resp, err := client.Do(req)
 	if err != nil {
-		return err
+		return "", err
 	}
 	defer resp.Body.Close()
 
+	// One byte over the cap lets Record cut back to a whole character
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxProviderResponseLength+1))
+
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("WhatsApp API error: status %d", resp.StatusCode)
+		return string(body), fmt.Errorf("WhatsApp API error: status %d", resp.StatusCode)
 	}
 
-	return nil
-}
\ No newline at end of file
+	return string(body), nil
+}
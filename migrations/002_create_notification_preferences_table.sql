@@ -0,0 +1,12 @@
+-- Create notification preferences table
+CREATE TABLE IF NOT EXISTS notification_preferences (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    transactional_enabled BOOLEAN DEFAULT TRUE,
+    marketing_opt_in BOOLEAN DEFAULT FALSE,
+    preferred_channel VARCHAR(20) DEFAULT 'email',
+    quiet_hours_start VARCHAR(5),
+    quiet_hours_end VARCHAR(5),
+    timezone VARCHAR(64) DEFAULT 'Asia/Jakarta',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,20 @@
+-- Add user roles
+ALTER TABLE users ADD COLUMN IF NOT EXISTS role VARCHAR(20) NOT NULL DEFAULT 'user';
+
+-- Create notification delivery log table
+CREATE TABLE IF NOT EXISTS notification_logs (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    channel VARCHAR(20) NOT NULL,
+    template VARCHAR(100) NOT NULL,
+    recipient_masked VARCHAR(255) NOT NULL,
+    recipient_hash VARCHAR(64) NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    provider_response TEXT,
+    latency_ms INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Indexes
+CREATE INDEX IF NOT EXISTS idx_notification_logs_recipient_hash ON notification_logs(recipient_hash);
+CREATE INDEX IF NOT EXISTS idx_notification_logs_created_at ON notification_logs(created_at);
+CREATE INDEX IF NOT EXISTS idx_notification_logs_channel_status ON notification_logs(channel, status);
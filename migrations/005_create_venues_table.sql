@@ -0,0 +1,25 @@
+-- Create venues table
+CREATE TABLE IF NOT EXISTS venues (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    created_by UUID NOT NULL REFERENCES users(id) ON DELETE RESTRICT,
+    name VARCHAR(255) NOT NULL,
+    address TEXT NOT NULL,
+    city VARCHAR(100) NOT NULL,
+    province VARCHAR(100) NOT NULL,
+    latitude DOUBLE PRECISION NOT NULL,
+    longitude DOUBLE PRECISION NOT NULL,
+    capacity INTEGER NOT NULL CHECK (capacity > 0),
+    timezone VARCHAR(64) NOT NULL DEFAULT 'Asia/Jakarta',
+    facilities TEXT[] NOT NULL DEFAULT '{}',
+    map_image_url VARCHAR(500),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Link events to venues
+ALTER TABLE events ADD COLUMN IF NOT EXISTS venue_id UUID REFERENCES venues(id) ON DELETE RESTRICT;
+
+-- Indexes
+CREATE INDEX IF NOT EXISTS idx_venues_city ON venues(city);
+CREATE INDEX IF NOT EXISTS idx_venues_location ON venues(latitude, longitude);
+CREATE INDEX IF NOT EXISTS idx_events_venue_id ON events(venue_id);
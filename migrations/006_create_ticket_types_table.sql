@@ -0,0 +1,23 @@
+-- Create ticket types table
+CREATE TABLE IF NOT EXISTS ticket_types (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    event_id UUID NOT NULL REFERENCES events(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    description TEXT,
+    price BIGINT NOT NULL CHECK (price >= 0),
+    currency VARCHAR(3) NOT NULL DEFAULT 'IDR',
+    quota INTEGER NOT NULL CHECK (quota > 0),
+    min_per_order INTEGER NOT NULL DEFAULT 1 CHECK (min_per_order > 0),
+    max_per_order INTEGER NOT NULL DEFAULT 4,
+    sales_start TIMESTAMPTZ NOT NULL,
+    sales_end TIMESTAMPTZ NOT NULL,
+    visibility VARCHAR(20) NOT NULL DEFAULT 'public',
+    sort_order INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT chk_ticket_types_order_limits CHECK (max_per_order >= min_per_order),
+    CONSTRAINT chk_ticket_types_sales_window CHECK (sales_end > sales_start)
+);
+
+-- Indexes
+CREATE INDEX IF NOT EXISTS idx_ticket_types_event_id ON ticket_types(event_id);
@@ -0,0 +1,22 @@
+-- Track available stock per ticket type
+ALTER TABLE ticket_types ADD COLUMN IF NOT EXISTS available INTEGER;
+UPDATE ticket_types SET available = quota WHERE available IS NULL;
+ALTER TABLE ticket_types ALTER COLUMN available SET NOT NULL;
+ALTER TABLE ticket_types DROP CONSTRAINT IF EXISTS chk_ticket_types_available;
+ALTER TABLE ticket_types ADD CONSTRAINT chk_ticket_types_available CHECK (available >= 0 AND available <= quota);
+
+-- Create inventory holds table
+CREATE TABLE IF NOT EXISTS inventory_holds (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    ticket_type_id UUID NOT NULL REFERENCES ticket_types(id) ON DELETE RESTRICT,
+    quantity INTEGER NOT NULL CHECK (quantity > 0),
+    status VARCHAR(20) NOT NULL DEFAULT 'active',
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Indexes
+CREATE INDEX IF NOT EXISTS idx_inventory_holds_user_id ON inventory_holds(user_id);
+CREATE INDEX IF NOT EXISTS idx_inventory_holds_active_expiry ON inventory_holds(expires_at) WHERE status = 'active';
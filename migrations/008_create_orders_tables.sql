@@ -0,0 +1,31 @@
+-- Create orders table
+CREATE TABLE IF NOT EXISTS orders (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    order_number VARCHAR(32) UNIQUE NOT NULL,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE RESTRICT,
+    event_id UUID NOT NULL REFERENCES events(id) ON DELETE RESTRICT,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    total_amount BIGINT NOT NULL CHECK (total_amount >= 0),
+    currency VARCHAR(3) NOT NULL DEFAULT 'IDR',
+    expires_at TIMESTAMP NOT NULL,
+    paid_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create order items table
+CREATE TABLE IF NOT EXISTS order_items (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    order_id UUID NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    ticket_type_id UUID NOT NULL REFERENCES ticket_types(id) ON DELETE RESTRICT,
+    hold_id UUID REFERENCES inventory_holds(id) ON DELETE SET NULL,
+    quantity INTEGER NOT NULL CHECK (quantity > 0),
+    unit_price BIGINT NOT NULL CHECK (unit_price >= 0),
+    subtotal BIGINT NOT NULL CHECK (subtotal >= 0),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Indexes
+CREATE INDEX IF NOT EXISTS idx_orders_user_id ON orders(user_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_orders_open_expiry ON orders(expires_at) WHERE status IN ('pending', 'awaiting_payment');
+CREATE INDEX IF NOT EXISTS idx_order_items_order_id ON order_items(order_id);
@@ -0,0 +1,25 @@
+-- Create payments table
+CREATE TABLE IF NOT EXISTS payments (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    order_id UUID NOT NULL REFERENCES orders(id) ON DELETE RESTRICT,
+    gateway VARCHAR(20) NOT NULL,
+    method VARCHAR(20) NOT NULL,
+    bank VARCHAR(20),
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    amount BIGINT NOT NULL CHECK (amount >= 0),
+    currency VARCHAR(3) NOT NULL DEFAULT 'IDR',
+    external_id VARCHAR(100) UNIQUE NOT NULL,
+    gateway_reference VARCHAR(255),
+    payment_token VARCHAR(255),
+    redirect_url VARCHAR(500),
+    va_number VARCHAR(50),
+    qr_string TEXT,
+    expires_at TIMESTAMP,
+    paid_at TIMESTAMP,
+    last_notification TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Indexes
+CREATE INDEX IF NOT EXISTS idx_payments_order_id ON payments(order_id);
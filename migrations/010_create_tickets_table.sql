@@ -0,0 +1,22 @@
+-- Create tickets table
+CREATE TABLE IF NOT EXISTS tickets (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    code VARCHAR(32) UNIQUE NOT NULL,
+    order_id UUID NOT NULL REFERENCES orders(id) ON DELETE RESTRICT,
+    order_item_id UUID NOT NULL REFERENCES order_items(id) ON DELETE RESTRICT,
+    sequence INTEGER NOT NULL CHECK (sequence > 0),
+    event_id UUID NOT NULL REFERENCES events(id) ON DELETE RESTRICT,
+    ticket_type_id UUID NOT NULL REFERENCES ticket_types(id) ON DELETE RESTRICT,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE RESTRICT,
+    holder_name VARCHAR(255) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'valid',
+    used_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (order_item_id, sequence)
+);
+
+-- Indexes
+CREATE INDEX IF NOT EXISTS idx_tickets_user_id ON tickets(user_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_tickets_order_id ON tickets(order_id);
+CREATE INDEX IF NOT EXISTS idx_tickets_event_id ON tickets(event_id);
@@ -0,0 +1,27 @@
+-- Create gate devices table
+CREATE TABLE IF NOT EXISTS gate_devices (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    event_id UUID NOT NULL REFERENCES events(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    gate VARCHAR(100) NOT NULL,
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    registered_by UUID NOT NULL REFERENCES users(id) ON DELETE RESTRICT,
+    last_seen_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (event_id, name)
+);
+
+-- Create check-ins table; one row per admitted ticket
+CREATE TABLE IF NOT EXISTS check_ins (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    ticket_id UUID UNIQUE NOT NULL REFERENCES tickets(id) ON DELETE RESTRICT,
+    event_id UUID NOT NULL REFERENCES events(id) ON DELETE RESTRICT,
+    gate_device_id UUID NOT NULL REFERENCES gate_devices(id) ON DELETE RESTRICT,
+    scanned_by UUID NOT NULL REFERENCES users(id) ON DELETE RESTRICT,
+    scanned_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Indexes
+CREATE INDEX IF NOT EXISTS idx_gate_devices_event_id ON gate_devices(event_id);
+CREATE INDEX IF NOT EXISTS idx_check_ins_event_id ON check_ins(event_id, gate_device_id);
@@ -0,0 +1,20 @@
+-- Create offline scans table; every scan uploaded by a scanner after working offline
+CREATE TABLE IF NOT EXISTS offline_scans (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    gate_device_id UUID NOT NULL REFERENCES gate_devices(id) ON DELETE RESTRICT,
+    event_id UUID NOT NULL REFERENCES events(id) ON DELETE RESTRICT,
+    ticket_id UUID NOT NULL,
+    client_scan_id VARCHAR(64) NOT NULL,
+    scanned_by UUID NOT NULL REFERENCES users(id) ON DELETE RESTRICT,
+    scanned_at TIMESTAMP NOT NULL,
+    outcome VARCHAR(20) NOT NULL,
+    reason VARCHAR(255),
+    suspicious BOOLEAN NOT NULL DEFAULT FALSE,
+    conflict_device_id UUID REFERENCES gate_devices(id) ON DELETE SET NULL,
+    synced_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (gate_device_id, client_scan_id)
+);
+
+-- Indexes
+CREATE INDEX IF NOT EXISTS idx_offline_scans_ticket_id ON offline_scans(ticket_id);
+CREATE INDEX IF NOT EXISTS idx_offline_scans_suspicious ON offline_scans(event_id, scanned_at DESC) WHERE suspicious;
@@ -0,0 +1,35 @@
+-- Ticket types sold per seat get their quota from the seat map
+ALTER TABLE ticket_types ADD COLUMN IF NOT EXISTS reserved_seating BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- Create seat sections table
+CREATE TABLE IF NOT EXISTS seat_sections (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    event_id UUID NOT NULL REFERENCES events(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    sort_order INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (event_id, name)
+);
+
+-- Create seats table
+CREATE TABLE IF NOT EXISTS seats (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    event_id UUID NOT NULL REFERENCES events(id) ON DELETE CASCADE,
+    section_id UUID NOT NULL REFERENCES seat_sections(id) ON DELETE CASCADE,
+    row_label VARCHAR(20) NOT NULL,
+    number VARCHAR(20) NOT NULL,
+    x DOUBLE PRECISION NOT NULL DEFAULT 0,
+    y DOUBLE PRECISION NOT NULL DEFAULT 0,
+    ticket_type_id UUID NOT NULL REFERENCES ticket_types(id) ON DELETE RESTRICT,
+    status VARCHAR(20) NOT NULL DEFAULT 'available',
+    hold_id UUID REFERENCES inventory_holds(id) ON DELETE SET NULL,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (section_id, row_label, number)
+);
+
+-- Issued tickets carry their seat
+ALTER TABLE tickets ADD COLUMN IF NOT EXISTS seat_id UUID UNIQUE REFERENCES seats(id) ON DELETE RESTRICT;
+
+-- Indexes
+CREATE INDEX IF NOT EXISTS idx_seats_event_id ON seats(event_id);
+CREATE INDEX IF NOT EXISTS idx_seats_hold_id ON seats(hold_id) WHERE hold_id IS NOT NULL;
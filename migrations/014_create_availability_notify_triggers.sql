@@ -0,0 +1,40 @@
+-- Broadcast stock and seat changes on the "availability" channel. NOTIFY is
+-- delivered on commit, so subscribers never see rolled back holds.
+CREATE OR REPLACE FUNCTION notify_ticket_type_availability() RETURNS TRIGGER AS $$
+BEGIN
+    PERFORM pg_notify('availability', json_build_object(
+        'event_id', NEW.event_id,
+        'type', 'ticket_type',
+        'id', NEW.id,
+        'available', NEW.available
+    )::text);
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION notify_seat_availability() RETURNS TRIGGER AS $$
+BEGIN
+    PERFORM pg_notify('availability', json_build_object(
+        'event_id', NEW.event_id,
+        'type', 'seat',
+        'id', NEW.id,
+        'ticket_type_id', NEW.ticket_type_id,
+        'status', NEW.status
+    )::text);
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS ticket_types_availability_notify ON ticket_types;
+CREATE TRIGGER ticket_types_availability_notify
+    AFTER UPDATE OF available ON ticket_types
+    FOR EACH ROW WHEN (OLD.available IS DISTINCT FROM NEW.available)
+    EXECUTE FUNCTION notify_ticket_type_availability();
+
+-- Seat inserts are not broadcast; a replaced seat map sends one "seatmap"
+-- notification instead of one per seat
+DROP TRIGGER IF EXISTS seats_availability_notify ON seats;
+CREATE TRIGGER seats_availability_notify
+    AFTER UPDATE OF status ON seats
+    FOR EACH ROW WHEN (OLD.status IS DISTINCT FROM NEW.status)
+    EXECUTE FUNCTION notify_seat_availability();
@@ -0,0 +1,33 @@
+-- Create waiting rooms table; one per event that queues its on-sale
+CREATE TABLE IF NOT EXISTS waiting_rooms (
+    event_id UUID PRIMARY KEY REFERENCES events(id) ON DELETE CASCADE,
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    paused BOOLEAN NOT NULL DEFAULT FALSE,
+    opens_at TIMESTAMP NOT NULL,
+    admission_rate INTEGER NOT NULL CHECK (admission_rate > 0),
+    admission_window_minutes INTEGER NOT NULL CHECK (admission_window_minutes > 0),
+    -- Early joiners are shuffled once when the room opens
+    shuffled BOOLEAN NOT NULL DEFAULT FALSE,
+    next_position BIGINT NOT NULL DEFAULT 0,
+    admitted_position BIGINT NOT NULL DEFAULT 0,
+    last_admission_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create queue entries table
+CREATE TABLE IF NOT EXISTS queue_entries (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    event_id UUID NOT NULL REFERENCES waiting_rooms(event_id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    position BIGINT,
+    status VARCHAR(20) NOT NULL DEFAULT 'waiting',
+    joined_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    admitted_at TIMESTAMP,
+    admission_expires_at TIMESTAMP,
+    UNIQUE (event_id, user_id)
+);
+
+-- Indexes
+CREATE INDEX IF NOT EXISTS idx_queue_entries_waiting ON queue_entries(event_id, position) WHERE status = 'waiting';
+CREATE INDEX IF NOT EXISTS idx_queue_entries_admitted ON queue_entries(admission_expires_at) WHERE status = 'admitted';
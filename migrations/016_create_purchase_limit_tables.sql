@@ -0,0 +1,36 @@
+-- Create purchase policies table; anti-scalping limits per event, 0 = no limit
+CREATE TABLE IF NOT EXISTS purchase_policies (
+    event_id UUID PRIMARY KEY REFERENCES events(id) ON DELETE CASCADE,
+    max_tickets_per_user INTEGER NOT NULL DEFAULT 0,
+    max_tickets_per_phone INTEGER NOT NULL DEFAULT 0,
+    max_tickets_per_instrument INTEGER NOT NULL DEFAULT 0,
+    require_step_up BOOLEAN NOT NULL DEFAULT FALSE,
+    step_up_window_minutes INTEGER NOT NULL DEFAULT 15,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Last OTP step-up, required before checkout by some policies
+ALTER TABLE users ADD COLUMN IF NOT EXISTS step_up_at TIMESTAMP;
+
+-- Hash of the card or account that paid, when the gateway reports one
+ALTER TABLE payments ADD COLUMN IF NOT EXISTS instrument_hash VARCHAR(64);
+
+-- Create purchase signals table; devices and payment instruments seen per
+-- buyer and event, used to find accounts acting together
+CREATE TABLE IF NOT EXISTS purchase_signals (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    event_id UUID NOT NULL REFERENCES events(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    kind VARCHAR(20) NOT NULL,
+    value_hash VARCHAR(64) NOT NULL,
+    first_seen_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_seen_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (event_id, user_id, kind, value_hash)
+);
+
+-- Indexes
+CREATE INDEX IF NOT EXISTS idx_purchase_signals_value ON purchase_signals(event_id, kind, value_hash);
+CREATE INDEX IF NOT EXISTS idx_payments_instrument_hash ON payments(instrument_hash) WHERE instrument_hash IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_users_phone_normalized
+    ON users ((regexp_replace(regexp_replace(phone, '\D', '', 'g'), '^0', '62')));
@@ -0,0 +1,32 @@
+-- Create attendee requirements table; which holder details an event asks
+-- for at checkout
+CREATE TABLE IF NOT EXISTS attendee_requirements (
+    event_id UUID PRIMARY KEY REFERENCES events(id) ON DELETE CASCADE,
+    require_name BOOLEAN NOT NULL DEFAULT FALSE,
+    require_id_number BOOLEAN NOT NULL DEFAULT FALSE,
+    require_email BOOLEAN NOT NULL DEFAULT FALSE,
+    require_phone BOOLEAN NOT NULL DEFAULT FALSE,
+    require_birth_date BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create order attendees table; the holder of each ticket of an order item,
+-- matched to the ticket by (order_item_id, sequence). ID numbers are stored
+-- encrypted, with a masked copy for display.
+CREATE TABLE IF NOT EXISTS order_attendees (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    order_item_id UUID NOT NULL REFERENCES order_items(id) ON DELETE CASCADE,
+    sequence INTEGER NOT NULL,
+    name VARCHAR(100) NOT NULL DEFAULT '',
+    id_type VARCHAR(20) NOT NULL DEFAULT '',
+    id_number_encrypted TEXT NOT NULL DEFAULT '',
+    id_number_masked VARCHAR(32) NOT NULL DEFAULT '',
+    email VARCHAR(255) NOT NULL DEFAULT '',
+    phone VARCHAR(20) NOT NULL DEFAULT '',
+    birth_date DATE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (order_item_id, sequence),
+    CONSTRAINT chk_order_attendees_id_type CHECK (id_type IN ('', 'ktp', 'passport'))
+);
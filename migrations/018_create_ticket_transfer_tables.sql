@@ -0,0 +1,46 @@
+-- Transfers void the ticket and issue a new one for the same item, sequence
+-- and seat, so those stay unique only among original and live tickets
+ALTER TABLE tickets ADD COLUMN IF NOT EXISTS previous_ticket_id UUID REFERENCES tickets(id) ON DELETE RESTRICT;
+ALTER TABLE tickets DROP CONSTRAINT IF EXISTS tickets_order_item_id_sequence_key;
+ALTER TABLE tickets DROP CONSTRAINT IF EXISTS tickets_seat_id_key;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_tickets_item_sequence ON tickets(order_item_id, sequence) WHERE previous_ticket_id IS NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_tickets_seat_id ON tickets(seat_id) WHERE status <> 'transferred';
+CREATE INDEX IF NOT EXISTS idx_tickets_previous_ticket_id ON tickets(previous_ticket_id) WHERE previous_ticket_id IS NOT NULL;
+
+-- Create transfer policies table; when and how often tickets of an event
+-- may change hands, 0 = no limit
+CREATE TABLE IF NOT EXISTS transfer_policies (
+    event_id UUID PRIMARY KEY REFERENCES events(id) ON DELETE CASCADE,
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    opens_at TIMESTAMP,
+    close_minutes_before_start INTEGER NOT NULL DEFAULT 0 CHECK (close_minutes_before_start >= 0),
+    max_transfers_per_ticket INTEGER NOT NULL DEFAULT 0 CHECK (max_transfers_per_ticket >= 0),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create ticket transfers table
+CREATE TABLE IF NOT EXISTS ticket_transfers (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    ticket_id UUID NOT NULL REFERENCES tickets(id) ON DELETE CASCADE,
+    event_id UUID NOT NULL REFERENCES events(id) ON DELETE CASCADE,
+    from_user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    to_email VARCHAR(255) NOT NULL DEFAULT '',
+    to_phone VARCHAR(20) NOT NULL DEFAULT '',
+    to_user_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    message VARCHAR(280) NOT NULL DEFAULT '',
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    new_ticket_id UUID REFERENCES tickets(id) ON DELETE SET NULL,
+    expires_at TIMESTAMP NOT NULL,
+    responded_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT chk_ticket_transfers_recipient CHECK (to_email <> '' OR to_phone <> '')
+);
+
+-- Indexes; one pending transfer per ticket
+CREATE UNIQUE INDEX IF NOT EXISTS idx_ticket_transfers_pending ON ticket_transfers(ticket_id) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_ticket_transfers_from_user ON ticket_transfers(from_user_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_ticket_transfers_to_email ON ticket_transfers(lower(to_email)) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_ticket_transfers_to_phone ON ticket_transfers(to_phone) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_ticket_transfers_to_user ON ticket_transfers(to_user_id, created_at DESC);
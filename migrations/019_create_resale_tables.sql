@@ -0,0 +1,47 @@
+-- Create resale policies table; resale stays off until the organizer
+-- enables it with a price cap in percent of face value
+CREATE TABLE IF NOT EXISTS resale_policies (
+    event_id UUID PRIMARY KEY REFERENCES events(id) ON DELETE CASCADE,
+    enabled BOOLEAN NOT NULL DEFAULT FALSE,
+    max_price_percent INTEGER NOT NULL DEFAULT 100 CHECK (max_price_percent BETWEEN 1 AND 100),
+    close_minutes_before_start INTEGER NOT NULL DEFAULT 0 CHECK (close_minutes_before_start >= 0),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create resale listings table. order_id is the buyer's order currently
+-- reserving the listing, or the one that bought it.
+CREATE TABLE IF NOT EXISTS resale_listings (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    ticket_id UUID NOT NULL REFERENCES tickets(id) ON DELETE CASCADE,
+    event_id UUID NOT NULL REFERENCES events(id) ON DELETE CASCADE,
+    seller_user_id UUID NOT NULL REFERENCES users(id) ON DELETE RESTRICT,
+    price BIGINT NOT NULL CHECK (price > 0),
+    face_value BIGINT NOT NULL CHECK (face_value >= 0),
+    fee_amount BIGINT NOT NULL CHECK (fee_amount >= 0),
+    payout_amount BIGINT NOT NULL CHECK (payout_amount >= 0),
+    currency VARCHAR(3) NOT NULL DEFAULT 'IDR',
+    status VARCHAR(20) NOT NULL DEFAULT 'active',
+    withdraw_reason VARCHAR(30) NOT NULL DEFAULT '',
+    order_id UUID REFERENCES orders(id) ON DELETE SET NULL,
+    buyer_user_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    new_ticket_id UUID REFERENCES tickets(id) ON DELETE SET NULL,
+    payout_status VARCHAR(20) NOT NULL DEFAULT '',
+    payout_reference VARCHAR(100) NOT NULL DEFAULT '',
+    sold_at TIMESTAMP,
+    withdrawn_at TIMESTAMP,
+    paid_out_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT chk_resale_listings_payout CHECK (fee_amount + payout_amount = price)
+);
+
+-- Resale order items buy a listed ticket instead of new stock
+ALTER TABLE order_items ADD COLUMN IF NOT EXISTS resale_listing_id UUID REFERENCES resale_listings(id) ON DELETE RESTRICT;
+
+-- Indexes; one active listing per ticket
+CREATE UNIQUE INDEX IF NOT EXISTS idx_resale_listings_active ON resale_listings(ticket_id) WHERE status = 'active';
+CREATE INDEX IF NOT EXISTS idx_resale_listings_event ON resale_listings(event_id, price) WHERE status = 'active';
+CREATE INDEX IF NOT EXISTS idx_resale_listings_seller ON resale_listings(seller_user_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_resale_listings_payout ON resale_listings(sold_at) WHERE payout_status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_order_items_resale_listing_id ON order_items(resale_listing_id) WHERE resale_listing_id IS NOT NULL;
@@ -0,0 +1,24 @@
+-- Create waitlist entries table. Offers either set aside offered_quantity of
+-- the ticket type's stock, or reserve one resale listing.
+CREATE TABLE IF NOT EXISTS waitlist_entries (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    event_id UUID NOT NULL REFERENCES events(id) ON DELETE CASCADE,
+    ticket_type_id UUID NOT NULL REFERENCES ticket_types(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    quantity INTEGER NOT NULL CHECK (quantity > 0),
+    status VARCHAR(20) NOT NULL DEFAULT 'waiting',
+    offered_quantity INTEGER NOT NULL DEFAULT 0 CHECK (offered_quantity >= 0 AND offered_quantity <= quantity),
+    resale_listing_id UUID REFERENCES resale_listings(id) ON DELETE SET NULL,
+    offered_at TIMESTAMP,
+    offer_expires_at TIMESTAMP,
+    claimed_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Indexes; one open entry per user and ticket type, served in join order
+CREATE UNIQUE INDEX IF NOT EXISTS idx_waitlist_entries_open ON waitlist_entries(ticket_type_id, user_id) WHERE status IN ('waiting', 'offered');
+CREATE INDEX IF NOT EXISTS idx_waitlist_entries_waiting ON waitlist_entries(ticket_type_id, created_at) WHERE status = 'waiting';
+CREATE INDEX IF NOT EXISTS idx_waitlist_entries_offer_expiry ON waitlist_entries(offer_expires_at) WHERE status = 'offered';
+CREATE INDEX IF NOT EXISTS idx_waitlist_entries_resale_listing ON waitlist_entries(resale_listing_id) WHERE status = 'offered';
+CREATE INDEX IF NOT EXISTS idx_waitlist_entries_user_id ON waitlist_entries(user_id, created_at DESC);
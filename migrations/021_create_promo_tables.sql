@@ -0,0 +1,66 @@
+-- Create promotions table. A promotion holds the discount rules; buyers
+-- redeem it through one of its codes.
+CREATE TABLE IF NOT EXISTS promotions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    event_id UUID NOT NULL REFERENCES events(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    discount_type VARCHAR(20) NOT NULL,
+    discount_value BIGINT NOT NULL CHECK (discount_value > 0),
+    max_discount BIGINT CHECK (max_discount > 0),
+    min_order_amount BIGINT NOT NULL DEFAULT 0 CHECK (min_order_amount >= 0),
+    usage_limit INTEGER CHECK (usage_limit > 0),
+    per_user_limit INTEGER CHECK (per_user_limit > 0),
+    usage_count INTEGER NOT NULL DEFAULT 0 CHECK (usage_count >= 0),
+    starts_at TIMESTAMPTZ,
+    ends_at TIMESTAMPTZ,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT chk_promotions_discount_type CHECK (discount_type IN ('fixed', 'percentage')),
+    CONSTRAINT chk_promotions_percentage CHECK (discount_type <> 'percentage' OR discount_value <= 100),
+    CONSTRAINT chk_promotions_window CHECK (starts_at IS NULL OR ends_at IS NULL OR ends_at > starts_at)
+);
+
+-- Ticket types a promotion applies to; none means all of the event's
+CREATE TABLE IF NOT EXISTS promotion_ticket_types (
+    promotion_id UUID NOT NULL REFERENCES promotions(id) ON DELETE CASCADE,
+    ticket_type_id UUID NOT NULL REFERENCES ticket_types(id) ON DELETE CASCADE,
+    PRIMARY KEY (promotion_id, ticket_type_id)
+);
+
+-- Create promo codes table. Codes are stored uppercase and unique per event;
+-- generated single-use codes have max_uses = 1.
+CREATE TABLE IF NOT EXISTS promo_codes (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    promotion_id UUID NOT NULL REFERENCES promotions(id) ON DELETE CASCADE,
+    event_id UUID NOT NULL REFERENCES events(id) ON DELETE CASCADE,
+    code VARCHAR(50) NOT NULL,
+    max_uses INTEGER CHECK (max_uses > 0),
+    usage_count INTEGER NOT NULL DEFAULT 0 CHECK (usage_count >= 0),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT uq_promo_codes_event_code UNIQUE (event_id, code)
+);
+
+-- Create promo redemptions table. A redemption counts against the limits
+-- while active and is released when its order is cancelled or expires.
+CREATE TABLE IF NOT EXISTS promo_redemptions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    promotion_id UUID NOT NULL REFERENCES promotions(id) ON DELETE CASCADE,
+    promo_code_id UUID NOT NULL REFERENCES promo_codes(id) ON DELETE CASCADE,
+    order_id UUID NOT NULL UNIQUE REFERENCES orders(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    discount_amount BIGINT NOT NULL CHECK (discount_amount >= 0),
+    status VARCHAR(20) NOT NULL DEFAULT 'active',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Discounts on orders; total_amount is what the buyer pays after them
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS discount_amount BIGINT NOT NULL DEFAULT 0 CHECK (discount_amount >= 0);
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS promo_code VARCHAR(50);
+ALTER TABLE order_items ADD COLUMN IF NOT EXISTS discount_amount BIGINT NOT NULL DEFAULT 0 CHECK (discount_amount >= 0);
+
+-- Indexes
+CREATE INDEX IF NOT EXISTS idx_promotions_event_id ON promotions(event_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_promo_codes_promotion_id ON promo_codes(promotion_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_promo_redemptions_user ON promo_redemptions(promotion_id, user_id) WHERE status = 'active';
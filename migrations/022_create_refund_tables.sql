@@ -0,0 +1,53 @@
+-- Create refund policies table; refunds stay off until the organizer enables
+-- them. Requests close deadline_hours_before_start before the event starts
+-- and fee_percent of the refunded amount is kept.
+CREATE TABLE IF NOT EXISTS refund_policies (
+    event_id UUID PRIMARY KEY REFERENCES events(id) ON DELETE CASCADE,
+    enabled BOOLEAN NOT NULL DEFAULT FALSE,
+    deadline_hours_before_start INTEGER NOT NULL DEFAULT 0 CHECK (deadline_hours_before_start >= 0),
+    fee_percent INTEGER NOT NULL DEFAULT 0 CHECK (fee_percent BETWEEN 0 AND 100),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create refunds table. amount is what was paid for the tickets, of which
+-- refund_amount goes back to the buyer after fee_amount.
+CREATE TABLE IF NOT EXISTS refunds (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    order_id UUID NOT NULL REFERENCES orders(id) ON DELETE RESTRICT,
+    event_id UUID NOT NULL REFERENCES events(id) ON DELETE RESTRICT,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE RESTRICT,
+    status VARCHAR(20) NOT NULL DEFAULT 'requested',
+    reason VARCHAR(500) NOT NULL,
+    amount BIGINT NOT NULL CHECK (amount >= 0),
+    fee_amount BIGINT NOT NULL CHECK (fee_amount >= 0),
+    refund_amount BIGINT NOT NULL CHECK (refund_amount >= 0),
+    currency VARCHAR(3) NOT NULL DEFAULT 'IDR',
+    reject_reason VARCHAR(500) NOT NULL DEFAULT '',
+    failure_reason TEXT NOT NULL DEFAULT '',
+    gateway_reference VARCHAR(255) NOT NULL DEFAULT '',
+    reviewed_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    reviewed_at TIMESTAMP,
+    refunded_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT chk_refunds_amount CHECK (fee_amount + refund_amount = amount)
+);
+
+-- Tickets a refund covers and what was paid for each
+CREATE TABLE IF NOT EXISTS refund_items (
+    refund_id UUID NOT NULL REFERENCES refunds(id) ON DELETE CASCADE,
+    ticket_id UUID NOT NULL REFERENCES tickets(id) ON DELETE RESTRICT,
+    amount BIGINT NOT NULL CHECK (amount >= 0),
+    PRIMARY KEY (refund_id, ticket_id)
+);
+
+-- What has been paid back on an order so far
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS refunded_amount BIGINT NOT NULL DEFAULT 0 CHECK (refunded_amount >= 0);
+
+-- Indexes; one open request per order
+CREATE UNIQUE INDEX IF NOT EXISTS idx_refunds_open_order ON refunds(order_id) WHERE status = 'requested';
+CREATE INDEX IF NOT EXISTS idx_refunds_event ON refunds(event_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_refunds_user ON refunds(user_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_refunds_status ON refunds(status, created_at);
+CREATE INDEX IF NOT EXISTS idx_refund_items_ticket ON refund_items(ticket_id);
@@ -0,0 +1,6 @@
+-- Refunds of a whole payment that cannot go towards its order, such as one
+-- that settled after the order expired or a second payment of a paid order.
+-- They have no items, and each payment is refunded at most once.
+ALTER TABLE refunds ADD COLUMN IF NOT EXISTS payment_id UUID REFERENCES payments(id) ON DELETE RESTRICT;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_refunds_payment ON refunds(payment_id) WHERE payment_id IS NOT NULL;
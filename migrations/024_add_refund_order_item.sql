@@ -0,0 +1,6 @@
+-- Refunds of a resale item whose listing could not be delivered after the
+-- order was paid. They have no items, and each order item is refunded at
+-- most once.
+ALTER TABLE refunds ADD COLUMN IF NOT EXISTS order_item_id UUID REFERENCES order_items(id) ON DELETE RESTRICT;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_refunds_order_item ON refunds(order_item_id) WHERE order_item_id IS NOT NULL;
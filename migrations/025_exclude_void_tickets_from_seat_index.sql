@@ -0,0 +1,4 @@
+-- Refunds void the ticket and return its seat for sale, so a void ticket must
+-- not keep the seat from being issued again
+DROP INDEX IF EXISTS idx_tickets_seat_id;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_tickets_seat_id ON tickets(seat_id) WHERE status NOT IN ('transferred', 'void');
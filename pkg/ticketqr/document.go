@@ -0,0 +1,97 @@
+package ticketqr
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"sort"
+)
+
+const documentPrefix = "ETKD1"
+
+// Document is a signed JSON document, such as the offline check-in manifest
+// a scanner downloads before losing connectivity. Payload is the base64url
+// JSON and Signature covers "ETKD1.<payload>".
+type Document struct {
+	KeyID     string `json:"kid"`
+	Payload   string `json:"payload"`
+	Signature string `json:"signature"`
+}
+
+// SignDocument marshals v and signs it with the active key.
+func (s *Signer) SignDocument(v interface{}) (*Document, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	payload := base64.RawURLEncoding.EncodeToString(data)
+	sig := ed25519.Sign(s.key, []byte(documentPrefix+"."+payload))
+	return &Document{
+		KeyID:     s.keyID,
+		Payload:   payload,
+		Signature: base64.RawURLEncoding.EncodeToString(sig),
+	}, nil
+}
+
+// VerifyDocument checks the signature and unmarshals the payload into out.
+func (v *Verifier) VerifyDocument(doc *Document, out interface{}) error {
+	key, ok := v.keys[doc.KeyID]
+	if !ok {
+		return ErrUnknownKey
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(doc.Signature)
+	if err != nil {
+		return ErrMalformed
+	}
+	if !ed25519.Verify(key, []byte(documentPrefix+"."+doc.Payload), sig) {
+		return ErrBadSignature
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(doc.Payload)
+	if err != nil {
+		return ErrMalformed
+	}
+	return json.Unmarshal(data, out)
+}
+
+// EncodeIDSet packs 16-byte IDs (e.g. UUIDs) sorted and concatenated as
+// base64url, less than half the size of a JSON array of UUID strings.
+func EncodeIDSet(ids [][16]byte) string {
+	sorted := make([][16]byte, len(ids))
+	copy(sorted, ids)
+	sort.Slice(sorted, func(i, j int) bool {
+		return bytes.Compare(sorted[i][:], sorted[j][:]) < 0
+	})
+
+	raw := make([]byte, 0, len(sorted)*16)
+	for _, id := range sorted {
+		raw = append(raw, id[:]...)
+	}
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// IDSet is a decoded EncodeIDSet value supporting binary-search lookups.
+type IDSet [][16]byte
+
+func DecodeIDSet(encoded string) (IDSet, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil || len(raw)%16 != 0 {
+		return nil, errors.New("daftar ID tidak valid")
+	}
+
+	set := make(IDSet, len(raw)/16)
+	for i := range set {
+		copy(set[i][:], raw[i*16:])
+	}
+	return set, nil
+}
+
+func (s IDSet) Contains(id [16]byte) bool {
+	i := sort.Search(len(s), func(i int) bool {
+		return bytes.Compare(s[i][:], id[:]) >= 0
+	})
+	return i < len(s) && s[i] == id
+}
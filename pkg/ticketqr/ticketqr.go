@@ -0,0 +1,173 @@
+// Package ticketqr signs and verifies the payload encoded in ticket QR codes.
+//
+// A payload has the form ETK1.<claims>.<signature>, where claims is the
+// base64url JSON of Claims and signature is the base64url Ed25519 signature
+// over "ETK1.<claims>". Claims name the signing key, so a gate scanner that
+// holds the public keys can verify tickets without a database round-trip and
+// keys can be rotated while already issued codes stay valid.
+package ticketqr
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const prefix = "ETK1"
+
+var (
+	ErrMalformed    = errors.New("format QR tiket tidak valid")
+	ErrUnknownKey   = errors.New("kunci penandatangan QR tidak dikenal")
+	ErrBadSignature = errors.New("signature QR tiket tidak valid")
+	ErrExpired      = errors.New("QR tiket sudah kedaluwarsa")
+	ErrNotYetValid  = errors.New("QR tiket belum berlaku")
+)
+
+// Claims is the signed content of a ticket QR code. Timestamps are Unix
+// seconds to keep the code small.
+type Claims struct {
+	KeyID      string `json:"kid"`
+	TicketID   string `json:"tid"`
+	EventID    string `json:"eid"`
+	HolderName string `json:"hn"`
+	IssuedAt   int64  `json:"iat"`
+	ExpiresAt  int64  `json:"exp"`
+}
+
+// Signer creates payloads with a single active key.
+type Signer struct {
+	keyID string
+	key   ed25519.PrivateKey
+}
+
+func NewSigner(keyID string, key ed25519.PrivateKey) (*Signer, error) {
+	if keyID == "" || strings.ContainsAny(keyID, ".") {
+		return nil, errors.New("key ID QR tiket tidak valid")
+	}
+	if len(key) != ed25519.PrivateKeySize {
+		return nil, errors.New("kunci privat QR tiket tidak valid")
+	}
+	return &Signer{keyID: keyID, key: key}, nil
+}
+
+func (s *Signer) KeyID() string {
+	return s.keyID
+}
+
+// Sign fills in the key ID and returns the encoded payload.
+func (s *Signer) Sign(claims Claims) (string, error) {
+	claims.KeyID = s.keyID
+	data, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+
+	signed := prefix + "." + base64.RawURLEncoding.EncodeToString(data)
+	sig := ed25519.Sign(s.key, []byte(signed))
+	return signed + "." + base64.RawURLEncoding.EncodeToString(sig), nil
+}
+
+// Verifier checks payloads against a set of public keys indexed by key ID.
+// It has no dependencies beyond the standard library so scanner apps can
+// embed it.
+type Verifier struct {
+	keys map[string]ed25519.PublicKey
+	// Leeway tolerates clock drift on offline devices
+	Leeway time.Duration
+}
+
+func NewVerifier(keys map[string]ed25519.PublicKey) *Verifier {
+	return &Verifier{keys: keys, Leeway: 5 * time.Minute}
+}
+
+// Verify validates the signature and validity window at now and returns
+// the claims.
+func (v *Verifier) Verify(payload string, now time.Time) (*Claims, error) {
+	parts := strings.Split(payload, ".")
+	if len(parts) != 3 || parts[0] != prefix {
+		return nil, ErrMalformed
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, ErrMalformed
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, ErrMalformed
+	}
+
+	var claims Claims
+	if err := json.Unmarshal(data, &claims); err != nil {
+		return nil, ErrMalformed
+	}
+
+	key, ok := v.keys[claims.KeyID]
+	if !ok {
+		return nil, ErrUnknownKey
+	}
+	if !ed25519.Verify(key, []byte(parts[0]+"."+parts[1]), sig) {
+		return nil, ErrBadSignature
+	}
+
+	if claims.IssuedAt > now.Add(v.Leeway).Unix() {
+		return nil, ErrNotYetValid
+	}
+	if claims.ExpiresAt != 0 && now.Add(-v.Leeway).Unix() > claims.ExpiresAt {
+		return nil, ErrExpired
+	}
+	return &claims, nil
+}
+
+// ParsePrivateKey accepts a base64 (standard or URL) 32-byte seed or 64-byte
+// private key. A 64-byte key must carry the public key of its seed, since
+// signatures are computed with the stored public half.
+func ParsePrivateKey(encoded string) (ed25519.PrivateKey, error) {
+	raw, err := decodeKey(encoded)
+	if err != nil {
+		return nil, err
+	}
+	switch len(raw) {
+	case ed25519.SeedSize:
+		return ed25519.NewKeyFromSeed(raw), nil
+	case ed25519.PrivateKeySize:
+		key := ed25519.NewKeyFromSeed(raw[:ed25519.SeedSize])
+		if !bytes.Equal(key, raw) {
+			return nil, errors.New("kunci publik pada kunci privat Ed25519 tidak cocok dengan seed")
+		}
+		return key, nil
+	}
+	return nil, fmt.Errorf("panjang kunci privat Ed25519 tidak valid: %d byte", len(raw))
+}
+
+// ParsePublicKey accepts a base64 (standard or URL) 32-byte public key.
+func ParsePublicKey(encoded string) (ed25519.PublicKey, error) {
+	raw, err := decodeKey(encoded)
+	if err != nil {
+		return nil, err
+	}
+	if len(raw) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("panjang kunci publik Ed25519 tidak valid: %d byte", len(raw))
+	}
+	return ed25519.PublicKey(raw), nil
+}
+
+// EncodePublicKey is the inverse of ParsePublicKey.
+func EncodePublicKey(key ed25519.PublicKey) string {
+	return base64.StdEncoding.EncodeToString(key)
+}
+
+func decodeKey(encoded string) ([]byte, error) {
+	encoded = strings.TrimSpace(encoded)
+	for _, enc := range []*base64.Encoding{base64.StdEncoding, base64.RawStdEncoding, base64.URLEncoding, base64.RawURLEncoding} {
+		if raw, err := enc.DecodeString(encoded); err == nil {
+			return raw, nil
+		}
+	}
+	return nil, errors.New("kunci Ed25519 harus dalam format base64")
+}
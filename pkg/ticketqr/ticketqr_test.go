@@ -0,0 +1,204 @@
+package ticketqr
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testKey(fill byte) ed25519.PrivateKey {
+	return ed25519.NewKeyFromSeed(bytes.Repeat([]byte{fill}, ed25519.SeedSize))
+}
+
+func testSigner(t *testing.T, keyID string, key ed25519.PrivateKey) *Signer {
+	signer, err := NewSigner(keyID, key)
+	if err != nil {
+		t.Fatalf("new signer: %v", err)
+	}
+	return signer
+}
+
+// replaceSegment swaps the i-th dot-separated part of a payload.
+func replaceSegment(payload string, i int, segment string) string {
+	parts := strings.Split(payload, ".")
+	parts[i] = segment
+	return strings.Join(parts, ".")
+}
+
+func TestVerify(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	signer := testSigner(t, "k1", testKey(1))
+	other := testSigner(t, "k1", testKey(2))
+	unknown := testSigner(t, "k9", testKey(1))
+
+	sign := func(s *Signer, claims Claims) string {
+		payload, err := s.Sign(claims)
+		if err != nil {
+			t.Fatalf("sign: %v", err)
+		}
+		return payload
+	}
+	valid := Claims{TicketID: "t1", EventID: "e1", HolderName: "Budi", IssuedAt: now.Unix(), ExpiresAt: now.Add(time.Hour).Unix()}
+	payload := sign(signer, valid)
+
+	forged := valid
+	forged.TicketID = "t2"
+	forgedClaims := strings.Split(sign(signer, forged), ".")[1]
+	otherSig := strings.Split(sign(other, valid), ".")[2]
+
+	expired := valid
+	expired.ExpiresAt = now.Add(-2 * time.Minute).Unix()
+	longExpired := valid
+	longExpired.ExpiresAt = now.Add(-10 * time.Minute).Unix()
+	early := valid
+	early.IssuedAt = now.Add(2 * time.Minute).Unix()
+	future := valid
+	future.IssuedAt = now.Add(10 * time.Minute).Unix()
+	noExpiry := valid
+	noExpiry.ExpiresAt = 0
+
+	tests := []struct {
+		name    string
+		payload string
+		leeway  time.Duration
+		wantErr error
+	}{
+		{"valid", payload, 5 * time.Minute, nil},
+		{"no expiry", sign(signer, noExpiry), 0, nil},
+		{"tampered claims", replaceSegment(payload, 1, forgedClaims), 5 * time.Minute, ErrBadSignature},
+		{"tampered signature", replaceSegment(payload, 2, otherSig), 5 * time.Minute, ErrBadSignature},
+		{"unknown kid", sign(unknown, valid), 5 * time.Minute, ErrUnknownKey},
+		{"expired within leeway", sign(signer, expired), 5 * time.Minute, nil},
+		{"expired without leeway", sign(signer, expired), 0, ErrExpired},
+		{"expired beyond leeway", sign(signer, longExpired), 5 * time.Minute, ErrExpired},
+		{"issued early within leeway", sign(signer, early), 5 * time.Minute, nil},
+		{"issued early without leeway", sign(signer, early), 0, ErrNotYetValid},
+		{"issued beyond leeway", sign(signer, future), 5 * time.Minute, ErrNotYetValid},
+		{"empty", "", 5 * time.Minute, ErrMalformed},
+		{"wrong prefix", replaceSegment(payload, 0, "ETK2"), 5 * time.Minute, ErrMalformed},
+		{"missing signature", strings.Join(strings.Split(payload, ".")[:2], "."), 5 * time.Minute, ErrMalformed},
+		{"extra segment", payload + ".x", 5 * time.Minute, ErrMalformed},
+		{"claims not base64", replaceSegment(payload, 1, "!!"), 5 * time.Minute, ErrMalformed},
+		{"signature not base64", replaceSegment(payload, 2, "!!"), 5 * time.Minute, ErrMalformed},
+		{"claims not json", replaceSegment(payload, 1, base64.RawURLEncoding.EncodeToString([]byte("nope"))), 5 * time.Minute, ErrMalformed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verifier := NewVerifier(map[string]ed25519.PublicKey{"k1": testKey(1).Public().(ed25519.PublicKey)})
+			verifier.Leeway = tt.leeway
+
+			claims, err := verifier.Verify(tt.payload, now)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Verify() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && (claims.TicketID != "t1" || claims.KeyID != "k1") {
+				t.Fatalf("Verify() claims = %+v", claims)
+			}
+		})
+	}
+}
+
+func TestDocumentRoundTrip(t *testing.T) {
+	signer := testSigner(t, "k1", testKey(1))
+	verifier := NewVerifier(map[string]ed25519.PublicKey{"k1": testKey(1).Public().(ed25519.PublicKey)})
+
+	type manifest struct {
+		EventID string   `json:"event_id"`
+		Tickets []string `json:"tickets"`
+	}
+	in := manifest{EventID: "e1", Tickets: []string{"t1", "t2"}}
+	doc, err := signer.SignDocument(in)
+	if err != nil {
+		t.Fatalf("sign document: %v", err)
+	}
+
+	var out manifest
+	if err := verifier.VerifyDocument(doc, &out); err != nil {
+		t.Fatalf("verify document: %v", err)
+	}
+	if out.EventID != in.EventID || len(out.Tickets) != 2 || out.Tickets[1] != "t2" {
+		t.Fatalf("VerifyDocument() = %+v, want %+v", out, in)
+	}
+
+	tampered := *doc
+	tampered.Payload = base64.RawURLEncoding.EncodeToString([]byte(`{"event_id":"e2"}`))
+	if err := verifier.VerifyDocument(&tampered, &out); !errors.Is(err, ErrBadSignature) {
+		t.Fatalf("tampered payload error = %v, want %v", err, ErrBadSignature)
+	}
+
+	unknown := *doc
+	unknown.KeyID = "k9"
+	if err := verifier.VerifyDocument(&unknown, &out); !errors.Is(err, ErrUnknownKey) {
+		t.Fatalf("unknown kid error = %v, want %v", err, ErrUnknownKey)
+	}
+}
+
+func TestIDSet(t *testing.T) {
+	ids := [][16]byte{{9}, {1}, {5, 5}, {0xff}}
+	set, err := DecodeIDSet(EncodeIDSet(ids))
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(set) != len(ids) {
+		t.Fatalf("len(set) = %d, want %d", len(set), len(ids))
+	}
+
+	tests := []struct {
+		id   [16]byte
+		want bool
+	}{
+		{[16]byte{1}, true},
+		{[16]byte{5, 5}, true},
+		{[16]byte{9}, true},
+		{[16]byte{0xff}, true},
+		{[16]byte{}, false},
+		{[16]byte{5}, false},
+		{[16]byte{0xff, 1}, false},
+	}
+	for _, tt := range tests {
+		if got := set.Contains(tt.id); got != tt.want {
+			t.Errorf("Contains(%x) = %v, want %v", tt.id, got, tt.want)
+		}
+	}
+
+	empty, err := DecodeIDSet(EncodeIDSet(nil))
+	if err != nil || empty.Contains([16]byte{1}) {
+		t.Fatalf("empty set = %v, %v", empty, err)
+	}
+	if _, err := DecodeIDSet(base64.RawURLEncoding.EncodeToString(make([]byte, 15))); err == nil {
+		t.Fatal("DecodeIDSet accepted a partial ID")
+	}
+}
+
+func TestParsePrivateKey(t *testing.T) {
+	key := testKey(1)
+	mismatched := append(append([]byte{}, key.Seed()...), testKey(2).Public().(ed25519.PublicKey)...)
+
+	tests := []struct {
+		name    string
+		encoded string
+		wantErr bool
+	}{
+		{"seed", base64.StdEncoding.EncodeToString(key.Seed()), false},
+		{"private key", base64.RawURLEncoding.EncodeToString(key), false},
+		{"mismatched public half", base64.StdEncoding.EncodeToString(mismatched), true},
+		{"wrong length", base64.StdEncoding.EncodeToString(make([]byte, 16)), true},
+		{"not base64", "!!", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParsePrivateKey(tt.encoded)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParsePrivateKey() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !got.Equal(key) {
+				t.Fatal("ParsePrivateKey() returned a different key")
+			}
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"unicode/utf8"
+)
+
+// MaskEmail keeps the first character of the local part and the domain,
+// e.g. john@example.com -> j***@example.com.
+func MaskEmail(email string) string {
+	at := strings.LastIndex(email, "@")
+	if at <= 0 {
+		return MaskString(email, 1, 0)
+	}
+	_, size := utf8.DecodeRuneInString(email)
+	return email[:size] + "***" + email[at:]
+}
+
+// MaskPhone keeps the first four and last three digits.
+func MaskPhone(phone string) string {
+	return MaskString(phone, 4, 3)
+}
+
+// MaskString replaces everything but the first keepStart and last keepEnd
+// characters with asterisks.
+func MaskString(value string, keepStart, keepEnd int) string {
+	runes := []rune(value)
+	if len(runes) <= keepStart+keepEnd {
+		return strings.Repeat("*", len(runes))
+	}
+	return string(runes[:keepStart]) + strings.Repeat("*", len(runes)-keepStart-keepEnd) + string(runes[len(runes)-keepEnd:])
+}
+
+// HashIdentifier returns a stable HMAC-SHA256 hex digest of a normalized
+// email, phone number or similar identifier, so it can be searched without
+// storing the raw value. The key keeps short values such as phone numbers
+// from being brute-forced out of a leaked hash.
+func HashIdentifier(key, value string) string {
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write([]byte(strings.ToLower(strings.TrimSpace(value))))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// TruncateString cuts value to at most maxBytes without splitting a
+// multi-byte character.
+func TruncateString(value string, maxBytes int) string {
+	if len(value) <= maxBytes {
+		return value
+	}
+	cut := maxBytes
+	for cut > 0 && !utf8.RuneStart(value[cut]) {
+		cut--
+	}
+	return value[:cut]
+}
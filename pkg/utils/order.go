@@ -0,0 +1,20 @@
+package utils
+
+import (
+	"crypto/rand"
+	"math/big"
+	"time"
+)
+
+const orderNumberAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+// GenerateOrderNumber returns a human-friendly order number such as
+// ORD-20260101-7KQ2M9XA. Ambiguous characters (0/O, 1/I) are left out.
+func GenerateOrderNumber() string {
+	suffix := make([]byte, 8)
+	for i := range suffix {
+		num, _ := rand.Int(rand.Reader, big.NewInt(int64(len(orderNumberAlphabet))))
+		suffix[i] = orderNumberAlphabet[num.Int64()]
+	}
+	return "ORD-" + time.Now().Format("20060102") + "-" + string(suffix)
+}
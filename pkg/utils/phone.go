@@ -0,0 +1,18 @@
+package utils
+
+import "strings"
+
+// NormalizePhone keeps the digits of a phone number and turns a leading 0
+// into the 62 country code, so +62 812-..., 0812... and 62812... compare equal.
+func NormalizePhone(phone string) string {
+	digits := strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return r
+		}
+		return -1
+	}, phone)
+	if strings.HasPrefix(digits, "0") {
+		digits = "62" + digits[1:]
+	}
+	return digits
+}
@@ -0,0 +1,72 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+)
+
+// SignedTokenPayload is the body of a stateless HMAC token. Purpose keeps a
+// token minted for one flow (e.g. unsubscribe) from being replayed in another.
+type SignedTokenPayload struct {
+	Subject   string `json:"sub"`
+	Purpose   string `json:"pur"`
+	ExpiresAt int64  `json:"exp,omitempty"`
+}
+
+var ErrInvalidSignedToken = errors.New("token tidak valid atau sudah expired")
+
+// GenerateSignedToken returns base64url(payload).base64url(hmac). A zero
+// expiresAt produces a token that never expires.
+func GenerateSignedToken(secret, subject, purpose string, expiresAt time.Time) string {
+	payload := SignedTokenPayload{Subject: subject, Purpose: purpose}
+	if !expiresAt.IsZero() {
+		payload.ExpiresAt = expiresAt.Unix()
+	}
+
+	body, _ := json.Marshal(payload)
+	encoded := base64.RawURLEncoding.EncodeToString(body)
+	return encoded + "." + signSegment(secret, encoded)
+}
+
+// VerifySignedToken checks the signature, purpose and expiry and returns the
+// token subject.
+func VerifySignedToken(secret, token, purpose string) (string, error) {
+	parts := strings.SplitN(token, ".", 2)
+	if len(parts) != 2 {
+		return "", ErrInvalidSignedToken
+	}
+
+	if !hmac.Equal([]byte(parts[1]), []byte(signSegment(secret, parts[0]))) {
+		return "", ErrInvalidSignedToken
+	}
+
+	body, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return "", ErrInvalidSignedToken
+	}
+
+	var payload SignedTokenPayload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return "", ErrInvalidSignedToken
+	}
+
+	if payload.Purpose != purpose {
+		return "", ErrInvalidSignedToken
+	}
+	if payload.ExpiresAt != 0 && time.Now().Unix() > payload.ExpiresAt {
+		return "", ErrInvalidSignedToken
+	}
+
+	return payload.Subject, nil
+}
+
+func signSegment(secret, segment string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(segment))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,70 @@
+package utils
+
+import (
+	"crypto/rand"
+	"math/big"
+	"strings"
+)
+
+const ticketCodeLength = 15
+
+// GenerateTicketCode returns an unguessable, human-readable ticket code such
+// as 7KQ2-M9XA-HT4C-PW3E. It uses the order number alphabet (75 random bits)
+// and ends with a Luhn mod 32 check character so typos are caught before a
+// lookup.
+func GenerateTicketCode() string {
+	code := make([]byte, ticketCodeLength, ticketCodeLength+1)
+	for i := range code {
+		num, _ := rand.Int(rand.Reader, big.NewInt(int64(len(orderNumberAlphabet))))
+		code[i] = orderNumberAlphabet[num.Int64()]
+	}
+	code = append(code, ticketCheckChar(code))
+	return FormatTicketCode(string(code))
+}
+
+// NormalizeTicketCode uppercases a typed or scanned code and drops the
+// display separators.
+func NormalizeTicketCode(code string) string {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	return strings.NewReplacer("-", "", " ", "").Replace(code)
+}
+
+// ValidTicketCode reports whether a normalized code has the right length,
+// alphabet and check character.
+func ValidTicketCode(code string) bool {
+	if len(code) != ticketCodeLength+1 {
+		return false
+	}
+	for i := 0; i < len(code); i++ {
+		if strings.IndexByte(orderNumberAlphabet, code[i]) < 0 {
+			return false
+		}
+	}
+	return ticketCheckChar([]byte(code[:ticketCodeLength])) == code[ticketCodeLength]
+}
+
+// FormatTicketCode re-inserts the display separators into a normalized code.
+func FormatTicketCode(code string) string {
+	groups := make([]string, 0, 4)
+	for i := 0; i < len(code); i += 4 {
+		end := i + 4
+		if end > len(code) {
+			end = len(code)
+		}
+		groups = append(groups, code[i:end])
+	}
+	return strings.Join(groups, "-")
+}
+
+// ticketCheckChar computes the Luhn mod N check character over the alphabet.
+func ticketCheckChar(code []byte) byte {
+	n := len(orderNumberAlphabet)
+	factor := 2
+	sum := 0
+	for i := len(code) - 1; i >= 0; i-- {
+		addend := factor * strings.IndexByte(orderNumberAlphabet, code[i])
+		factor = 3 - factor
+		sum += addend/n + addend%n
+	}
+	return orderNumberAlphabet[(n-sum%n)%n]
+}